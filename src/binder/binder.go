@@ -7,8 +7,11 @@ package binder
 
 import (
 	"fmt"
+	"math"
 	"slices"
 	"strconv"
+	"strings"
+	"unicode"
 
 	"bytespace.network/rerect/boundnodes"
 	"bytespace.network/rerect/compunit"
@@ -20,10 +23,106 @@ import (
 	"bytespace.network/rerect/syntaxnodes"
 )
 
+// --------------------------------------------------------
+// Enum indexing
+// --------------------------------------------------------
+func IndexEnums(comp *compunit.Compilation, file *packageprocessor.CompilationFile) {
+    // look through all member nodes
+    for _, v := range file.Members {
+        // we're only looking for enum nodes
+        if v.Type() != syntaxnodes.NT_Enum {
+            continue
+        }
+
+        enmMem := v.(*syntaxnodes.EnumNode)
+
+        // enums are just ints at runtime
+        typ := symbols.NewTypeSymbol(enmMem.EnumName.Buffer, []*symbols.TypeSymbol{}, symbols.ENUM, 32, int32(0))
+
+        enm := symbols.NewEnumSymbol(file.Package, enmMem.EnumName.Buffer, typ)
+        enm.Doc = enmMem.Doc
+
+        // an enum without any values cant have a default value either
+        if len(enmMem.Members) == 0 {
+            comp.Report(error.NewError(error.BND, enmMem.EnumName.Position, "Enum '%s' needs at least one member!", enm.EnumName))
+            continue
+        }
+
+        // work out the values (no value -> one more than the last one)
+        next := int64(0)
+        for _, mem := range enmMem.Members {
+            value := next
+
+            if mem.HasValue {
+                val, ok := enumMemberValue(comp, mem)
+                if !ok {
+                    continue
+                }
+
+                value = val
+            }
+
+            if value < math.MinInt32 || value > math.MaxInt32 {
+                comp.Report(error.NewError(error.BND, mem.Position(), "Value of enum member '%s' is out of range for type 'int'!", mem.MemberName.Buffer))
+                continue
+            }
+
+            if enm.LookupMember(mem.MemberName.Buffer) != nil {
+                comp.Report(error.NewError(error.BND, mem.MemberName.Position, "Enum member '%s' has already been declared!", mem.MemberName.Buffer))
+                continue
+            }
+
+            if other := enm.LookupValue(int32(value)); other != nil {
+                comp.Report(error.NewError(error.BND, mem.Position(), "Enum member '%s' has the same value as '%s' (%d)!", mem.MemberName.Buffer, other.MemberName, value))
+                continue
+            }
+
+            enm.AddMember(mem.MemberName.Buffer, int32(value), mem.Doc)
+            next = value + 1
+        }
+
+        // register the enum in the package
+        ok := file.Package.TryRegisterEnum(enm)
+
+        if !ok {
+            comp.Report(error.NewError(error.BND, enmMem.EnumName.Position, "Cannot register enum '%s'! A symbol with that name already exists!", enm.EnumName))
+            continue
+        }
+
+        file.Enums = append(file.Enums, enm)
+        file.EnumSrc[enm] = enmMem
+    }
+}
+
+// Reads the explicit value of an enum member (Red <- -1)
+func enumMemberValue(comp *compunit.Compilation, mem *syntaxnodes.EnumMemberClauseNode) (int64, bool) {
+    // let the normal literal binding deal with prefixes and suffixes
+    bin := &Binder{Comp: comp}
+    lit := bin.bindNumberLiteral(syntaxnodes.NewLiteralExpressionNode(mem.Value), mem.Value, mem.IsNegative)
+
+    if lit.Type() != boundnodes.BT_LiteralExpr {
+        return 0, false
+    }
+
+    var value int64
+    switch v := lit.(*boundnodes.BoundLiteralExpressionNode).LiteralValue.(type) {
+    case int64:
+        value = v
+    case int32:
+        value = int64(v)
+    case int16:
+        value = int64(v)
+    case int8:
+        value = int64(v)
+    }
+
+    return value, true
+}
+
 // --------------------------------------------------------
 // Trait indexing
 // --------------------------------------------------------
-func IndexTraitTypes(file *packageprocessor.CompilationFile) {
+func IndexTraitTypes(comp *compunit.Compilation, file *packageprocessor.CompilationFile) {
     // look through all member nodes
     for _, v := range file.Members {
         // we're only looking for trait nodes
@@ -34,16 +133,19 @@ func IndexTraitTypes(file *packageprocessor.CompilationFile) {
         trtMem := v.(*syntaxnodes.TraitNode)
 
         // register a type symbol for this trait 
-        typ := symbols.NewTypeSymbol(trtMem.TraitName.Buffer, []*symbols.TypeSymbol{}, symbols.TRT, 0, nil)
+        // (generic traits carry their type parameters as subtypes)
+        prms := createTypeParameters(comp, trtMem.TypeParameters)
+        typ := symbols.NewTypeSymbol(trtMem.TraitName.Buffer, prms, symbols.TRT, 0, nil)
 
         // register a trait symbol for this trait 
         trt := symbols.NewTraitSymbol(file.Package, trtMem.TraitName.Buffer, typ)
+        trt.Doc = trtMem.Doc
 
         // register the type in the package
         ok := file.Package.TryRegisterTrait(trt) 
 
         if !ok {
-            error.Report(error.NewError(error.BND, trtMem.TraitName.Position, "Cannot register trait '%s'! A symbol with that name already exists!", trt.TraitName))
+            comp.Report(error.NewError(error.BND, trtMem.TraitName.Position, "Cannot register trait '%s'! A symbol with that name already exists!", trt.TraitName))
             continue
         }
 
@@ -52,7 +154,7 @@ func IndexTraitTypes(file *packageprocessor.CompilationFile) {
     }
 }
 
-func IndexTraitContents(file *packageprocessor.CompilationFile) {
+func IndexTraitContents(comp *compunit.Compilation, file *packageprocessor.CompilationFile) {
 
     // work through all traits
     for _, trt := range file.Traits {
@@ -66,17 +168,23 @@ func IndexTraitContents(file *packageprocessor.CompilationFile) {
         // bind all fields
         for _, v := range src.Fields {
             // resolve the field type
-            typ := LookupTypeClause(v.FieldType, file.Package)
+            typ := LookupTypeClause(comp, v.FieldType, file.Package, trt.TypeParameters)
 
             // WAIT A MINUTE, DID WE HAVE A FIELD WITH THIS NAME ALREADY???
             if slices.Contains(trt.Symbols, v.FieldName.Buffer) {
                 // jes -> DIE!!!! >:)
-                error.Report(error.NewError(error.BND, v.Position(), "Cannot register field '%s'! A symbol with that name already exists!", v.FieldName.Buffer))
+                comp.Report(error.NewError(error.BND, v.Position(), "Cannot register field '%s'! A symbol with that name already exists!", v.FieldName.Buffer))
                 continue
             }
 
+            // only containers have a Constructor to set these
+            if v.IsReadonly {
+                comp.Report(error.NewError(error.BND, v.ReadonlyKw.Position, "Trait fields cannot be readonly! (traits dont have a Constructor to set them in)"))
+            }
+
             // nah, we good
             sym := symbols.NewTraitFieldSymbol(trt, v.FieldName.Buffer, typ)
+            sym.Doc = v.Doc
 
             // add it to the list
             fields = append(fields, sym)
@@ -89,7 +197,13 @@ func IndexTraitContents(file *packageprocessor.CompilationFile) {
             // is this a constructor?
             if fncMem.IsConstructor {
                 // cringe
-                error.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Illegal constructor outside of a container!"))
+                comp.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Illegal constructor outside of a container!"))
+                continue
+            }
+
+            // methods get their type parameters from the trait
+            if len(fncMem.TypeParameters) > 0 {
+                comp.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Methods cannot have type parameters of their own! (try giving them to trait '%s' instead)", trt.Name()))
                 continue
             }
 
@@ -99,11 +213,11 @@ func IndexTraitContents(file *packageprocessor.CompilationFile) {
                 prms = append(prms, symbols.NewParameterSymbol(
                     prm.ParameterName.Buffer,
                     i,
-                    LookupTypeClause(prm.ParameterType, file.Package),
+                    LookupTypeClause(comp, prm.ParameterType, file.Package, trt.TypeParameters),
                 ))
             }
 
-            ret := LookupTypeClause(fncMem.ReturnType, file.Package)
+            ret := LookupTypeClause(comp, fncMem.ReturnType, file.Package, trt.TypeParameters)
 
             // register a function symbol for this method
             fnc := symbols.NewMethodSymbol(
@@ -114,6 +228,8 @@ func IndexTraitContents(file *packageprocessor.CompilationFile) {
                 prms,
             )
 
+            fnc.Doc = fncMem.Doc
+
             // if this is just a declaration -> mark this is needing to be called virtually
             if !fncMem.HasBody {
                 fnc.NeedsVirtualCallToContainer = true
@@ -121,7 +237,7 @@ func IndexTraitContents(file *packageprocessor.CompilationFile) {
 
             // okay but like, is this legal?
             if slices.Contains(trt.Symbols, fnc.Name()) {
-                error.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Cannot register method '%s'! A symbol with that name already exists!", fnc.Name()))
+                comp.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Cannot register method '%s'! A symbol with that name already exists!", fnc.Name()))
                 continue
             }
 
@@ -151,7 +267,7 @@ func IndexTraitContents(file *packageprocessor.CompilationFile) {
 // --------------------------------------------------------
 // Container indexing
 // --------------------------------------------------------
-func IndexContainerTypes(file *packageprocessor.CompilationFile) {
+func IndexContainerTypes(comp *compunit.Compilation, file *packageprocessor.CompilationFile) {
     // look through all member nodes
     for _, v := range file.Members {
         // we're only looking for container nodes
@@ -162,10 +278,13 @@ func IndexContainerTypes(file *packageprocessor.CompilationFile) {
         cntMem := v.(*syntaxnodes.ContainerNode)
 
         // register a type symbol for this container
-        typ := symbols.NewTypeSymbol(cntMem.ContainerName.Buffer, []*symbols.TypeSymbol{}, symbols.CONT, 0, nil)
+        // (generic containers carry their type parameters as subtypes)
+        prms := createTypeParameters(comp, cntMem.TypeParameters)
+        typ := symbols.NewTypeSymbol(cntMem.ContainerName.Buffer, prms, symbols.CONT, 0, nil)
 
         // register a container symbol for this container 
         cnt := symbols.NewContainerSymbol(file.Package, cntMem.ContainerName.Buffer, typ)
+        cnt.Doc = cntMem.Doc
 
         // now: look up the traits we got
         for _, v := range cntMem.Traits {
@@ -179,7 +298,7 @@ func IndexContainerTypes(file *packageprocessor.CompilationFile) {
 
                 // aw man no package
                 if pack == nil {
-                    error.Report(error.NewError(error.BND, v.Package.Position, "Could not find a package called '%s'", v.Package.Buffer))
+                    comp.Report(error.NewError(error.BND, v.Package.Position, "Could not find a package called '%s'", v.Package.Buffer))
                     continue
 
                 // aw man a package
@@ -187,7 +306,7 @@ func IndexContainerTypes(file *packageprocessor.CompilationFile) {
                     trt = LookupTraitInPackage(v.TraitName.Buffer, pack)
 
                     if trt == nil {
-                        error.Report(error.NewError(error.BND, v.Position(), "Could not find a trait called '%s' in package '%s'!", v.TraitName.Buffer, v.Package.Buffer))
+                        comp.Report(error.NewError(error.BND, v.Position(), "Could not find a trait called '%s' in package '%s'!", v.TraitName.Buffer, v.Package.Buffer))
                         continue
                     }
                 } 
@@ -197,7 +316,7 @@ func IndexContainerTypes(file *packageprocessor.CompilationFile) {
                 trt = LookupTrait(v.TraitName.Buffer, file.Package)
 
                 if trt == nil {
-                    error.Report(error.NewError(error.BND, v.Position(), "Could not find a trait called '%s'!", v.TraitName.Buffer))
+                    comp.Report(error.NewError(error.BND, v.Position(), "Could not find a trait called '%s'!", v.TraitName.Buffer))
                     continue
                 }
             }
@@ -210,7 +329,7 @@ func IndexContainerTypes(file *packageprocessor.CompilationFile) {
         ok := file.Package.TryRegisterContainer(cnt) 
 
         if !ok {
-            error.Report(error.NewError(error.BND, cntMem.ContainerName.Position, "Cannot register container '%s'! A symbol with that name already exists!", cnt.ContainerName))
+            comp.Report(error.NewError(error.BND, cntMem.ContainerName.Position, "Cannot register container '%s'! A symbol with that name already exists!", cnt.ContainerName))
             continue
         }
 
@@ -219,7 +338,7 @@ func IndexContainerTypes(file *packageprocessor.CompilationFile) {
     }
 }
 
-func IndexContainerContents(file *packageprocessor.CompilationFile) {
+func IndexContainerContents(comp *compunit.Compilation, file *packageprocessor.CompilationFile) {
 
     // work through all containers
     for _, cnt := range file.Containers {
@@ -231,20 +350,33 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
         meths := []*symbols.FunctionSymbol{}
         hasConstructor := false
 
+        // find out what our traits look like
+        // (generic traits need to know their type arguments, which might be our own type parameters)
+        for i, trt := range cnt.Traits {
+            args := []*symbols.TypeSymbol{}
+            for _, v := range src.Traits[i].SubTypes {
+                args = append(args, LookupTypeClause(comp, v, file.Package, cnt.TypeParameters))
+            }
+
+            cnt.TraitTypes = append(cnt.TraitTypes, instantiateType(comp, trt.TraitType, args, src.Traits[i].Position()))
+        }
+
         // bind all fields
         for _, v := range src.Fields {
             // resolve the field type
-            typ := LookupTypeClause(v.FieldType, file.Package)
+            typ := LookupTypeClause(comp, v.FieldType, file.Package, cnt.TypeParameters)
 
             // WAIT A MINUTE, DID WE HAVE A FIELD WITH THIS NAME ALREADY???
             if slices.Contains(cnt.Symbols, v.FieldName.Buffer) {
                 // jes -> DIE!!!! >:)
-                error.Report(error.NewError(error.BND, v.Position(), "Cannot register field '%s'! A symbol with that name already exists!", v.FieldName.Buffer))
+                comp.Report(error.NewError(error.BND, v.Position(), "Cannot register field '%s'! A symbol with that name already exists!", v.FieldName.Buffer))
                 continue
             }
 
             // nah, we good
             sym := symbols.NewFieldSymbol(cnt, v.FieldName.Buffer, typ)
+            sym.IsReadonly = v.IsReadonly
+            sym.Doc = v.Doc
 
             // add it to the list
             fields = append(fields, sym)
@@ -256,14 +388,20 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
 
             // Do we have multiple constructors?
             if fncMem.IsConstructor && hasConstructor {
-                error.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Only one constructor per container is allowed!"))
+                comp.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Only one constructor per container is allowed!"))
                 continue
             }
 
             // is this a function declaration?
             if !fncMem.HasBody {
                 // cringe
-                error.Report(error.NewError(error.BND, fncMem.Position(), "Illegal function declaration outside of a trait!"))
+                comp.Report(error.NewError(error.BND, fncMem.Position(), "Illegal function declaration outside of a trait!"))
+                continue
+            }
+
+            // methods get their type parameters from the container
+            if len(fncMem.TypeParameters) > 0 {
+                comp.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Methods cannot have type parameters of their own! (try giving them to container '%s' instead)", cnt.Name()))
                 continue
             }
 
@@ -273,17 +411,17 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
                 prms = append(prms, symbols.NewParameterSymbol(
                     prm.ParameterName.Buffer,
                     i,
-                    LookupTypeClause(prm.ParameterType, file.Package),
+                    LookupTypeClause(comp, prm.ParameterType, file.Package, cnt.TypeParameters),
                 ))
             }
 
-            ret := LookupTypeClause(fncMem.ReturnType, file.Package)
+            ret := LookupTypeClause(comp, fncMem.ReturnType, file.Package, cnt.TypeParameters)
 
             // if this is a constructor -> we found one
             if fncMem.IsConstructor {
                 // is this legal doe?
-                if !ret.Equal(compunit.GlobalDataTypeRegister["void"]) {
-                    error.Report(error.NewError(error.BND, fncMem.ReturnType.Position(), "Constructor is required to be of type void!"))
+                if !ret.Equal(compunit.GlobalDataType("void")) {
+                    comp.Report(error.NewError(error.BND, fncMem.ReturnType.Position(), "Constructor is required to be of type void!"))
                     continue
                 }
 
@@ -299,9 +437,11 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
                 prms,
             )
 
+            fnc.Doc = fncMem.Doc
+
             // okay but like, is this legal?
             if slices.Contains(cnt.Symbols, fnc.Name()) {
-                error.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Cannot register method '%s'! A symbol with that name already exists!", fnc.Name()))
+                comp.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Cannot register method '%s'! A symbol with that name already exists!", fnc.Name()))
                 continue
             }
 
@@ -338,6 +478,8 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
 
             // implement fields one by one
             for _, fld := range trt.Fields {
+                // (generic traits get their type arguments filled in)
+                fldType := symbols.Substitute(fld.FieldType, trt.TypeParameters, cnt.TraitTypes[i].SubTypes)
 
                 // does this container already have a field with this name?
                 var containerField *symbols.FieldSymbol
@@ -351,20 +493,26 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
                 if containerField != nil {
 
                     // do the datatypes match?
-                    if !containerField.VarType().Equal(fld.VarType()) {
+                    if !containerField.VarType().Equal(fldType) {
 
                         // was this field added by another trait? (this is just for nicer error messages)
                         if containerField.HasParentTrait {
-                            error.Report(error.NewError(error.BND, trtSrc.Position(), "Unable to apply trait '%s'! A field with the name '%s' has already been added by trait '%s' with a different datatype!", trt.Name(), fld.Name(), containerField.ParentTrait.Name()))
+                            comp.Report(error.NewError(error.BND, trtSrc.Position(), "Unable to apply trait '%s'! A field with the name '%s' has already been added by trait '%s' with a different datatype!", trt.Name(), fld.Name(), containerField.ParentTrait.Name()))
                             continue
 
                         // otherwise: a less complicated error message
                         } else {
-                            error.Report(error.NewError(error.BND, trtSrc.Position(), "Unable to apply trait '%s'! The container '%s' already defines a field called '%s' with a different type!", trt.Name(), cnt.Name(), fld.Name()))
+                            comp.Report(error.NewError(error.BND, trtSrc.Position(), "Unable to apply trait '%s'! The container '%s' already defines a field called '%s' with a different type!", trt.Name(), cnt.Name(), fld.Name()))
                             continue
                         }
                     }
 
+                    // anyone holding the trait could just set it
+                    if containerField.IsReadonly {
+                        comp.Report(error.NewError(error.BND, trtSrc.Position(), "Unable to apply trait '%s'! The field '%s' is readonly in container '%s', but the trait can set it!", trt.Name(), fld.Name(), cnt.Name()))
+                        continue
+                    }
+
                     // if the datatypes match -> everything is cool
                     // we dont need to add another field, the field required by this trait has already been added
                     continue
@@ -375,12 +523,13 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
                 // WAIT A MINUTE, DID WE HAVE A SYMBOL WITH THIS NAME ALREADY???
                 if slices.Contains(cnt.Symbols, fld.Name()) {
                     // jes -> DIE!!!! >:)
-                    error.Report(error.NewError(error.BND, trtSrc.Position(), "Cannot register field '%s' of trait '%s'! A symbol with that name already exists!", fld.Name(), trt.Name()))
+                    comp.Report(error.NewError(error.BND, trtSrc.Position(), "Cannot register field '%s' of trait '%s'! A symbol with that name already exists!", fld.Name(), trt.Name()))
                     continue
                 }
 
                 // nah, we good
-                sym := symbols.NewFieldSymbol(cnt, fld.Name(), fld.FieldType)
+                sym := symbols.NewFieldSymbol(cnt, fld.Name(), fldType)
+                sym.Doc = fld.Doc
 
                 // add the trait in here (in case another trait also defines this field)
                 sym.HasParentTrait = true
@@ -420,9 +569,9 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
                         // where did this method come from?
                         // did another trait add it? (this is just for more helpful error messages)
                         if f.SourceTrait != nil {
-                            error.Report(error.NewError(error.BND, trtSrc.Position(), "Cannot add method '%s' of trait '%s'! A method with the same name has already been added by trait '%s'!", meth.Name(), trt.Name(), f.SourceTrait.Name()))
+                            comp.Report(error.NewError(error.BND, trtSrc.Position(), "Cannot add method '%s' of trait '%s'! A method with the same name has already been added by trait '%s'!", meth.Name(), trt.Name(), f.SourceTrait.Name()))
                         } else {
-                            error.Report(error.NewError(error.BND, trtSrc.Position(), "Cannot add method '%s' of trait '%s'! The container already implements a method with that name!", meth.Name(), trt.Name()))
+                            comp.Report(error.NewError(error.BND, trtSrc.Position(), "Cannot add method '%s' of trait '%s'! The container already implements a method with that name!", meth.Name(), trt.Name()))
                         }
 
                         isConflicting = true
@@ -439,7 +588,7 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
                 // WAIT A MINUTE, DID WE HAVE A SYMBOL WITH THIS NAME ALREADY???
                 if slices.Contains(cnt.Symbols, meth.Name()) {
                     // yea :(
-                    error.Report(error.NewError(error.BND, trtSrc.Position(), "Cannot register method '%s' of trait '%s'! A symbol with that name already exists!", meth.Name(), trt.Name()))
+                    comp.Report(error.NewError(error.BND, trtSrc.Position(), "Cannot register method '%s' of trait '%s'! A symbol with that name already exists!", meth.Name(), trt.Name()))
                     continue
                 }
 
@@ -457,6 +606,7 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
 
                 // remember from which trait this method came (for better error reporting)
                 fnc.SourceTrait = trt
+                fnc.Doc = meth.Doc
 
                 // mark this symbol as a redirection to another method and add a ref to that method
                 fnc.NeedsVirtualCallToTrait = true
@@ -483,7 +633,7 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
             // include these methods one by one 
             for _, meth := range trt.Methods {
                 // we only care about declarations
-                if meth.NeedsVirtualCallToTrait {
+                if !meth.NeedsVirtualCallToContainer {
                     continue
                 }
 
@@ -499,26 +649,31 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
 
                 // if we did not find an implementation -> complain
                 if fnc == nil {
-                    error.Report(error.NewError(error.BND, trtSrc.Position(), "Container '%s' did not implement method '%s' which is required by trait '%s'!", cnt.Name(), meth.Name(), trt.Name()))
+                    comp.Report(error.NewError(error.BND, trtSrc.Position(), "Container '%s' did not implement method '%s' which is required by trait '%s'!", cnt.Name(), meth.Name(), trt.Name()))
                     continue
                 }
 
                 // if we found a method with the correct name -> make sure the signatures match up
+                // (generic traits get their type arguments filled in first)
                 // -------------------------------------------------------------------------------
+                args := cnt.TraitTypes[i].SubTypes
+                ret := symbols.Substitute(meth.ReturnType, trt.TypeParameters, args)
                 
-                if !meth.ReturnType.Equal(fnc.ReturnType) {
-                    error.Report(error.NewError(error.BND, trtSrc.Position(), "Container '%s' did not implement method '%s' correctly. Trait '%s' requires a return type of '%s',got '%s' instead!", cnt.Name(), meth.Name(), trt.Name(), meth.ReturnType.Name(), fnc.ReturnType.Name()))
+                if !ret.Equal(fnc.ReturnType) {
+                    comp.Report(error.NewError(error.BND, trtSrc.Position(), "Container '%s' did not implement method '%s' correctly. Trait '%s' requires a return type of '%s',got '%s' instead!", cnt.Name(), meth.Name(), trt.Name(), ret.Name(), fnc.ReturnType.Name()))
                     continue
                 }
 
                 if len(meth.Parameters) != len(fnc.Parameters) {
-                    error.Report(error.NewError(error.BND, trtSrc.Position(), "Container '%s' did not implement method '%s' correctly. Trait '%s' requires %d parameters, got %d instead!", cnt.Name(), meth.Name(), trt.Name(), len(meth.Parameters), len(fnc.Parameters)))
+                    comp.Report(error.NewError(error.BND, trtSrc.Position(), "Container '%s' did not implement method '%s' correctly. Trait '%s' requires %d parameters, got %d instead!", cnt.Name(), meth.Name(), trt.Name(), len(meth.Parameters), len(fnc.Parameters)))
                     continue
                 }
 
                 for i := range meth.Parameters {
-                    if !meth.Parameters[i].VarType().Equal(fnc.Parameters[i].VarType()) {
-                        error.Report(error.NewError(error.BND, trtSrc.Position(), "Container '%s' did not implement method '%s' correctly. Trait '%s' requires the parameter at index %d to be of type '%s', got '%s' instead!", cnt.Name(), meth.Name(), trt.Name(), i, meth.Parameters[i].VarType().Name(), fnc.Parameters[i].VarType().Name()))
+                    prm := symbols.Substitute(meth.Parameters[i].VarType(), trt.TypeParameters, args)
+
+                    if !prm.Equal(fnc.Parameters[i].VarType()) {
+                        comp.Report(error.NewError(error.BND, trtSrc.Position(), "Container '%s' did not implement method '%s' correctly. Trait '%s' requires the parameter at index %d to be of type '%s', got '%s' instead!", cnt.Name(), meth.Name(), trt.Name(), i, prm.Name(), fnc.Parameters[i].VarType().Name()))
                         break
                     }
                 }
@@ -538,7 +693,7 @@ func IndexContainerContents(file *packageprocessor.CompilationFile) {
 // --------------------------------------------------------
 // Function indexing
 // --------------------------------------------------------
-func IndexFunctions(file *packageprocessor.CompilationFile) {
+func IndexFunctions(comp *compunit.Compilation, file *packageprocessor.CompilationFile) {
     // look through all member nodes
     for _, v := range file.Members {
         // we're only looking for function nodes
@@ -551,24 +706,27 @@ func IndexFunctions(file *packageprocessor.CompilationFile) {
         // is this a constructor?
         if fncMem.IsConstructor {
             // cringe
-            error.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Illegal constructor outside of a container!"))
+            comp.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Illegal constructor outside of a container!"))
             continue
         }
 
         // is this a function declaration?
         if !fncMem.HasBody {
             // cringe
-            error.Report(error.NewError(error.BND, fncMem.Position(), "Illegal function declaration outside of a trait!"))
+            comp.Report(error.NewError(error.BND, fncMem.Position(), "Illegal function declaration outside of a trait!"))
             continue
         }
 
+        // create the type parameters (if this is a generic function)
+        typprms := createTypeParameters(comp, fncMem.TypeParameters)
+
         // create parameter symbols
         prms := []*symbols.ParameterSymbol{}
         for i, prm := range fncMem.Parameters {
             prms = append(prms, symbols.NewParameterSymbol(
                 prm.ParameterName.Buffer,
                 i,
-                LookupTypeClause(prm.ParameterType, file.Package),
+                LookupTypeClause(comp, prm.ParameterType, file.Package, typprms),
             ))
         }
 
@@ -576,14 +734,17 @@ func IndexFunctions(file *packageprocessor.CompilationFile) {
         fnc := symbols.NewFunctionSymbol(
             file.Package,
             fncMem.FunctionName.Buffer,
-            LookupTypeClause(fncMem.ReturnType, file.Package),
+            LookupTypeClause(comp, fncMem.ReturnType, file.Package, typprms),
             prms,
         )
 
+        fnc.TypeParameters = typprms
+        fnc.Doc = fncMem.Doc
+
         ok := file.Package.TryRegisterFunction(fnc) 
 
         if !ok {
-            error.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Cannot register function '%s'! A function with that name already exists!", fnc.FuncName))
+            comp.Report(error.NewError(error.BND, fncMem.FunctionName.Position, "Cannot register function '%s'! A function with that name already exists!", fnc.FuncName))
             continue
         }
 
@@ -595,7 +756,7 @@ func IndexFunctions(file *packageprocessor.CompilationFile) {
 // --------------------------------------------------------
 // Global indexing
 // --------------------------------------------------------
-func IndexGlobals(file *packageprocessor.CompilationFile) {
+func IndexGlobals(comp *compunit.Compilation, file *packageprocessor.CompilationFile) {
     // look through all member nodes
     for _, v := range file.Members {
         // we're only looking for function nodes
@@ -605,16 +766,25 @@ func IndexGlobals(file *packageprocessor.CompilationFile) {
 
         glbMem := v.(*syntaxnodes.GlobalNode)
 
+        // constants without a type find out what they are once their value is bound
+        typ := compunit.GlobalDataType("error")
+        if glbMem.HasExplicitType {
+            typ = LookupTypeClause(comp, glbMem.VarType, file.Package, nil)
+        }
+
         // register a global symbol for this function
-        glb := symbols.NewGlobalSymbol(file.Package, glbMem.GlobalName.Buffer, LookupTypeClause(glbMem.VarType, file.Package))
+        glb := symbols.NewGlobalSymbol(file.Package, glbMem.GlobalName.Buffer, typ)
+        glb.IsConst = glbMem.IsConst()
+        glb.Doc = glbMem.Doc
         ok := file.Package.TryRegisterGlobal(glb) 
 
         if !ok {
-            error.Report(error.NewError(error.BND, glbMem.GlobalName.Position, "Cannot register global '%s'! A global with that name already exists!", glb.Name()))
+            comp.Report(error.NewError(error.BND, glbMem.GlobalName.Position, "Cannot register global '%s'! A global with that name already exists!", glb.Name()))
             continue
         }
 
         file.Globals = append(file.Globals, glb)
+        file.GlobalSrc[glb] = glbMem
     }
 }
 
@@ -622,6 +792,8 @@ func IndexGlobals(file *packageprocessor.CompilationFile) {
 // Binding
 // --------------------------------------------------------
 type Binder struct {
+    Comp *compunit.Compilation
+
     CurrentPackage *symbols.PackageSymbol
     CurrentType *symbols.TypeSymbol
     CurrentFunction *symbols.FunctionSymbol
@@ -630,6 +802,25 @@ type Binder struct {
     BreakLabels []boundnodes.BoundLabel
     ContinueLabels []boundnodes.BoundLabel
     LabelCount int
+
+    // lambdas get bound right where they are, but compiled like any other function of the file
+    File *packageprocessor.CompilationFile
+    Lambdas []*LambdaContext // the lambdas we're currently in (innermost last)
+
+    // the body of the function (or lambda) we're currently in
+    // (narrowing needs to know what else is going on in there)
+    CurrentBody syntaxnodes.SyntaxNode
+
+    // constants whose values are being figured out (true) or have been (false)
+    // (only while binding constants, nil otherwise)
+    Constants map[*symbols.GlobalSymbol]bool
+}
+
+// Everything we need to know about a lambda while binding its body
+type LambdaContext struct {
+    Function *symbols.FunctionSymbol
+    Outer *symbols.FunctionSymbol // whatever function the lambda was written in
+    Scope *Scope                  // everything declared above this gets captured
 }
 
 func (bin *Binder) EnterNewScope() {
@@ -654,13 +845,16 @@ func (bin *Binder) PopLabels() {
 	bin.ContinueLabels = bin.ContinueLabels[:len(bin.ContinueLabels)-1]
 }
 
-func BindFunctions(file *packageprocessor.CompilationFile) {
+func BindFunctions(comp *compunit.Compilation, file *packageprocessor.CompilationFile) {
     for _, sym := range file.Functions {
         // create a new binder
         bin := Binder{
+            Comp: comp,
             CurrentPackage: file.Package,
             CurrentFunction: sym,
             CurrentScope: NewScope(nil),
+            CurrentBody: file.FunctionBodiesSrc[sym],
+            File: file,
         }
 
         // if this is a method -> register the current type
@@ -708,6 +902,124 @@ func BindFunctions(file *packageprocessor.CompilationFile) {
     }
 }
 
+// --------------------------------------------------------
+// Script binding (used by the REPL)
+// --------------------------------------------------------
+// Binds a list of loose statements into the body of the given function symbol
+// - top level variable declarations become globals of the current package (so they survive between REPL inputs)
+// - if the last statement is a bare expression with a value, it is returned from the function
+func BindScript(comp *compunit.Compilation, file *packageprocessor.CompilationFile, sym *symbols.FunctionSymbol, stmts []syntaxnodes.StatementNode) {
+    // create a new binder
+    bin := Binder{
+        Comp: comp,
+        CurrentPackage: file.Package,
+        CurrentFunction: sym,
+        CurrentScope: NewScope(nil),
+        CurrentBody: syntaxnodes.NewBlockStatementNode(lexer.Token{}, stmts, lexer.Token{}),
+        File: file,
+    }
+
+    // register the package globals as variables
+    for _, v := range file.Globals {
+        bin.CurrentScope.RegisterVariable(v)
+    }
+
+    // create sub-scope so globals can be overwritten
+    bin.EnterNewScope()
+
+    body := []boundnodes.BoundStatementNode{}
+    for i, stmt := range stmts {
+        // top level declarations -> globals
+        if stmt.Type() == syntaxnodes.NT_DeclarationStmt {
+            body = append(body, bin.bindScriptDeclaration(file, stmt.(*syntaxnodes.DeclarationStatementNode)))
+            continue
+        }
+
+        // the last statement is a bare expression -> this is the result of this script
+        if i == len(stmts) - 1 && stmt.Type() == syntaxnodes.NT_ExpressionStmt {
+            expr := bin.bindExpression(stmt.(*syntaxnodes.ExpressionStatementNode).Expression)
+
+            // only return things that actually have a value
+            if expr.Type() != boundnodes.BT_AssignmentExpr &&
+               expr.Type() != boundnodes.BT_ErrorExpr      &&
+               !expr.ExprType().Equal(compunit.GlobalDataType("void")) {
+
+                body = append(body, boundnodes.NewBoundReturnStatementNode(stmt, bin.bindConversion(expr, sym.ReturnType, false), true))
+            } else {
+                body = append(body, boundnodes.NewBoundExpressionStatementNode(stmt, expr))
+            }
+
+            continue
+        }
+
+        // otherwise -> bind it like any other statement
+        body = append(body, bin.bindStatement(stmt))
+    }
+
+    bin.LeaveScope()
+
+    // use the first statement as the source of the whole thing
+    var src syntaxnodes.SyntaxNode
+    if len(stmts) > 0 {
+        src = stmts[0]
+    }
+
+    // register the script as a function in this file
+    file.Functions = append(file.Functions, sym)
+    file.FunctionBodies[sym] = boundnodes.NewBoundBlockStatementNode(src, body)
+}
+
+func (bin *Binder) bindScriptDeclaration(file *packageprocessor.CompilationFile, stmt *syntaxnodes.DeclarationStatementNode) boundnodes.BoundStatementNode {
+    // bind this like a normal declaration first
+    count := len(bin.CurrentScope.Variables)
+    decl := bin.bindDeclarationStmt(stmt)
+    typ := decl.Variable.VarType()
+
+    // the binder has put a local into our scope, we dont want that
+    bin.CurrentScope.Variables = bin.CurrentScope.Variables[:count]
+
+    // if the declaration already failed -> dont bother
+    if typ.Equal(compunit.GlobalDataType("error")) {
+        return boundnodes.NewBoundExpressionStatementNode(stmt, boundnodes.NewBoundErrorExpressionNode(stmt))
+    }
+
+    // does this global already exist?
+    glb := bin.LookupGlobal(stmt.VarName.Buffer)
+
+    if glb != nil {
+        // constants stay what they are
+        if glb.IsConst {
+            bin.Comp.Report(error.NewError(error.BND, stmt.VarName.Position, "Cannot redeclare constant '%s'!", glb.Name()))
+            return boundnodes.NewBoundExpressionStatementNode(stmt, boundnodes.NewBoundErrorExpressionNode(stmt))
+        }
+
+        // we can only reuse it if the types line up
+        if !glb.VarType().Equal(typ) {
+            bin.Comp.Report(error.NewError(error.BND, stmt.VarName.Position, "Cannot redeclare global '%s' of type '%s' as '%s'!", glb.Name(), glb.VarType().Name(), typ.Name()))
+            return boundnodes.NewBoundExpressionStatementNode(stmt, boundnodes.NewBoundErrorExpressionNode(stmt))
+        }
+
+    // if not -> create a new one
+    } else {
+        glb = symbols.NewGlobalSymbol(file.Package, stmt.VarName.Buffer, typ)
+        glb.IsConst = stmt.IsConst()
+        file.Package.TryRegisterGlobal(glb)
+        file.Globals = append(file.Globals, glb)
+
+        // make it visible for all following statements
+        bin.CurrentScope.Parent.RegisterVariable(glb)
+    }
+
+    // no initializer -> nothing to do at runtime
+    if !decl.HasInitializer {
+        return boundnodes.NewBoundBlockStatementNode(stmt, []boundnodes.BoundStatementNode{})
+    }
+
+    // otherwise -> assign the initial value
+    target := boundnodes.NewBoundNameExpressionNode(stmt, glb)
+    return boundnodes.NewBoundExpressionStatementNode(stmt, boundnodes.NewBoundAssignmentExpressionNode(stmt, target, decl.Initializer))
+}
+
 // --------------------------------------------------------
 // Statements
 // --------------------------------------------------------
@@ -727,6 +1039,9 @@ func (bin *Binder) bindStatement(stmt syntaxnodes.StatementNode) boundnodes.Boun
     } else if stmt.Type() == syntaxnodes.NT_ForStmt {
         return bin.bindForStmt(stmt.(*syntaxnodes.ForStatementNode))
 
+    } else if stmt.Type() == syntaxnodes.NT_ForEachStmt {
+        return bin.bindForEachStmt(stmt.(*syntaxnodes.ForEachStatementNode))
+
     } else if stmt.Type() == syntaxnodes.NT_LoopStmt {
         return bin.bindLoopStmt(stmt.(*syntaxnodes.LoopStatementNode))
 
@@ -745,9 +1060,18 @@ func (bin *Binder) bindStatement(stmt syntaxnodes.StatementNode) boundnodes.Boun
     } else if stmt.Type() == syntaxnodes.NT_IfStmt {
         return bin.bindIfStmt(stmt.(*syntaxnodes.IfStatementNode))
 
+    } else if stmt.Type() == syntaxnodes.NT_TryStmt {
+        return bin.bindTryStmt(stmt.(*syntaxnodes.TryStatementNode))
+
+    } else if stmt.Type() == syntaxnodes.NT_ThrowStmt {
+        return bin.bindThrowStmt(stmt.(*syntaxnodes.ThrowStatementNode))
+
+    } else if stmt.Type() == syntaxnodes.NT_SwitchStmt {
+        return bin.bindSwitchStmt(stmt.(*syntaxnodes.SwitchStatementNode))
+
     } else {
 
-        error.Report(error.NewError(error.BND, stmt.Position(), "Unknown statement type '%s'!", stmt.Type()))
+        bin.Comp.Report(error.NewError(error.BND, stmt.Position(), "Unknown statement type '%s'!", stmt.Type()))
         return boundnodes.NewBoundExpressionStatementNode(stmt, boundnodes.NewBoundErrorExpressionNode(stmt))
     }
 }
@@ -758,13 +1082,13 @@ func (bin *Binder) bindDeclarationStmt(stmt *syntaxnodes.DeclarationStatementNod
 
     // do we have an explicit type or initializer?
     if !stmt.HasExplicitType && !stmt.HasInitializer {
-        typ = compunit.GlobalDataTypeRegister["error"]
-        error.Report(error.NewError(error.BND, stmt.Position(), "Variable declaration either needs explicit type declaration or initializer!"))
+        typ = compunit.GlobalDataType("error")
+        bin.Comp.Report(error.NewError(error.BND, stmt.Position(), "Variable declaration either needs explicit type declaration or initializer!"))
     }
 
     // if theres an explicit type -> resolve it
     if stmt.HasExplicitType {
-        typ = LookupTypeClause(stmt.VarType, bin.CurrentPackage)
+        typ = bin.lookupTypeClause(stmt.VarType)
     }
 
     // if we have an initializer -> bind it
@@ -772,17 +1096,35 @@ func (bin *Binder) bindDeclarationStmt(stmt *syntaxnodes.DeclarationStatementNod
         initializer = bin.bindExpression(stmt.Initializer)
 
         // if theres an explicit type -> make sure they match
+        // (getting rid of a question mark still needs a cast though, 'var n Node <- Node(maybe)')
         if stmt.HasExplicitType {
-            initializer = bin.bindConversion(initializer, typ, true)
+            explicit := !initializer.ExprType().Nullable || typ.Nullable
+            initializer = bin.bindConversion(initializer, typ, explicit)
 
         // if not -> set the variable type
         } else {
             typ = initializer.ExprType()
+
+            // null alone doesnt tell us anything
+            if typ.Equal(compunit.GlobalDataType("null")) {
+                bin.Comp.Report(error.NewError(error.BND, stmt.Initializer.Position(), "Unable to infer the type of variable '%s' from null! (give it an explicit type like 'Foo?')", stmt.VarName.Buffer))
+                typ = compunit.GlobalDataType("error")
+            }
         }
     }
 
+    // constants wont get another chance to be set
+    if stmt.IsConst() && !stmt.HasInitializer {
+        bin.Comp.Report(error.NewError(error.BND, stmt.Position(), "Constant '%s' needs a value!", stmt.VarName.Buffer))
+
+    // containers and traits would start out as null, which only nullable ones are allowed to be
+    } else if !stmt.HasInitializer && typ.CanBeNullable() && !typ.Nullable {
+        bin.Comp.Report(error.NewError(error.BND, stmt.Position(), "Variable '%s' of non-nullable type '%s' needs a value! (or make it '%s?')", stmt.VarName.Buffer, typ.Name(), typ.Name()))
+    }
+
     // create a variable symbol
     vari := symbols.NewLocalSymbol(stmt.VarName.Buffer, typ)
+    vari.IsConst = stmt.IsConst()
 
     // register this variable
     bin.CurrentScope.RegisterVariable(vari)
@@ -800,14 +1142,20 @@ func (bin *Binder) bindReturnStmt(stmt *syntaxnodes.ReturnStatementNode) boundno
     }
 
     // make sure the return value kind matches the function type
-    if retValue == nil && !bin.CurrentFunction.ReturnType.Equal(compunit.GlobalDataTypeRegister["void"]) {
-        error.Report(error.NewError(error.BND, stmt.Position(), "A function of type 'void' is not allowed to return a value!"))
+    if retValue == nil && !bin.CurrentFunction.ReturnType.Equal(compunit.GlobalDataType("void")) {
+        bin.Comp.Report(error.NewError(error.BND, stmt.Position(), "A function of type 'void' is not allowed to return a value!"))
         return boundnodes.NewBoundExpressionStatementNode(stmt, boundnodes.NewBoundErrorExpressionNode(stmt))
     }
 
+    // nullable functions can give back null or a plain instance
+    if retValue != nil && bin.CurrentFunction.ReturnType.Nullable {
+        retValue = bin.bindConversion(retValue, bin.CurrentFunction.ReturnType, false)
+        return boundnodes.NewBoundReturnStatementNode(stmt, retValue, stmt.HasExpression)
+    }
+
     if retValue != nil {
         if !retValue.ExprType().Equal(bin.CurrentFunction.ReturnType) {
-            error.Report(error.NewError(error.BND, stmt.Position(), "A function of type '%s' is not allowed to return a value of type '%s'!", bin.CurrentFunction.ReturnType.Name(), retValue.ExprType().Name()))
+            bin.Comp.Report(error.NewError(error.BND, stmt.Position(), "A function of type '%s' is not allowed to return a value of type '%s'!", bin.CurrentFunction.ReturnType.Name(), retValue.ExprType().Name()))
             return boundnodes.NewBoundExpressionStatementNode(stmt, boundnodes.NewBoundErrorExpressionNode(stmt))
         }
     }
@@ -840,7 +1188,7 @@ func (bin *Binder) bindWhileStmt(stmt *syntaxnodes.WhileStatementNode) boundnode
     cond := bin.bindExpression(stmt.Expression)
 
     // make sure the expression is a boolean
-    cond = bin.bindConversion(cond, compunit.GlobalDataTypeRegister["bool"], false)
+    cond = bin.bindConversion(cond, compunit.GlobalDataType("bool"), false)
 
     // bind the loop body
     bin.EnterNewScope()
@@ -853,7 +1201,7 @@ func (bin *Binder) bindWhileStmt(stmt *syntaxnodes.WhileStatementNode) boundnode
 
 func (bin *Binder) bindFromToStmt(stmt *syntaxnodes.FromToStatementNode) boundnodes.BoundStatementNode {
     // create the iterator
-    vari := symbols.NewLocalSymbol(stmt.Iterator.Buffer, compunit.GlobalDataTypeRegister["int"])
+    vari := symbols.NewLocalSymbol(stmt.Iterator.Buffer, compunit.GlobalDataType("int"))
 
     bin.EnterNewScope()
     bin.CurrentScope.RegisterVariable(vari) // will always work because the scope is empty
@@ -882,7 +1230,7 @@ func (bin *Binder) bindForStmt(stmt *syntaxnodes.ForStatementNode) boundnodes.Bo
 
     // bind the condition
     cond := bin.bindExpression(stmt.Condition)
-    cond = bin.bindConversion(cond, compunit.GlobalDataTypeRegister["bool"], false)
+    cond = bin.bindConversion(cond, compunit.GlobalDataType("bool"), false)
 
     // bind the action
     action := bin.bindStatement(stmt.Action)
@@ -897,6 +1245,91 @@ func (bin *Binder) bindForStmt(stmt *syntaxnodes.ForStatementNode) boundnodes.Bo
     return boundnodes.NewBoundForStatementNode(stmt, init, cond, action, body, brk, cnt)
 }
 
+func (bin *Binder) bindForEachStmt(stmt *syntaxnodes.ForEachStatementNode) boundnodes.BoundStatementNode {
+    // bind the collection
+    coll := bin.bindExpression(stmt.Collection)
+    typ := coll.ExprType()
+
+    // figure out how to walk through it
+    var length, get *symbols.FunctionSymbol
+    elem := compunit.GlobalDataType("error")
+
+    if coll.Type() == boundnodes.BT_ErrorExpr || typ.Equal(compunit.GlobalDataType("error")) {
+        // something already went wrong -> dont make it worse
+
+    } else if typ.Nullable {
+        bin.Comp.Report(error.NewError(error.BND, stmt.Collection.Position(), "Cannot iterate over nullable type '%s'! (convert it to '%s' first)", typ.Name(), typ.NonNullable().Name()))
+
+    // arrays just get indexed
+    } else if typ.TypeGroup == symbols.ARR {
+        elem = typ.SubTypes[0]
+        length = bin.LookupMethod("Length", typ)
+
+    // strings get split into their characters first
+    } else if typ.Equal(compunit.GlobalDataType("string")) {
+        chars := bin.LookupMethod("Chars", typ)
+        coll = boundnodes.NewBoundAccessCallExpressionNode(stmt.Collection, coll, chars, []boundnodes.BoundExpressionNode{}, chars.ReturnType, false)
+
+        elem = typ
+        length = bin.LookupMethod("Length", chars.ReturnType)
+
+    // everything else has to be Iterable
+    } else if iter := bin.iterableTypeFor(typ); iter != nil {
+        coll = bin.bindConversion(coll, iter, false)
+
+        elem = iter.SubTypes[0]
+        length = bin.LookupMethod("Length", iter)
+        get = bin.LookupMethod("Get", iter)
+
+    } else {
+        bin.Comp.Report(error.NewError(error.BND, stmt.Collection.Position(), "Cannot iterate over type '%s'! (only arrays, strings and Iterable containers can be used in a for-in loop)", typ.Name()))
+    }
+
+    bin.EnterNewScope()
+
+    // create the loop variables
+    var index symbols.VariableSymbol
+    if stmt.HasIndex {
+        index = symbols.NewLocalSymbol(stmt.Index.Buffer, compunit.GlobalDataType("int"))
+        bin.CurrentScope.RegisterVariable(index) // will always work because the scope is empty
+    }
+
+    vari := symbols.NewLocalSymbol(stmt.Variable.Buffer, elem)
+    if !bin.CurrentScope.RegisterVariable(vari) {
+        bin.Comp.Report(error.NewError(error.BND, stmt.Variable.Position, "Cannot use '%s' as both the index and the element of a for-in loop!", stmt.Variable.Buffer))
+    }
+
+    // bind the loop body
+    body, brk, cnt := bin.bindLoopBody(stmt.Body)
+
+    bin.LeaveScope()
+
+    // create new node
+    return boundnodes.NewBoundForEachStatementNode(stmt, stmt.HasIndex, index, vari, coll, length, get, body, brk, cnt)
+}
+
+// What Iterable does this type implement?
+// (returns nil if it doesnt implement any)
+func (bin *Binder) iterableTypeFor(typ *symbols.TypeSymbol) *symbols.TypeSymbol {
+    iterable := LookupTraitInPackage("Iterable", bin.Comp.GetPackage("internal"))
+
+    // already an Iterable
+    if typ.TypeGroup == symbols.TRT && typ.Trait == iterable {
+        return typ
+    }
+
+    // containers might implement it
+    if typ.TypeGroup == symbols.CONT {
+        for i, trt := range typ.Container.Traits {
+            if trt == iterable {
+                return typ.Container.TraitTypeFor(i, typ)
+            }
+        }
+    }
+
+    return nil
+}
+
 func (bin *Binder) bindLoopStmt(stmt *syntaxnodes.LoopStatementNode) boundnodes.BoundStatementNode {
     // register a new scope
     bin.EnterNewScope()
@@ -917,7 +1350,7 @@ func (bin *Binder) bindLoopStmt(stmt *syntaxnodes.LoopStatementNode) boundnodes.
 func (bin *Binder) bindBreakStmt(stmt *syntaxnodes.BreakStatementNode) boundnodes.BoundStatementNode {
     // are there actually any loops around rn?
     if len(bin.BreakLabels) == 0 {
-        error.Report(error.NewError(error.BND, stmt.Position(), "Unable to use break statement outside of a loop!"))
+        bin.Comp.Report(error.NewError(error.BND, stmt.Position(), "Unable to use break statement outside of a loop!"))
         return boundnodes.NewBoundExpressionStatementNode(stmt, boundnodes.NewBoundErrorExpressionNode(stmt))
     }
 
@@ -928,7 +1361,7 @@ func (bin *Binder) bindBreakStmt(stmt *syntaxnodes.BreakStatementNode) boundnode
 func (bin *Binder) bindContinueStmt(stmt *syntaxnodes.ContinueStatementNode) boundnodes.BoundStatementNode {
     // are there actually any loops around rn?
     if len(bin.ContinueLabels) == 0 {
-        error.Report(error.NewError(error.BND, stmt.Position(), "Unable to use continue statement outside of a loop!"))
+        bin.Comp.Report(error.NewError(error.BND, stmt.Position(), "Unable to use continue statement outside of a loop!"))
         return boundnodes.NewBoundExpressionStatementNode(stmt, boundnodes.NewBoundErrorExpressionNode(stmt))
     }
 
@@ -953,6 +1386,44 @@ func (bin *Binder) bindBlockStmt(stmt *syntaxnodes.BlockStatementNode) boundnode
     return boundnodes.NewBoundBlockStatementNode(stmt, stmts)
 }
 
+func (bin *Binder) bindTryStmt(stmt *syntaxnodes.TryStatementNode) boundnodes.BoundStatementNode {
+    // bind the protected body
+    bin.EnterNewScope()
+    body := bin.bindStatement(stmt.Body)
+    bin.LeaveScope()
+
+    // look up the type of the error variable
+    typ := bin.lookupTypeClause(stmt.ErrorType)
+
+    // only Errors can be caught
+    if !typ.Equal(compunit.GlobalDataType("error")) && !typ.Equal(bin.errorType()) {
+        bin.Comp.Report(error.NewError(error.BND, stmt.ErrorType.Position(), "Only values of type 'Error' can be caught, got '%s'!", typ.Name()))
+    }
+
+    // create the error variable
+    vari := symbols.NewLocalSymbol(stmt.ErrorName.Buffer, bin.errorType())
+
+    // bind the catch body (with the error variable in scope)
+    bin.EnterNewScope()
+    bin.CurrentScope.RegisterVariable(vari) // will always work because the scope is empty
+    catchBody := bin.bindStatement(stmt.CatchBody)
+    bin.LeaveScope()
+
+    // create a new node
+    return boundnodes.NewBoundTryStatementNode(stmt, body, vari, catchBody)
+}
+
+func (bin *Binder) bindThrowStmt(stmt *syntaxnodes.ThrowStatementNode) boundnodes.BoundStatementNode {
+    // bind the error
+    err := bin.bindExpression(stmt.Expression)
+
+    // only Errors can be thrown
+    err = bin.bindConversion(err, bin.errorType(), false)
+
+    // create a new node
+    return boundnodes.NewBoundThrowStatementNode(stmt, err)
+}
+
 func (bin *Binder) bindExpressionStmt(stmt *syntaxnodes.ExpressionStatementNode) boundnodes.BoundStatementNode {
     // bind the expression in question
     expr := bin.bindExpression(stmt.Expression)
@@ -960,10 +1431,12 @@ func (bin *Binder) bindExpressionStmt(stmt *syntaxnodes.ExpressionStatementNode)
     // is this expression allowed to be a statement?
     if expr.Type() != boundnodes.BT_CallExpr       && 
        expr.Type() != boundnodes.BT_AccessCallExpr &&
+       expr.Type() != boundnodes.BT_CallValueExpr  &&
        expr.Type() != boundnodes.BT_AssignmentExpr &&
+       expr.Type() != boundnodes.BT_CompoundExpr   &&
        expr.Type() != boundnodes.BT_ErrorExpr {
 
-        error.Report(error.NewError(error.BND, stmt.Expression.Position(), "Expression of type '%s' is not allowed to be used as a statement!", expr.ExprType().Name()))
+        bin.Comp.Report(error.NewError(error.BND, stmt.Expression.Position(), "Expression of type '%s' is not allowed to be used as a statement!", expr.ExprType().Name()))
     }
 
     // create a new node
@@ -976,6 +1449,12 @@ func (bin *Binder) bindIfStmt(stmt *syntaxnodes.IfStatementNode) boundnodes.Boun
 
     // bind if block
     bin.EnterNewScope()
+
+    // if (x is Foo) -> x is a Foo in here
+    if vari, typ := bin.narrowedBy(cond, stmt.Body); vari != nil {
+        bin.CurrentScope.Narrow(vari, typ)
+    }
+
     body := bin.bindStatement(stmt.Body)
     bin.LeaveScope()
 
@@ -992,6 +1471,114 @@ func (bin *Binder) bindIfStmt(stmt *syntaxnodes.IfStatementNode) boundnodes.Boun
     return boundnodes.NewBoundIfStatementNode(stmt, cond, body, elseBody, stmt.HasElseClause)
 }
 
+func (bin *Binder) bindSwitchStmt(stmt *syntaxnodes.SwitchStatementNode) boundnodes.BoundStatementNode {
+    // bind the value we're switching on
+    subject := bin.bindExpression(stmt.Expression)
+    typ := subject.ExprType()
+
+    // figure out how to compare case values against it
+    op := boundnodes.GetBinaryOperator(lexer.TT_Equal, typ, typ)
+
+    cases := []*boundnodes.BoundSwitchCase{}
+    seen := map[string]bool{}
+
+    var defaultBody boundnodes.BoundStatementNode
+    hasDefault := false
+
+    for _, cse := range stmt.Cases {
+        // there can only be one default
+        if cse.IsDefault && hasDefault {
+            bin.Comp.Report(error.NewError(error.BND, cse.CaseKw.Position, "Switch statement already has a default case!"))
+        }
+
+        bin.EnterNewScope()
+
+        values := []boundnodes.BoundExpressionNode{}
+        var vari symbols.VariableSymbol
+        var pattern *symbols.TypeSymbol
+
+        // case c SomeContainer:
+        if cse.IsPattern {
+            pattern = bin.lookupTypeClause(cse.PatternType)
+
+            // we can only narrow things that could be more than one type
+            if !typ.Equal(compunit.GlobalDataType("any")) && typ.NonNullable().TypeGroup != symbols.TRT {
+                bin.Comp.Report(error.NewError(error.BND, cse.PatternType.Position(), "Type patterns can only be used when switching on 'any' or a trait, got '%s'!", typ.Name()))
+
+            // ...and only into containers and traits
+            } else if pattern.TypeGroup != symbols.CONT && pattern.TypeGroup != symbols.TRT {
+                bin.Comp.Report(error.NewError(error.BND, cse.PatternType.Position(), "Type patterns can only match containers and traits, got '%s'!", pattern.Name()))
+
+            // and it has to actually be possible
+            } else if boundnodes.ClassifyConversion(typ, pattern) == boundnodes.CT_None {
+                bin.Comp.Report(error.NewError(error.BND, cse.PatternType.Position(), "A value of type '%s' can never be a '%s'!", typ.Name(), pattern.Name()))
+            }
+
+            vari = symbols.NewLocalSymbol(cse.PatternName.Buffer, pattern)
+            bin.CurrentScope.RegisterVariable(vari) // will always work because the scope is empty
+
+        // case 1, 2:
+        } else if !cse.IsDefault {
+            for _, v := range cse.Values {
+                val := bin.bindConversion(bin.bindExpression(v), typ, false)
+
+                // some things just cant be compared
+                if op == nil {
+                    if val.Type() != boundnodes.BT_ErrorExpr {
+                        bin.Comp.Report(error.NewError(error.BND, v.Position(), "Values of type '%s' can not be compared!", typ.Name()))
+                    }
+
+                // the same value twice makes no sense
+                } else if lit, ok := val.(*boundnodes.BoundLiteralExpressionNode); ok {
+                    key := fmt.Sprintf("%v", lit.LiteralValue)
+
+                    if seen[key] {
+                        bin.Comp.Report(error.NewError(error.BND, v.Position(), "Duplicate case value '%s'!", key))
+                    }
+
+                    seen[key] = true
+                }
+
+                values = append(values, val)
+            }
+        }
+
+        // bind the body (up to the next case)
+        stmts := []boundnodes.BoundStatementNode{}
+        for _, v := range cse.Statements {
+            stmts = append(stmts, bin.bindStatement(v))
+        }
+
+        body := boundnodes.NewBoundBlockStatementNode(cse, stmts)
+
+        bin.LeaveScope()
+
+        if cse.IsDefault {
+            defaultBody = body
+            hasDefault = true
+        } else {
+            cases = append(cases, boundnodes.NewBoundSwitchCase(cse, values, cse.IsPattern, vari, pattern, body))
+        }
+    }
+
+    // switches over enums need to handle every member (or have a default)
+    if typ.TypeGroup == symbols.ENUM && !typ.Nullable && !hasDefault {
+        missing := []string{}
+        for _, mem := range typ.Enum.Members {
+            if !seen[fmt.Sprintf("%v", mem.Value)] {
+                missing = append(missing, mem.MemberName)
+            }
+        }
+
+        if len(missing) > 0 {
+            bin.Comp.Report(error.NewError(error.BND, stmt.SwitchKw.Position, "Switch on enum '%s' does not handle %s! (add the missing cases or a default)", typ.Name(), strings.Join(missing, ", ")))
+        }
+    }
+
+    // create a new node
+    return boundnodes.NewBoundSwitchStatementNode(stmt, subject, op, cases, defaultBody, hasDefault)
+}
+
 // --------------------------------------------------------
 // Expressions
 // --------------------------------------------------------
@@ -1006,6 +1593,12 @@ func (bin *Binder) bindExpression(expr syntaxnodes.ExpressionNode) boundnodes.Bo
     } else if expr.Type() == syntaxnodes.NT_AssignmentExpr {
         return bin.bindAssignmentExpression(expr.(*syntaxnodes.AssignmentExpressionNode))
 
+    } else if expr.Type() == syntaxnodes.NT_CompoundExpr {
+        return bin.bindCompoundAssignmentExpression(expr.(*syntaxnodes.CompoundAssignmentExpressionNode))
+
+    } else if expr.Type() == syntaxnodes.NT_IncrementExpr {
+        return bin.bindIncrementExpression(expr.(*syntaxnodes.IncrementExpressionNode))
+
     } else if expr.Type() == syntaxnodes.NT_UnaryExpr {
         return bin.bindUnaryExpression(expr.(*syntaxnodes.UnaryExpressionNode))
 
@@ -1015,12 +1608,18 @@ func (bin *Binder) bindExpression(expr syntaxnodes.ExpressionNode) boundnodes.Bo
     } else if expr.Type() == syntaxnodes.NT_CallExpr {
         return bin.bindCallExpression(expr.(*syntaxnodes.CallExpressionNode))
 
+    } else if expr.Type() == syntaxnodes.NT_CallValueExpr {
+        return bin.bindCallValueExpression(expr.(*syntaxnodes.CallValueExpressionNode))
+
     } else if expr.Type() == syntaxnodes.NT_NameExpr {
         return bin.bindNameExpression(expr.(*syntaxnodes.NameExpressionNode))
 
     } else if expr.Type() == syntaxnodes.NT_MakeArrayExpr {
         return bin.bindMakeArrayExpression(expr.(*syntaxnodes.MakeArrayExpressionNode))
     
+    } else if expr.Type() == syntaxnodes.NT_MakeMapExpr {
+        return bin.bindMakeMapExpression(expr.(*syntaxnodes.MakeMapExpressionNode))
+
     } else if expr.Type() == syntaxnodes.NT_ArrayIndexExpr {
         return bin.bindArrayIndexExpression(expr.(*syntaxnodes.ArrayIndexExpressionNode))
 
@@ -1030,8 +1629,14 @@ func (bin *Binder) bindExpression(expr syntaxnodes.ExpressionNode) boundnodes.Bo
     } else if expr.Type() == syntaxnodes.NT_MakeExpr {
         return bin.bindMakeExpression(expr.(*syntaxnodes.MakeExpressionNode))
 
+    } else if expr.Type() == syntaxnodes.NT_LambdaExpr {
+        return bin.bindLambdaExpression(expr.(*syntaxnodes.LambdaExpressionNode))
+
+    } else if expr.Type() == syntaxnodes.NT_TypeCheckExpr {
+        return bin.bindTypeCheckExpression(expr.(*syntaxnodes.TypeCheckExpressionNode))
+
     } else {
-        error.Report(error.NewError(error.BND, expr.Position(), "Unknown expression type '%s'!", expr.Type()))
+        bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Unknown expression type '%s'!", expr.Type()))
         return boundnodes.NewBoundErrorExpressionNode(expr)
     }
 }
@@ -1047,40 +1652,25 @@ func (bin *Binder) bindLiteralExpression(expr *syntaxnodes.LiteralExpressionNode
     // evaluate the literal expression
     if expr.Literal.Type == lexer.TT_String {
         value = expr.Literal.Buffer
-        typ = compunit.GlobalDataTypeRegister["string"]
+        typ = compunit.GlobalDataType("string")
 
     } else if expr.Literal.Type == lexer.TT_KW_True {
         value = true
-        typ = compunit.GlobalDataTypeRegister["bool"]
+        typ = compunit.GlobalDataType("bool")
 
     } else if expr.Literal.Type == lexer.TT_KW_False {
         value = false
-        typ = compunit.GlobalDataTypeRegister["bool"]
-
-    } else if expr.Literal.Type == lexer.TT_Integer {
-        val, err := strconv.ParseInt(expr.Literal.Buffer, 10, 32) 
-        
-        if err != nil {
-            error.Report(error.NewError(error.BND, expr.Position(), "Could not convert '%s' to an integer!", expr.Literal.Buffer))
-            return boundnodes.NewBoundErrorExpressionNode(expr)
-        }
+        typ = compunit.GlobalDataType("bool")
 
-        value = int32(val)
-        typ = compunit.GlobalDataTypeRegister["int"]
-
-    } else if expr.Literal.Type == lexer.TT_Float {
-        val, err := strconv.ParseFloat(expr.Literal.Buffer, 32)
-        
-        if err != nil {
-            error.Report(error.NewError(error.BND, expr.Position(), "Could not convert '%s' to a float!", expr.Literal.Buffer))
-            return boundnodes.NewBoundErrorExpressionNode(expr)
-        }
+    } else if expr.Literal.Type == lexer.TT_KW_Null {
+        value = nil
+        typ = compunit.GlobalDataType("null")
 
-        value = float32(val)
-        typ = compunit.GlobalDataTypeRegister["float"]
+    } else if expr.Literal.Type == lexer.TT_Integer || expr.Literal.Type == lexer.TT_Float {
+        return bin.bindNumberLiteral(expr, expr.Literal, false)
 
     } else {
-        error.Report(error.NewError(error.BND, expr.Position(), "Expected literal value, got: '%s' (%s)!", expr.Literal.Buffer, expr.Literal.Type))
+        bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Expected literal value, got: '%s' (%s)!", expr.Literal.Buffer, expr.Literal.Type))
         return boundnodes.NewBoundErrorExpressionNode(expr)
     }
 
@@ -1088,24 +1678,135 @@ func (bin *Binder) bindLiteralExpression(expr *syntaxnodes.LiteralExpressionNode
     return boundnodes.NewBoundLiteralExpressionNode(expr, typ, value)
 }
 
-func (bin *Binder) bindParenthesizedExpression(expr *syntaxnodes.ParenthesizedExpressionNode) boundnodes.BoundExpressionNode {
-    // bind the inner expression
-    exp := bin.bindExpression(expr.Expression)
+// Number literals
+// ---------------
+// suffixes decide the type:   b -> byte, w -> word, L -> long (integers)
+//                             f -> float, d -> double       (floats)
+// hex, binary and octal literals may use every bit of their type
+// (so 0xFFb is a byte with all bits set, aka -1)
+// a minus in front belongs to the literal, so -128b is a byte just fine
+var integerSuffixes = map[string]string{"": "int", "b": "byte", "w": "word", "l": "long", "L": "long"}
+var floatSuffixes   = map[string]string{"": "float", "f": "float", "d": "double"}
 
-    // done lol
-    return exp
-}
+func (bin *Binder) bindNumberLiteral(expr syntaxnodes.SyntaxNode, lit lexer.Token, negative bool) boundnodes.BoundExpressionNode {
+    buf := lit.Buffer
+    isFloat := lit.Type == lexer.TT_Float
 
-func (bin *Binder) bindAssignmentExpression(expr *syntaxnodes.AssignmentExpressionNode) boundnodes.BoundExpressionNode {
-    // bind the source expression
+    // (what the user wrote, for error messages)
+    text := buf
+    sign := ""
+    if negative {
+        text = "-" + buf
+        sign = "-"
+    }
+
+    // cut off the prefix
+    base := 10
+    digits := "0123456789"
+
+    prefixDigits := map[byte]string{'x': "0123456789abcdefABCDEF", 'b': "01", 'o': "01234567"}
+
+    if len(buf) > 2 && buf[0] == '0' && strings.ContainsRune(prefixDigits[buf[1]], rune(buf[2])) {
+        base   = map[byte]int{'x': 16, 'b': 2, 'o': 8}[buf[1]]
+        digits = prefixDigits[buf[1]]
+        buf    = buf[2:]
+    }
+
+    // cut off the suffix (any trailing letters that arent digits)
+    end := len(buf)
+    for end > 0 && unicode.IsLetter(rune(buf[end-1])) && !strings.ContainsRune(digits, rune(buf[end-1])) {
+        end--
+    }
+
+    number, suffix := buf[:end], buf[end:]
+
+    // what type are we going for?
+    suffixes := integerSuffixes
+    if isFloat {
+        suffixes = floatSuffixes
+    }
+
+    typName, ok := suffixes[suffix]
+    if !ok {
+        bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Unknown suffix '%s' on number literal '%s'!", suffix, text))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    typ := compunit.GlobalDataType(typName)
+    bits := typ.TypeSize
+
+    // tells apart values that are too big from ones that are just broken
+    report := func(err interface{}, kind string) boundnodes.BoundExpressionNode {
+        if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+            bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Number literal '%s' is out of range for type '%s'!", text, typName))
+        } else {
+            bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Could not convert '%s' to %s!", text, kind))
+        }
+
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // floats
+    // ------
+    if isFloat {
+        val, err := strconv.ParseFloat(sign + number, bits)
+        if err != nil {
+            return report(err, "a float")
+        }
+
+        if typName == "double" {
+            return boundnodes.NewBoundLiteralExpressionNode(expr, typ, val)
+        }
+
+        return boundnodes.NewBoundLiteralExpressionNode(expr, typ, float32(val))
+    }
+
+    // integers
+    // --------
+    val, err := strconv.ParseInt(sign + number, 10, bits)
+
+    // (these get to use the sign bit too, a minus just flips whatever bits they had)
+    if base != 10 {
+        var uval uint64
+        uval, err = strconv.ParseUint(number, base, bits)
+        val = int64(uval)
+
+        if negative {
+            val = -val
+        }
+    }
+
+    if err != nil {
+        return report(err, "an integer")
+    }
+
+    var value interface{}
+    switch typName {
+    case "long":
+        value = val
+    case "int":
+        value = int32(val)
+    case "word":
+        value = int16(val)
+    case "byte":
+        value = int8(val)
+    }
+
+    return boundnodes.NewBoundLiteralExpressionNode(expr, typ, value)
+}
+
+func (bin *Binder) bindParenthesizedExpression(expr *syntaxnodes.ParenthesizedExpressionNode) boundnodes.BoundExpressionNode {
+    // bind the inner expression
     exp := bin.bindExpression(expr.Expression)
 
-    // make sure we're allowed to assign to this type of expression
-    if exp.Type() != boundnodes.BT_NameExpr       &&
-       exp.Type() != boundnodes.BT_ArrayIndexExpr &&
-       exp.Type() != boundnodes.BT_AccessFieldExpr {
+    // done lol
+    return exp
+}
 
-        error.Report(error.NewError(error.BND, expr.Position(), "Cannot assign to expression of type '%s'!", expr.Expression.Type()))
+func (bin *Binder) bindAssignmentExpression(expr *syntaxnodes.AssignmentExpressionNode) boundnodes.BoundExpressionNode {
+    // bind the source expression
+    exp := bin.bindAssignmentTarget(expr, expr.Expression)
+    if exp == nil {
         return boundnodes.NewBoundErrorExpressionNode(expr)
     }
 
@@ -1119,16 +1820,200 @@ func (bin *Binder) bindAssignmentExpression(expr *syntaxnodes.AssignmentExpressi
     return boundnodes.NewBoundAssignmentExpressionNode(expr, exp, val)
 }
 
+func (bin *Binder) bindCompoundAssignmentExpression(expr *syntaxnodes.CompoundAssignmentExpressionNode) boundnodes.BoundExpressionNode {
+    // bind the source expression
+    exp := bin.bindAssignmentTarget(expr, expr.Expression)
+    if exp == nil {
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // bind assignment value
+    val := bin.bindExpression(expr.Value)
+
+    // look up the operator this compound is based on (+<- -> +)
+    opTok, _ := syntaxnodes.GetCompoundOperator(expr.Operator.Type)
+    op := boundnodes.GetBinaryOperator(opTok, exp.ExprType(), val.ExprType())
+
+    if op == nil {
+        bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Operator '%s' is not defined for data types '%s' and '%s'!", expr.Operator.Type, exp.ExprType().Name(), val.ExprType().Name()))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // the result has to fit back into the target, just like with a normal assignment
+    // (so no sneaky down casts, and comparisons and friends dont make sense here either)
+    if !op.Result.Equal(exp.ExprType()) && boundnodes.ClassifyConversion(op.Result, exp.ExprType()) != boundnodes.CT_Implicit {
+        bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Unable to assign result of '%s' (%s) back to data type '%s'!", expr.Operator.Type, op.Result.Name(), exp.ExprType().Name()))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // make sure the value matches the operator
+    val = bin.bindConversion(val, op.Right, false)
+
+    return boundnodes.NewBoundCompoundAssignmentExpressionNode(expr, exp, op, val, false)
+}
+
+func (bin *Binder) bindIncrementExpression(expr *syntaxnodes.IncrementExpressionNode) boundnodes.BoundExpressionNode {
+    // bind the source expression
+    exp := bin.bindAssignmentTarget(expr, expr.Expression)
+    if exp == nil {
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // only integers can be incremented
+    if exp.ExprType().TypeGroup != symbols.INT {
+        bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Operator '%s' is not defined for data type '%s'!", expr.Operator.Type, exp.ExprType().Name()))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // x++ is really just x +<- 1
+    opTok := lexer.TT_Plus
+    if expr.Operator.Type == lexer.TT_MinusMinus {
+        opTok = lexer.TT_Minus
+    }
+
+    one := boundnodes.NewBoundLiteralExpressionNode(expr, compunit.GlobalDataType("int"), int32(1))
+    op  := boundnodes.GetBinaryOperator(opTok, exp.ExprType(), exp.ExprType())
+    val := bin.bindConversion(one, exp.ExprType(), true)
+
+    return boundnodes.NewBoundCompoundAssignmentExpressionNode(expr, exp, op, val, expr.IsPostfix)
+}
+
+// binds the left side of any kind of assignment
+// returns nil (and reports an error) if we can't assign to it
+func (bin *Binder) bindAssignmentTarget(src syntaxnodes.SyntaxNode, target syntaxnodes.ExpressionNode) boundnodes.BoundExpressionNode {
+    exp := bin.bindExpression(target)
+
+    // constants and readonly fields only get set once
+    if !bin.checkWritable(src, target, exp) {
+        return nil
+    }
+
+    // make sure we're allowed to assign to this type of expression
+    if exp.Type() != boundnodes.BT_NameExpr       &&
+       exp.Type() != boundnodes.BT_ArrayIndexExpr &&
+       exp.Type() != boundnodes.BT_AccessFieldExpr {
+
+        bin.Comp.Report(error.NewError(error.BND, src.Position(), "Cannot assign to expression of type '%s'!", target.Type()))
+        return nil
+    }
+
+    // there might not be anything to assign to
+    if exp.Type() == boundnodes.BT_AccessFieldExpr && exp.(*boundnodes.BoundAccessFieldExpressionNode).NullSafe {
+        bin.Comp.Report(error.NewError(error.BND, src.Position(), "Cannot assign to a '?->' access!"))
+        return nil
+    }
+
+    return exp
+}
+
+// Make sure whatever we're about to assign to isnt read-only
+// (reports an error and returns false if it is)
+func (bin *Binder) checkWritable(src syntaxnodes.SyntaxNode, target syntaxnodes.ExpressionNode, exp boundnodes.BoundExpressionNode) bool {
+    var vari symbols.VariableSymbol
+
+    if exp.Type() == boundnodes.BT_NameExpr {
+        vari = exp.(*boundnodes.BoundNameExpressionNode).Variable
+
+    } else if exp.Type() == boundnodes.BT_AccessFieldExpr {
+        vari = exp.(*boundnodes.BoundAccessFieldExpressionNode).Field
+
+    // folded constants dont even have a variable anymore
+    } else if name, ok := target.(*syntaxnodes.NameExpressionNode); ok && exp.Type() == boundnodes.BT_LiteralExpr && exp.ExprType().Enum == nil {
+        bin.Comp.Report(error.NewError(error.BND, src.Position(), "Cannot assign to constant '%s'!", name.Identifier.Buffer))
+        return false
+    }
+
+    if lcl, ok := vari.(*symbols.LocalSymbol); ok && lcl.IsConst {
+        bin.Comp.Report(error.NewError(error.BND, src.Position(), "Cannot assign to constant '%s'!", lcl.LocalName))
+        return false
+    }
+
+    if glb, ok := vari.(*symbols.GlobalSymbol); ok && glb.IsConst {
+        bin.Comp.Report(error.NewError(error.BND, src.Position(), "Cannot assign to constant '%s'!", glb.GlobalName))
+        return false
+    }
+
+    if fld, ok := vari.(*symbols.FieldSymbol); ok && !bin.canSetField(fld) {
+        bin.Comp.Report(error.NewError(error.BND, src.Position(), "Cannot assign to readonly field '%s' outside of the Constructor of '%s'!", fld.FieldName, fld.ParentContainer.Name()))
+        return false
+    }
+
+    return true
+}
+
+// Readonly fields can only be set by the Constructor of their container
+func (bin *Binder) canSetField(fld *symbols.FieldSymbol) bool {
+    return !fld.IsReadonly || (bin.CurrentFunction != nil && bin.CurrentFunction == fld.ParentContainer.Constructor)
+}
+
+func (bin *Binder) bindTypeCheckExpression(expr *syntaxnodes.TypeCheckExpressionNode) boundnodes.BoundExpressionNode {
+    // bind the value and the type we're testing for
+    val := bin.bindExpression(expr.Value)
+    typ := bin.lookupTypeClause(expr.TestType)
+    from := val.ExprType()
+
+    // something already went wrong
+    if val.Type() == boundnodes.BT_ErrorExpr || typ.Equal(compunit.GlobalDataType("error")) {
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // we can only test things that could be more than one type
+    if !from.Equal(compunit.GlobalDataType("any")) && from.NonNullable().TypeGroup != symbols.CONT && from.NonNullable().TypeGroup != symbols.TRT {
+        bin.Comp.Report(error.NewError(error.BND, expr.Value.Position(), "Type tests can only be used on 'any', containers and traits, got '%s'!", from.Name()))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // ...and only for containers and traits
+    if typ.TypeGroup != symbols.CONT && typ.TypeGroup != symbols.TRT {
+        bin.Comp.Report(error.NewError(error.BND, expr.TestType.Position(), "Type tests can only check for containers and traits, got '%s'!", typ.Name()))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // null is never an instance of anything anyways
+    if typ.Nullable {
+        bin.Comp.Report(error.NewError(error.BND, expr.TestType.Position(), "Type tests cannot check for nullable type '%s'! (null is never an instance of anything)", typ.Name()))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // and it has to actually be possible
+    // (a trait could always be implemented by something that also implements another one)
+    bothTraits := from.NonNullable().TypeGroup == symbols.TRT && typ.TypeGroup == symbols.TRT
+    if !bothTraits && boundnodes.ClassifyConversion(from, typ) == boundnodes.CT_None {
+        bin.Comp.Report(error.NewError(error.BND, expr.Position(), "A value of type '%s' can never be a '%s'!", from.Name(), typ.Name()))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // x as Foo
+    if expr.IsCast() {
+        return boundnodes.NewBoundCheckedCastExpressionNode(expr, val, typ)
+    }
+
+    // x is Foo
+    return boundnodes.NewBoundTypeCheckExpressionNode(expr, val, typ)
+}
+
 func (bin *Binder) bindUnaryExpression(expr *syntaxnodes.UnaryExpressionNode) boundnodes.BoundExpressionNode {
+    // negative number literals -> one literal
+    // (otherwise the smallest value of each type would be out of range before we even get to negate it)
+    if lit, ok := expr.Operand.(*syntaxnodes.LiteralExpressionNode); ok && expr.Operator.Type == lexer.TT_Minus &&
+       (lit.Literal.Type == lexer.TT_Integer || lit.Literal.Type == lexer.TT_Float) {
+        return bin.bindNumberLiteral(expr, lit.Literal, true)
+    }
+
     // bind the operand
     operand := bin.bindExpression(expr.Operand)
 
+    // the operand already went wrong -> no need to complain twice
+    if operand.Type() == boundnodes.BT_ErrorExpr {
+        return operand
+    }
+
     // bind a unary operator
     op := boundnodes.GetUnaryOperator(expr.Operator.Type, operand.ExprType())
 
     // did we find a fitting operator?
     if op == nil {
-        error.Report(error.NewError(error.BND, expr.Position(), "Operator '%s' is not defined for data type '%s'!", expr.Operator.Type, operand.ExprType().Name()))
+        bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Operator '%s' is not defined for data type '%s'!", expr.Operator.Type, operand.ExprType().Name()))
         return boundnodes.NewBoundErrorExpressionNode(expr)
     }
 
@@ -1145,18 +2030,18 @@ func (bin *Binder) bindBinaryExpression(expr *syntaxnodes.BinaryExpressionNode)
 
     // did we find a fitting operator?
     if op == nil {
-        error.Report(error.NewError(error.BND, expr.Position(), "Operator '%s' is not defined for data types '%s' and '%s'!", expr.Operator.Type, left.ExprType().Name(), right.ExprType().Name()))
+        bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Operator '%s' is not defined for data types '%s' and '%s'!", expr.Operator.Type, left.ExprType().Name(), right.ExprType().Name()))
         return boundnodes.NewBoundErrorExpressionNode(expr)
     }
 
-    // do we need to up cast the left side?
-    if left.ExprType().TypeSize < right.ExprType().TypeSize {
-        left = bin.bindConversion(left, right.ExprType(), false)
+    // do we need to promote the left side?
+    if !left.ExprType().Equal(op.Left) {
+        left = bin.bindConversion(left, op.Left, false)
     }
 
-    // do we need to up cast the right side?
-    if left.ExprType().TypeSize > right.ExprType().TypeSize {
-        right = bin.bindConversion(right, left.ExprType(), false)
+    // do we need to promote the right side?
+    if !right.ExprType().Equal(op.Right) {
+        right = bin.bindConversion(right, op.Right, false)
     }
 
     return boundnodes.NewBoundBinaryExpressionNode(expr, op, left, right)
@@ -1167,10 +2052,20 @@ func (bin *Binder) bindCallExpression(expr *syntaxnodes.CallExpressionNode) boun
     // is this actually a cast?
     if !expr.HasPackage && len(expr.Parameters) == 1 {
         // are we calling a type name?
-        typ := LookupType(expr.Identifier.Buffer, expr.Identifier.Position, bin.CurrentPackage, true)
+        typ := LookupType(bin.Comp, expr.Identifier.Buffer, expr.Identifier.Position, bin.CurrentPackage, true)
+
+        // (or the name of a type parameter?)
+        for _, v := range bin.typeParameters() {
+            if v.TypeName == expr.Identifier.Buffer {
+                typ = v
+            }
+        }
         
         // if so -> bind a conversion
         if typ != nil {
+            // (generic types cant be casted to without knowing their type arguments)
+            typ = instantiateType(bin.Comp, typ, []*symbols.TypeSymbol{}, expr.Identifier.Position)
+
             exp := bin.bindExpression(expr.Parameters[0])
             return bin.bindConversion(exp, typ, true)
         }
@@ -1183,8 +2078,23 @@ func (bin *Binder) bindCallExpression(expr *syntaxnodes.CallExpressionNode) boun
 
         // if we got something -> bind a conversion
         if cnt != nil {
+            typ := instantiateType(bin.Comp, cnt.ContainerType, []*symbols.TypeSymbol{}, expr.Identifier.Position)
+
             exp := bin.bindExpression(expr.Parameters[0])
-            return bin.bindConversion(exp, cnt.ContainerType, true)
+            return bin.bindConversion(exp, typ, true)
+        }
+    }
+
+    // are we calling a variable that holds a function?
+    if !expr.HasPackage {
+        vari, scp := bin.CurrentScope.LookupVariableScope(expr.Identifier.Buffer)
+
+        if vari != nil && vari.VarType().TypeGroup == symbols.FUNC {
+            if !bin.captureVariable(vari, scp, expr.Identifier.Position) {
+                return boundnodes.NewBoundErrorExpressionNode(expr)
+            }
+
+            return bin.bindCallValue(expr, boundnodes.NewBoundNameExpressionNode(expr, vari), expr.Parameters)
         }
     }
 
@@ -1201,17 +2111,23 @@ func (bin *Binder) bindCallExpression(expr *syntaxnodes.CallExpressionNode) boun
 
     if fnc == nil {
         if !expr.HasPackage {
-            error.Report(error.NewError(error.BND, expr.Identifier.Position, "Could not find function '%s'!", expr.Identifier.Buffer))
+            bin.Comp.Report(error.NewError(error.BND, expr.Identifier.Position, "Could not find function '%s'!", expr.Identifier.Buffer))
             return boundnodes.NewBoundErrorExpressionNode(expr)
         } else {
-            error.Report(error.NewError(error.BND, expr.Identifier.Position.SpanBetween(expr.Package.Position), "Could not find function '%s::%s'!", expr.Package.Buffer, expr.Identifier.Buffer))
+            bin.Comp.Report(error.NewError(error.BND, expr.Identifier.Position.SpanBetween(expr.Package.Position), "Could not find function '%s::%s'!", expr.Package.Buffer, expr.Identifier.Buffer))
             return boundnodes.NewBoundErrorExpressionNode(expr)
         }
     }
+
+    // lambdas dont have a 'this' to call methods on
+    if fnc.FunctionKind == symbols.FT_METH && len(bin.Lambdas) > 0 {
+        bin.Comp.Report(error.NewError(error.BND, expr.Identifier.Position, "Lambdas cannot call method '%s' without an instance! (lambdas have no 'this')", fnc.FuncName))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
     
     // was the right amount of arguments given?
     if len(fnc.Parameters) != len(expr.Parameters) {
-        error.Report(error.NewError(error.BND, expr.Position(), "Function '%s' expects %d arguments, got: %d!", fnc.FuncName, len(fnc.Parameters), len(expr.Parameters)))
+        bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Function '%s' expects %d arguments, got: %d!", fnc.FuncName, len(fnc.Parameters), len(expr.Parameters)))
         return boundnodes.NewBoundErrorExpressionNode(expr)
     }
 
@@ -1221,21 +2137,92 @@ func (bin *Binder) bindCallExpression(expr *syntaxnodes.CallExpressionNode) boun
         args = append(args, bin.bindExpression(v))
     }
 
+    // if this is a generic function -> figure out what its type arguments are
+    typargs := make([]*symbols.TypeSymbol, len(fnc.TypeParameters))
+    for i := range fnc.Parameters {
+        inferTypeArguments(fnc.Parameters[i].VarType(), args[i].ExprType(), fnc.TypeParameters, typargs)
+    }
+
+    for i, v := range typargs {
+        if v == nil {
+            bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Unable to infer type parameter '%s' of function '%s'!", fnc.TypeParameters[i].Name(), fnc.FuncName))
+            return boundnodes.NewBoundErrorExpressionNode(expr)
+        }
+    }
+
     // make sure the datatypes match up
     for i := range fnc.Parameters {
-        args[i] = bin.bindConversion(args[i], fnc.Parameters[i].VarType(), false)
+        args[i] = bin.bindConversion(args[i], symbols.Substitute(fnc.Parameters[i].VarType(), fnc.TypeParameters, typargs), false)
     }
 
     // ok cool
-    return boundnodes.NewBoundCallExpressionNode(expr, fnc, args)
+    ret := symbols.Substitute(fnc.ReturnType, fnc.TypeParameters, typargs)
+    return boundnodes.NewBoundCallExpressionNode(expr, fnc, args, ret)
+}
+
+// Call whatever function value we were given
+// ------------------------------------------
+func (bin *Binder) bindCallValue(src syntaxnodes.SyntaxNode, val boundnodes.BoundExpressionNode, params []syntaxnodes.ExpressionNode) boundnodes.BoundExpressionNode {
+    prms := val.ExprType().FunctionParameters()
+
+    // was the right amount of arguments given?
+    if len(prms) != len(params) {
+        bin.Comp.Report(error.NewError(error.BND, src.Position(), "Function of type '%s' expects %d arguments, got: %d!", val.ExprType().Name(), len(prms), len(params)))
+        return boundnodes.NewBoundErrorExpressionNode(src)
+    }
+
+    // bind all args (and make sure the datatypes match up)
+    args := []boundnodes.BoundExpressionNode{}
+    for i, v := range params {
+        args = append(args, bin.bindConversion(bin.bindExpression(v), prms[i], false))
+    }
+
+    return boundnodes.NewBoundCallValueExpressionNode(src, val, args)
+}
+
+func (bin *Binder) bindCallValueExpression(expr *syntaxnodes.CallValueExpressionNode) boundnodes.BoundExpressionNode {
+    // bind whatever is being called
+    val := bin.bindExpression(expr.Expression)
+
+    // something already went wrong -> no need to complain twice
+    if val.Type() == boundnodes.BT_ErrorExpr {
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // only functions can be called
+    if val.ExprType().TypeGroup != symbols.FUNC {
+        bin.Comp.Report(error.NewError(error.BND, expr.Expression.Position(), "Unable to call a value of non function type '%s'!", val.ExprType().Name()))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    return bin.bindCallValue(expr, val, expr.Parameters)
 }
 
 func (bin *Binder) bindNameExpression(expr *syntaxnodes.NameExpressionNode) boundnodes.BoundExpressionNode {
     // is this a global name expression?
     if expr.HasPackage {
+        // enum members look just like globals (Color::Red)
+        enm := LookupEnum(expr.PackageName.Buffer, bin.CurrentPackage)
+        if enm != nil {
+            mem := enm.LookupMember(expr.Identifier.Buffer)
+            if mem == nil {
+                bin.Comp.Report(error.NewError(error.BND, expr.Identifier.Position, "Enum '%s' has no member '%s'!", enm.EnumName, expr.Identifier.Buffer))
+                return boundnodes.NewBoundErrorExpressionNode(expr)
+            }
+
+            // members are known at compile time -> just use the value
+            return boundnodes.NewBoundLiteralExpressionNode(expr, enm.EnumType, mem.Value)
+        }
+
+        // functions of other packages can be used as values too (sys::Print)
+        fnc := bin.LookupFunctionInPackage(expr.PackageName.Buffer, expr.Identifier.Buffer)
+        if fnc != nil {
+            return bin.bindFunctionValue(expr, fnc)
+        }
+
         // only globals in this package are accessible
         if bin.CurrentPackage.Name() != expr.PackageName.Buffer {
-            error.Report(error.NewError(error.BND, expr.Position(), "Unable to resolve global '%s' in package '%s': only globals in the current package ('%s') are allowed to be accessed!", expr.Identifier.Buffer, expr.PackageName.Buffer, bin.CurrentPackage.Name()))
+            bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Unable to resolve global '%s' in package '%s': only globals in the current package ('%s') are allowed to be accessed!", expr.Identifier.Buffer, expr.PackageName.Buffer, bin.CurrentPackage.Name()))
             return boundnodes.NewBoundErrorExpressionNode(expr)
         }
 
@@ -1245,30 +2232,166 @@ func (bin *Binder) bindNameExpression(expr *syntaxnodes.NameExpressionNode) boun
 
         // did we find one?
         if glb == nil {
-            error.Report(error.NewError(error.BND, expr.Position(), "Could not find global called '%s'!", expr.Identifier.Buffer))
+            bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Could not find global called '%s'!", expr.Identifier.Buffer))
             return boundnodes.NewBoundErrorExpressionNode(expr)
         }
 
+        // constants are known at compile time
+        if val := bin.bindConstantValue(expr, glb); val != nil {
+            return val
+        }
+
         // ok cool
         return boundnodes.NewBoundNameExpressionNode(expr, glb)
     }
 
     // look up variable
-    vari := bin.CurrentScope.LookupVariable(expr.Identifier.Buffer)
+    vari, scp := bin.CurrentScope.LookupVariableScope(expr.Identifier.Buffer)
 
     // did we find one?
     if vari == nil {
-        error.Report(error.NewError(error.BND, expr.Position(), "Could not find variable called '%s'!", expr.Identifier.Buffer))
+        // no? -> maybe it's a function being used as a value
+        fnc := bin.LookupFunction(expr.Identifier.Buffer)
+        if fnc != nil {
+            return bin.bindFunctionValue(expr, fnc)
+        }
+
+        bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Could not find variable called '%s'!", expr.Identifier.Buffer))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // constants are known at compile time
+    if val := bin.bindConstantValue(expr, vari); val != nil {
+        return val
+    }
+
+    // variables from outside of a lambda need to be brought along
+    if !bin.captureVariable(vari, scp, expr.Position()) {
         return boundnodes.NewBoundErrorExpressionNode(expr)
     }
 
+    // do we know better what this is? (if (x is Foo))
+    if typ := bin.CurrentScope.LookupNarrowing(vari); typ != nil {
+        return boundnodes.NewBoundConversionExpressionNode(expr, boundnodes.NewBoundNameExpressionNode(expr, vari), typ)
+    }
+
     // ok cool
     return boundnodes.NewBoundNameExpressionNode(expr, vari)
 }
 
+// A function used as a value instead of being called
+// --------------------------------------------------
+func (bin *Binder) bindFunctionValue(src syntaxnodes.SyntaxNode, fnc *symbols.FunctionSymbol) boundnodes.BoundExpressionNode {
+    // methods would need an instance to go with them
+    if fnc.FunctionKind == symbols.FT_METH {
+        bin.Comp.Report(error.NewError(error.BND, src.Position(), "Method '%s' cannot be used as a value! (wrap it in a lambda)", fnc.FuncName))
+        return boundnodes.NewBoundErrorExpressionNode(src)
+    }
+
+    // and generic functions dont know what their type arguments are
+    if len(fnc.TypeParameters) > 0 {
+        bin.Comp.Report(error.NewError(error.BND, src.Position(), "Generic function '%s' cannot be used as a value!", fnc.FuncName))
+        return boundnodes.NewBoundErrorExpressionNode(src)
+    }
+
+    return boundnodes.NewBoundFunctionExpressionNode(src, fnc)
+}
+
+// Lambdas
+// -------
+// function(x int y int) bool { ... }
+func (bin *Binder) bindLambdaExpression(expr *syntaxnodes.LambdaExpressionNode) boundnodes.BoundExpressionNode {
+    // create parameter symbols
+    prms := []*symbols.ParameterSymbol{}
+    for i, prm := range expr.Parameters {
+        prms = append(prms, symbols.NewParameterSymbol(prm.ParameterName.Buffer, i, bin.lookupTypeClause(prm.ParameterType)))
+    }
+
+    ret := compunit.GlobalDataType("void")
+    if expr.HasReturnType {
+        ret = bin.lookupTypeClause(expr.ReturnType)
+    }
+
+    // lambdas are just functions without a name (so we make one up)
+    bin.CurrentPackage.LambdaCount++
+    sym := symbols.NewFunctionSymbol(bin.CurrentPackage, fmt.Sprintf("__lambda%d", bin.CurrentPackage.LambdaCount), ret, prms)
+    sym.IsLambda = true
+
+    // returns, breaks and continues only mean something inside of the lambda now
+    outer, outerBody := bin.CurrentFunction, bin.CurrentBody
+    brk, cnt := bin.BreakLabels, bin.ContinueLabels
+
+    bin.CurrentFunction = sym
+    bin.CurrentBody = expr.Body
+    bin.BreakLabels = []boundnodes.BoundLabel{}
+    bin.ContinueLabels = []boundnodes.BoundLabel{}
+
+    // register the parameters in a new scope
+    bin.EnterNewScope()
+    for _, v := range prms {
+        bin.CurrentScope.RegisterVariable(v)
+    }
+
+    bin.Lambdas = append(bin.Lambdas, &LambdaContext{
+        Function: sym,
+        Outer: outer,
+        Scope: bin.CurrentScope,
+    })
+
+    body := bin.bindStatement(expr.Body)
+
+    // and back to where we were
+    bin.Lambdas = bin.Lambdas[:len(bin.Lambdas)-1]
+    bin.LeaveScope()
+
+    bin.CurrentFunction, bin.CurrentBody = outer, outerBody
+    bin.BreakLabels, bin.ContinueLabels = brk, cnt
+
+    // the body gets lowered and compiled like any other function
+    bin.File.Functions = append(bin.File.Functions, sym)
+    bin.File.FunctionBodies[sym] = body
+
+    return boundnodes.NewBoundFunctionExpressionNode(expr, sym)
+}
+
+// Mark a variable as captured by every lambda it comes from outside of
+// (returns false and reports an error if it can't be captured)
+// --------------------------------------------------------------------
+func (bin *Binder) captureVariable(vari symbols.VariableSymbol, scp *Scope, pos span.Span) bool {
+    for _, ctx := range bin.Lambdas {
+        // declared inside of this lambda -> nothing to capture
+        if !ctx.Scope.IsBelow(scp) {
+            continue
+        }
+
+        switch v := vari.(type) {
+        case *symbols.LocalSymbol:
+            v.Captured = true
+
+        case *symbols.ParameterSymbol:
+            v.Captured = true
+
+        case *symbols.GlobalSymbol:
+            // globals are reachable from everywhere anyways
+            continue
+
+        default:
+            // fields and 'this' would need the instance to come along
+            bin.Comp.Report(error.NewError(error.BND, pos, "Lambdas cannot use '%s'! (lambdas have no 'this', copy it into a local first)", vari.Name()))
+            return false
+        }
+
+        if !slices.Contains(ctx.Function.Captures, vari) {
+            ctx.Function.Captures = append(ctx.Function.Captures, vari)
+        }
+    }
+
+    return true
+}
+
 func (bin *Binder) bindMakeArrayExpression(expr *syntaxnodes.MakeArrayExpressionNode) boundnodes.BoundExpressionNode {
     // resolve the array type
-    typ := LookupTypeClause(expr.ArrType, bin.CurrentPackage)
+    typ := bin.lookupTypeClause(expr.ArrType)
 
     // create an array type for it
     arrtyp := createArrayType(typ)
@@ -1282,7 +2405,7 @@ func (bin *Binder) bindMakeArrayExpression(expr *syntaxnodes.MakeArrayExpression
         length = bin.bindExpression(expr.Length)
         
         // make sure its an int
-        length = bin.bindConversion(length, compunit.GlobalDataTypeRegister["int"], false)
+        length = bin.bindConversion(length, compunit.GlobalDataType("int"), false)
 
     } else {
         // bind each element of the initializer
@@ -1300,13 +2423,42 @@ func (bin *Binder) bindMakeArrayExpression(expr *syntaxnodes.MakeArrayExpression
     return boundnodes.NewBoundMakeArrayExpressionNode(expr, arrtyp, length, initializer, expr.HasInitializers)
 }
 
+func (bin *Binder) bindMakeMapExpression(expr *syntaxnodes.MakeMapExpressionNode) boundnodes.BoundExpressionNode {
+    // resolve key and value types
+    keytyp := bin.lookupTypeClause(expr.KeyType)
+    valtyp := bin.lookupTypeClause(expr.ValueType)
+
+    // create a map type for them
+    maptyp := createMapType(keytyp, valtyp)
+
+    // bind every entry of the literal
+    keys := []boundnodes.BoundExpressionNode{}
+    vals := []boundnodes.BoundExpressionNode{}
+
+    for i := range expr.Keys {
+        // make sure the types match
+        keys = append(keys, bin.bindConversion(bin.bindExpression(expr.Keys[i]), keytyp, false))
+        vals = append(vals, bin.bindConversion(bin.bindExpression(expr.Values[i]), valtyp, false))
+    }
+
+    return boundnodes.NewBoundMakeMapExpressionNode(expr, maptyp, keys, vals)
+}
+
 func (bin *Binder) bindArrayIndexExpression(expr *syntaxnodes.ArrayIndexExpressionNode) boundnodes.BoundExpressionNode {
     // bind the source of the array
     src := bin.bindExpression(expr.Expression)
 
+    // maps are indexed by their key
+    if src.ExprType().TypeGroup == symbols.MAP {
+        key := bin.bindExpression(expr.Index)
+        key = bin.bindConversion(key, src.ExprType().SubTypes[0], false)
+
+        return boundnodes.NewBoundArrayIndexExpressionNode(expr, src, key)
+    }
+
     // make sure the src is an array
     if src.ExprType().TypeGroup != symbols.ARR {
-        error.Report(error.NewError(error.BND, expr.Expression.Position(), "Indexing is only allowed on array types, got '%s'!", src.ExprType().Name()))
+        bin.Comp.Report(error.NewError(error.BND, expr.Expression.Position(), "Indexing is only allowed on array and map types, got '%s'!", src.ExprType().Name()))
         return boundnodes.NewBoundErrorExpressionNode(expr)
     }
 
@@ -1314,7 +2466,7 @@ func (bin *Binder) bindArrayIndexExpression(expr *syntaxnodes.ArrayIndexExpressi
     idx := bin.bindExpression(expr.Index)
 
     // make sure the index is an int
-    idx = bin.bindConversion(idx, compunit.GlobalDataTypeRegister["int"], false)
+    idx = bin.bindConversion(idx, compunit.GlobalDataType("int"), false)
 
     // ok cool
     return boundnodes.NewBoundArrayIndexExpressionNode(expr, src, idx)
@@ -1330,46 +2482,90 @@ func (bin *Binder) bindAccessExpression(expr *syntaxnodes.AccessExpressionNode)
 
     // damn but like, is this even a container?
     if src.ExprType().TypeGroup != symbols.CONT && src.ExprType().TypeGroup != symbols.TRT {
-        error.Report(error.NewError(error.BND, expr.Identifier.Position, "Unable to access a field on non container or trait type '%s'!", src.ExprType().Name()))
+        bin.Comp.Report(error.NewError(error.BND, expr.Identifier.Position, "Unable to access a field on non container or trait type '%s'!", src.ExprType().Name()))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    if !bin.checkNullSafety(expr, src.ExprType()) {
         return boundnodes.NewBoundErrorExpressionNode(expr)
     }
 
+    fld, typ := lookupFieldOf(expr.Identifier.Buffer, src.ExprType())
+
+    // did we actually find something?
+    if fld == nil {
+        bin.Comp.Report(error.NewError(error.BND, expr.Identifier.Position, "Did not find field '%s' in container or trait type '%s'!", expr.Identifier.Buffer, src.ExprType().Name()))
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // ?-> might not reach the field at all
+    if expr.IsNullSafe && typ.CanBeNullable() {
+        typ = symbols.NewNullableType(typ)
+    }
+
+    // ok cool
+    return boundnodes.NewBoundAccessFieldExpressionNode(expr, src, fld, typ, expr.IsNullSafe)
+}
+
+// Look up a field of a container or trait type
+// (also hands back the type of the field, with the type arguments of the instance filled in)
+func lookupFieldOf(name string, src *symbols.TypeSymbol) (*symbols.FieldSymbol, *symbols.TypeSymbol) {
     var fld *symbols.FieldSymbol
+    var prms []*symbols.TypeSymbol
 
-    if src.ExprType().TypeGroup == symbols.TRT {
+    if src.TypeGroup == symbols.TRT {
         // look up the field in a trait
-        fld = LookupFieldInTrait(expr.Identifier.Buffer, src.ExprType().Trait) 
-    } else {
+        fld = LookupFieldInTrait(name, src.Trait)
+        prms = src.Trait.TypeParameters
+    } else if src.TypeGroup == symbols.CONT {
         // look up the field in a container
-        fld = LookupFieldInContainer(expr.Identifier.Buffer, src.ExprType().Container) 
+        fld = LookupFieldInContainer(name, src.Container)
+        prms = src.Container.TypeParameters
     }
 
-    // did we actually find something?
     if fld == nil {
-        error.Report(error.NewError(error.BND, expr.Identifier.Position, "Did not find field '%s' in container or trait type '%s'!", expr.Identifier.Buffer, src.ExprType().Name()))
-        return boundnodes.NewBoundErrorExpressionNode(expr)
+        return nil, nil
     }
 
-    // ok cool
-    return boundnodes.NewBoundAccessFieldExpressionNode(expr, src, fld)
+    // fill in the type arguments (a Box[int] holds an int, not a T)
+    return fld, symbols.Substitute(fld.FieldType, prms, src.SubTypes)
 }
 
 func (bin *Binder) bindAccessCallExpression(expr *syntaxnodes.AccessExpressionNode) boundnodes.BoundExpressionNode {
     // bind the source expression
     src := bin.bindExpression(expr.Expression)
 
+    if !bin.checkNullSafety(expr, src.ExprType()) {
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
     // lookup this method
-    meth := bin.LookupMethod(expr.Identifier.Buffer, src.ExprType())
+    meth := bin.LookupMethod(expr.Identifier.Buffer, src.ExprType().NonNullable())
+
+    // no method, but maybe a field holding a function?
+    if meth == nil {
+        fld, typ := lookupFieldOf(expr.Identifier.Buffer, src.ExprType().NonNullable())
+
+        if fld != nil && typ.TypeGroup == symbols.FUNC {
+            // ?-> would have to skip the call as well, thats not something we can do (yet)
+            if expr.IsNullSafe {
+                bin.Comp.Report(error.NewError(error.BND, expr.Identifier.Position, "Unable to call function field '%s' through '?->'! (convert the instance to '%s' first)", fld.FieldName, src.ExprType().NonNullable().Name()))
+                return boundnodes.NewBoundErrorExpressionNode(expr)
+            }
+
+            return bin.bindCallValue(expr, boundnodes.NewBoundAccessFieldExpressionNode(expr, src, fld, typ, false), expr.Arguments)
+        }
+    }
 
     // did we find something?
     if meth == nil {
-        error.Report(error.NewError(error.BND, expr.Identifier.Position, "Could not find method '%s' for type '%s'!", expr.Identifier.Buffer, src.ExprType().Name()))
+        bin.Comp.Report(error.NewError(error.BND, expr.Identifier.Position, "Could not find method '%s' for type '%s'!", expr.Identifier.Buffer, src.ExprType().Name()))
         return boundnodes.NewBoundErrorExpressionNode(expr)
     }
 
     // was the right amount of arguments given?
     if len(meth.Parameters) != len(expr.Arguments) {
-        error.Report(error.NewError(error.BND, expr.Position(), "Method '%s' expects %d arguments, got: %d!", meth.FuncName, len(meth.Parameters), len(expr.Arguments)))
+        bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Method '%s' expects %d arguments, got: %d!", meth.FuncName, len(meth.Parameters), len(expr.Arguments)))
         return boundnodes.NewBoundErrorExpressionNode(expr)
     }
 
@@ -1379,13 +2575,57 @@ func (bin *Binder) bindAccessCallExpression(expr *syntaxnodes.AccessExpressionNo
         args = append(args, bin.bindExpression(v))
     }
 
+    // fill in the type arguments of the instance (if there are any)
+    prms, typargs := typeArgumentsFor(meth, src.ExprType())
+
+    // generic methods figure out the rest from their arguments (arr->Map(...))
+    if len(meth.TypeParameters) > 0 {
+        own := make([]*symbols.TypeSymbol, len(meth.TypeParameters))
+        for i := range meth.Parameters {
+            inferTypeArguments(symbols.Substitute(meth.Parameters[i].VarType(), prms, typargs), args[i].ExprType(), meth.TypeParameters, own)
+        }
+
+        for i, v := range own {
+            if v == nil {
+                bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Unable to infer type parameter '%s' of method '%s'!", meth.TypeParameters[i].Name(), meth.FuncName))
+                return boundnodes.NewBoundErrorExpressionNode(expr)
+            }
+        }
+
+        prms = append(append([]*symbols.TypeSymbol{}, prms...), meth.TypeParameters...)
+        typargs = append(append([]*symbols.TypeSymbol{}, typargs...), own...)
+    }
+
     // make sure the datatypes match up
     for i := range meth.Parameters {
-        args[i] = bin.bindConversion(args[i], meth.Parameters[i].VarType(), false)
+        args[i] = bin.bindConversion(args[i], symbols.Substitute(meth.Parameters[i].VarType(), prms, typargs), false)
     }
 
     // ok cool
-    return boundnodes.NewBoundAccessCallExpressionNode(expr, src, meth, args)
+    ret := symbols.Substitute(meth.ReturnType, prms, typargs)
+
+    // ?-> might not call the method at all
+    if expr.IsNullSafe && ret.CanBeNullable() {
+        ret = symbols.NewNullableType(ret)
+    }
+
+    return boundnodes.NewBoundAccessCallExpressionNode(expr, src, meth, args, ret, expr.IsNullSafe)
+}
+
+// Nullable instances need ?->, everything else needs ->
+// (returns false and reports an error if the wrong one was used)
+func (bin *Binder) checkNullSafety(expr *syntaxnodes.AccessExpressionNode, typ *symbols.TypeSymbol) bool {
+    if typ.Nullable && !expr.IsNullSafe {
+        bin.Comp.Report(error.NewError(error.BND, expr.Identifier.Position, "Unable to access '%s' on nullable type '%s'! (use '?->' or convert it to '%s' first)", expr.Identifier.Buffer, typ.Name(), typ.NonNullable().Name()))
+        return false
+    }
+
+    if !typ.Nullable && expr.IsNullSafe && !typ.CanBeNullable() {
+        bin.Comp.Report(error.NewError(error.BND, expr.Identifier.Position, "Unable to use '?->' on type '%s'! (it can never be null)", typ.Name()))
+        return false
+    }
+
+    return true
 }
 
 func (bin *Binder) bindMakeExpression(expr *syntaxnodes.MakeExpressionNode) boundnodes.BoundExpressionNode {
@@ -1403,7 +2643,18 @@ func (bin *Binder) bindMakeExpression(expr *syntaxnodes.MakeExpressionNode) boun
 
     // did we find it?
     if cnt == nil {
-        error.Report(error.NewError(error.BND, expr.Container.Position, "Unable to find a container called '%s'!", expr.Container.Buffer)) 
+        bin.Comp.Report(error.NewError(error.BND, expr.Container.Position, "Unable to find a container called '%s'!", expr.Container.Buffer)) 
+        return boundnodes.NewBoundErrorExpressionNode(expr)
+    }
+
+    // resolve the type arguments (if this is a generic container)
+    typargs := []*symbols.TypeSymbol{}
+    for _, v := range expr.TypeArguments {
+        typargs = append(typargs, bin.lookupTypeClause(v))
+    }
+
+    typ := instantiateType(bin.Comp, cnt.ContainerType, typargs, expr.Container.Position)
+    if typ.TypeGroup != symbols.CONT {
         return boundnodes.NewBoundErrorExpressionNode(expr)
     }
 
@@ -1416,14 +2667,20 @@ func (bin *Binder) bindMakeExpression(expr *syntaxnodes.MakeExpressionNode) boun
             field := LookupFieldInContainer(v.FieldName.Buffer, cnt)
 
             if field == nil {
-                error.Report(error.NewError(error.BND, v.FieldName.Position, "Unable to find field '%s' in container '%s'!", v.FieldName.Buffer, cnt.Name())) 
+                bin.Comp.Report(error.NewError(error.BND, v.FieldName.Position, "Unable to find field '%s' in container '%s'!", v.FieldName.Buffer, cnt.Name())) 
+                return boundnodes.NewBoundErrorExpressionNode(expr)
+            }
+
+            // readonly fields are the Constructors business
+            if !bin.canSetField(field) {
+                bin.Comp.Report(error.NewError(error.BND, v.FieldName.Position, "Cannot assign to readonly field '%s' outside of the Constructor of '%s'!", field.FieldName, cnt.Name()))
                 return boundnodes.NewBoundErrorExpressionNode(expr)
             }
 
             val := bin.bindExpression(v.Value)
 
             // make sure the types match up
-            val = bin.bindConversion(val, field.VarType(), false)
+            val = bin.bindConversion(val, symbols.Substitute(field.VarType(), cnt.TypeParameters, typ.SubTypes), false)
 
             // add it to the list
             initializer[field] = val
@@ -1434,26 +2691,26 @@ func (bin *Binder) bindMakeExpression(expr *syntaxnodes.MakeExpressionNode) boun
     if expr.HasConstructor {
         // does the container even have a constructor??
         if cnt.Constructor == nil {
-            error.Report(error.NewError(error.BND, expr.Position(), "Unable to call constructor: container '%s' does not have a constructor!", cnt.Name()))
+            bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Unable to call constructor: container '%s' does not have a constructor!", cnt.Name()))
             return boundnodes.NewBoundErrorExpressionNode(expr)
         }
 
         // otherwise -> make sure the call is correct
         if len(cnt.Constructor.Parameters) != len(expr.ConstructorArguments) {
-            error.Report(error.NewError(error.BND, expr.Position(), "Unable to call constructor: constructor for container '%s' expects %d arguments, got %d!", cnt.Name(), len(cnt.Constructor.Parameters), len(expr.ConstructorArguments)))
+            bin.Comp.Report(error.NewError(error.BND, expr.Position(), "Unable to call constructor: constructor for container '%s' expects %d arguments, got %d!", cnt.Name(), len(cnt.Constructor.Parameters), len(expr.ConstructorArguments)))
             return boundnodes.NewBoundErrorExpressionNode(expr)
         }
 
         // bind all args, make sure the types match up
         for i, v := range expr.ConstructorArguments {
             val := bin.bindExpression(v)
-            val = bin.bindConversion(val, cnt.Constructor.Parameters[i].VarType(), false)
+            val = bin.bindConversion(val, symbols.Substitute(cnt.Constructor.Parameters[i].VarType(), cnt.TypeParameters, typ.SubTypes), false)
             args = append(args, val)
         }
     }
 
     // create the node
-    return boundnodes.NewBoundMakeExpressionNode(expr, cnt, initializer, expr.HasInitializer, args, expr.HasConstructor)
+    return boundnodes.NewBoundMakeExpressionNode(expr, cnt, typ, initializer, expr.HasInitializer, args, expr.HasConstructor)
 }
 
 // --------------------------------------------------------
@@ -1463,9 +2720,15 @@ func (bin *Binder) bindConversion(expr boundnodes.BoundExpressionNode, typ *symb
     // lookup this converion 
     con := boundnodes.ClassifyConversion(expr.ExprType(), typ)
 
+    // null only fits into nullable types, give a hint if someone forgot the question mark
+    if con == boundnodes.CT_None && expr.ExprType().Equal(compunit.GlobalDataType("null")) && typ.CanBeNullable() {
+        bin.Comp.Report(error.NewError(error.BND, expr.Source().Position(), "Unable to convert null into non-nullable type '%s'! (did you mean '%s?')", typ.Name(), typ.Name()))
+        return boundnodes.NewBoundErrorExpressionNode(expr.Source())
+    }
+
     // no conversion exists
     if con == boundnodes.CT_None {
-        error.Report(error.NewError(error.BND, expr.Source().Position(), "Unable to convert type '%s' into '%s'!", expr.ExprType().Name(), typ.Name()))
+        bin.Comp.Report(error.NewError(error.BND, expr.Source().Position(), "Unable to convert type '%s' into '%s'!", expr.ExprType().Name(), typ.Name()))
         return boundnodes.NewBoundErrorExpressionNode(expr.Source())
     }
     
@@ -1477,10 +2740,15 @@ func (bin *Binder) bindConversion(expr boundnodes.BoundExpressionNode, typ *symb
 
     // explicit conversion exists, but explicit isnt allowed
     if con == boundnodes.CT_Explicit && !explicit {
-        error.Report(error.NewError(error.BND, expr.Source().Position(), "Unable to implicitly convert type '%s' into '%s'! An explicit conversion exists (are you missing a cast?)", expr.ExprType().Name(), typ.Name()))
+        bin.Comp.Report(error.NewError(error.BND, expr.Source().Position(), "Unable to implicitly convert type '%s' into '%s'! An explicit conversion exists (are you missing a cast?)", expr.ExprType().Name(), typ.Name()))
         return boundnodes.NewBoundErrorExpressionNode(expr.Source())
     }
 
+    // null doesnt need converting, it just takes on the type it's assigned to
+    if expr.ExprType().Equal(compunit.GlobalDataType("null")) {
+        return boundnodes.NewBoundLiteralExpressionNode(expr.Source(), typ, nil)
+    }
+
     // otherwise -> we cool
     return boundnodes.NewBoundConversionExpressionNode(expr.Source(), expr, typ)
 }
@@ -1488,9 +2756,9 @@ func (bin *Binder) bindConversion(expr boundnodes.BoundExpressionNode, typ *symb
 // --------------------------------------------------------
 // Helper functions
 // --------------------------------------------------------
-func LookupType(name string, pos span.Span, pck *symbols.PackageSymbol, canfail bool) *symbols.TypeSymbol {
+func LookupType(comp *compunit.Compilation, name string, pos span.Span, pck *symbols.PackageSymbol, canfail bool) *symbols.TypeSymbol {
     // lookup primitives
-    typ, ok := compunit.GlobalDataTypeRegister[name]
+    typ, ok := compunit.LookupGlobalDataType(name)
     if ok {
         return typ
     }
@@ -1507,39 +2775,107 @@ func LookupType(name string, pos span.Span, pck *symbols.PackageSymbol, canfail
         return trt.TraitType
     }
 
+    // lookup enums
+    enm := LookupEnum(name, pck)
+    if enm != nil {
+        return enm.EnumType
+    }
+
     // if this allowed to fail -> do that
     if canfail {
         return nil
     }
 
     // otherwise -> DIE!!!!! (but like, gently, no crashing here :) ) 
-    error.Report(error.NewError(error.BND, pos, "Unknown data type '%s'!", name))
-    return compunit.GlobalDataTypeRegister["error"]
+    comp.Report(error.NewError(error.BND, pos, "Unknown data type '%s'!", name))
+    return compunit.GlobalDataType("error")
 }
 
-func LookupTypeClause(typ *syntaxnodes.TypeClauseNode, pack *symbols.PackageSymbol) *symbols.TypeSymbol {
+func LookupTypeClause(comp *compunit.Compilation, typ *syntaxnodes.TypeClauseNode, pack *symbols.PackageSymbol, prms []*symbols.TypeSymbol) *symbols.TypeSymbol {
    
     // if the type clause does not exists -> void return type
     if typ == nil {
-        return compunit.GlobalDataTypeRegister["void"]
+        return compunit.GlobalDataType("void")
+    }
+
+    base := lookupBaseTypeClause(comp, typ, pack, prms)
+
+    // no question mark -> nothing else to do
+    if !typ.IsNullable || base.Equal(compunit.GlobalDataType("error")) {
+        return base
+    }
+
+    // only references can be null (an int? would need a whole box around it)
+    if !base.CanBeNullable() {
+        comp.Report(error.NewError(error.BND, typ.NullableMarker.Position, "Only container and trait types can be nullable, got '%s'!", base.Name()))
+        return compunit.GlobalDataType("error")
+    }
+
+    return symbols.NewNullableType(base)
+}
+
+// Resolves a type clause without looking at its question mark
+func lookupBaseTypeClause(comp *compunit.Compilation, typ *syntaxnodes.TypeClauseNode, pack *symbols.PackageSymbol, prms []*symbols.TypeSymbol) *symbols.TypeSymbol {
+    // is this one of the type parameters we're allowed to use?
+    if !typ.HasPackageName {
+        for _, v := range prms {
+            if v.TypeName == typ.TypeName.Buffer {
+                // type parameters dont take any subtypes themselves
+                if len(typ.SubTypes) != 0 {
+                    comp.Report(error.NewError(error.BND, typ.Position(), "Type parameter '%s' does not take any subtypes, got: %d!", typ.TypeName.Buffer, len(typ.SubTypes)))
+                    return compunit.GlobalDataType("error")
+                }
+
+                return v
+            }
+        }
     }
 
     // if this is an array type, we will need to construct it
     if typ.TypeName.Buffer == "array" {
         // make sure we have exactly one subtype 
         if len(typ.SubTypes) != 1 {
-            error.Report(error.NewError(error.BND, typ.Position(), "Data type '%s' takes exactly one subtype, got: %d!", typ.TypeName.Buffer, len(typ.SubTypes)))
-            return compunit.GlobalDataTypeRegister["error"]
+            comp.Report(error.NewError(error.BND, typ.Position(), "Data type '%s' takes exactly one subtype, got: %d!", typ.TypeName.Buffer, len(typ.SubTypes)))
+            return compunit.GlobalDataType("error")
         }
 
         // if we do -> resolve it
-        subtype := LookupTypeClause(typ.SubTypes[0], pack)
+        subtype := LookupTypeClause(comp, typ.SubTypes[0], pack, prms)
 
         // create a new type symbol
         arrsym := createArrayType(subtype) 
         return arrsym
     }
 
+    // function types list their parameters and return type (func[int, int -> bool])
+    if typ.IsFunctionType {
+        prmtyps := []*symbols.TypeSymbol{}
+        for _, v := range typ.SubTypes {
+            prmtyps = append(prmtyps, LookupTypeClause(comp, v, pack, prms))
+        }
+
+        return symbols.NewFunctionType(prmtyps, LookupTypeClause(comp, typ.ReturnType, pack, prms))
+    }
+
+    // same goes for maps (map[key, value])
+    if typ.TypeName.Buffer == "map" {
+        if len(typ.SubTypes) != 2 {
+            comp.Report(error.NewError(error.BND, typ.Position(), "Data type '%s' takes exactly two subtypes, got: %d!", typ.TypeName.Buffer, len(typ.SubTypes)))
+            return compunit.GlobalDataType("error")
+        }
+
+        keytyp := LookupTypeClause(comp, typ.SubTypes[0], pack, prms)
+        valtyp := LookupTypeClause(comp, typ.SubTypes[1], pack, prms)
+
+        return createMapType(keytyp, valtyp)
+    }
+
+    // resolve the type arguments (if there are any)
+    args := []*symbols.TypeSymbol{}
+    for _, v := range typ.SubTypes {
+        args = append(args, LookupTypeClause(comp, v, pack, prms))
+    }
+
     // if the type clause has a package prefix -> this is def a container
     // (packages cant just contain random primitives)
     if typ.HasPackageName {
@@ -1548,15 +2884,21 @@ func LookupTypeClause(typ *syntaxnodes.TypeClauseNode, pack *symbols.PackageSymb
 
         // did it work?
         if pck == nil {
-            error.Report(error.NewError(error.BND, typ.PackageName.Position, "Could not find package '%s'!", typ.PackageName.Buffer))
-            return compunit.GlobalDataTypeRegister["error"]
+            comp.Report(error.NewError(error.BND, typ.PackageName.Position, "Could not find package '%s'!", typ.PackageName.Buffer))
+            return compunit.GlobalDataType("error")
         }
 
         // try looking up a trait first
         trt := LookupTraitInPackage(typ.TypeName.Buffer, pck)
         if trt != nil {
             // we found something? great success!
-            return trt.TraitType
+            return instantiateType(comp, trt.TraitType, args, typ.Position())
+        }
+
+        // maybe its an enum
+        enm := LookupEnumInPackage(typ.TypeName.Buffer, pck)
+        if enm != nil {
+            return instantiateType(comp, enm.EnumType, args, typ.Position())
         }
 
         // look up the container
@@ -1564,22 +2906,122 @@ func LookupTypeClause(typ *syntaxnodes.TypeClauseNode, pack *symbols.PackageSymb
 
         // did it work?
         if cnt == nil {
-            error.Report(error.NewError(error.BND, typ.TypeName.Position, "Could not find type '%s' in '%s'!", typ.TypeName.Buffer, typ.PackageName.Buffer))
-            return compunit.GlobalDataTypeRegister["error"]
+            comp.Report(error.NewError(error.BND, typ.TypeName.Position, "Could not find type '%s' in '%s'!", typ.TypeName.Buffer, typ.PackageName.Buffer))
+            return compunit.GlobalDataType("error")
         }
 
         // ok cool
-        return cnt.ContainerType
+        return instantiateType(comp, cnt.ContainerType, args, typ.Position())
     }
 
     // otherwise -> look up the type
-    return LookupType(typ.TypeName.Buffer, typ.Position(), pack, false)
+    base := LookupType(comp, typ.TypeName.Buffer, typ.Position(), pack, false)
+    return instantiateType(comp, base, args, typ.Position())
 }
 
 func createArrayType(subtype *symbols.TypeSymbol) *symbols.TypeSymbol {
     return symbols.NewTypeSymbol(subtype.Name() + " Array", []*symbols.TypeSymbol{subtype}, symbols.ARR, 0, nil)
 }
 
+func createMapType(keytyp *symbols.TypeSymbol, valtyp *symbols.TypeSymbol) *symbols.TypeSymbol {
+    return symbols.NewTypeSymbol(keytyp.Name() + " " + valtyp.Name() + " Map", []*symbols.TypeSymbol{keytyp, valtyp}, symbols.MAP, 0, nil)
+}
+
+// Fill in the type arguments of a generic container or trait
+// (and make sure we got the right amount of them)
+// -----------------------------------------------------------
+func instantiateType(comp *compunit.Compilation, base *symbols.TypeSymbol, args []*symbols.TypeSymbol, pos span.Span) *symbols.TypeSymbol {
+    // something already went wrong -> dont make it worse
+    if base.Equal(compunit.GlobalDataType("error")) {
+        return base
+    }
+
+    // only generic containers and traits take type arguments
+    isGeneric := (base.TypeGroup == symbols.CONT || base.TypeGroup == symbols.TRT) && len(base.SubTypes) > 0
+
+    // not generic and no arguments? -> thats just a normal type
+    if !isGeneric && len(args) == 0 {
+        return base
+    }
+
+    // not generic but we got arguments anyways?
+    if !isGeneric {
+        comp.Report(error.NewError(error.BND, pos, "Data type '%s' does not take any type arguments, got: %d!", base.Name(), len(args)))
+        return compunit.GlobalDataType("error")
+    }
+
+    // generic but the amount is off?
+    if len(base.SubTypes) != len(args) {
+        comp.Report(error.NewError(error.BND, pos, "Data type '%s' takes exactly %d type arguments, got: %d!", base.Name(), len(base.SubTypes), len(args)))
+        return compunit.GlobalDataType("error")
+    }
+
+    return symbols.NewInstanceType(base, args)
+}
+
+// Create type parameter symbols for a generic container, trait or function
+// -------------------------------------------------------------------------
+func createTypeParameters(comp *compunit.Compilation, toks []lexer.Token) []*symbols.TypeSymbol {
+    prms := []*symbols.TypeSymbol{}
+
+    for _, tok := range toks {
+        // no shadowing primitives, that would just be confusing
+        if _, ok := compunit.LookupGlobalDataType(tok.Buffer); ok || tok.Buffer == "array" || tok.Buffer == "map" || tok.Buffer == "func" {
+            comp.Report(error.NewError(error.BND, tok.Position, "Cannot use '%s' as a type parameter! A data type with that name already exists!", tok.Buffer))
+            continue
+        }
+
+        // no duplicates either
+        isDuplicate := false
+        for _, v := range prms {
+            if v.TypeName == tok.Buffer {
+                isDuplicate = true
+            }
+        }
+
+        if isDuplicate {
+            comp.Report(error.NewError(error.BND, tok.Position, "Type parameter '%s' has already been declared!", tok.Buffer))
+            continue
+        }
+
+        prms = append(prms, symbols.NewTypeSymbol(tok.Buffer, []*symbols.TypeSymbol{}, symbols.TPRM, 0, nil))
+    }
+
+    return prms
+}
+
+// All type parameters that can be used right now
+// (the ones of the current function and the ones of the container or trait its in)
+// ---------------------------------------------------------------------------------
+func (bin *Binder) typeParameters() []*symbols.TypeSymbol {
+    prms := []*symbols.TypeSymbol{}
+
+    if bin.CurrentFunction != nil {
+        prms = append(prms, bin.CurrentFunction.TypeParameters...)
+    }
+
+    // lambdas can use everything the functions around them can
+    for _, v := range bin.Lambdas {
+        prms = append(prms, v.Outer.TypeParameters...)
+    }
+
+    if bin.CurrentType != nil && (bin.CurrentType.TypeGroup == symbols.CONT || bin.CurrentType.TypeGroup == symbols.TRT) {
+        prms = append(prms, bin.CurrentType.SubTypes...)
+    }
+
+    return prms
+}
+
+func (bin *Binder) lookupTypeClause(typ *syntaxnodes.TypeClauseNode) *symbols.TypeSymbol {
+    return LookupTypeClause(bin.Comp, typ, bin.CurrentPackage, bin.typeParameters())
+}
+
+// The type of everything that gets thrown around
+// -----------------------------------------------
+func (bin *Binder) errorType() *symbols.TypeSymbol {
+    return LookupContainerInPackage("Error", bin.CurrentPackage.LoadedPackages["internal"]).ContainerType
+}
+
 // --------------------------------------------------------
 // Trait Lookup
 // --------------------------------------------------------
@@ -1612,6 +3054,39 @@ func LookupTraitInPackage(name string, pack *symbols.PackageSymbol) *symbols.Tra
     return nil
 }
 
+// --------------------------------------------------------
+// Enum Lookup
+// --------------------------------------------------------
+func LookupEnum(name string, pck *symbols.PackageSymbol) *symbols.EnumSymbol {
+    enm := LookupEnumInPackage(name, pck)
+    if enm != nil {
+        return enm
+    }
+
+    // lookup enums in loaded packages
+    for _, packname := range pck.IncludedPackages {
+        pack := pck.LoadedPackages[packname]
+
+        enm := LookupEnumInPackage(name, pack)
+        if enm != nil {
+            return enm
+        }
+    }
+
+    // got nothin man
+    return nil
+}
+
+func LookupEnumInPackage(name string, pack *symbols.PackageSymbol) *symbols.EnumSymbol {
+    for _, v := range pack.Enums {
+        if v.EnumName == name {
+            return v
+        }
+    }
+
+    return nil
+}
+
 // --------------------------------------------------------
 // Container Lookup
 // --------------------------------------------------------
@@ -1747,7 +3222,7 @@ func (bin *Binder) LookupFunction(name string) *symbols.FunctionSymbol {
 
     // if we didnt find anything -> start looking through included packages
     for _, pname := range bin.CurrentPackage.IncludedPackages {
-        pck := compunit.GetPackage(pname)
+        pck := bin.CurrentPackage.LoadedPackages[pname]
 
         fnc := LookupFunctionInPackage(pck, name)
         if fnc != nil {
@@ -1805,29 +3280,93 @@ func (bin *Binder) LookupMethod(name string, typ *symbols.TypeSymbol) *symbols.F
 
 func LookupMethodInPackage(pck *symbols.PackageSymbol, name string, typ *symbols.TypeSymbol) *symbols.FunctionSymbol {
     for _, v := range pck.Functions {
-        if v.FunctionKind == symbols.FT_METH && v.FuncName == name {
+        if v.FunctionKind == symbols.FT_METH && v.FuncName == name && MethodAppliesTo(v, typ) {
+            return v
+        }
+    }
 
-            // make sure this method applies for this type
-            // -------------------------------------------
+    return nil
+}
 
-            // this method applies to all types
-            if v.MethodKind == symbols.MT_ALL {
-                return v
-            }
+// Make sure a method applies for a type
+// -------------------------------------
+func MethodAppliesTo(meth *symbols.FunctionSymbol, typ *symbols.TypeSymbol) bool {
+    // this method applies to all types
+    if meth.MethodKind == symbols.MT_ALL {
+        return true
+    }
 
-            // this method applies to all types of a group
-            if v.MethodKind == symbols.MT_GROUP && v.MethodSource.TypeGroup == typ.TypeGroup {
-                return v
-            }
+    // this method applies to all types of a group
+    if meth.MethodKind == symbols.MT_GROUP && meth.MethodSource.TypeGroup == typ.TypeGroup {
+        return true
+    }
 
+    // this method only applies to one specific type
+    if meth.MethodKind == symbols.MT_STRICT && meth.MethodSource.Equal(typ) {
+        return true
+    }
 
-            // this method only applies to one specific type
-            if v.MethodKind == symbols.MT_STRICT && v.MethodSource.Equal(typ) {
-                return v
+    // methods of generic containers and traits apply to all of their instances
+    if meth.MethodKind == symbols.MT_STRICT && len(meth.MethodSource.SubTypes) > 0 && meth.MethodSource.TypeGroup == typ.TypeGroup {
+        src := meth.MethodSource
+        if (src.TypeGroup == symbols.CONT && src.Container == typ.Container) ||
+           (src.TypeGroup == symbols.TRT  && src.Trait == typ.Trait) {
+            return true
+        }
+    }
+
+    return false
+}
+
+// What type arguments does a method get when called on an instance?
+// (returns the type parameters to replace and what to replace them with)
+// -----------------------------------------------------------------------
+func typeArgumentsFor(meth *symbols.FunctionSymbol, inst *symbols.TypeSymbol) ([]*symbols.TypeSymbol, []*symbols.TypeSymbol) {
+    // methods copied over from a trait use the trait's type parameters
+    if meth.SourceTrait != nil && inst.TypeGroup == symbols.CONT {
+        for i, v := range inst.Container.Traits {
+            if v == meth.SourceTrait {
+                return v.TypeParameters, inst.Container.TraitTypeFor(i, inst).SubTypes
             }
+        }
+    }
 
+    if inst.TypeGroup == symbols.CONT && meth.MethodSource.TypeGroup == symbols.CONT {
+        return inst.Container.TypeParameters, inst.SubTypes
+    }
+
+    if inst.TypeGroup == symbols.TRT && meth.MethodSource.TypeGroup == symbols.TRT {
+        return inst.Trait.TypeParameters, inst.SubTypes
+    }
+
+    // group methods of arrays and maps are written against the type parameters of a dummy
+    if meth.MethodKind == symbols.MT_GROUP && (inst.TypeGroup == symbols.ARR || inst.TypeGroup == symbols.MAP) && len(meth.MethodSource.SubTypes) == len(inst.SubTypes) {
+        return meth.MethodSource.SubTypes, inst.SubTypes
+    }
+
+    // not generic at all
+    return nil, nil
+}
+
+// Figure out type arguments of a generic function by comparing
+// its parameter types to the types of the given arguments
+// -------------------------------------------------------------
+func inferTypeArguments(prm *symbols.TypeSymbol, arg *symbols.TypeSymbol, prms []*symbols.TypeSymbol, args []*symbols.TypeSymbol) {
+    // found a type parameter -> the first one to show up decides what it is
+    if prm.TypeGroup == symbols.TPRM {
+        for i, v := range prms {
+            if v == prm && args[i] == nil {
+                args[i] = arg
+            }
         }
+
+        return
     }
 
-    return nil
+    // otherwise -> look deeper (T Array vs. int Array)
+    if prm.TypeGroup == arg.TypeGroup && len(prm.SubTypes) == len(arg.SubTypes) {
+        for i := range prm.SubTypes {
+            inferTypeArguments(prm.SubTypes[i], arg.SubTypes[i], prms, args)
+        }
+    }
 }
@@ -0,0 +1,371 @@
+// Binder - constants.go
+// --------------------------------------------------------
+// Constants: works out the values of const globals (and
+// anything else only depending on things known at compile
+// time) so they can be used like literals
+// --------------------------------------------------------
+package binder
+
+import (
+	"fmt"
+
+	"bytespace.network/rerect/boundnodes"
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/error"
+	evalobjects "bytespace.network/rerect/eval_objects"
+	packageprocessor "bytespace.network/rerect/package_processor"
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Constant globals
+// ----------------
+// Figures out the values of all constant globals at compile time
+// (everyone using them just gets the value instead of the global)
+func BindConstants(comp *compunit.Compilation, file *packageprocessor.CompilationFile) {
+    // create a new binder
+    // (the initializers dont live in a real function, so they get a little one of their own)
+    bin := Binder{
+        Comp: comp,
+        CurrentPackage: file.Package,
+        CurrentFunction: symbols.NewFunctionSymbol(file.Package, "<constants>", compunit.GlobalDataType("void"), []*symbols.ParameterSymbol{}),
+        CurrentScope: NewScope(nil),
+        File: file,
+        Constants: make(map[*symbols.GlobalSymbol]bool),
+    }
+
+    // register the package globals as variables
+    for _, v := range file.Globals {
+        bin.CurrentScope.RegisterVariable(v)
+    }
+
+    for _, glb := range file.Globals {
+        if glb.IsConst {
+            bin.bindConstant(glb)
+        }
+    }
+}
+
+func (bin *Binder) bindConstant(glb *symbols.GlobalSymbol) {
+    src, ok := bin.File.GlobalSrc[glb]
+    if !ok {
+        return
+    }
+
+    // did we already do this one? (or are we doing it right now?)
+    if busy, ok := bin.Constants[glb]; ok {
+        if busy {
+            bin.Comp.Report(error.NewError(error.BND, src.GlobalName.Position, "Constant '%s' depends on itself!", glb.GlobalName))
+        }
+
+        return
+    }
+
+    bin.Constants[glb] = true
+    defer func() { bin.Constants[glb] = false }()
+
+    // bind the value
+    val := bin.bindExpression(src.Initializer)
+
+    // if theres an explicit type -> make sure they match
+    if src.HasExplicitType {
+        val = bin.bindConversion(val, glb.GlobalType, false)
+
+    // if not -> this is the type now
+    } else {
+        glb.GlobalType = val.ExprType()
+    }
+
+    // something already went wrong -> no need to complain twice
+    if val.Type() == boundnodes.BT_ErrorExpr || glb.GlobalType.Equal(compunit.GlobalDataType("error")) {
+        return
+    }
+
+    // now, what is it?
+    value, ok, problem := foldConstant(val)
+    if problem != nil {
+        bin.Comp.Report(*problem)
+        return
+    }
+
+    if !ok || value == nil {
+        bin.Comp.Report(error.NewError(error.BND, src.Initializer.Position(), "The value of constant '%s' has to be known at compile time!", glb.GlobalName))
+        return
+    }
+
+    glb.Value = value
+}
+
+// Use the value of a constant instead of the constant itself
+// (returns nil if this isnt a constant, or we dont know its value)
+func (bin *Binder) bindConstantValue(src syntaxnodes.SyntaxNode, vari symbols.VariableSymbol) boundnodes.BoundExpressionNode {
+    glb, ok := vari.(*symbols.GlobalSymbol)
+    if !ok || !glb.IsConst {
+        return nil
+    }
+
+    // constants used by other constants need to be figured out first
+    if glb.Value == nil && bin.Constants != nil {
+        bin.bindConstant(glb)
+    }
+
+    if glb.Value == nil {
+        return nil
+    }
+
+    return boundnodes.NewBoundLiteralExpressionNode(src, glb.GlobalType, glb.Value)
+}
+
+// Folding
+// -------
+// Figure out the value of an expression
+// (returns false if it cant be known at compile time, and an error if it would
+//  blow up at runtime anyways)
+func foldConstant(expr boundnodes.BoundExpressionNode) (interface{}, bool, *error.Error) {
+    if expr.Type() == boundnodes.BT_LiteralExpr {
+        return expr.(*boundnodes.BoundLiteralExpressionNode).LiteralValue, true, nil
+
+    } else if expr.Type() == boundnodes.BT_UnaryExpr {
+        node := expr.(*boundnodes.BoundUnaryExpressionNode)
+
+        operand, ok, problem := foldConstant(node.Operand)
+        if !ok {
+            return nil, false, problem
+        }
+
+        value, ok := foldUnary(node.Operator.Operation, operand)
+        return value, ok, nil
+
+    } else if expr.Type() == boundnodes.BT_BinaryExpr {
+        node := expr.(*boundnodes.BoundBinaryExpressionNode)
+
+        left, ok, problem := foldConstant(node.Left)
+        if !ok {
+            return nil, false, problem
+        }
+
+        right, ok, problem := foldConstant(node.Right)
+        if !ok {
+            return nil, false, problem
+        }
+
+        // these would throw at runtime -> might as well say so right away
+        if msg := foldProblem(node.Operator.Operation, right); msg != "" {
+            err := error.NewError(error.BND, node.Source().Position(), "%s", msg)
+            return nil, false, &err
+        }
+
+        value, ok := foldBinary(node.Operator.Operation, left, right)
+        return value, ok, nil
+
+    } else if expr.Type() == boundnodes.BT_ConversionExpr {
+        node := expr.(*boundnodes.BoundConversionExpressionNode)
+
+        // only conversions between primitives are safe to do here
+        // (enums to strings need to know their member names, strings to numbers can blow up)
+        from := node.Value.ExprType()
+        if !isPrimitive(from) || !isPrimitive(node.TargetType) ||
+           (from.Equal(compunit.GlobalDataType("string")) && !node.TargetType.Equal(from)) {
+            return nil, false, nil
+        }
+
+        val, ok, problem := foldConstant(node.Value)
+        if !ok {
+            return nil, false, problem
+        }
+
+        value, ok := evalobjects.EvalConversion(val, node.TargetType)
+        return value, ok, nil
+    }
+
+    // anything else has to happen at runtime
+    return nil, false, nil
+}
+
+// Integer division and modulo by zero, and shifts by negative amounts
+// (same messages the runtime would give us)
+func foldProblem(op boundnodes.BinaryOperatorType, right interface{}) string {
+    var r int64
+    switch v := right.(type) {
+    case int64:
+        r = v
+    case int32:
+        r = int64(v)
+    case int16:
+        r = int64(v)
+    case int8:
+        r = int64(v)
+    default:
+        return ""
+    }
+
+    if op == boundnodes.BO_Division && r == 0 {
+        return "Division by zero!"
+    }
+
+    if op == boundnodes.BO_Modulo && r == 0 {
+        return "Modulo by zero!"
+    }
+
+    if (op == boundnodes.BO_ShiftLeft || op == boundnodes.BO_ShiftRight) && r < 0 {
+        return fmt.Sprintf("Cannot shift by a negative amount! (%v)", right)
+    }
+
+    return ""
+}
+
+func isPrimitive(typ *symbols.TypeSymbol) bool {
+    return typ.TypeGroup == symbols.INT ||
+           typ.TypeGroup == symbols.FLOAT ||
+           typ.Equal(compunit.GlobalDataType("bool")) ||
+           typ.Equal(compunit.GlobalDataType("string"))
+}
+
+// Unary operations
+// ----------------
+func foldUnary(op boundnodes.UnaryOperatorType, operand interface{}) (interface{}, bool) {
+    switch op {
+    case boundnodes.UO_Identity:
+        return operand, true
+
+    case boundnodes.UO_LogicalNegation:
+        if v, ok := operand.(bool); ok {
+            return !v, true
+        }
+
+    case boundnodes.UO_Negation:
+        switch v := operand.(type) {
+        case int64:
+            return -v, true
+        case int32:
+            return -v, true
+        case int16:
+            return -v, true
+        case int8:
+            return -v, true
+        case float64:
+            return -v, true
+        case float32:
+            return -v, true
+        }
+
+    case boundnodes.UO_BitwiseNegation:
+        switch v := operand.(type) {
+        case int64:
+            return ^v, true
+        case int32:
+            return ^v, true
+        case int16:
+            return ^v, true
+        case int8:
+            return ^v, true
+        }
+    }
+
+    return nil, false
+}
+
+// Binary operations
+// -----------------
+func foldBinary(op boundnodes.BinaryOperatorType, left interface{}, right interface{}) (interface{}, bool) {
+    // these work on anything (as long as both sides are the same)
+    switch op {
+    case boundnodes.BO_Equal:
+        return left == right, true
+    case boundnodes.BO_UnEqual:
+        return left != right, true
+    }
+
+    switch l := left.(type) {
+    case bool:
+        r := right.(bool)
+
+        switch op {
+        case boundnodes.BO_LogicalAnd:
+            return l && r, true
+        case boundnodes.BO_LogicalOr:
+            return l || r, true
+        }
+
+    case string:
+        if op == boundnodes.BO_Concat {
+            return l + right.(string), true
+        }
+
+    case int64:
+        return foldInteger(op, l, right.(int64))
+    case int32:
+        return foldInteger(op, l, right.(int32))
+    case int16:
+        return foldInteger(op, l, right.(int16))
+    case int8:
+        return foldInteger(op, l, right.(int8))
+    case float64:
+        return foldNumber(op, l, right.(float64))
+    case float32:
+        return foldNumber(op, l, right.(float32))
+    }
+
+    return nil, false
+}
+
+// arithmetic and comparisons (anything numeric)
+func foldNumber[T int64 | int32 | int16 | int8 | float64 | float32](op boundnodes.BinaryOperatorType, left T, right T) (interface{}, bool) {
+    switch op {
+    case boundnodes.BO_Addition:
+        return left + right, true
+    case boundnodes.BO_Subtraction:
+        return left - right, true
+    case boundnodes.BO_Multiplication:
+        return left * right, true
+    case boundnodes.BO_Division:
+        // leave this one for the runtime to complain about
+        if right == 0 {
+            return nil, false
+        }
+
+        return left / right, true
+    case boundnodes.BO_LessThan:
+        return left < right, true
+    case boundnodes.BO_LessEqual:
+        return left <= right, true
+    case boundnodes.BO_GreaterThan:
+        return left > right, true
+    case boundnodes.BO_GreaterEqual:
+        return left >= right, true
+    }
+
+    return nil, false
+}
+
+// modulo, bitwise and shifts (integers only)
+func foldInteger[T int64 | int32 | int16 | int8](op boundnodes.BinaryOperatorType, left T, right T) (interface{}, bool) {
+    switch op {
+    case boundnodes.BO_Modulo:
+        if right == 0 {
+            return nil, false
+        }
+
+        return left % right, true
+    case boundnodes.BO_BitwiseAnd:
+        return left & right, true
+    case boundnodes.BO_BitwiseOr:
+        return left | right, true
+    case boundnodes.BO_BitwiseXor:
+        return left ^ right, true
+    case boundnodes.BO_ShiftLeft:
+        if right < 0 {
+            return nil, false
+        }
+
+        return left << right, true
+    case boundnodes.BO_ShiftRight:
+        if right < 0 {
+            return nil, false
+        }
+
+        return left >> right, true
+    }
+
+    return foldNumber(op, left, right)
+}
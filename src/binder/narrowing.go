@@ -0,0 +1,150 @@
+// Binder - narrowing.go
+// --------------------------------------------------------
+// Narrowing: figures out when an 'if (x is Foo)' can
+// safely treat x as a Foo for its whole block
+// --------------------------------------------------------
+package binder
+
+import (
+	"reflect"
+
+	"bytespace.network/rerect/boundnodes"
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// If this condition is a plain 'x is Foo' -> which variable does it narrow down?
+// (nil if there is nothing we can safely narrow down for the given body)
+// -------------------------------------------------------------------------------
+func (bin *Binder) narrowedBy(cond boundnodes.BoundExpressionNode, body syntaxnodes.StatementNode) (symbols.VariableSymbol, *symbols.TypeSymbol) {
+    chk, ok := cond.(*boundnodes.BoundTypeCheckExpressionNode)
+    if !ok {
+        return nil, nil
+    }
+
+    // (the value might already be narrowed down by an outer if)
+    val := chk.Value
+    if cnv, ok := val.(*boundnodes.BoundConversionExpressionNode); ok && cnv.SourceNode.Type() == syntaxnodes.NT_NameExpr {
+        val = cnv.Value
+    }
+
+    name, ok := val.(*boundnodes.BoundNameExpressionNode)
+    if !ok {
+        return nil, nil
+    }
+
+    // only locals and parameters, anyone could change globals and fields behind our back
+    vari := name.Variable
+    if vari.Type() != symbols.ST_Local && vari.Type() != symbols.ST_Parameter {
+        return nil, nil
+    }
+
+    // same goes for anything a lambda holds on to
+    // (captured from further out, or used by any lambda in here, no matter where it is)
+    if symbols.IsCaptured(vari) || usedByLambda(bin.CurrentBody, vari.Name()) {
+        return nil, nil
+    }
+
+    // if the block changes it itself we cant be sure either
+    // (loops in there might come back around to code that expects a Foo)
+    if assignsTo(body, vari.Name()) {
+        return nil, nil
+    }
+
+    return vari, chk.CheckType
+}
+
+// Does anything in here assign something new to the given name?
+func assignsTo(node syntaxnodes.SyntaxNode, name string) bool {
+    found := false
+
+    walkSyntax(node, func(nd syntaxnodes.SyntaxNode) bool {
+        var target syntaxnodes.ExpressionNode
+
+        if asg, ok := nd.(*syntaxnodes.AssignmentExpressionNode); ok {
+            target = asg.Expression
+        } else if cmp, ok := nd.(*syntaxnodes.CompoundAssignmentExpressionNode); ok {
+            target = cmp.Expression
+        } else if inc, ok := nd.(*syntaxnodes.IncrementExpressionNode); ok {
+            target = inc.Expression
+        }
+
+        if isName(target, name) {
+            found = true
+        }
+
+        return !found
+    })
+
+    return found
+}
+
+// Does any lambda in here use the given name?
+func usedByLambda(node syntaxnodes.SyntaxNode, name string) bool {
+    found := false
+
+    walkSyntax(node, func(nd syntaxnodes.SyntaxNode) bool {
+        lmb, ok := nd.(*syntaxnodes.LambdaExpressionNode)
+        if !ok {
+            return !found
+        }
+
+        walkSyntax(lmb.Body, func(inner syntaxnodes.SyntaxNode) bool {
+            if isName(inner, name) {
+                found = true
+            }
+
+            // calls of variables holding functions count too
+            if call, ok := inner.(*syntaxnodes.CallExpressionNode); ok && !call.HasPackage && call.Identifier.Buffer == name {
+                found = true
+            }
+
+            return !found
+        })
+
+        return false
+    })
+
+    return found
+}
+
+func isName(node syntaxnodes.SyntaxNode, name string) bool {
+    nm, ok := node.(*syntaxnodes.NameExpressionNode)
+    return ok && !nm.HasPackage && nm.Identifier.Buffer == name
+}
+
+// Walking syntax trees
+// --------------------
+// Calls visit on the given node and everything below it
+// (visit returns false -> dont go any deeper here)
+var syntaxNodeType = reflect.TypeOf((*syntaxnodes.SyntaxNode)(nil)).Elem()
+
+func walkSyntax(node syntaxnodes.SyntaxNode, visit func(syntaxnodes.SyntaxNode) bool) {
+    if node == nil || reflect.ValueOf(node).IsNil() {
+        return
+    }
+
+    if !visit(node) {
+        return
+    }
+
+    // nodes are all pointers to structs, so we just look for anything node-shaped in there
+    val := reflect.ValueOf(node).Elem()
+    for i := 0; i < val.NumField(); i++ {
+        walkSyntaxValue(val.Field(i), visit)
+    }
+}
+
+func walkSyntaxValue(val reflect.Value, visit func(syntaxnodes.SyntaxNode) bool) {
+    if val.Kind() == reflect.Slice {
+        for i := 0; i < val.Len(); i++ {
+            walkSyntaxValue(val.Index(i), visit)
+        }
+
+        return
+    }
+
+    if (val.Kind() == reflect.Interface || val.Kind() == reflect.Pointer) && !val.IsNil() && val.Type().Implements(syntaxNodeType) {
+        walkSyntax(val.Interface().(syntaxnodes.SyntaxNode), visit)
+    }
+}
@@ -10,6 +10,7 @@ type Scope struct {
     Parent *Scope
 
     Variables []symbols.VariableSymbol
+    Narrowings map[symbols.VariableSymbol]*symbols.TypeSymbol // variables known to be of a more specific type in here (if (x is Foo))
 }
 
 // Constructor
@@ -17,6 +18,7 @@ func NewScope(parent *Scope) *Scope {
     return &Scope{
         Parent: parent,
         Variables: make([]symbols.VariableSymbol, 0),
+        Narrowings: make(map[symbols.VariableSymbol]*symbols.TypeSymbol),
     }
 }
 
@@ -34,20 +36,54 @@ func (scp *Scope) RegisterVariable(vari symbols.VariableSymbol) bool {
 }
 
 func (scp *Scope) LookupVariable(name string) symbols.VariableSymbol {
+    vari, _ := scp.LookupVariableScope(name)
+    return vari
+}
+
+// Same as LookupVariable, but also tells us which scope the variable was found in
+func (scp *Scope) LookupVariableScope(name string) (symbols.VariableSymbol, *Scope) {
     // Look for this variable locally
     for _, v := range scp.Variables {
         if v.Name() == name {
-            return v
+            return v, scp
         }
     }
 
     // if it wasnt found -> do we have a parent scope?
     if scp.Parent != nil {
         // do a lookup on the parent
-        return scp.Parent.LookupVariable(name)
+        return scp.Parent.LookupVariableScope(name)
 
     // otherwise: no fucking clue
     } else {
-        return nil
+        return nil, nil
+    }
+}
+
+// Is the given scope somewhere above this one?
+func (scp *Scope) IsBelow(other *Scope) bool {
+    for s := scp.Parent; s != nil; s = s.Parent {
+        if s == other {
+            return true
+        }
+    }
+
+    return false
+}
+
+// Narrowing
+// ---------
+func (scp *Scope) Narrow(vari symbols.VariableSymbol, typ *symbols.TypeSymbol) {
+    scp.Narrowings[vari] = typ
+}
+
+// What type do we know this variable has in here? (nil if we dont know any better)
+func (scp *Scope) LookupNarrowing(vari symbols.VariableSymbol) *symbols.TypeSymbol {
+    for s := scp; s != nil; s = s.Parent {
+        if typ, ok := s.Narrowings[vari]; ok {
+            return typ
+        }
     }
+
+    return nil
 }
@@ -45,10 +45,14 @@ const (
     BT_WhileStmt       BoundNodeType = "While statement"
     BT_FromToStmt      BoundNodeType = "From-To statement"
     BT_ForStmt         BoundNodeType = "For statement"
+    BT_ForEachStmt     BoundNodeType = "For-each statement"
     BT_LoopStmt        BoundNodeType = "Loop statement"
     BT_BlockStmt       BoundNodeType = "Block statement"
     BT_ExpressionStmt  BoundNodeType = "Expression statement"
     BT_IfStmt          BoundNodeType = "If statement"
+    BT_TryStmt         BoundNodeType = "Try statement"
+    BT_ThrowStmt       BoundNodeType = "Throw statement"
+    BT_SwitchStmt      BoundNodeType = "Switch statement"
 
     // Internal VM statements
     BT_LabelIStmt      BoundNodeType = "Internal label statement"
@@ -56,20 +60,28 @@ const (
     BT_GoToIfIStmt     BoundNodeType = "Internal conditional goto statement"
     BT_DeleteIStmt     BoundNodeType = "Internal variable deletion statement"
     BT_ApproachIStmt   BoundNodeType = "Internal increment / decrement statement"
+    BT_ProtectIStmt    BoundNodeType = "Internal protected region statement"
 
     // Expressions
     BT_LiteralExpr     BoundNodeType = "Literal expression"
     BT_AssignmentExpr  BoundNodeType = "Assignment expression"
+    BT_CompoundExpr    BoundNodeType = "Compound assignment expression"
     BT_UnaryExpr       BoundNodeType = "Unary expression"
     BT_BinaryExpr      BoundNodeType = "Binary expression"
     BT_CallExpr        BoundNodeType = "Call expression"
     BT_NameExpr        BoundNodeType = "Name expression"
     BT_ConversionExpr  BoundNodeType = "Conversion expression"
     BT_MakeArrayExpr   BoundNodeType = "Array creation expression"
+    BT_MakeMapExpr     BoundNodeType = "Map creation expression"
     BT_ArrayIndexExpr  BoundNodeType = "Array index expression"
     BT_AccessCallExpr  BoundNodeType = "Access call expression"
     BT_MakeExpr        BoundNodeType = "Object creation expression"
     BT_AccessFieldExpr BoundNodeType = "Access field expression"
+    BT_TypeCheckExpr   BoundNodeType = "Type check expression"
+    BT_FunctionExpr    BoundNodeType = "Function expression"
+    BT_CallValueExpr   BoundNodeType = "Call value expression"
+    BT_CheckedCastExpr BoundNodeType = "Checked cast expression"
+    BT_SequenceExpr    BoundNodeType = "Sequence expression"
 
     BT_ErrorExpr       BoundNodeType = "Error expression"
 )
@@ -15,14 +15,20 @@ type BoundAccessCallExpressionNode struct {
     Expression BoundExpressionNode
     Function *symbols.FunctionSymbol
     Arguments []BoundExpressionNode
+
+    ReturnType *symbols.TypeSymbol // (methods of generic containers depend on the type of the instance)
+
+    NullSafe bool // ?-> (null instances skip the call and give back the default of the return type)
 }
 
-func NewBoundAccessCallExpressionNode(src syntaxnodes.SyntaxNode, exp BoundExpressionNode, fnc *symbols.FunctionSymbol, args []BoundExpressionNode) *BoundAccessCallExpressionNode {
+func NewBoundAccessCallExpressionNode(src syntaxnodes.SyntaxNode, exp BoundExpressionNode, fnc *symbols.FunctionSymbol, args []BoundExpressionNode, ret *symbols.TypeSymbol, nullsafe bool) *BoundAccessCallExpressionNode {
     return &BoundAccessCallExpressionNode {
         SourceNode: src,
         Expression: exp,
         Function: fnc,
         Arguments: args,
+        ReturnType: ret,
+        NullSafe: nullsafe,
     }
 }
 
@@ -35,5 +41,5 @@ func (nd *BoundAccessCallExpressionNode) Source() syntaxnodes.SyntaxNode {
 }
 
 func (nd *BoundAccessCallExpressionNode) ExprType() *symbols.TypeSymbol {
-    return nd.Function.ReturnType
+    return nd.ReturnType
 } 
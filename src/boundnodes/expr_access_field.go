@@ -14,13 +14,19 @@ type BoundAccessFieldExpressionNode struct {
 
     Expression BoundExpressionNode
     Field *symbols.FieldSymbol
+
+    FieldType *symbols.TypeSymbol // (fields of generic containers depend on the type of the instance)
+
+    NullSafe bool // ?-> (null instances give back the default of the field type)
 }
 
-func NewBoundAccessFieldExpressionNode(src syntaxnodes.SyntaxNode, exp BoundExpressionNode, fld *symbols.FieldSymbol) *BoundAccessFieldExpressionNode {
+func NewBoundAccessFieldExpressionNode(src syntaxnodes.SyntaxNode, exp BoundExpressionNode, fld *symbols.FieldSymbol, typ *symbols.TypeSymbol, nullsafe bool) *BoundAccessFieldExpressionNode {
     return &BoundAccessFieldExpressionNode {
         SourceNode: src,
         Expression: exp,
         Field: fld,
+        FieldType: typ,
+        NullSafe: nullsafe,
     }
 }
 
@@ -33,5 +39,5 @@ func (nd *BoundAccessFieldExpressionNode) Source() syntaxnodes.SyntaxNode {
 }
 
 func (nd *BoundAccessFieldExpressionNode) ExprType() *symbols.TypeSymbol {
-    return nd.Field.VarType()
+    return nd.FieldType
 } 
@@ -33,5 +33,10 @@ func (nd *BoundArrayIndexExpressionNode) Source() syntaxnodes.SyntaxNode {
 }
 
 func (nd *BoundArrayIndexExpressionNode) ExprType() *symbols.TypeSymbol {
+    // maps give out their values, not their keys
+    if nd.SourceArray.ExprType().TypeGroup == symbols.MAP {
+        return nd.SourceArray.ExprType().SubTypes[1]
+    }
+
     return nd.SourceArray.ExprType().SubTypes[0]
 } 
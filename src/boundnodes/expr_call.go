@@ -14,13 +14,16 @@ type BoundCallExpressionNode struct {
 
     Function *symbols.FunctionSymbol
     Arguments []BoundExpressionNode
+
+    ReturnType *symbols.TypeSymbol // (generic functions return different things depending on their arguments)
 }
 
-func NewBoundCallExpressionNode(src syntaxnodes.SyntaxNode, fnc *symbols.FunctionSymbol, args []BoundExpressionNode) *BoundCallExpressionNode {
+func NewBoundCallExpressionNode(src syntaxnodes.SyntaxNode, fnc *symbols.FunctionSymbol, args []BoundExpressionNode, ret *symbols.TypeSymbol) *BoundCallExpressionNode {
     return &BoundCallExpressionNode {
         SourceNode: src,
         Function: fnc,
         Arguments: args,
+        ReturnType: ret,
     }
 }
 
@@ -33,5 +36,5 @@ func (nd *BoundCallExpressionNode) Source() syntaxnodes.SyntaxNode {
 }
 
 func (nd *BoundCallExpressionNode) ExprType() *symbols.TypeSymbol {
-    return nd.Function.ReturnType
+    return nd.ReturnType
 } 
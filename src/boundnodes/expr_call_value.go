@@ -0,0 +1,38 @@
+package boundnodes
+
+import (
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Call value expression
+// ---------------------
+// (calling whatever function value an expression gives us)
+type BoundCallValueExpressionNode struct {
+    BoundExpressionNode
+
+    SourceNode syntaxnodes.SyntaxNode
+
+    Value BoundExpressionNode
+    Arguments []BoundExpressionNode
+}
+
+func NewBoundCallValueExpressionNode(src syntaxnodes.SyntaxNode, val BoundExpressionNode, args []BoundExpressionNode) *BoundCallValueExpressionNode {
+    return &BoundCallValueExpressionNode {
+        SourceNode: src,
+        Value: val,
+        Arguments: args,
+    }
+}
+
+func (nd *BoundCallValueExpressionNode) Type() BoundNodeType {
+    return BT_CallValueExpr
+}
+
+func (nd *BoundCallValueExpressionNode) Source() syntaxnodes.SyntaxNode {
+    return nd.SourceNode
+}
+
+func (nd *BoundCallValueExpressionNode) ExprType() *symbols.TypeSymbol {
+    return nd.Value.ExprType().FunctionReturnType()
+} 
@@ -0,0 +1,38 @@
+package boundnodes
+
+import (
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Checked cast expression
+// -----------------------
+// (the value if it is an instance of the given type, null otherwise)
+type BoundCheckedCastExpressionNode struct {
+    BoundExpressionNode
+
+    SourceNode syntaxnodes.SyntaxNode
+
+    Value BoundExpressionNode
+    TargetType *symbols.TypeSymbol
+}
+
+func NewBoundCheckedCastExpressionNode(src syntaxnodes.SyntaxNode, val BoundExpressionNode, typ *symbols.TypeSymbol) *BoundCheckedCastExpressionNode {
+    return &BoundCheckedCastExpressionNode {
+        SourceNode: src,
+        Value: val,
+        TargetType: typ,
+    }
+}
+
+func (nd *BoundCheckedCastExpressionNode) Type() BoundNodeType {
+    return BT_CheckedCastExpr
+}
+
+func (nd *BoundCheckedCastExpressionNode) Source() syntaxnodes.SyntaxNode {
+    return nd.SourceNode
+}
+
+func (nd *BoundCheckedCastExpressionNode) ExprType() *symbols.TypeSymbol {
+    return symbols.NewNullableType(nd.TargetType)
+}
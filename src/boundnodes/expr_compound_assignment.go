@@ -0,0 +1,42 @@
+package boundnodes
+
+import (
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Compound assignment expression
+// ------------------------------
+// (x +<- 1, x++ and friends, the lowerer turns them into plain assignments)
+type BoundCompoundAssignmentExpressionNode struct {
+    BoundExpressionNode
+
+    SourceNode syntaxnodes.SyntaxNode
+
+    Expression BoundExpressionNode
+    Operator *BoundBinaryOperator
+    Value BoundExpressionNode
+    IsPostfix bool // x++ and x-- hand out the old value
+}
+
+func NewBoundCompoundAssignmentExpressionNode(src syntaxnodes.SyntaxNode, expr BoundExpressionNode, op *BoundBinaryOperator, val BoundExpressionNode, postfix bool) *BoundCompoundAssignmentExpressionNode {
+    return &BoundCompoundAssignmentExpressionNode {
+        SourceNode: src,
+        Expression: expr,
+        Operator: op,
+        Value: val,
+        IsPostfix: postfix,
+    }
+}
+
+func (nd *BoundCompoundAssignmentExpressionNode) Type() BoundNodeType {
+    return BT_CompoundExpr
+}
+
+func (nd *BoundCompoundAssignmentExpressionNode) Source() syntaxnodes.SyntaxNode {
+    return nd.SourceNode
+}
+
+func (nd *BoundCompoundAssignmentExpressionNode) ExprType() *symbols.TypeSymbol {
+    return nd.Expression.ExprType()
+}
@@ -29,5 +29,5 @@ func (nd *BoundErrorExpressionNode) Source() syntaxnodes.SyntaxNode {
 }
 
 func (nd *BoundErrorExpressionNode) ExprType() *symbols.TypeSymbol {
-    return compunit.GlobalDataTypeRegister["error"]
+    return compunit.GlobalDataType("error")
 } 
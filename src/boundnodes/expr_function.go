@@ -0,0 +1,38 @@
+package boundnodes
+
+import (
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Function expression
+// -------------------
+// (a lambda or a function name used as a value, lambdas bring their captures along)
+type BoundFunctionExpressionNode struct {
+    BoundExpressionNode
+
+    SourceNode syntaxnodes.SyntaxNode
+
+    Function *symbols.FunctionSymbol
+    FunctionType *symbols.TypeSymbol
+}
+
+func NewBoundFunctionExpressionNode(src syntaxnodes.SyntaxNode, fnc *symbols.FunctionSymbol) *BoundFunctionExpressionNode {
+    return &BoundFunctionExpressionNode {
+        SourceNode: src,
+        Function: fnc,
+        FunctionType: symbols.FunctionTypeOf(fnc),
+    }
+}
+
+func (nd *BoundFunctionExpressionNode) Type() BoundNodeType {
+    return BT_FunctionExpr
+}
+
+func (nd *BoundFunctionExpressionNode) Source() syntaxnodes.SyntaxNode {
+    return nd.SourceNode
+}
+
+func (nd *BoundFunctionExpressionNode) ExprType() *symbols.TypeSymbol {
+    return nd.FunctionType
+} 
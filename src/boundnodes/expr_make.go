@@ -13,6 +13,7 @@ type BoundMakeExpressionNode struct {
     SourceNode syntaxnodes.SyntaxNode
 
     Container *symbols.ContainerSymbol
+    ContainerType *symbols.TypeSymbol // (generic containers need to know their type arguments)
     
     Initializer map[*symbols.FieldSymbol]BoundExpressionNode
     HasInitializer bool
@@ -21,10 +22,11 @@ type BoundMakeExpressionNode struct {
     HasConstructor bool
 }
 
-func NewBoundMakeExpressionNode(src syntaxnodes.SyntaxNode, cnt *symbols.ContainerSymbol, init map[*symbols.FieldSymbol]BoundExpressionNode, hasinit bool, args []BoundExpressionNode, hascst bool) *BoundMakeExpressionNode {
+func NewBoundMakeExpressionNode(src syntaxnodes.SyntaxNode, cnt *symbols.ContainerSymbol, typ *symbols.TypeSymbol, init map[*symbols.FieldSymbol]BoundExpressionNode, hasinit bool, args []BoundExpressionNode, hascst bool) *BoundMakeExpressionNode {
     return &BoundMakeExpressionNode {
         SourceNode: src,
         Container: cnt,
+        ContainerType: typ,
 
         Initializer: init,
         HasInitializer: hasinit,
@@ -43,5 +45,5 @@ func (nd *BoundMakeExpressionNode) Source() syntaxnodes.SyntaxNode {
 }
 
 func (nd *BoundMakeExpressionNode) ExprType() *symbols.TypeSymbol {
-    return nd.Container.ContainerType
+    return nd.ContainerType
 } 
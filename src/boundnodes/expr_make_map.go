@@ -0,0 +1,39 @@
+package boundnodes
+
+import (
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// MakeMap expression
+// ------------------
+type BoundMakeMapExpressionNode struct {
+    BoundExpressionNode
+
+    SourceNode syntaxnodes.SyntaxNode
+
+    MapType *symbols.TypeSymbol
+    Keys []BoundExpressionNode
+    Values []BoundExpressionNode
+}
+
+func NewBoundMakeMapExpressionNode(src syntaxnodes.SyntaxNode, maptyp *symbols.TypeSymbol, keys []BoundExpressionNode, vals []BoundExpressionNode) *BoundMakeMapExpressionNode {
+    return &BoundMakeMapExpressionNode {
+        SourceNode: src,
+        MapType: maptyp,
+        Keys: keys,
+        Values: vals,
+    }
+}
+
+func (nd *BoundMakeMapExpressionNode) Type() BoundNodeType {
+    return BT_MakeMapExpr
+}
+
+func (nd *BoundMakeMapExpressionNode) Source() syntaxnodes.SyntaxNode {
+    return nd.SourceNode
+}
+
+func (nd *BoundMakeMapExpressionNode) ExprType() *symbols.TypeSymbol {
+    return nd.MapType
+}
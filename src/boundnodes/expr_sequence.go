@@ -0,0 +1,39 @@
+package boundnodes
+
+import (
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Sequence expression
+// -------------------
+// (runs a couple of statements before handing out its value, only created by
+// the lowerer -> the statements are only ever declarations and expression statements)
+type BoundSequenceExpressionNode struct {
+    BoundExpressionNode
+
+    SourceNode syntaxnodes.SyntaxNode
+
+    Statements []BoundStatementNode
+    Value BoundExpressionNode
+}
+
+func NewBoundSequenceExpressionNode(src syntaxnodes.SyntaxNode, stmts []BoundStatementNode, val BoundExpressionNode) *BoundSequenceExpressionNode {
+    return &BoundSequenceExpressionNode {
+        SourceNode: src,
+        Statements: stmts,
+        Value: val,
+    }
+}
+
+func (nd *BoundSequenceExpressionNode) Type() BoundNodeType {
+    return BT_SequenceExpr
+}
+
+func (nd *BoundSequenceExpressionNode) Source() syntaxnodes.SyntaxNode {
+    return nd.SourceNode
+}
+
+func (nd *BoundSequenceExpressionNode) ExprType() *symbols.TypeSymbol {
+    return nd.Value.ExprType()
+}
@@ -0,0 +1,39 @@
+package boundnodes
+
+import (
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Type check expression
+// ---------------------
+// (is the value an instance of the given type? never true for null)
+type BoundTypeCheckExpressionNode struct {
+    BoundExpressionNode
+
+    SourceNode syntaxnodes.SyntaxNode
+
+    Value BoundExpressionNode
+    CheckType *symbols.TypeSymbol
+}
+
+func NewBoundTypeCheckExpressionNode(src syntaxnodes.SyntaxNode, val BoundExpressionNode, typ *symbols.TypeSymbol) *BoundTypeCheckExpressionNode {
+    return &BoundTypeCheckExpressionNode {
+        SourceNode: src,
+        Value: val,
+        CheckType: typ,
+    }
+}
+
+func (nd *BoundTypeCheckExpressionNode) Type() BoundNodeType {
+    return BT_TypeCheckExpr
+}
+
+func (nd *BoundTypeCheckExpressionNode) Source() syntaxnodes.SyntaxNode {
+    return nd.SourceNode
+}
+
+func (nd *BoundTypeCheckExpressionNode) ExprType() *symbols.TypeSymbol {
+    return compunit.GlobalDataType("bool")
+}
@@ -32,6 +32,13 @@ const (
     BO_Subtraction    BinaryOperatorType = "Subtraction operator"
     BO_Multiplication BinaryOperatorType = "Multiplication operator"
     BO_Division       BinaryOperatorType = "Division operator"
+    BO_Modulo         BinaryOperatorType = "Modulo operator"
+
+    BO_BitwiseAnd     BinaryOperatorType = "Bitwise and operator"
+    BO_BitwiseOr      BinaryOperatorType = "Bitwise or operator"
+    BO_BitwiseXor     BinaryOperatorType = "Bitwise xor operator"
+    BO_ShiftLeft      BinaryOperatorType = "Left shift operator"
+    BO_ShiftRight     BinaryOperatorType = "Right shift operator"
 
     BO_LogicalAnd     BinaryOperatorType = "Logical and operator"
     BO_LogicalOr      BinaryOperatorType = "Logical or operator"
@@ -58,15 +65,14 @@ func GetBinaryOperator(op lexer.TokenType, left *symbols.TypeSymbol, right *symb
         op == lexer.TT_LessThan      ||
         op == lexer.TT_LessEqual     ||
         op == lexer.TT_GreaterThan   ||
-        op == lexer.TT_GreaterEqual) && (
-        (left.TypeGroup == symbols.INT   && right.TypeGroup == symbols.INT) ||
-        (left.TypeGroup == symbols.FLOAT && right.TypeGroup == symbols.FLOAT)){ 
+        op == lexer.TT_GreaterEqual) &&
+        PromoteNumeric(left, right) != nil { 
     
-        // always use the larger type
-        typ := left
-        if (right.TypeSize > typ.TypeSize) {
-            typ = right
-        }
+        // both sides get promoted to whatever is higher up (int * float -> float)
+        typ := PromoteNumeric(left, right)
+
+        // comparisons always end up as a bool
+        boolean := compunit.GlobalDataType("bool")
        
         // mmmm operations
         switch op {
@@ -79,27 +85,56 @@ func GetBinaryOperator(op lexer.TokenType, left *symbols.TypeSymbol, right *symb
         case lexer.TT_Slash: 
             return NewBoundBinaryOperator(BO_Division, typ, typ, typ)
         case lexer.TT_Equal: 
-            return NewBoundBinaryOperator(BO_Equal, typ, typ, typ)
+            return NewBoundBinaryOperator(BO_Equal, typ, typ, boolean)
         case lexer.TT_Unequal: 
-            return NewBoundBinaryOperator(BO_UnEqual, typ, typ, typ)
+            return NewBoundBinaryOperator(BO_UnEqual, typ, typ, boolean)
         case lexer.TT_LessThan: 
-            return NewBoundBinaryOperator(BO_LessThan, typ, typ, typ)
+            return NewBoundBinaryOperator(BO_LessThan, typ, typ, boolean)
         case lexer.TT_LessEqual: 
-            return NewBoundBinaryOperator(BO_LessEqual, typ, typ, typ)
+            return NewBoundBinaryOperator(BO_LessEqual, typ, typ, boolean)
         case lexer.TT_GreaterThan: 
-            return NewBoundBinaryOperator(BO_GreaterThan, typ, typ, typ)
+            return NewBoundBinaryOperator(BO_GreaterThan, typ, typ, boolean)
         case lexer.TT_GreaterEqual: 
-            return NewBoundBinaryOperator(BO_GreaterEqual, typ, typ, typ)
+            return NewBoundBinaryOperator(BO_GreaterEqual, typ, typ, boolean)
+        }
+    }
+
+    // Integer only operations (modulo, bitwise and shifts)
+    if (op == lexer.TT_Percent    ||
+        op == lexer.TT_Ampersand  ||
+        op == lexer.TT_Pipe       ||
+        op == lexer.TT_Caret      ||
+        op == lexer.TT_ShiftLeft  ||
+        op == lexer.TT_ShiftRight) &&
+        left.TypeGroup  == symbols.INT &&
+        right.TypeGroup == symbols.INT {
+
+        // same deal, the larger type wins
+        typ := PromoteNumeric(left, right)
+
+        switch op {
+        case lexer.TT_Percent:
+            return NewBoundBinaryOperator(BO_Modulo, typ, typ, typ)
+        case lexer.TT_Ampersand:
+            return NewBoundBinaryOperator(BO_BitwiseAnd, typ, typ, typ)
+        case lexer.TT_Pipe:
+            return NewBoundBinaryOperator(BO_BitwiseOr, typ, typ, typ)
+        case lexer.TT_Caret:
+            return NewBoundBinaryOperator(BO_BitwiseXor, typ, typ, typ)
+        case lexer.TT_ShiftLeft:
+            return NewBoundBinaryOperator(BO_ShiftLeft, typ, typ, typ)
+        case lexer.TT_ShiftRight:
+            return NewBoundBinaryOperator(BO_ShiftRight, typ, typ, typ)
         }
     }
 
     // Logical operations (and, or)
     if (op == lexer.TT_Ampersands ||
         op == lexer.TT_Pipes    ) && (
-            left.Equal(compunit.GlobalDataTypeRegister["bool"]) &&
-            right.Equal(compunit.GlobalDataTypeRegister["bool"])){ 
+            left.Equal(compunit.GlobalDataType("bool")) &&
+            right.Equal(compunit.GlobalDataType("bool"))){ 
       
-        typ := compunit.GlobalDataTypeRegister["bool"]
+        typ := compunit.GlobalDataType("bool")
 
         switch op {
         case lexer.TT_Ampersands: 
@@ -111,16 +146,38 @@ func GetBinaryOperator(op lexer.TokenType, left *symbols.TypeSymbol, right *symb
 
     // Equality and unequality
     if left.Equal(right) && op == lexer.TT_Equal {
-        return NewBoundBinaryOperator(BO_Equal, left, right, compunit.GlobalDataTypeRegister["bool"])
+        return NewBoundBinaryOperator(BO_Equal, left, right, compunit.GlobalDataType("bool"))
     } 
 
     if left.Equal(right) && op == lexer.TT_Unequal {
-        return NewBoundBinaryOperator(BO_UnEqual, left, right, compunit.GlobalDataTypeRegister["bool"])
+        return NewBoundBinaryOperator(BO_UnEqual, left, right, compunit.GlobalDataType("bool"))
     } 
 
+    // null checks (Foo? = null, Foo = Foo?)
+    // both sides get compared as the nullable type
+    if op == lexer.TT_Equal || op == lexer.TT_Unequal {
+        var typ *symbols.TypeSymbol
+
+        if left.Equal(compunit.GlobalDataType("null")) && right.CanBeNullable() {
+            typ = symbols.NewNullableType(right)
+        } else if right.Equal(compunit.GlobalDataType("null")) && left.CanBeNullable() {
+            typ = symbols.NewNullableType(left)
+        } else if (left.Nullable || right.Nullable) && left.NonNullable().Equal(right.NonNullable()) {
+            typ = symbols.NewNullableType(left)
+        }
+
+        if typ != nil && op == lexer.TT_Equal {
+            return NewBoundBinaryOperator(BO_Equal, typ, typ, compunit.GlobalDataType("bool"))
+        }
+
+        if typ != nil && op == lexer.TT_Unequal {
+            return NewBoundBinaryOperator(BO_UnEqual, typ, typ, compunit.GlobalDataType("bool"))
+        }
+    }
+
     // string concat
-    if left.Equal(compunit.GlobalDataTypeRegister["string"]) &&
-       right.Equal(compunit.GlobalDataTypeRegister["string"]) {
+    if left.Equal(compunit.GlobalDataType("string")) &&
+       right.Equal(compunit.GlobalDataType("string")) {
         return NewBoundBinaryOperator(BO_Concat, left, right, left) 
     }
 
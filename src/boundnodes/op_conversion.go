@@ -15,6 +15,40 @@ const (
     CT_None     ConversionType = "No conversion"
 )
 
+// Numeric promotion
+// -----------------
+// every number type has a spot on this ladder, when two
+// different ones meet, the one further up wins
+var numericRanks = []string{"byte", "word", "int", "long", "float", "double"}
+
+// position of a type on the ladder (0 if its not a number)
+func NumericRank(typ *symbols.TypeSymbol) int {
+    if typ.TypeGroup != symbols.INT && typ.TypeGroup != symbols.FLOAT {
+        return 0
+    }
+
+    for i, v := range numericRanks {
+        if typ.Equal(compunit.GlobalDataType(v)) {
+            return i + 1
+        }
+    }
+
+    return 0
+}
+
+// the type both sides of a numeric operation end up as (nil if one isnt a number)
+func PromoteNumeric(left *symbols.TypeSymbol, right *symbols.TypeSymbol) *symbols.TypeSymbol {
+    if NumericRank(left) == 0 || NumericRank(right) == 0 {
+        return nil
+    }
+
+    if NumericRank(right) > NumericRank(left) {
+        return right
+    }
+
+    return left
+}
+
 func ClassifyConversion(from *symbols.TypeSymbol, to *symbols.TypeSymbol) ConversionType {
     // identity casts be gaming
     if from.Equal(to) {
@@ -22,51 +56,94 @@ func ClassifyConversion(from *symbols.TypeSymbol, to *symbols.TypeSymbol) Conver
     }
 
     // Anything can be cast to any
-    if to.Equal(compunit.GlobalDataTypeRegister["any"]) &&
-       !from.Equal(compunit.GlobalDataTypeRegister["void"]) {
+    if to.Equal(compunit.GlobalDataType("any")) &&
+       !from.Equal(compunit.GlobalDataType("void")) {
         return CT_Implicit
     }
 
     // any can be cast to anything
-    if !to.Equal(compunit.GlobalDataTypeRegister["void"]) &&
-        from.Equal(compunit.GlobalDataTypeRegister["any"]){
+    if !to.Equal(compunit.GlobalDataType("void")) &&
+        from.Equal(compunit.GlobalDataType("any")){
         return CT_Implicit
     }
 
-    // up and down casts
-    if (from.TypeGroup == symbols.INT   && to.TypeGroup == symbols.INT) ||
-       (from.TypeGroup == symbols.FLOAT && to.TypeGroup == symbols.FLOAT) {
-        
-        // allow implicit upcasts
-        if to.TypeSize > from.TypeSize {
+    // null fits into every nullable type (and nothing else)
+    if from.Equal(compunit.GlobalDataType("null")) {
+        if to.Nullable {
             return CT_Implicit
         }
 
-        // down casts need to be explicit
-        if to.TypeSize < from.TypeSize {
+        return CT_None
+    }
+
+    // nullable types follow the same rules as their non-nullable versions,
+    // except that getting rid of the question mark is always explicit
+    if from.Nullable || to.Nullable {
+        con := ClassifyConversion(from.NonNullable(), to.NonNullable())
+
+        if con == CT_None {
+            return CT_None
+        }
+
+        // Foo? -> Foo can blow up at runtime
+        if from.Nullable && !to.Nullable {
             return CT_Explicit
         }
+
+        // Foo -> Foo? always works
+        if con == CT_Identity {
+            return CT_Implicit
+        }
+
+        return con
+    }
+
+    // nothing else can become a type parameter
+    // (we have no idea what it will end up being)
+    if to.TypeGroup == symbols.TPRM {
+        return CT_None
+    }
+
+    // type parameters can only be turned into strings (see below)
+    if from.TypeGroup == symbols.TPRM &&
+       !to.Equal(compunit.GlobalDataType("string")) {
+        return CT_None
     }
 
-    // allow explicit int -> float
-    if from.TypeGroup == symbols.INT && to.TypeGroup == symbols.FLOAT {
+    // up and down casts between numbers
+    // (byte -> word -> int -> long -> float -> double)
+    if NumericRank(from) != 0 && NumericRank(to) != 0 {
+        
+        // allow implicit upcasts
+        if NumericRank(to) > NumericRank(from) {
+            return CT_Implicit
+        }
+
+        // down casts need to be explicit
         return CT_Explicit
     }
 
-    // allow explicit float -> int
-    if from.TypeGroup == symbols.FLOAT && to.TypeGroup == symbols.INT {
+    // enums are just ints with names, so they can be turned into any integer
+    // type and back (but only explicitly, not every int is a valid member)
+    if (from.TypeGroup == symbols.ENUM && to.TypeGroup == symbols.INT) ||
+       (from.TypeGroup == symbols.INT  && to.TypeGroup == symbols.ENUM) {
         return CT_Explicit
     }
 
     // allow anything explicitly to string
-    if !from.Equal(compunit.GlobalDataTypeRegister["void"]) &&
-        to.Equal(compunit.GlobalDataTypeRegister["string"]) {
+    if !from.Equal(compunit.GlobalDataType("void")) &&
+        to.Equal(compunit.GlobalDataType("string")) {
         return CT_Explicit
     }
 
+    // strings dont turn into functions, no matter how nicely you ask
+    if to.TypeGroup == symbols.FUNC {
+        return CT_None
+    }
+
     // allow anything explicitly from string
-    if  from.Equal(compunit.GlobalDataTypeRegister["string"]) &&
-       !to.Equal(compunit.GlobalDataTypeRegister["void"]) {
+    if  from.Equal(compunit.GlobalDataType("string")) &&
+       !to.Equal(compunit.GlobalDataType("void")) {
         return CT_Explicit
     }
 
@@ -75,9 +152,10 @@ func ClassifyConversion(from *symbols.TypeSymbol, to *symbols.TypeSymbol) Conver
        to.TypeGroup   == symbols.TRT {
 
         // check if the container implements the trait
+        // (with the right type arguments)
         cnt := from.Container
-        for _, t := range cnt.Traits {
-            if t.TraitType.Equal(to) {
+        for i := range cnt.Traits {
+            if cnt.TraitTypeFor(i, from).Equal(to) {
                 return CT_Implicit
             }
         }
@@ -91,9 +169,10 @@ func ClassifyConversion(from *symbols.TypeSymbol, to *symbols.TypeSymbol) Conver
        to.TypeGroup   == symbols.CONT {
 
         // check if the container implements the trait
+        // (with the right type arguments)
         cnt := to.Container
-        for _, t := range cnt.Traits {
-            if t.TraitType.Equal(from) {
+        for i := range cnt.Traits {
+            if cnt.TraitTypeFor(i, to).Equal(from) {
                 return CT_Explicit
             }
         }
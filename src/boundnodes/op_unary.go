@@ -29,6 +29,7 @@ const (
     UO_Identity        UnaryOperatorType = "Identity operator"
     UO_Negation        UnaryOperatorType = "Negation operator"
     UO_LogicalNegation UnaryOperatorType = "Logical negation operator"
+    UO_BitwiseNegation UnaryOperatorType = "Bitwise negation operator"
 )
 
 func GetUnaryOperator(op lexer.TokenType, operand *symbols.TypeSymbol) *BoundUnaryOperator {
@@ -46,10 +47,14 @@ func GetUnaryOperator(op lexer.TokenType, operand *symbols.TypeSymbol) *BoundUna
         return NewBoundUnaryOperator(UO_Negation, operand, operand)
     }
 
-    if op == lexer.TT_Bang && operand.Equal(compunit.GlobalDataTypeRegister["bool"]) {
+    if op == lexer.TT_Bang && operand.Equal(compunit.GlobalDataType("bool")) {
         return NewBoundUnaryOperator(UO_LogicalNegation, operand, operand)
     }
 
+    if op == lexer.TT_Tilde && operand.TypeGroup == symbols.INT {
+        return NewBoundUnaryOperator(UO_BitwiseNegation, operand, operand)
+    }
+
     return nil
 }
 
@@ -0,0 +1,57 @@
+package boundnodes
+
+import (
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// ForEach statement
+// -----------------
+type BoundForEachStatementNode struct {
+    BoundStatementNode
+
+    SourceNode syntaxnodes.SyntaxNode
+
+    HasIndex bool
+    Index symbols.VariableSymbol
+    Variable symbols.VariableSymbol
+    Collection BoundExpressionNode
+    Body BoundStatementNode
+
+    LengthMethod *symbols.FunctionSymbol
+    GetMethod *symbols.FunctionSymbol // nil for arrays, those just get indexed
+
+    BreakLbl BoundLabel
+    ContinueLbl BoundLabel
+}
+
+func NewBoundForEachStatementNode(src syntaxnodes.SyntaxNode, hasIndex bool, index symbols.VariableSymbol, vari symbols.VariableSymbol, coll BoundExpressionNode, length *symbols.FunctionSymbol, get *symbols.FunctionSymbol, body BoundStatementNode, brk BoundLabel, cnt BoundLabel) *BoundForEachStatementNode {
+    return &BoundForEachStatementNode {
+        SourceNode: src,
+        HasIndex: hasIndex,
+        Index: index,
+        Variable: vari,
+        Collection: coll,
+        Body: body,
+        LengthMethod: length,
+        GetMethod: get,
+        BreakLbl: brk,
+        ContinueLbl: cnt,
+    }
+}
+
+func (nd *BoundForEachStatementNode) Type() BoundNodeType {
+    return BT_ForEachStmt
+}
+
+func (nd *BoundForEachStatementNode) Source() syntaxnodes.SyntaxNode {
+    return nd.SourceNode
+}
+
+func (nd *BoundForEachStatementNode) BreakLabel() BoundLabel {
+    return nd.BreakLbl
+}
+
+func (nd *BoundForEachStatementNode) ContinueLabel() BoundLabel {
+    return nd.ContinueLbl
+}
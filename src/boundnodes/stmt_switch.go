@@ -0,0 +1,67 @@
+package boundnodes
+
+import (
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Switch statement
+// ----------------
+type BoundSwitchStatementNode struct {
+    BoundStatementNode
+
+    SourceNode syntaxnodes.SyntaxNode
+
+    Subject BoundExpressionNode
+    Operator *BoundBinaryOperator // (how values get compared to the subject)
+    Cases []*BoundSwitchCase
+
+    DefaultBody BoundStatementNode
+    HasDefault bool
+}
+
+// A single case (default is not one of these)
+// -------------------------------------------
+type BoundSwitchCase struct {
+    SourceNode syntaxnodes.SyntaxNode
+
+    // case 1, 2:
+    Values []BoundExpressionNode
+
+    // case c SomeContainer:
+    IsPattern bool
+    PatternVariable symbols.VariableSymbol
+    PatternType *symbols.TypeSymbol
+
+    Body BoundStatementNode
+}
+
+func NewBoundSwitchStatementNode(src syntaxnodes.SyntaxNode, subject BoundExpressionNode, op *BoundBinaryOperator, cases []*BoundSwitchCase, defaultbody BoundStatementNode, hasdefault bool) *BoundSwitchStatementNode {
+    return &BoundSwitchStatementNode {
+        SourceNode: src,
+        Subject: subject,
+        Operator: op,
+        Cases: cases,
+        DefaultBody: defaultbody,
+        HasDefault: hasdefault,
+    }
+}
+
+func NewBoundSwitchCase(src syntaxnodes.SyntaxNode, values []BoundExpressionNode, ispattern bool, patternvar symbols.VariableSymbol, patterntyp *symbols.TypeSymbol, body BoundStatementNode) *BoundSwitchCase {
+    return &BoundSwitchCase {
+        SourceNode: src,
+        Values: values,
+        IsPattern: ispattern,
+        PatternVariable: patternvar,
+        PatternType: patterntyp,
+        Body: body,
+    }
+}
+
+func (nd *BoundSwitchStatementNode) Type() BoundNodeType {
+    return BT_SwitchStmt
+}
+
+func (nd *BoundSwitchStatementNode) Source() syntaxnodes.SyntaxNode {
+    return nd.SourceNode
+}
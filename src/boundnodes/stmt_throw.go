@@ -0,0 +1,30 @@
+package boundnodes
+
+import (
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Throw statement
+// ---------------
+type BoundThrowStatementNode struct {
+    BoundStatementNode
+
+    SourceNode syntaxnodes.SyntaxNode
+
+    Error BoundExpressionNode
+}
+
+func NewBoundThrowStatementNode(src syntaxnodes.SyntaxNode, err BoundExpressionNode) *BoundThrowStatementNode {
+    return &BoundThrowStatementNode {
+        SourceNode: src,
+        Error: err,
+    }
+}
+
+func (nd *BoundThrowStatementNode) Type() BoundNodeType {
+    return BT_ThrowStmt
+}
+
+func (nd *BoundThrowStatementNode) Source() syntaxnodes.SyntaxNode {
+    return nd.SourceNode
+}
@@ -0,0 +1,36 @@
+package boundnodes
+
+import (
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Try statement
+// -------------
+type BoundTryStatementNode struct {
+    BoundStatementNode
+
+    SourceNode syntaxnodes.SyntaxNode
+
+    Body BoundStatementNode
+
+    ErrorVariable symbols.VariableSymbol
+    CatchBody BoundStatementNode
+}
+
+func NewBoundTryStatementNode(src syntaxnodes.SyntaxNode, body BoundStatementNode, errvar symbols.VariableSymbol, catchbody BoundStatementNode) *BoundTryStatementNode {
+    return &BoundTryStatementNode {
+        SourceNode: src,
+        Body: body,
+        ErrorVariable: errvar,
+        CatchBody: catchbody,
+    }
+}
+
+func (nd *BoundTryStatementNode) Type() BoundNodeType {
+    return BT_TryStmt
+}
+
+func (nd *BoundTryStatementNode) Source() syntaxnodes.SyntaxNode {
+    return nd.SourceNode
+}
@@ -0,0 +1,41 @@
+package boundnodes
+
+import (
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Protected region statement
+// --------------------------
+// Marks everything between the Start and End labels as protected:
+// if an error happens in there, it is stored in ErrorVariable and
+// execution continues at the Catch label
+type BoundProtectStatementNode struct {
+    BoundStatementNode
+
+    SourceNode syntaxnodes.SyntaxNode
+
+    Start BoundLabel
+    End BoundLabel
+    Catch BoundLabel
+
+    ErrorVariable symbols.VariableSymbol
+}
+
+func NewBoundProtectStatementNode(src syntaxnodes.SyntaxNode, start BoundLabel, end BoundLabel, catch BoundLabel, errvar symbols.VariableSymbol) *BoundProtectStatementNode {
+    return &BoundProtectStatementNode {
+        SourceNode: src,
+        Start: start,
+        End: end,
+        Catch: catch,
+        ErrorVariable: errvar,
+    }
+}
+
+func (nd *BoundProtectStatementNode) Type() BoundNodeType {
+    return BT_ProtectIStmt
+}
+
+func (nd *BoundProtectStatementNode) Source() syntaxnodes.SyntaxNode {
+    return nd.SourceNode
+}
@@ -0,0 +1,725 @@
+// Bytecode - compiler.go
+// --------------------------------------------------------
+// Turns lowered function bodies into instructions
+// (locals become slots, labels become instruction indices)
+// --------------------------------------------------------
+package bytecode
+
+import (
+	"bytespace.network/rerect/boundnodes"
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/error"
+	packageprocessor "bytespace.network/rerect/package_processor"
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+)
+
+type Compiler struct {
+    Comp *compunit.Compilation
+    Program *Program
+
+    // state of the function currently being compiled
+    Function *Function
+    Locals map[symbols.VariableSymbol]int
+    Labels map[boundnodes.BoundLabel]int
+    Jumps []int                                       // jumps whose targets still need to be filled in
+    JumpLabels []boundnodes.BoundLabel
+    Protects []*boundnodes.BoundProtectStatementNode  // regions that need resolving once all labels are known
+    CatchCells map[boundnodes.BoundLabel]symbols.VariableSymbol // captured error variables that need a cell once they're caught
+}
+
+// Compile all lowered functions of the given files into a program
+// (can be done multiple times, the REPL keeps adding stuff as it goes)
+// --------------------------------------------------------------------
+func Compile(comp *compunit.Compilation, prg *Program, files []*packageprocessor.CompilationFile) {
+    cmp := Compiler{
+        Comp: comp,
+        Program: prg,
+    }
+
+    // give every global and function an id first
+    // (so calls to functions further down already know where to go)
+    for _, file := range files {
+        for _, glb := range file.Globals {
+            prg.globalId(glb)
+        }
+
+        for _, fnc := range file.Functions {
+            prg.functionId(fnc)
+        }
+    }
+
+    // now compile all the bodies
+    for _, file := range files {
+        for _, fnc := range file.Functions {
+            body, ok := file.FunctionBodies[fnc].(*boundnodes.BoundBlockStatementNode)
+
+            if !ok {
+                comp.Report(error.NewError(error.BTC, span.Internal(), "Function '%s' has not been lowered! (this should never happen)", fnc.FuncName))
+                continue
+            }
+
+            cmp.compileFunction(prg.Functions[prg.functionId(fnc)], body)
+        }
+    }
+}
+
+// --------------------------------------------------------
+// Functions
+// --------------------------------------------------------
+func (cmp *Compiler) compileFunction(fnc *Function, body *boundnodes.BoundBlockStatementNode) {
+    cmp.Function = fnc
+    cmp.Locals = make(map[symbols.VariableSymbol]int)
+    cmp.Labels = make(map[boundnodes.BoundLabel]int)
+    cmp.Jumps = []int{}
+    cmp.JumpLabels = []boundnodes.BoundLabel{}
+    cmp.Protects = []*boundnodes.BoundProtectStatementNode{}
+    cmp.CatchCells = make(map[boundnodes.BoundLabel]symbols.VariableSymbol)
+
+    fnc.Code = []Instruction{}
+    fnc.Spans = []span.Span{}
+    fnc.Regions = []Region{}
+    fnc.LocalCount = 0
+
+    // captures and parameters always take the first slots
+    for _, v := range fnc.Symbol.Captures {
+        cmp.slot(v)
+    }
+
+    for _, v := range fnc.Symbol.Parameters {
+        cmp.slot(v)
+    }
+
+    // parameters some lambda holds on to need to be put in a cell first
+    for _, v := range fnc.Symbol.Parameters {
+        if v.Captured {
+            cmp.emit(OP_LoadLocal, cmp.slot(v), 0, body)
+            cmp.emit(OP_MakeCell, cmp.slot(v), 0, body)
+        }
+    }
+
+    for _, stmt := range body.Statements {
+        cmp.compileStatement(stmt)
+    }
+
+    // someone forgot to return lol
+    cmp.emit(OP_Null, 0, 0, body)
+    cmp.emit(OP_Return, 0, 0, body)
+
+    // fill in all the jump targets
+    for i, idx := range cmp.Jumps {
+        fnc.Code[idx].A = int32(cmp.label(cmp.JumpLabels[i]))
+    }
+
+    // and resolve the protected regions
+    for _, v := range cmp.Protects {
+        fnc.Regions = append(fnc.Regions, Region{
+            Start: cmp.label(v.Start),
+            End: cmp.label(v.End),
+            Catch: cmp.label(v.Catch),
+            ErrorSlot: cmp.slot(v.ErrorVariable),
+        })
+    }
+}
+
+// --------------------------------------------------------
+// Helpers
+// --------------------------------------------------------
+func (cmp *Compiler) emit(op Opcode, a int, b int, node boundnodes.BoundNode) int {
+    pos := span.Internal()
+    if node != nil && node.Source() != nil {
+        pos = node.Source().Position()
+    }
+
+    cmp.Function.Code = append(cmp.Function.Code, Instruction{Op: op, A: int32(a), B: int32(b)})
+    cmp.Function.Spans = append(cmp.Function.Spans, pos)
+
+    return len(cmp.Function.Code) - 1
+}
+
+// Get the slot of a local (or give it a new one)
+// ----------------------------------------------
+func (cmp *Compiler) slot(vari symbols.VariableSymbol) int {
+    if idx, ok := cmp.Locals[vari]; ok {
+        return idx
+    }
+
+    cmp.Locals[vari] = cmp.Function.LocalCount
+    cmp.Function.LocalCount++
+
+    return cmp.Locals[vari]
+}
+
+func (cmp *Compiler) label(lbl boundnodes.BoundLabel) int {
+    idx, ok := cmp.Labels[lbl]
+
+    if !ok {
+        cmp.Comp.Report(error.NewError(error.BTC, span.Internal(), "Someone fucked up the label lookup :) (%s)", lbl))
+        return 0
+    }
+
+    return idx
+}
+
+func (cmp *Compiler) jump(op Opcode, lbl boundnodes.BoundLabel, node boundnodes.BoundNode) {
+    cmp.Jumps = append(cmp.Jumps, cmp.emit(op, 0, 0, node))
+    cmp.JumpLabels = append(cmp.JumpLabels, lbl)
+}
+
+// Where does the typed block of an operator start for the given type?
+// -------------------------------------------------------------------
+func numericKind(typ *symbols.TypeSymbol) (Opcode, bool) {
+    names := []string{"long", "int", "word", "byte", "double", "float"}
+
+    for i, v := range names {
+        if typ.Equal(compunit.GlobalDataType(v)) {
+            return Opcode(i), true
+        }
+    }
+
+    return 0, false
+}
+
+// --------------------------------------------------------
+// Statements
+// --------------------------------------------------------
+func (cmp *Compiler) compileStatement(stmt boundnodes.BoundStatementNode) {
+    if stmt.Type() == boundnodes.BT_DeclarationStmt {
+        cmp.compileDeclarationStatement(stmt.(*boundnodes.BoundDeclarationStatementNode))
+
+    } else if stmt.Type() == boundnodes.BT_ReturnStmt {
+        cmp.compileReturnStatement(stmt.(*boundnodes.BoundReturnStatementNode))
+
+    } else if stmt.Type() == boundnodes.BT_ExpressionStmt {
+        cmp.compileExpressionStatement(stmt.(*boundnodes.BoundExpressionStatementNode))
+
+    } else if stmt.Type() == boundnodes.BT_GoToIStmt {
+        cmp.jump(OP_Jump, stmt.(*boundnodes.BoundGotoStatementNode).Label, stmt)
+
+    } else if stmt.Type() == boundnodes.BT_GoToIfIStmt {
+        gti := stmt.(*boundnodes.BoundGotoIfStatementNode)
+        cmp.compileExpression(gti.Condition)
+        cmp.jump(OP_JumpIf, gti.Label, stmt)
+
+    } else if stmt.Type() == boundnodes.BT_LabelIStmt {
+        // labels are just the index of whatever comes next
+        lbl := stmt.(*boundnodes.BoundLabelStatementNode).Label
+        cmp.Labels[lbl] = len(cmp.Function.Code)
+
+        // a caught error might need to be put into a cell
+        if vari, ok := cmp.CatchCells[lbl]; ok {
+            cmp.emit(OP_LoadLocal, cmp.slot(vari), 0, stmt)
+            cmp.emit(OP_MakeCell, cmp.slot(vari), 0, stmt)
+        }
+
+    } else if stmt.Type() == boundnodes.BT_DeleteIStmt {
+        // slots dont need to be deleted, the next declaration just overwrites them
+
+    } else if stmt.Type() == boundnodes.BT_ApproachIStmt {
+        apr := stmt.(*boundnodes.BoundApproachStatementNode)
+        cmp.compileExpression(apr.Target)
+
+        if symbols.IsCaptured(apr.Iterator) {
+            cmp.emit(OP_ApproachCell, cmp.slot(apr.Iterator), 0, stmt)
+        } else {
+            cmp.emit(OP_ApproachLocal, cmp.slot(apr.Iterator), 0, stmt)
+        }
+
+    } else if stmt.Type() == boundnodes.BT_ThrowStmt {
+        cmp.compileExpression(stmt.(*boundnodes.BoundThrowStatementNode).Error)
+        cmp.emit(OP_Throw, 0, 0, stmt)
+
+    } else if stmt.Type() == boundnodes.BT_ProtectIStmt {
+        // regions get resolved once all labels are known
+        prt := stmt.(*boundnodes.BoundProtectStatementNode)
+        cmp.Protects = append(cmp.Protects, prt)
+
+        if symbols.IsCaptured(prt.ErrorVariable) {
+            cmp.CatchCells[prt.Catch] = prt.ErrorVariable
+        }
+
+    } else {
+        cmp.Comp.Report(error.NewError(error.BTC, stmt.Source().Position(), "Statement compilation not implemented! You should implement NOW! (%s)", stmt.Type()))
+    }
+}
+
+func (cmp *Compiler) compileDeclarationStatement(stmt *boundnodes.BoundDeclarationStatementNode) {
+    if stmt.HasInitializer {
+        cmp.compileExpression(stmt.Initializer)
+    } else {
+        cmp.emit(OP_Default, cmp.Program.typeId(stmt.Variable.VarType()), 0, stmt)
+    }
+
+    // every declaration of a captured variable gets a fresh cell
+    // (so lambdas created in a loop dont all share the same one)
+    if symbols.IsCaptured(stmt.Variable) {
+        cmp.emit(OP_MakeCell, cmp.slot(stmt.Variable), 0, stmt)
+        return
+    }
+
+    cmp.compileStore(stmt.Variable, stmt, false)
+}
+
+func (cmp *Compiler) compileExpressionStatement(stmt *boundnodes.BoundExpressionStatementNode) {
+    // assignments dont need to keep their value around if nobody wants it
+    if stmt.Expression.Type() == boundnodes.BT_AssignmentExpr {
+        cmp.compileAssignment(stmt.Expression.(*boundnodes.BoundAssignmentExpressionNode), false)
+        return
+    }
+
+    cmp.compileExpression(stmt.Expression)
+    cmp.emit(OP_Pop, 0, 0, stmt)
+}
+
+func (cmp *Compiler) compileReturnStatement(stmt *boundnodes.BoundReturnStatementNode) {
+    if stmt.HasReturnValue {
+        cmp.compileExpression(stmt.ReturnValue)
+    } else {
+        cmp.emit(OP_Null, 0, 0, stmt)
+    }
+
+    cmp.emit(OP_Return, 0, 0, stmt)
+}
+
+// --------------------------------------------------------
+// Variables
+// --------------------------------------------------------
+func (cmp *Compiler) compileLoad(vari symbols.VariableSymbol, node boundnodes.BoundNode) {
+    if vari.Type() == symbols.ST_Global {
+        cmp.emit(OP_LoadGlobal, cmp.Program.globalId(vari.(*symbols.GlobalSymbol)), 0, node)
+
+    } else if vari.Type() == symbols.ST_Field {
+        cmp.emit(OP_LoadThis, 0, 0, node)
+        cmp.emit(OP_LoadField, cmp.Program.constantId(vari.Name()), 0, node)
+
+    } else if vari.Type() == symbols.ST_Instance {
+        cmp.emit(OP_LoadThis, 0, 0, node)
+
+    } else if symbols.IsCaptured(vari) {
+        cmp.emit(OP_LoadCell, cmp.slot(vari), 0, node)
+
+    } else {
+        cmp.emit(OP_LoadLocal, cmp.slot(vari), 0, node)
+    }
+}
+
+// Store the top value in a variable (keep -> the value stays on the stack)
+// ------------------------------------------------------------------------
+func (cmp *Compiler) compileStore(vari symbols.VariableSymbol, node boundnodes.BoundNode, keep bool) {
+    if vari.Type() == symbols.ST_Global {
+        if keep {
+            cmp.emit(OP_Dup, 0, 0, node)
+        }
+
+        cmp.emit(OP_StoreGlobal, cmp.Program.globalId(vari.(*symbols.GlobalSymbol)), 0, node)
+
+    } else if vari.Type() == symbols.ST_Field {
+        cmp.emit(OP_LoadThis, 0, 0, node)
+        cmp.emit(OP_StoreField, cmp.Program.constantId(vari.Name()), 0, node)
+
+        if !keep {
+            cmp.emit(OP_Pop, 0, 0, node)
+        }
+
+    } else if vari.Type() == symbols.ST_Instance {
+        // do absolutely nothing
+
+        // sike
+        cmp.Comp.Report(error.NewError(error.BTC, node.Source().Position(), "Instance variables are read only!"))
+
+    } else {
+        if keep {
+            cmp.emit(OP_Dup, 0, 0, node)
+        }
+
+        if symbols.IsCaptured(vari) {
+            cmp.emit(OP_StoreCell, cmp.slot(vari), 0, node)
+        } else {
+            cmp.emit(OP_StoreLocal, cmp.slot(vari), 0, node)
+        }
+    }
+}
+
+// --------------------------------------------------------
+// Expressions
+// --------------------------------------------------------
+func (cmp *Compiler) compileExpression(expr boundnodes.BoundExpressionNode) {
+    if expr.Type() == boundnodes.BT_LiteralExpr {
+        cmp.compileLiteralExpression(expr.(*boundnodes.BoundLiteralExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_AssignmentExpr {
+        cmp.compileAssignment(expr.(*boundnodes.BoundAssignmentExpressionNode), true)
+
+    } else if expr.Type() == boundnodes.BT_UnaryExpr {
+        cmp.compileUnaryExpression(expr.(*boundnodes.BoundUnaryExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_BinaryExpr {
+        cmp.compileBinaryExpression(expr.(*boundnodes.BoundBinaryExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_CallExpr {
+        cmp.compileCallExpression(expr.(*boundnodes.BoundCallExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_AccessCallExpr {
+        cmp.compileAccessCallExpression(expr.(*boundnodes.BoundAccessCallExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_NameExpr {
+        cmp.compileLoad(expr.(*boundnodes.BoundNameExpressionNode).Variable, expr)
+
+    } else if expr.Type() == boundnodes.BT_ConversionExpr {
+        cnv := expr.(*boundnodes.BoundConversionExpressionNode)
+        cmp.compileExpression(cnv.Value)
+
+        // enum values only know their names through their type
+        if cnv.Value.ExprType().TypeGroup == symbols.ENUM && cnv.TargetType.Equal(compunit.GlobalDataType("string")) {
+            cmp.emit(OP_EnumName, cmp.Program.typeId(cnv.Value.ExprType()), 0, expr)
+
+        // Foo -> Foo? doesnt change anything at runtime
+        } else if !cnv.TargetType.Nullable || !cnv.TargetType.NonNullable().Equal(cnv.Value.ExprType()) {
+            cmp.emit(OP_Convert, cmp.Program.typeId(cnv.TargetType), 0, expr)
+        }
+
+    } else if expr.Type() == boundnodes.BT_TypeCheckExpr {
+        chk := expr.(*boundnodes.BoundTypeCheckExpressionNode)
+        cmp.compileExpression(chk.Value)
+        cmp.emit(OP_Is, cmp.Program.typeId(chk.CheckType), 0, expr)
+
+    } else if expr.Type() == boundnodes.BT_CheckedCastExpr {
+        cst := expr.(*boundnodes.BoundCheckedCastExpressionNode)
+        cmp.compileExpression(cst.Value)
+        cmp.emit(OP_As, cmp.Program.typeId(cst.TargetType), 0, expr)
+
+    } else if expr.Type() == boundnodes.BT_MakeArrayExpr {
+        cmp.compileMakeArrayExpression(expr.(*boundnodes.BoundMakeArrayExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_MakeMapExpr {
+        cmp.compileMakeMapExpression(expr.(*boundnodes.BoundMakeMapExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_ArrayIndexExpr {
+        idx := expr.(*boundnodes.BoundArrayIndexExpressionNode)
+        cmp.compileExpression(idx.SourceArray)
+        cmp.compileExpression(idx.Index)
+
+        if idx.SourceArray.ExprType().TypeGroup == symbols.MAP {
+            cmp.emit(OP_LoadKey, 0, 0, expr)
+        } else {
+            cmp.emit(OP_LoadIndex, 0, 0, expr)
+        }
+
+    } else if expr.Type() == boundnodes.BT_MakeExpr {
+        cmp.compileMakeExpression(expr.(*boundnodes.BoundMakeExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_FunctionExpr {
+        cmp.compileFunctionExpression(expr.(*boundnodes.BoundFunctionExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_CallValueExpr {
+        cv := expr.(*boundnodes.BoundCallValueExpressionNode)
+        cmp.compileExpression(cv.Value)
+
+        for _, arg := range cv.Arguments {
+            cmp.compileExpression(arg)
+        }
+
+        cmp.emit(OP_CallValue, 0, len(cv.Arguments), expr)
+
+    } else if expr.Type() == boundnodes.BT_SequenceExpr {
+        // the statements dont leave anything behind, so only the value stays on the stack
+        seq := expr.(*boundnodes.BoundSequenceExpressionNode)
+        for _, stmt := range seq.Statements {
+            cmp.compileStatement(stmt)
+        }
+
+        cmp.compileExpression(seq.Value)
+
+    } else if expr.Type() == boundnodes.BT_AccessFieldExpr {
+        fld := expr.(*boundnodes.BoundAccessFieldExpressionNode)
+        cmp.compileExpression(fld.Expression)
+
+        if fld.NullSafe {
+            cmp.compileNullSafe(fld.FieldType, expr, func() {
+                cmp.emit(OP_LoadField, cmp.Program.constantId(fld.Field.FieldName), 0, expr)
+            })
+        } else {
+            cmp.emit(OP_LoadField, cmp.Program.constantId(fld.Field.FieldName), 0, expr)
+        }
+
+    } else {
+        cmp.Comp.Report(error.NewError(error.BTC, expr.Source().Position(), "Expression compilation not implemented! You should implement NOW! (%s)", expr.Type()))
+    }
+}
+
+func (cmp *Compiler) compileLiteralExpression(expr *boundnodes.BoundLiteralExpressionNode) {
+    if expr.LiteralValue == nil {
+        cmp.emit(OP_Null, 0, 0, expr)
+        return
+    }
+
+    cmp.emit(OP_Const, cmp.Program.constantId(expr.LiteralValue), 0, expr)
+}
+
+func (cmp *Compiler) compileAssignment(expr *boundnodes.BoundAssignmentExpressionNode, keep bool) {
+    // the value always comes first
+    cmp.compileExpression(expr.Value)
+
+    // classic variable assignment
+    if expr.Expression.Type() == boundnodes.BT_NameExpr {
+        cmp.compileStore(expr.Expression.(*boundnodes.BoundNameExpressionNode).Variable, expr, keep)
+        return
+
+    // array index assignment
+    } else if expr.Expression.Type() == boundnodes.BT_ArrayIndexExpr {
+        exp := expr.Expression.(*boundnodes.BoundArrayIndexExpressionNode)
+
+        cmp.compileExpression(exp.SourceArray)
+        cmp.compileExpression(exp.Index)
+
+        if exp.SourceArray.ExprType().TypeGroup == symbols.MAP {
+            cmp.emit(OP_StoreKey, 0, 0, expr)
+        } else {
+            cmp.emit(OP_StoreIndex, 0, 0, expr)
+        }
+
+    // container field assignment
+    } else if expr.Expression.Type() == boundnodes.BT_AccessFieldExpr {
+        exp := expr.Expression.(*boundnodes.BoundAccessFieldExpressionNode)
+
+        cmp.compileExpression(exp.Expression)
+        cmp.emit(OP_StoreField, cmp.Program.constantId(exp.Field.FieldName), 0, expr)
+    }
+
+    // index and field stores always leave the value behind
+    if !keep {
+        cmp.emit(OP_Pop, 0, 0, expr)
+    }
+}
+
+func (cmp *Compiler) compileUnaryExpression(expr *boundnodes.BoundUnaryExpressionNode) {
+    cmp.compileExpression(expr.Operand)
+
+    switch expr.Operator.Operation {
+    case boundnodes.UO_Identity:
+        // nothing to do here
+        return
+
+    case boundnodes.UO_Negation:
+        if kind, ok := numericKind(expr.Operator.Operand); ok {
+            cmp.emit(OP_NegI64 + kind, 0, 0, expr)
+            return
+        }
+
+    case boundnodes.UO_BitwiseNegation:
+        if kind, ok := numericKind(expr.Operator.Operand); ok {
+            cmp.emit(OP_BitNotI64 + kind, 0, 0, expr)
+            return
+        }
+
+    case boundnodes.UO_LogicalNegation:
+        if expr.Operator.Operand.Equal(compunit.GlobalDataType("bool")) {
+            cmp.emit(OP_Not, 0, 0, expr)
+            return
+        }
+    }
+
+    cmp.Comp.Report(error.NewError(error.BTC, expr.Source().Position(), "Unary operator not implemented! You should implement NOW!"))
+}
+
+func (cmp *Compiler) compileBinaryExpression(expr *boundnodes.BoundBinaryExpressionNode) {
+    cmp.compileExpression(expr.Left)
+    cmp.compileExpression(expr.Right)
+
+    // operators that dont care about types
+    switch expr.Operator.Operation {
+    case boundnodes.BO_Equal:
+        cmp.emit(OP_Equal, 0, 0, expr)
+        return
+
+    case boundnodes.BO_UnEqual:
+        cmp.emit(OP_Unequal, 0, 0, expr)
+        return
+
+    case boundnodes.BO_LogicalAnd:
+        cmp.emit(OP_And, 0, 0, expr)
+        return
+
+    case boundnodes.BO_LogicalOr:
+        cmp.emit(OP_Or, 0, 0, expr)
+        return
+
+    case boundnodes.BO_Concat:
+        cmp.emit(OP_Concat, 0, 0, expr)
+        return
+    }
+
+    // typed arithmetic
+    blocks := map[boundnodes.BinaryOperatorType]Opcode{
+        boundnodes.BO_Addition:       OP_AddI64,
+        boundnodes.BO_Subtraction:    OP_SubI64,
+        boundnodes.BO_Multiplication: OP_MulI64,
+        boundnodes.BO_Division:       OP_DivI64,
+        boundnodes.BO_LessThan:       OP_LessI64,
+        boundnodes.BO_LessEqual:      OP_LessEqualI64,
+        boundnodes.BO_GreaterThan:    OP_GreaterI64,
+        boundnodes.BO_GreaterEqual:   OP_GreaterEqualI64,
+        boundnodes.BO_Modulo:         OP_ModI64,
+        boundnodes.BO_BitwiseAnd:     OP_BitAndI64,
+        boundnodes.BO_BitwiseOr:      OP_BitOrI64,
+        boundnodes.BO_BitwiseXor:     OP_BitXorI64,
+        boundnodes.BO_ShiftLeft:      OP_ShlI64,
+        boundnodes.BO_ShiftRight:     OP_ShrI64,
+    }
+
+    block, ok := blocks[expr.Operator.Operation]
+    kind, isNumeric := numericKind(expr.Operator.Left)
+
+    if ok && isNumeric {
+        cmp.emit(block + kind, 0, 0, expr)
+        return
+    }
+
+    cmp.Comp.Report(error.NewError(error.BTC, expr.Source().Position(), "Binary operator not implemented! You should implement NOW!"))
+}
+
+func (cmp *Compiler) compileCallExpression(expr *boundnodes.BoundCallExpressionNode) {
+    // is this a method? (a function call without prefix happening inside a container)
+    if expr.Function.FunctionKind == symbols.FT_METH {
+        cmp.emit(OP_LoadThis, 0, 0, expr)
+    }
+
+    for _, arg := range expr.Arguments {
+        cmp.compileExpression(arg)
+    }
+
+    argc := len(expr.Arguments)
+
+    if expr.Function.FunctionKind == symbols.FT_METH {
+        cmp.compileMethodCall(expr.Function, argc, expr)
+
+    } else if expr.Function.IsVMFunction {
+        cmp.emit(OP_CallNative, cmp.Program.nativeId(expr.Function), argc, expr)
+
+    } else {
+        cmp.emit(OP_Call, cmp.Program.functionId(expr.Function), argc, expr)
+    }
+}
+
+func (cmp *Compiler) compileAccessCallExpression(expr *boundnodes.BoundAccessCallExpressionNode) {
+    cmp.compileExpression(expr.Expression)
+
+    call := func() {
+        for _, arg := range expr.Arguments {
+            cmp.compileExpression(arg)
+        }
+
+        cmp.compileMethodCall(expr.Function, len(expr.Arguments), expr)
+    }
+
+    if expr.NullSafe {
+        cmp.compileNullSafe(expr.ReturnType, expr, call)
+    } else {
+        call()
+    }
+}
+
+// ?-> only runs the access if the instance on the stack isnt null
+// (otherwise the instance gets swapped for the default of the result)
+// -------------------------------------------------------------------
+func (cmp *Compiler) compileNullSafe(typ *symbols.TypeSymbol, node boundnodes.BoundNode, access func()) {
+    cmp.emit(OP_Dup, 0, 0, node)
+    cmp.emit(OP_Null, 0, 0, node)
+    cmp.emit(OP_Equal, 0, 0, node)
+    isNull := cmp.emit(OP_JumpIf, 0, 0, node)
+
+    access()
+    end := cmp.emit(OP_Jump, 0, 0, node)
+
+    // these jumps dont have bound labels, so just point them at the right spot directly
+    cmp.Function.Code[isNull].A = int32(len(cmp.Function.Code))
+    cmp.emit(OP_Pop, 0, 0, node)
+    cmp.emit(OP_Default, cmp.Program.typeId(typ), 0, node)
+
+    cmp.Function.Code[end].A = int32(len(cmp.Function.Code))
+}
+
+// Call a method on the instance sitting below the arguments
+// ---------------------------------------------------------
+func (cmp *Compiler) compileMethodCall(fnc *symbols.FunctionSymbol, argc int, node boundnodes.BoundNode) {
+    if fnc.IsVMFunction {
+        cmp.emit(OP_CallNativeMethod, cmp.Program.nativeId(fnc), argc, node)
+        return
+    }
+
+    cmp.emit(OP_CallMethod, cmp.Program.functionId(fnc), argc, node)
+}
+
+func (cmp *Compiler) compileFunctionExpression(expr *boundnodes.BoundFunctionExpressionNode) {
+    if expr.Function.IsVMFunction {
+        cmp.emit(OP_MakeNativeFunction, cmp.Program.nativeId(expr.Function), 0, expr)
+        return
+    }
+
+    // lambdas get the cells of their captures (not the values inside of them)
+    for _, v := range expr.Function.Captures {
+        cmp.emit(OP_LoadLocal, cmp.slot(v), 0, expr)
+    }
+
+    cmp.emit(OP_MakeFunction, cmp.Program.functionId(expr.Function), len(expr.Function.Captures), expr)
+}
+
+func (cmp *Compiler) compileMakeArrayExpression(expr *boundnodes.BoundMakeArrayExpressionNode) {
+    // This is a length defined array
+    if !expr.HasInitializer {
+        cmp.compileExpression(expr.Length)
+        cmp.emit(OP_MakeArray, cmp.Program.typeId(expr.ArrType), 0, expr)
+        return
+    }
+
+    // This is an element defined array
+    for _, v := range expr.Initializer {
+        cmp.compileExpression(v)
+    }
+
+    cmp.emit(OP_MakeArrayFrom, cmp.Program.typeId(expr.ArrType), len(expr.Initializer), expr)
+}
+
+func (cmp *Compiler) compileMakeMapExpression(expr *boundnodes.BoundMakeMapExpressionNode) {
+    // keys and values take turns
+    for i := range expr.Keys {
+        cmp.compileExpression(expr.Keys[i])
+        cmp.compileExpression(expr.Values[i])
+    }
+
+    cmp.emit(OP_MakeMap, cmp.Program.typeId(expr.MapType), len(expr.Keys) * 2, expr)
+}
+
+func (cmp *Compiler) compileMakeExpression(expr *boundnodes.BoundMakeExpressionNode) {
+    // create an instance
+    cmp.emit(OP_Make, cmp.Program.containerId(expr.Container), cmp.Program.typeId(expr.ContainerType), expr)
+
+    // are we calling a constructor?
+    if expr.HasConstructor {
+        // keep a copy of the instance around (the call eats one)
+        cmp.emit(OP_Dup, 0, 0, expr)
+
+        for _, v := range expr.Arguments {
+            cmp.compileExpression(v)
+        }
+
+        cmp.compileMethodCall(expr.Container.Constructor, len(expr.Arguments), expr)
+
+        // we dont care about what the constructor returns
+        cmp.emit(OP_Pop, 0, 0, expr)
+    }
+
+    // are we initializing fields ourselves like a caveman?
+    // (go through the container fields so the order is always the same)
+    if expr.HasInitializer {
+        for _, fld := range expr.Container.Fields {
+            v, ok := expr.Initializer[fld]
+            if !ok {
+                continue
+            }
+
+            cmp.compileExpression(v)
+            cmp.emit(OP_InitField, cmp.Program.constantId(fld.FieldName), 0, expr)
+        }
+    }
+}
@@ -0,0 +1,87 @@
+// Bytecode - module.go
+// --------------------------------------------------------
+// Saving compiled programs to disk (and loading them back)
+// so they can be run without their sources
+// --------------------------------------------------------
+package bytecode
+
+import (
+	"fmt"
+	"os"
+
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/error"
+	"bytespace.network/rerect/span"
+)
+
+// Module file layout
+// ------------------
+// magic "RRX\0", version (uint16, little endian), then these sections in order:
+//  1. sources     (path + content of every source file, so runtime errors can still point at code)
+//  2. packages    (names)
+//  3. types       (builtins by name, containers and traits by name + type arguments + nullability,
+//                  enums by name + members, everything else by structure)
+//  4. traits      (natives by name, everything else with fields and methods)
+//  5. containers  (same as traits, plus traits (and their type arguments) and constructor)
+//  6. functions   (symbol info, and code if the function has a body)
+//  7. natives     (package, method source and name -> resolved on load)
+//  8. globals
+//  9. constants   (tagged values)
+// 10. the programs type and container tables
+//
+// All numbers are varints unless noted otherwise, strings are length prefixed.
+// Anything that changes this layout (or the opcode list!) needs a new version.
+const ModuleMagic   = "RRX\x00"
+const ModuleVersion = 9
+
+// Constant tags
+// -------------
+const (
+    CT_Long   byte = 1
+    CT_Int    byte = 2
+    CT_Word   byte = 3
+    CT_Byte   byte = 4
+    CT_Double byte = 5
+    CT_Float  byte = 6
+    CT_Bool   byte = 7
+    CT_String byte = 8
+)
+
+// Type entry kinds
+// ----------------
+const (
+    TE_Builtin   byte = 0 // one of compunit.GlobalDataType()
+    TE_Plain     byte = 1 // arrays and the like
+    TE_Container byte = 2
+    TE_Trait     byte = 3
+    TE_Enum      byte = 4 // with all of its members
+)
+
+// Write a program into a module file
+// ----------------------------------
+func Save(comp *compunit.Compilation, prg *Program, path string) {
+    data := encodeModule(comp, prg)
+
+    err := os.WriteFile(path, data, 0644)
+    if err != nil {
+        comp.Report(error.NewError(error.FIO, span.Internal(), "Unable to write module '%s'! (%s)", path, err.Error()))
+    }
+}
+
+// Read a program from a module file
+// (native packages need to be loaded into comp beforehand)
+// --------------------------------------------------------
+func Load(comp *compunit.Compilation, path string) *Program {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        comp.Report(error.NewError(error.FIO, span.Internal(), "Unable to read module '%s'! (%s)", path, err.Error()))
+        return nil
+    }
+
+    return decodeModule(comp, path, data)
+}
+
+// Shortcut for reader errors
+func corrupted(msg string, prm ...any) {
+    panic(fmt.Sprintf(msg, prm...))
+}
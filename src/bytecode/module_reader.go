@@ -0,0 +1,693 @@
+// Bytecode - module_reader.go
+// --------------------------------------------------------
+// Turns the bytes of a module back into a program
+// (natives get looked up by package and name)
+// --------------------------------------------------------
+package bytecode
+
+import (
+	"encoding/binary"
+	"math"
+
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/error"
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+)
+
+type moduleReader struct {
+    Comp *compunit.Compilation
+    Program *Program
+    Data []byte
+    Pos int
+
+    // where this modules source files ended up in the compilation
+    SourceOffset int
+
+    // symbol tables (same order as in the file)
+    Packages []*symbols.PackageSymbol
+    NativePackages map[*symbols.PackageSymbol]bool
+    Types []*symbols.TypeSymbol
+    Traits []*symbols.TraitSymbol
+    Containers []*symbols.ContainerSymbol
+
+    // container and trait types only know their package and name until those have been read
+    Unlinked map[*symbols.TypeSymbol]*symbols.PackageSymbol
+
+    // method lists can only be filled in once all functions exist
+    TraitMethods map[*symbols.TraitSymbol][]int
+    ContainerMethods map[*symbols.ContainerSymbol][]int
+    Constructors map[*symbols.ContainerSymbol]int
+    TraitSources map[*symbols.FunctionSymbol]int
+}
+
+func decodeModule(comp *compunit.Compilation, path string, data []byte) (prg *Program) {
+    rdr := moduleReader{
+        Comp: comp,
+        Program: NewProgram(),
+        Data: data,
+        NativePackages: make(map[*symbols.PackageSymbol]bool),
+        Unlinked: make(map[*symbols.TypeSymbol]*symbols.PackageSymbol),
+        TraitMethods: make(map[*symbols.TraitSymbol][]int),
+        ContainerMethods: make(map[*symbols.ContainerSymbol][]int),
+        Constructors: make(map[*symbols.ContainerSymbol]int),
+        TraitSources: make(map[*symbols.FunctionSymbol]int),
+    }
+
+    // anything going wrong in here means the file is broken
+    defer func() {
+        r := recover()
+        if r == nil {
+            return
+        }
+
+        comp.Report(error.NewError(error.BTC, span.Internal(), "Unable to load module '%s'! (%v)", path, r))
+        prg = nil
+    }()
+
+    // Header
+    // ------
+    if len(data) < len(ModuleMagic) + 2 || string(data[:len(ModuleMagic)]) != ModuleMagic {
+        corrupted("not a ReRect module")
+    }
+
+    rdr.Pos = len(ModuleMagic)
+    version := binary.LittleEndian.Uint16(data[rdr.Pos:])
+    rdr.Pos += 2
+
+    if version != ModuleVersion {
+        corrupted("module has version %d, but this rrc only knows version %d", version, ModuleVersion)
+    }
+
+    // Sections
+    // --------
+    rdr.readSources()
+    rdr.readPackages()
+    rdr.readTypes()
+    rdr.readTraits()
+    rdr.readContainers()
+    rdr.readFunctions()
+    rdr.readNatives()
+    rdr.readGlobals()
+    rdr.readConstants()
+    rdr.readTables()
+
+    rdr.link()
+    rdr.check()
+
+    return rdr.Program
+}
+
+// --------------------------------------------------------
+// Primitives
+// --------------------------------------------------------
+func (rdr *moduleReader) int() int {
+    val, n := binary.Varint(rdr.Data[rdr.Pos:])
+    if n <= 0 {
+        corrupted("bad number at byte %d", rdr.Pos)
+    }
+
+    rdr.Pos += n
+    return int(val)
+}
+
+func (rdr *moduleReader) byte() byte {
+    if rdr.Pos >= len(rdr.Data) {
+        corrupted("unexpected end of file")
+    }
+
+    rdr.Pos++
+    return rdr.Data[rdr.Pos-1]
+}
+
+func (rdr *moduleReader) bool() bool {
+    return rdr.byte() != 0
+}
+
+func (rdr *moduleReader) string() string {
+    length := rdr.int()
+    if length < 0 || rdr.Pos + length > len(rdr.Data) {
+        corrupted("bad string at byte %d", rdr.Pos)
+    }
+
+    rdr.Pos += length
+    return string(rdr.Data[rdr.Pos-length:rdr.Pos])
+}
+
+func (rdr *moduleReader) count() int {
+    n := rdr.int()
+    if n < 0 || n > len(rdr.Data) {
+        corrupted("bad count at byte %d", rdr.Pos)
+    }
+
+    return n
+}
+
+func (rdr *moduleReader) raw(n int) []byte {
+    if rdr.Pos + n > len(rdr.Data) {
+        corrupted("unexpected end of file")
+    }
+
+    rdr.Pos += n
+    return rdr.Data[rdr.Pos-n:rdr.Pos]
+}
+
+// Symbol references (-1 for nothing)
+func (rdr *moduleReader) typeRef() *symbols.TypeSymbol {
+    id := rdr.int()
+    if id == -1 {
+        return nil
+    }
+
+    return rdr.Types[id]
+}
+
+func (rdr *moduleReader) packageRef() *symbols.PackageSymbol {
+    return rdr.Packages[rdr.int()]
+}
+
+// --------------------------------------------------------
+// Sections
+// --------------------------------------------------------
+func (rdr *moduleReader) readSources() {
+    rdr.SourceOffset = len(rdr.Comp.SourceFiles)
+
+    for n := rdr.count(); n > 0; n-- {
+        path := rdr.string()
+        rdr.Comp.RegisterSource(path, rdr.string())
+    }
+}
+
+func (rdr *moduleReader) readPackages() {
+    for n := rdr.count(); n > 0; n-- {
+        name := rdr.string()
+
+        // packages that already exist came from go land
+        pck := rdr.Comp.GetPackage(name)
+        if pck != nil {
+            rdr.NativePackages[pck] = true
+        } else {
+            pck = rdr.Comp.CreatePackage(name)
+        }
+
+        rdr.Packages = append(rdr.Packages, pck)
+    }
+}
+
+func (rdr *moduleReader) readTypes() {
+    for n := rdr.count(); n > 0; n-- {
+        kind := rdr.byte()
+        var typ *symbols.TypeSymbol
+
+        if kind == TE_Builtin {
+            name := rdr.string()
+            typ = compunit.GlobalDataType(name)
+
+            if typ == nil {
+                corrupted("unknown builtin type '%s'", name)
+            }
+
+        } else if kind == TE_Container || kind == TE_Trait {
+            pck := rdr.packageRef()
+            name := rdr.string()
+
+            args := []*symbols.TypeSymbol{}
+            for i := rdr.count(); i > 0; i-- {
+                args = append(args, rdr.typeRef())
+            }
+
+            nullable := rdr.bool()
+
+            // natives already have their type
+            if rdr.NativePackages[pck] {
+                typ = rdr.nativeType(pck, name)
+
+                if len(args) > 0 {
+                    typ = symbols.NewInstanceType(typ, args)
+                }
+
+                if nullable {
+                    typ = symbols.NewNullableType(typ)
+                }
+
+            // everyone else gets linked up once the container / trait is read
+            } else if kind == TE_Container {
+                typ = symbols.NewTypeSymbol(name, args, symbols.CONT, 0, nil)
+                typ.Nullable = nullable
+                rdr.Unlinked[typ] = pck
+            } else {
+                typ = symbols.NewTypeSymbol(name, args, symbols.TRT, 0, nil)
+                typ.Nullable = nullable
+                rdr.Unlinked[typ] = pck
+            }
+
+        } else if kind == TE_Enum {
+            pck := rdr.packageRef()
+            name := rdr.string()
+
+            typ = symbols.NewTypeSymbol(name, []*symbols.TypeSymbol{}, symbols.ENUM, 32, int32(0))
+            enm := symbols.NewEnumSymbol(pck, name, typ)
+
+            for i := rdr.count(); i > 0; i-- {
+                mem := rdr.string()
+                enm.AddMember(mem, int32(rdr.int()), "")
+            }
+
+            pck.TryRegisterEnum(enm)
+
+        } else if kind == TE_Plain {
+            name := rdr.string()
+            grp := symbols.TypeGroupType(rdr.string())
+            size := rdr.int()
+
+            subtypes := []*symbols.TypeSymbol{}
+            for i := rdr.count(); i > 0; i-- {
+                subtypes = append(subtypes, rdr.typeRef())
+            }
+
+            typ = symbols.NewTypeSymbol(name, subtypes, grp, size, nil)
+
+        } else {
+            corrupted("unknown type entry kind %d", kind)
+        }
+
+        rdr.Types = append(rdr.Types, typ)
+    }
+}
+
+func (rdr *moduleReader) nativeType(pck *symbols.PackageSymbol, name string) *symbols.TypeSymbol {
+    for _, v := range pck.Containers {
+        if v.ContainerName == name {
+            return v.ContainerType
+        }
+    }
+
+    for _, v := range pck.Traits {
+        if v.TraitName == name {
+            return v.TraitType
+        }
+    }
+
+    corrupted("could not find native type '%s' in package '%s'", name, pck.Name())
+    return nil
+}
+
+func (rdr *moduleReader) readTraits() {
+    for n := rdr.count(); n > 0; n-- {
+        pck := rdr.packageRef()
+        name := rdr.string()
+
+        // natives are looked up by name
+        if rdr.bool() {
+            rdr.Traits = append(rdr.Traits, rdr.nativeType(pck, name).Trait)
+            continue
+        }
+
+        trt := symbols.NewTraitSymbol(pck, name, rdr.typeRef())
+        pck.TryRegisterTrait(trt)
+
+        for i := rdr.count(); i > 0; i-- {
+            fld := rdr.string()
+            trt.Fields = append(trt.Fields, symbols.NewTraitFieldSymbol(trt, fld, rdr.typeRef()))
+        }
+
+        for i := rdr.count(); i > 0; i-- {
+            rdr.TraitMethods[trt] = append(rdr.TraitMethods[trt], rdr.int())
+        }
+
+        rdr.Traits = append(rdr.Traits, trt)
+    }
+}
+
+func (rdr *moduleReader) readContainers() {
+    for n := rdr.count(); n > 0; n-- {
+        pck := rdr.packageRef()
+        name := rdr.string()
+
+        // natives are looked up by name
+        if rdr.bool() {
+            rdr.Containers = append(rdr.Containers, rdr.nativeType(pck, name).Container)
+            continue
+        }
+
+        cnt := symbols.NewContainerSymbol(pck, name, rdr.typeRef())
+        pck.TryRegisterContainer(cnt)
+
+        for i := rdr.count(); i > 0; i-- {
+            cnt.Traits = append(cnt.Traits, rdr.Traits[rdr.int()])
+            cnt.TraitTypes = append(cnt.TraitTypes, rdr.typeRef())
+        }
+
+        for i := rdr.count(); i > 0; i-- {
+            fld := rdr.string()
+            cnt.Fields = append(cnt.Fields, symbols.NewFieldSymbol(cnt, fld, rdr.typeRef()))
+        }
+
+        for i := rdr.count(); i > 0; i-- {
+            rdr.ContainerMethods[cnt] = append(rdr.ContainerMethods[cnt], rdr.int())
+        }
+
+        rdr.Constructors[cnt] = rdr.int()
+        rdr.Containers = append(rdr.Containers, cnt)
+    }
+
+    // now that every container and trait exists -> tell their types about them
+    // (this also covers instances of generic ones, like Box[int])
+    for typ, pck := range rdr.Unlinked {
+        if typ.TypeGroup == symbols.CONT {
+            for _, v := range pck.Containers {
+                if v.ContainerName == typ.TypeName {
+                    typ.Container = v
+                }
+            }
+        } else {
+            for _, v := range pck.Traits {
+                if v.TraitName == typ.TypeName {
+                    typ.Trait = v
+                }
+            }
+        }
+
+        if typ.Container == nil && typ.Trait == nil {
+            corrupted("could not find type '%s' in package '%s'", typ.TypeName, pck.Name())
+        }
+    }
+}
+
+func (rdr *moduleReader) readFunctions() {
+    for n := rdr.count(); n > 0; n-- {
+        // Symbol
+        // ------
+        pck := rdr.packageRef()
+        name := rdr.string()
+        kind := symbols.FunctionType(rdr.string())
+        meth := symbols.MethodType(rdr.string())
+        src := rdr.typeRef()
+        ret := rdr.typeRef()
+
+        prms := []*symbols.ParameterSymbol{}
+        for i := rdr.count(); i > 0; i-- {
+            prm := rdr.string()
+            prms = append(prms, symbols.NewParameterSymbol(prm, len(prms), rdr.typeRef()))
+        }
+
+        var sym *symbols.FunctionSymbol
+        if kind == symbols.FT_METH {
+            sym = symbols.NewMethodSymbol(pck, src, name, ret, prms)
+            sym.MethodKind = meth
+        } else {
+            sym = symbols.NewFunctionSymbol(pck, name, ret, prms)
+        }
+
+        pck.TryRegisterFunction(sym)
+
+        sym.NeedsVirtualCallToTrait = rdr.bool()
+        rdr.TraitSources[sym] = rdr.int()
+        sym.NeedsVirtualCallToContainer = rdr.bool()
+
+        fnc := &Function{Symbol: sym}
+        rdr.Program.Functions = append(rdr.Program.Functions, fnc)
+        rdr.Program.FunctionIds[sym] = len(rdr.Program.Functions) - 1
+
+        // Body
+        // ----
+        if !rdr.bool() {
+            continue
+        }
+
+        fnc.LocalCount = rdr.int()
+        fnc.Code = []Instruction{}
+        fnc.Spans = []span.Span{}
+        fnc.Regions = []Region{}
+
+        for i := rdr.count(); i > 0; i-- {
+            ins := Instruction{
+                Op: Opcode(rdr.byte()),
+                A: int32(rdr.int()),
+                B: int32(rdr.int()),
+            }
+
+            if ins.Op >= OP_Count {
+                corrupted("unknown instruction %d in '%s'", ins.Op, name)
+            }
+
+            pos := span.Internal()
+            if !rdr.bool() {
+                pos = span.Span{
+                    File: rdr.int() + rdr.SourceOffset,
+                    FromIdx: rdr.int(),
+                    ToIdx: rdr.int(),
+                }
+            }
+
+            fnc.Code = append(fnc.Code, ins)
+            fnc.Spans = append(fnc.Spans, pos)
+        }
+
+        for i := rdr.count(); i > 0; i-- {
+            fnc.Regions = append(fnc.Regions, Region{
+                Start: rdr.int(),
+                End: rdr.int(),
+                Catch: rdr.int(),
+                ErrorSlot: rdr.int(),
+            })
+        }
+    }
+}
+
+func (rdr *moduleReader) readNatives() {
+    for n := rdr.count(); n > 0; n-- {
+        pname := rdr.string()
+        kind := symbols.FunctionType(rdr.string())
+        src := rdr.string()
+        name := rdr.string()
+
+        pck := rdr.Comp.GetPackage(pname)
+        if pck == nil {
+            corrupted("could not find native package '%s'", pname)
+        }
+
+        var ntv *symbols.FunctionSymbol
+        for _, v := range pck.Functions {
+            if !v.IsVMFunction || v.FuncName != name || v.FunctionKind != kind {
+                continue
+            }
+
+            // methods also need to be called on the right thing
+            if kind == symbols.FT_METH && v.MethodSource.Name() != src {
+                continue
+            }
+
+            ntv = v
+            break
+        }
+
+        if ntv == nil {
+            corrupted("could not find native function '%s' in package '%s'", name, pname)
+        }
+
+        rdr.Program.Natives = append(rdr.Program.Natives, ntv)
+        rdr.Program.NativeIds[ntv] = len(rdr.Program.Natives) - 1
+    }
+}
+
+func (rdr *moduleReader) readGlobals() {
+    for n := rdr.count(); n > 0; n-- {
+        pck := rdr.packageRef()
+        name := rdr.string()
+
+        glb := symbols.NewGlobalSymbol(pck, name, rdr.typeRef())
+        pck.TryRegisterGlobal(glb)
+
+        rdr.Program.Globals = append(rdr.Program.Globals, glb)
+        rdr.Program.GlobalIds[glb] = len(rdr.Program.Globals) - 1
+    }
+}
+
+func (rdr *moduleReader) readConstants() {
+    for n := rdr.count(); n > 0; n-- {
+        var val interface{}
+
+        switch tag := rdr.byte(); tag {
+        case CT_Long:
+            val = int64(rdr.int())
+        case CT_Int:
+            val = int32(rdr.int())
+        case CT_Word:
+            val = int16(rdr.int())
+        case CT_Byte:
+            val = int8(rdr.int())
+        case CT_Double:
+            val = math.Float64frombits(binary.LittleEndian.Uint64(rdr.raw(8)))
+        case CT_Float:
+            val = math.Float32frombits(binary.LittleEndian.Uint32(rdr.raw(4)))
+        case CT_Bool:
+            val = rdr.bool()
+        case CT_String:
+            val = rdr.string()
+        default:
+            corrupted("unknown constant tag %d", tag)
+        }
+
+        rdr.Program.Constants = append(rdr.Program.Constants, val)
+    }
+}
+
+func (rdr *moduleReader) readTables() {
+    for n := rdr.count(); n > 0; n-- {
+        rdr.Program.Types = append(rdr.Program.Types, rdr.typeRef())
+    }
+
+    for n := rdr.count(); n > 0; n-- {
+        rdr.Program.Containers = append(rdr.Program.Containers, rdr.Containers[rdr.int()])
+    }
+}
+
+// Fill in everything that points at functions
+// -------------------------------------------
+func (rdr *moduleReader) link() {
+    fncs := rdr.Program.Functions
+
+    for trt, ids := range rdr.TraitMethods {
+        for _, id := range ids {
+            trt.Methods = append(trt.Methods, fncs[id].Symbol)
+        }
+    }
+
+    for cnt, ids := range rdr.ContainerMethods {
+        for _, id := range ids {
+            cnt.Methods = append(cnt.Methods, fncs[id].Symbol)
+        }
+    }
+
+    for cnt, id := range rdr.Constructors {
+        if id != -1 {
+            cnt.Constructor = fncs[id].Symbol
+        }
+    }
+
+    for sym, id := range rdr.TraitSources {
+        if id != -1 {
+            sym.TraitSourceMethod = fncs[id].Symbol
+        }
+    }
+}
+
+// --------------------------------------------------------
+// Checking
+// --------------------------------------------------------
+// The vm trusts its bytecode blindly, so anything pointing
+// somewhere it shouldnt has to be caught right here
+func (rdr *moduleReader) check() {
+    // (source lengths in runes, thats what spans count in)
+    lengths := map[int]int{}
+    for i := rdr.SourceOffset; i < len(rdr.Comp.SourceFiles); i++ {
+        lengths[i] = len([]rune(rdr.Comp.SourceFiles[i].Content))
+    }
+
+    for _, fnc := range rdr.Program.Functions {
+        if fnc.Code == nil {
+            continue
+        }
+
+        name := fnc.Symbol.FuncName
+
+        if fnc.LocalCount < len(fnc.Symbol.Parameters) {
+            corrupted("'%s' has %d locals but %d parameters", name, fnc.LocalCount, len(fnc.Symbol.Parameters))
+        }
+
+        for i, ins := range fnc.Code {
+            rdr.checkInstruction(fnc, ins, i)
+
+            pos := fnc.Spans[i]
+            if pos.Internal {
+                continue
+            }
+
+            length, ok := lengths[pos.File]
+            if !ok || pos.FromIdx < 0 || pos.FromIdx > pos.ToIdx || pos.ToIdx > length {
+                corrupted("bad source position for instruction %d in '%s'", i, name)
+            }
+        }
+
+        for _, rgn := range fnc.Regions {
+            if rgn.Start < 0 || rgn.Start > rgn.End || rgn.End > len(fnc.Code) ||
+               rgn.Catch < 0 || rgn.Catch >= len(fnc.Code) ||
+               rgn.ErrorSlot < 0 || rgn.ErrorSlot >= fnc.LocalCount {
+                corrupted("bad try / catch region in '%s'", name)
+            }
+        }
+    }
+}
+
+func (rdr *moduleReader) checkInstruction(fnc *Function, ins Instruction, at int) {
+    prg := rdr.Program
+    a, b := int(ins.A), int(ins.B)
+
+    // is this a valid index into something of this length?
+    fits := func(idx int, length int, what string) {
+        if idx < 0 || idx >= length {
+            corrupted("instruction %d in '%s' points at %s %d (there are only %d)", at, fnc.Symbol.FuncName, what, idx, length)
+        }
+    }
+
+    // (counts only need to be positive)
+    count := func(n int, what string) {
+        if n < 0 {
+            corrupted("instruction %d in '%s' has a negative %s", at, fnc.Symbol.FuncName, what)
+        }
+    }
+
+    // types can be missing in the table, these cant
+    typ := func(idx int) {
+        fits(idx, len(prg.Types), "type")
+        if prg.Types[idx] == nil {
+            corrupted("instruction %d in '%s' points at an empty type", at, fnc.Symbol.FuncName)
+        }
+    }
+
+    switch ins.Op {
+    case OP_Const:
+        fits(a, len(prg.Constants), "constant")
+
+    case OP_LoadField, OP_StoreField, OP_InitField:
+        fits(a, len(prg.Constants), "constant")
+        if _, ok := prg.Constants[a].(string); !ok {
+            corrupted("instruction %d in '%s' uses a field name that isnt a string", at, fnc.Symbol.FuncName)
+        }
+
+    case OP_Default, OP_MakeArray, OP_Convert, OP_EnumName, OP_Is, OP_As:
+        typ(a)
+
+    case OP_MakeArrayFrom, OP_MakeMap:
+        typ(a)
+        count(b, "element count")
+
+    case OP_Make:
+        fits(a, len(prg.Containers), "container")
+        typ(b)
+
+    case OP_LoadLocal, OP_StoreLocal, OP_ApproachLocal, OP_MakeCell, OP_LoadCell, OP_StoreCell, OP_ApproachCell:
+        fits(a, fnc.LocalCount, "local")
+
+    case OP_LoadGlobal, OP_StoreGlobal:
+        fits(a, len(prg.Globals), "global")
+
+    case OP_Jump, OP_JumpIf:
+        fits(a, len(fnc.Code), "instruction")
+
+    case OP_Call, OP_CallMethod, OP_MakeFunction:
+        fits(a, len(prg.Functions), "function")
+        count(b, "argument count")
+
+    case OP_CallNative, OP_CallNativeMethod:
+        fits(a, len(prg.Natives), "native")
+        count(b, "argument count")
+
+    case OP_MakeNativeFunction:
+        fits(a, len(prg.Natives), "native")
+
+    case OP_CallValue:
+        count(b, "argument count")
+    }
+}
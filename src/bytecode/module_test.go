@@ -0,0 +1,130 @@
+package bytecode_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bytespace.network/rerect/bytecode"
+	"bytespace.network/rerect/compctl"
+	"bytespace.network/rerect/compunit"
+	packageprocessor "bytespace.network/rerect/package_processor"
+	"bytespace.network/rerect/vm"
+)
+
+const moduleSource = `package main;
+
+function main() {
+    var x <- 1;
+    from i <- 0 to 3 {
+        x <- x + i;
+    }
+}
+`
+
+// compile the test program, break it with damage and write it out as a module
+func buildModule(t *testing.T, damage func(prg *bytecode.Program, main *bytecode.Function)) string {
+    dir := t.TempDir()
+    src := filepath.Join(dir, "main.rr")
+    if err := os.WriteFile(src, []byte(moduleSource), 0644); err != nil {
+        t.Fatal(err)
+    }
+
+    res := compctl.Compile(compunit.NewCompilation(), []string{src})
+    if !res.Ok {
+        t.Fatal("test program did not compile")
+    }
+
+    var main *bytecode.Function
+    for _, fnc := range res.Program.Functions {
+        if fnc.Symbol.FuncName == "main" && fnc.Code != nil {
+            main = fnc
+        }
+    }
+
+    if damage != nil {
+        damage(res.Program, main)
+    }
+
+    out := filepath.Join(dir, "main.rrx")
+    bytecode.Save(res.Comp, res.Program, out)
+
+    return out
+}
+
+func loadModule(path string) (*compunit.Compilation, *bytecode.Program) {
+    comp := compunit.NewCompilation()
+    packageprocessor.Init(comp)
+
+    return comp, bytecode.Load(comp, path)
+}
+
+func TestLoadModule(t *testing.T) {
+    comp, prg := loadModule(buildModule(t, nil))
+    if prg == nil {
+        t.Fatalf("valid module did not load: %v", comp.Errors)
+    }
+
+    if res := vm.Evaluate(comp, prg); res.Error != nil {
+        t.Fatalf("valid module did not run: %s", res.Error.Error.Message)
+    }
+}
+
+func TestLoadCorruptedModule(t *testing.T) {
+    cases := map[string]func(prg *bytecode.Program, main *bytecode.Function){
+        "source file": func(prg *bytecode.Program, main *bytecode.Function) {
+            main.Spans[0].Internal = false
+            main.Spans[0].File = 990
+        },
+        "source position": func(prg *bytecode.Program, main *bytecode.Function) {
+            main.Spans[0].Internal = false
+            main.Spans[0].ToIdx = 1 << 20
+        },
+        "jump target": func(prg *bytecode.Program, main *bytecode.Function) {
+            main.Code[0] = bytecode.Instruction{Op: bytecode.OP_Jump, A: int32(len(main.Code))}
+        },
+        "constant": func(prg *bytecode.Program, main *bytecode.Function) {
+            main.Code[0] = bytecode.Instruction{Op: bytecode.OP_Const, A: 990}
+        },
+        "field name": func(prg *bytecode.Program, main *bytecode.Function) {
+            prg.Constants = append(prg.Constants, int32(1))
+            main.Code[0] = bytecode.Instruction{Op: bytecode.OP_LoadField, A: int32(len(prg.Constants) - 1)}
+        },
+        "function": func(prg *bytecode.Program, main *bytecode.Function) {
+            main.Code[0] = bytecode.Instruction{Op: bytecode.OP_Call, A: 990}
+        },
+        "native": func(prg *bytecode.Program, main *bytecode.Function) {
+            main.Code[0] = bytecode.Instruction{Op: bytecode.OP_CallNative, A: -1}
+        },
+        "local": func(prg *bytecode.Program, main *bytecode.Function) {
+            main.Code[0] = bytecode.Instruction{Op: bytecode.OP_StoreLocal, A: int32(main.LocalCount)}
+        },
+        "global": func(prg *bytecode.Program, main *bytecode.Function) {
+            main.Code[0] = bytecode.Instruction{Op: bytecode.OP_LoadGlobal, A: 990}
+        },
+        "type": func(prg *bytecode.Program, main *bytecode.Function) {
+            main.Code[0] = bytecode.Instruction{Op: bytecode.OP_Default, A: 990}
+        },
+        "argument count": func(prg *bytecode.Program, main *bytecode.Function) {
+            main.Code[0] = bytecode.Instruction{Op: bytecode.OP_CallValue, B: -5}
+        },
+        "region": func(prg *bytecode.Program, main *bytecode.Function) {
+            main.Regions = append(main.Regions, bytecode.Region{Start: 0, End: 1, Catch: 990, ErrorSlot: 0})
+        },
+    }
+
+    for name, damage := range cases {
+        t.Run(name, func(t *testing.T) {
+            comp, prg := loadModule(buildModule(t, damage))
+
+            if prg != nil {
+                t.Fatal("corrupted module was loaded anyways")
+            }
+
+            if len(comp.Errors) != 1 || !strings.HasPrefix(comp.Errors[0].Message, "Unable to load module") {
+                t.Fatalf("expected a load error, got %v", comp.Errors)
+            }
+        })
+    }
+}
@@ -0,0 +1,537 @@
+// Bytecode - module_writer.go
+// --------------------------------------------------------
+// Turns a program (and every symbol it touches) into bytes
+// --------------------------------------------------------
+package bytecode
+
+import (
+	"encoding/binary"
+	"math"
+
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/symbols"
+)
+
+type moduleWriter struct {
+    Comp *compunit.Compilation
+    Program *Program
+    Buffer []byte
+
+    // symbol tables (everything gets referenced by index)
+    Packages []*symbols.PackageSymbol
+    PackageIds map[*symbols.PackageSymbol]int
+    Types []*symbols.TypeSymbol
+    TypeIds map[*symbols.TypeSymbol]int
+    Traits []*symbols.TraitSymbol
+    TraitIds map[*symbols.TraitSymbol]int
+    Containers []*symbols.ContainerSymbol
+    ContainerIds map[*symbols.ContainerSymbol]int
+}
+
+func encodeModule(comp *compunit.Compilation, prg *Program) []byte {
+    wrt := moduleWriter{
+        Comp: comp,
+        Program: prg,
+        Buffer: []byte(ModuleMagic),
+        PackageIds: make(map[*symbols.PackageSymbol]int),
+        TypeIds: make(map[*symbols.TypeSymbol]int),
+        TraitIds: make(map[*symbols.TraitSymbol]int),
+        ContainerIds: make(map[*symbols.ContainerSymbol]int),
+    }
+
+    wrt.Buffer = binary.LittleEndian.AppendUint16(wrt.Buffer, ModuleVersion)
+
+    // find out about every symbol first
+    wrt.collect()
+
+    // and then write everything down
+    wrt.writeSources()
+    wrt.writePackages()
+    wrt.writeTypes()
+    wrt.writeTraits()
+    wrt.writeContainers()
+    wrt.writeFunctions()
+    wrt.writeNatives()
+    wrt.writeGlobals()
+    wrt.writeConstants()
+    wrt.writeTables()
+
+    return wrt.Buffer
+}
+
+// --------------------------------------------------------
+// Primitives
+// --------------------------------------------------------
+func (wrt *moduleWriter) int(val int) {
+    wrt.Buffer = binary.AppendVarint(wrt.Buffer, int64(val))
+}
+
+func (wrt *moduleWriter) byte(val byte) {
+    wrt.Buffer = append(wrt.Buffer, val)
+}
+
+func (wrt *moduleWriter) bool(val bool) {
+    if val {
+        wrt.byte(1)
+    } else {
+        wrt.byte(0)
+    }
+}
+
+func (wrt *moduleWriter) string(val string) {
+    wrt.int(len(val))
+    wrt.Buffer = append(wrt.Buffer, val...)
+}
+
+// Symbol references (-1 for nothing)
+func (wrt *moduleWriter) typeRef(typ *symbols.TypeSymbol) {
+    if typ == nil {
+        wrt.int(-1)
+        return
+    }
+
+    wrt.int(wrt.TypeIds[typ])
+}
+
+func (wrt *moduleWriter) functionRef(fnc *symbols.FunctionSymbol) {
+    if fnc == nil {
+        wrt.int(-1)
+        return
+    }
+
+    wrt.int(wrt.Program.functionId(fnc))
+}
+
+// --------------------------------------------------------
+// Collecting
+// --------------------------------------------------------
+func (wrt *moduleWriter) collect() {
+    for _, glb := range wrt.Program.Globals {
+        wrt.collectPackage(glb.ParentPackage)
+        wrt.collectType(glb.GlobalType)
+    }
+
+    for _, typ := range wrt.Program.Types {
+        wrt.collectType(typ)
+    }
+
+    for _, cnt := range wrt.Program.Containers {
+        wrt.collectContainer(cnt)
+    }
+
+    for _, ntv := range wrt.Program.Natives {
+        wrt.collectPackage(ntv.ParentPackage)
+    }
+
+    // collecting containers can add new (bodiless) functions to the list
+    // -> no range loop here
+    for i := 0; i < len(wrt.Program.Functions); i++ {
+        sym := wrt.Program.Functions[i].Symbol
+
+        wrt.collectPackage(sym.ParentPackage)
+        wrt.collectType(sym.MethodSource)
+        wrt.collectType(sym.ReturnType)
+
+        for _, prm := range sym.Parameters {
+            wrt.collectType(prm.ParameterType)
+        }
+
+        if sym.TraitSourceMethod != nil {
+            wrt.Program.functionId(sym.TraitSourceMethod)
+        }
+    }
+}
+
+func (wrt *moduleWriter) collectPackage(pck *symbols.PackageSymbol) {
+    if _, ok := wrt.PackageIds[pck]; ok {
+        return
+    }
+
+    wrt.Packages = append(wrt.Packages, pck)
+    wrt.PackageIds[pck] = len(wrt.Packages) - 1
+}
+
+func (wrt *moduleWriter) collectType(typ *symbols.TypeSymbol) {
+    if typ == nil {
+        return
+    }
+
+    if _, ok := wrt.TypeIds[typ]; ok {
+        return
+    }
+
+    // subtypes always come first (so the reader already knows them)
+    for _, sub := range typ.SubTypes {
+        wrt.collectType(sub)
+    }
+
+    wrt.Types = append(wrt.Types, typ)
+    wrt.TypeIds[typ] = len(wrt.Types) - 1
+
+    if typ.Container != nil {
+        wrt.collectContainer(typ.Container)
+    }
+
+    if typ.Trait != nil {
+        wrt.collectTrait(typ.Trait)
+    }
+
+    if typ.Enum != nil {
+        wrt.collectPackage(typ.Enum.ParentPackage)
+    }
+}
+
+func (wrt *moduleWriter) collectTrait(trt *symbols.TraitSymbol) {
+    if _, ok := wrt.TraitIds[trt]; ok {
+        return
+    }
+
+    wrt.Traits = append(wrt.Traits, trt)
+    wrt.TraitIds[trt] = len(wrt.Traits) - 1
+
+    wrt.collectPackage(trt.ParentPackage)
+    wrt.collectType(trt.TraitType)
+
+    for _, fld := range trt.Fields {
+        wrt.collectType(fld.FieldType)
+    }
+
+    for _, mth := range trt.Methods {
+        wrt.Program.functionId(mth)
+    }
+}
+
+func (wrt *moduleWriter) collectContainer(cnt *symbols.ContainerSymbol) {
+    if _, ok := wrt.ContainerIds[cnt]; ok {
+        return
+    }
+
+    wrt.Containers = append(wrt.Containers, cnt)
+    wrt.ContainerIds[cnt] = len(wrt.Containers) - 1
+
+    wrt.collectPackage(cnt.ParentPackage)
+    wrt.collectType(cnt.ContainerType)
+
+    // natives get looked up by name, we dont need to know whats inside
+    if wrt.isNative(cnt.ParentPackage) {
+        return
+    }
+
+    for _, trt := range cnt.Traits {
+        wrt.collectTrait(trt)
+    }
+
+    for _, typ := range cnt.TraitTypes {
+        wrt.collectType(typ)
+    }
+
+    for _, fld := range cnt.Fields {
+        wrt.collectType(fld.FieldType)
+    }
+
+    for _, mth := range cnt.Methods {
+        wrt.Program.functionId(mth)
+    }
+
+    if cnt.Constructor != nil {
+        wrt.Program.functionId(cnt.Constructor)
+    }
+}
+
+// Does a package come from go land?
+// (those get resolved by name when loading)
+func (wrt *moduleWriter) isNative(pck *symbols.PackageSymbol) bool {
+    for _, v := range pck.Functions {
+        if v.IsVMFunction {
+            return true
+        }
+    }
+
+    return false
+}
+
+// --------------------------------------------------------
+// Sections
+// --------------------------------------------------------
+func (wrt *moduleWriter) writeSources() {
+    wrt.int(len(wrt.Comp.SourceFiles))
+
+    for _, src := range wrt.Comp.SourceFiles {
+        wrt.string(src.Path)
+        wrt.string(src.Content)
+    }
+}
+
+func (wrt *moduleWriter) writePackages() {
+    wrt.int(len(wrt.Packages))
+
+    for _, pck := range wrt.Packages {
+        wrt.string(pck.Name())
+    }
+}
+
+func (wrt *moduleWriter) writeTypes() {
+    wrt.int(len(wrt.Types))
+
+    for _, typ := range wrt.Types {
+        // builtins are shared by everyone
+        if compunit.GlobalDataType(typ.Name()) == typ {
+            wrt.byte(TE_Builtin)
+            wrt.string(typ.Name())
+            continue
+        }
+
+        // containers and traits are named after their symbol
+        // (plus whatever type arguments they got)
+        if typ.Container != nil {
+            wrt.byte(TE_Container)
+            wrt.int(wrt.PackageIds[typ.Container.ParentPackage])
+            wrt.string(typ.TypeName)
+            wrt.typeArgs(typ)
+            wrt.bool(typ.Nullable)
+            continue
+        }
+
+        if typ.Trait != nil {
+            wrt.byte(TE_Trait)
+            wrt.int(wrt.PackageIds[typ.Trait.ParentPackage])
+            wrt.string(typ.TypeName)
+            wrt.typeArgs(typ)
+            wrt.bool(typ.Nullable)
+            continue
+        }
+
+        // enums bring all of their members along
+        if typ.Enum != nil {
+            wrt.byte(TE_Enum)
+            wrt.int(wrt.PackageIds[typ.Enum.ParentPackage])
+            wrt.string(typ.TypeName)
+
+            wrt.int(len(typ.Enum.Members))
+            for _, mem := range typ.Enum.Members {
+                wrt.string(mem.MemberName)
+                wrt.int(int(mem.Value))
+            }
+
+            continue
+        }
+
+        // anything else is described by its structure
+        wrt.byte(TE_Plain)
+        wrt.string(typ.Name())
+        wrt.string(string(typ.TypeGroup))
+        wrt.int(typ.TypeSize)
+
+        wrt.int(len(typ.SubTypes))
+        for _, sub := range typ.SubTypes {
+            wrt.typeRef(sub)
+        }
+    }
+}
+
+func (wrt *moduleWriter) typeArgs(typ *symbols.TypeSymbol) {
+    wrt.int(len(typ.SubTypes))
+    for _, sub := range typ.SubTypes {
+        wrt.typeRef(sub)
+    }
+}
+
+func (wrt *moduleWriter) writeTraits() {
+    wrt.int(len(wrt.Traits))
+
+    for _, trt := range wrt.Traits {
+        wrt.int(wrt.PackageIds[trt.ParentPackage])
+        wrt.string(trt.TraitName)
+
+        native := wrt.isNative(trt.ParentPackage)
+        wrt.bool(native)
+
+        if native {
+            continue
+        }
+
+        wrt.typeRef(trt.TraitType)
+
+        wrt.int(len(trt.Fields))
+        for _, fld := range trt.Fields {
+            wrt.string(fld.FieldName)
+            wrt.typeRef(fld.FieldType)
+        }
+
+        wrt.int(len(trt.Methods))
+        for _, mth := range trt.Methods {
+            wrt.functionRef(mth)
+        }
+    }
+}
+
+func (wrt *moduleWriter) writeContainers() {
+    wrt.int(len(wrt.Containers))
+
+    for _, cnt := range wrt.Containers {
+        wrt.int(wrt.PackageIds[cnt.ParentPackage])
+        wrt.string(cnt.ContainerName)
+
+        native := wrt.isNative(cnt.ParentPackage)
+        wrt.bool(native)
+
+        if native {
+            continue
+        }
+
+        wrt.typeRef(cnt.ContainerType)
+
+        wrt.int(len(cnt.Traits))
+        for i, trt := range cnt.Traits {
+            wrt.int(wrt.TraitIds[trt])
+            wrt.typeRef(cnt.TraitTypes[i])
+        }
+
+        wrt.int(len(cnt.Fields))
+        for _, fld := range cnt.Fields {
+            wrt.string(fld.FieldName)
+            wrt.typeRef(fld.FieldType)
+        }
+
+        wrt.int(len(cnt.Methods))
+        for _, mth := range cnt.Methods {
+            wrt.functionRef(mth)
+        }
+
+        wrt.functionRef(cnt.Constructor)
+    }
+}
+
+func (wrt *moduleWriter) writeFunctions() {
+    wrt.int(len(wrt.Program.Functions))
+
+    for _, fnc := range wrt.Program.Functions {
+        sym := fnc.Symbol
+
+        // Symbol
+        // ------
+        wrt.int(wrt.PackageIds[sym.ParentPackage])
+        wrt.string(sym.FuncName)
+        wrt.string(string(sym.FunctionKind))
+        wrt.string(string(sym.MethodKind))
+        wrt.typeRef(sym.MethodSource)
+        wrt.typeRef(sym.ReturnType)
+
+        wrt.int(len(sym.Parameters))
+        for _, prm := range sym.Parameters {
+            wrt.string(prm.ParameterName)
+            wrt.typeRef(prm.ParameterType)
+        }
+
+        wrt.bool(sym.NeedsVirtualCallToTrait)
+        wrt.functionRef(sym.TraitSourceMethod)
+        wrt.bool(sym.NeedsVirtualCallToContainer)
+
+        // Body
+        // ----
+        wrt.bool(fnc.Code != nil)
+        if fnc.Code == nil {
+            continue
+        }
+
+        wrt.int(fnc.LocalCount)
+
+        wrt.int(len(fnc.Code))
+        for i, ins := range fnc.Code {
+            wrt.byte(byte(ins.Op))
+            wrt.int(int(ins.A))
+            wrt.int(int(ins.B))
+
+            pos := fnc.Spans[i]
+            wrt.bool(pos.Internal)
+            if !pos.Internal {
+                wrt.int(pos.File)
+                wrt.int(pos.FromIdx)
+                wrt.int(pos.ToIdx)
+            }
+        }
+
+        wrt.int(len(fnc.Regions))
+        for _, rgn := range fnc.Regions {
+            wrt.int(rgn.Start)
+            wrt.int(rgn.End)
+            wrt.int(rgn.Catch)
+            wrt.int(rgn.ErrorSlot)
+        }
+    }
+}
+
+func (wrt *moduleWriter) writeNatives() {
+    wrt.int(len(wrt.Program.Natives))
+
+    for _, ntv := range wrt.Program.Natives {
+        wrt.string(ntv.ParentPackage.Name())
+        wrt.string(string(ntv.FunctionKind))
+
+        // methods are only unique together with what theyre called on
+        src := ""
+        if ntv.MethodSource != nil {
+            src = ntv.MethodSource.Name()
+        }
+
+        wrt.string(src)
+        wrt.string(ntv.FuncName)
+    }
+}
+
+func (wrt *moduleWriter) writeGlobals() {
+    wrt.int(len(wrt.Program.Globals))
+
+    for _, glb := range wrt.Program.Globals {
+        wrt.int(wrt.PackageIds[glb.ParentPackage])
+        wrt.string(glb.GlobalName)
+        wrt.typeRef(glb.GlobalType)
+    }
+}
+
+func (wrt *moduleWriter) writeConstants() {
+    wrt.int(len(wrt.Program.Constants))
+
+    for _, cst := range wrt.Program.Constants {
+        switch v := cst.(type) {
+        case int64:
+            wrt.byte(CT_Long)
+            wrt.int(int(v))
+        case int32:
+            wrt.byte(CT_Int)
+            wrt.int(int(v))
+        case int16:
+            wrt.byte(CT_Word)
+            wrt.int(int(v))
+        case int8:
+            wrt.byte(CT_Byte)
+            wrt.int(int(v))
+        case float64:
+            wrt.byte(CT_Double)
+            wrt.Buffer = binary.LittleEndian.AppendUint64(wrt.Buffer, math.Float64bits(v))
+        case float32:
+            wrt.byte(CT_Float)
+            wrt.Buffer = binary.LittleEndian.AppendUint32(wrt.Buffer, math.Float32bits(v))
+        case bool:
+            wrt.byte(CT_Bool)
+            wrt.bool(v)
+        case string:
+            wrt.byte(CT_String)
+            wrt.string(v)
+        default:
+            panic("Constant type not implemented! You should implement NOW!")
+        }
+    }
+}
+
+func (wrt *moduleWriter) writeTables() {
+    wrt.int(len(wrt.Program.Types))
+    for _, typ := range wrt.Program.Types {
+        wrt.typeRef(typ)
+    }
+
+    wrt.int(len(wrt.Program.Containers))
+    for _, cnt := range wrt.Program.Containers {
+        wrt.int(wrt.ContainerIds[cnt])
+    }
+}
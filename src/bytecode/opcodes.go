@@ -0,0 +1,270 @@
+// Bytecode - opcodes.go
+// --------------------------------------------------------
+// All the instructions our little stack machine knows
+// --------------------------------------------------------
+package bytecode
+
+import "fmt"
+
+// Instruction struct
+// ------------------
+// A and B are the operands, what they mean depends on the opcode
+type Instruction struct {
+    Op Opcode
+    A int32
+    B int32
+}
+
+// Opcodes
+// -------
+type Opcode byte
+const (
+    // Stack
+    // -----
+    OP_Nop             Opcode = iota
+    OP_Pop                     // drop the top value
+    OP_Dup                     // duplicate the top value
+    OP_Const                   // push Constants[A]
+    OP_Null                    // push null
+    OP_Default                 // push the default value of Types[A]
+
+    // Variables
+    // ---------
+    OP_LoadLocal               // push Locals[A]
+    OP_StoreLocal              // pop into Locals[A]
+    OP_LoadGlobal              // push Globals[A]
+    OP_StoreGlobal             // pop into Globals[A]
+    OP_LoadThis                // push the current instance
+    OP_LoadField               // [inst] -> [inst.Constants[A]]
+    OP_StoreField              // [val, inst] -> [val] (and inst.Constants[A] <- val)
+    OP_InitField               // [inst, val] -> [inst] (and inst.Constants[A] <- val)
+    OP_LoadIndex               // [arr, idx] -> [arr[idx]]
+    OP_StoreIndex              // [val, arr, idx] -> [val] (and arr[idx] <- val)
+    OP_LoadKey                 // [map, key] -> [map[key]]
+    OP_StoreKey                // [val, map, key] -> [val] (and map[key] <- val)
+
+    // Control flow
+    // ------------
+    OP_Jump                    // continue at instruction A
+    OP_JumpIf                  // pop a bool, if its true -> continue at instruction A
+    OP_Return                  // pop the return value and leave the function
+    OP_Throw                   // pop an Error and throw it
+    OP_Call                    // call Functions[A] with B arguments
+    OP_CallNative              // call Natives[A] with B arguments
+    OP_CallMethod              // [inst, args...] call Functions[A] with B arguments on inst
+    OP_CallNativeMethod        // [inst, args...] call Natives[A] with B arguments on inst
+
+    // Objects
+    // -------
+    OP_Make                    // push a new instance of Containers[A] (with type Types[B])
+    OP_MakeArray               // [len] -> [new array of type Types[A]]
+    OP_MakeArrayFrom           // [B elements] -> [new array of type Types[A]]
+    OP_MakeMap                 // [B keys and values (key, val, key, val...)] -> [new map of type Types[A]]
+    OP_Convert                 // convert the top value into Types[A]
+    OP_EnumName                // convert the top value (a member of enum Types[A]) into its name
+    OP_Is                      // [value] -> [is the value an instance of Types[A]?]
+    OP_As                      // [value] -> [the value if it is an instance of Types[A], null otherwise]
+    OP_ApproachLocal           // [target] move Locals[A] one step closer to target
+
+    // Functions
+    // ---------
+    OP_MakeCell                // pop into a new cell in Locals[A] (for captured variables)
+    OP_LoadCell                // push the value of the cell in Locals[A]
+    OP_StoreCell               // pop into the cell in Locals[A]
+    OP_ApproachCell            // [target] move the cell in Locals[A] one step closer to target
+    OP_MakeFunction            // [B cells] -> [function value for Functions[A]]
+    OP_MakeNativeFunction      // push a function value for Natives[A]
+    OP_CallValue               // [fn, args...] call the function value fn with B arguments
+
+    // Logic
+    // -----
+    OP_Equal
+    OP_Unequal
+    OP_Not
+    OP_And
+    OP_Or
+    OP_Concat
+
+    // Typed arithmetic
+    // ----------------
+    // (these always come in blocks of long, int, word, byte, double, float)
+
+    // a + b
+    OP_AddI64
+    OP_AddI32
+    OP_AddI16
+    OP_AddI8
+    OP_AddF64
+    OP_AddF32
+
+    // a - b
+    OP_SubI64
+    OP_SubI32
+    OP_SubI16
+    OP_SubI8
+    OP_SubF64
+    OP_SubF32
+
+    // a * b
+    OP_MulI64
+    OP_MulI32
+    OP_MulI16
+    OP_MulI8
+    OP_MulF64
+    OP_MulF32
+
+    // a / b
+    OP_DivI64
+    OP_DivI32
+    OP_DivI16
+    OP_DivI8
+    OP_DivF64
+    OP_DivF32
+
+    // a < b
+    OP_LessI64
+    OP_LessI32
+    OP_LessI16
+    OP_LessI8
+    OP_LessF64
+    OP_LessF32
+
+    // a <= b
+    OP_LessEqualI64
+    OP_LessEqualI32
+    OP_LessEqualI16
+    OP_LessEqualI8
+    OP_LessEqualF64
+    OP_LessEqualF32
+
+    // a > b
+    OP_GreaterI64
+    OP_GreaterI32
+    OP_GreaterI16
+    OP_GreaterI8
+    OP_GreaterF64
+    OP_GreaterF32
+
+    // a >= b
+    OP_GreaterEqualI64
+    OP_GreaterEqualI32
+    OP_GreaterEqualI16
+    OP_GreaterEqualI8
+    OP_GreaterEqualF64
+    OP_GreaterEqualF32
+
+    // a % b (integers only, same goes for all the bit stuff)
+    OP_ModI64
+    OP_ModI32
+    OP_ModI16
+    OP_ModI8
+    OP_ModF64
+    OP_ModF32
+
+    // a & b
+    OP_BitAndI64
+    OP_BitAndI32
+    OP_BitAndI16
+    OP_BitAndI8
+    OP_BitAndF64
+    OP_BitAndF32
+
+    // a | b
+    OP_BitOrI64
+    OP_BitOrI32
+    OP_BitOrI16
+    OP_BitOrI8
+    OP_BitOrF64
+    OP_BitOrF32
+
+    // a ^ b
+    OP_BitXorI64
+    OP_BitXorI32
+    OP_BitXorI16
+    OP_BitXorI8
+    OP_BitXorF64
+    OP_BitXorF32
+
+    // a << b
+    OP_ShlI64
+    OP_ShlI32
+    OP_ShlI16
+    OP_ShlI8
+    OP_ShlF64
+    OP_ShlF32
+
+    // a >> b
+    OP_ShrI64
+    OP_ShrI32
+    OP_ShrI16
+    OP_ShrI8
+    OP_ShrF64
+    OP_ShrF32
+
+    // -a
+    OP_NegI64
+    OP_NegI32
+    OP_NegI16
+    OP_NegI8
+    OP_NegF64
+    OP_NegF32
+
+    // ~a
+    OP_BitNotI64
+    OP_BitNotI32
+    OP_BitNotI16
+    OP_BitNotI8
+    OP_BitNotF64
+    OP_BitNotF32
+
+    OP_Count                   // not an instruction, just the amount of them
+)
+
+// Numeric kinds (the order of each typed block)
+// ---------------------------------------------
+const (
+    NK_Long   = 0
+    NK_Int    = 1
+    NK_Word   = 2
+    NK_Byte   = 3
+    NK_Double = 4
+    NK_Float  = 5
+)
+
+// Opcode names (for printing)
+// ---------------------------
+var opcodeNames = map[Opcode]string{
+    OP_Nop: "Nop", OP_Pop: "Pop", OP_Dup: "Dup", OP_Const: "Const", OP_Null: "Null", OP_Default: "Default",
+
+    OP_LoadLocal: "LoadLocal", OP_StoreLocal: "StoreLocal", OP_LoadGlobal: "LoadGlobal", OP_StoreGlobal: "StoreGlobal",
+    OP_LoadThis: "LoadThis", OP_LoadField: "LoadField", OP_StoreField: "StoreField", OP_InitField: "InitField",
+    OP_LoadIndex: "LoadIndex", OP_StoreIndex: "StoreIndex", OP_LoadKey: "LoadKey", OP_StoreKey: "StoreKey",
+
+    OP_Jump: "Jump", OP_JumpIf: "JumpIf", OP_Return: "Return", OP_Throw: "Throw",
+    OP_Call: "Call", OP_CallNative: "CallNative", OP_CallMethod: "CallMethod", OP_CallNativeMethod: "CallNativeMethod",
+
+    OP_Make: "Make", OP_MakeArray: "MakeArray", OP_MakeArrayFrom: "MakeArrayFrom", OP_MakeMap: "MakeMap", OP_Convert: "Convert", OP_EnumName: "EnumName", OP_Is: "Is", OP_As: "As", OP_ApproachLocal: "ApproachLocal",
+
+    OP_MakeCell: "MakeCell", OP_LoadCell: "LoadCell", OP_StoreCell: "StoreCell", OP_ApproachCell: "ApproachCell",
+    OP_MakeFunction: "MakeFunction", OP_MakeNativeFunction: "MakeNativeFunction", OP_CallValue: "CallValue",
+
+    OP_Equal: "Equal", OP_Unequal: "Unequal", OP_Not: "Not", OP_And: "And", OP_Or: "Or", OP_Concat: "Concat",
+}
+
+func (op Opcode) String() string {
+    if name, ok := opcodeNames[op]; ok {
+        return name
+    }
+
+    // typed arithmetic -> name of the block + the type
+    if op >= OP_AddI64 && op < OP_Count {
+        blocks := []string{"Add", "Sub", "Mul", "Div", "Less", "LessEqual", "Greater", "GreaterEqual",
+                            "Mod", "BitAnd", "BitOr", "BitXor", "Shl", "Shr", "Neg", "BitNot"}
+        kinds  := []string{"I64", "I32", "I16", "I8", "F64", "F32"}
+
+        idx := int(op - OP_AddI64)
+        return blocks[idx / len(kinds)] + kinds[idx % len(kinds)]
+    }
+
+    return fmt.Sprintf("Opcode(%d)", byte(op))
+}
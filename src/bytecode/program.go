@@ -0,0 +1,152 @@
+// Bytecode - program.go
+// --------------------------------------------------------
+// A compiled program: functions, their code and all the
+// tables the instructions point into
+// --------------------------------------------------------
+package bytecode
+
+import (
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+)
+
+// Program struct
+// --------------
+type Program struct {
+    Functions []*Function                            // every function we know about (index = function id)
+    FunctionIds map[*symbols.FunctionSymbol]int
+
+    Natives []*symbols.FunctionSymbol                // every native that gets called (index = native id)
+    NativeIds map[*symbols.FunctionSymbol]int
+
+    Globals []*symbols.GlobalSymbol                  // every global variable (index = global slot)
+    GlobalIds map[symbols.VariableSymbol]int
+
+    Constants []interface{}                          // literal values and field names
+    Types []*symbols.TypeSymbol                      // types needed for defaults, conversions and arrays
+    Containers []*symbols.ContainerSymbol            // containers that get made
+}
+
+// Function struct
+// ---------------
+type Function struct {
+    Symbol *symbols.FunctionSymbol
+
+    Code []Instruction
+    Spans []span.Span       // source position of every instruction (for runtime errors)
+    Regions []Region        // protected regions (try / catch)
+
+    LocalCount int          // amount of local slots (captures and parameters come first)
+}
+
+// Protected region
+// ----------------
+// Errors thrown from any instruction in [Start, End) continue
+// at Catch with the Error stored in the local slot ErrorSlot
+type Region struct {
+    Start int
+    End int
+    Catch int
+
+    ErrorSlot int
+}
+
+func NewProgram() *Program {
+    return &Program{
+        Functions: make([]*Function, 0),
+        FunctionIds: make(map[*symbols.FunctionSymbol]int),
+        Natives: make([]*symbols.FunctionSymbol, 0),
+        NativeIds: make(map[*symbols.FunctionSymbol]int),
+        Globals: make([]*symbols.GlobalSymbol, 0),
+        GlobalIds: make(map[symbols.VariableSymbol]int),
+        Constants: make([]interface{}, 0),
+        Types: make([]*symbols.TypeSymbol, 0),
+        Containers: make([]*symbols.ContainerSymbol, 0),
+    }
+}
+
+// --------------------------------------------------------
+// Lookups
+// --------------------------------------------------------
+
+// Get a function by its symbol (nil if it has never been compiled)
+// -----------------------------------------------------------------
+func (prg *Program) Function(sym *symbols.FunctionSymbol) *Function {
+    id, ok := prg.FunctionIds[sym]
+
+    if !ok {
+        return nil
+    }
+
+    return prg.Functions[id]
+}
+
+// Find the id of a function (and reserve one if its new)
+// ------------------------------------------------------
+func (prg *Program) functionId(sym *symbols.FunctionSymbol) int {
+    if id, ok := prg.FunctionIds[sym]; ok {
+        return id
+    }
+
+    // bodies get filled in once the compiler gets to them
+    prg.Functions = append(prg.Functions, &Function{Symbol: sym})
+    prg.FunctionIds[sym] = len(prg.Functions) - 1
+
+    return len(prg.Functions) - 1
+}
+
+func (prg *Program) nativeId(sym *symbols.FunctionSymbol) int {
+    if id, ok := prg.NativeIds[sym]; ok {
+        return id
+    }
+
+    prg.Natives = append(prg.Natives, sym)
+    prg.NativeIds[sym] = len(prg.Natives) - 1
+
+    return len(prg.Natives) - 1
+}
+
+func (prg *Program) globalId(sym *symbols.GlobalSymbol) int {
+    if id, ok := prg.GlobalIds[sym]; ok {
+        return id
+    }
+
+    prg.Globals = append(prg.Globals, sym)
+    prg.GlobalIds[sym] = len(prg.Globals) - 1
+
+    return len(prg.Globals) - 1
+}
+
+func (prg *Program) constantId(val interface{}) int {
+    // reuse constants we already have (only simple values can be compared)
+    for i, v := range prg.Constants {
+        if v == val {
+            return i
+        }
+    }
+
+    prg.Constants = append(prg.Constants, val)
+    return len(prg.Constants) - 1
+}
+
+func (prg *Program) typeId(typ *symbols.TypeSymbol) int {
+    for i, v := range prg.Types {
+        if v == typ {
+            return i
+        }
+    }
+
+    prg.Types = append(prg.Types, typ)
+    return len(prg.Types) - 1
+}
+
+func (prg *Program) containerId(cnt *symbols.ContainerSymbol) int {
+    for i, v := range prg.Containers {
+        if v == cnt {
+            return i
+        }
+    }
+
+    prg.Containers = append(prg.Containers, cnt)
+    return len(prg.Containers) - 1
+}
@@ -0,0 +1,78 @@
+// Codegen - build.go
+// --------------------------------------------------------
+// Turns generated go code into an actual executable
+// (by handing it to the go toolchain)
+// --------------------------------------------------------
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/error"
+	"bytespace.network/rerect/span"
+)
+
+// Build an executable from generated code
+// ---------------------------------------
+func Build(comp *compunit.Compilation, src []byte, out string) {
+    // generated programs link against the compilers own packages
+    // -> we need to know where those are
+    rerect := sourceDir()
+    if _, err := os.Stat(filepath.Join(rerect, "go.mod")); err != nil {
+        comp.Report(error.NewError(error.GEN, span.Internal(), "Unable to find the ReRect sources at '%s'! (set RERECT_SRC to the directory containing go.mod)", rerect))
+        return
+    }
+
+    out, err := filepath.Abs(out)
+    if err != nil {
+        comp.Report(error.NewError(error.FIO, span.Internal(), "Unable to resolve output path '%s'! (%s)", out, err.Error()))
+        return
+    }
+
+    // build everything in a temporary module
+    dir, err := os.MkdirTemp("", "rrc-build-")
+    if err != nil {
+        comp.Report(error.NewError(error.FIO, span.Internal(), "Unable to create build directory! (%s)", err.Error()))
+        return
+    }
+    defer os.RemoveAll(dir)
+
+    mod := fmt.Sprintf("module rerect/program\n\ngo 1.21\n\nrequire bytespace.network/rerect v0.0.0\n\nreplace bytespace.network/rerect => %s\n", rerect)
+
+    if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0644); err != nil {
+        comp.Report(error.NewError(error.FIO, span.Internal(), "Unable to write build files! (%s)", err.Error()))
+        return
+    }
+
+    if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0644); err != nil {
+        comp.Report(error.NewError(error.FIO, span.Internal(), "Unable to write build files! (%s)", err.Error()))
+        return
+    }
+
+    // let go do the heavy lifting
+    cmd := exec.Command("go", "build", "-o", out, ".")
+    cmd.Dir = dir
+    cmd.Stdout = os.Stdout
+    cmd.Stderr = os.Stderr
+
+    if err := cmd.Run(); err != nil {
+        comp.Report(error.NewError(error.GEN, span.Internal(), "Unable to build '%s'! (%s)", out, err.Error()))
+    }
+}
+
+// Where the compiler sources live
+// -------------------------------
+func sourceDir() string {
+    if dir := os.Getenv("RERECT_SRC"); dir != "" {
+        return dir
+    }
+
+    // this file is in <src>/codegen
+    _, file, _, _ := runtime.Caller(0)
+    return filepath.Dir(filepath.Dir(file))
+}
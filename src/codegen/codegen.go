@@ -0,0 +1,1122 @@
+// Codegen - codegen.go
+// --------------------------------------------------------
+// Turns lowered function bodies into go source code
+// (containers become structs, traits become interfaces and
+// labels and gotos stay labels and gotos)
+// --------------------------------------------------------
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bytespace.network/rerect/boundnodes"
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/error"
+	packageprocessor "bytespace.network/rerect/package_processor"
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+)
+
+// Import paths generated code needs all the time
+const rtPath = "bytespace.network/rerect/codegen/rt"
+const evalObjectsPath = "bytespace.network/rerect/eval_objects"
+
+type Generator struct {
+    Comp *compunit.Compilation
+    Files []*packageprocessor.CompilationFile
+
+    Out strings.Builder
+
+    Imports map[string]string                        // import path -> name
+    Types []*symbols.TypeSymbol                      // type symbols the program needs at runtime
+    Spans []span.Span                                // every position a runtime error could point to
+    SpanIds map[span.Span]int
+    Containers map[*symbols.ContainerSymbol]bool     // containers that are turned into structs
+    Trampolines []*symbols.FunctionSymbol            // functions that are used as values
+
+    // state of the function currently being generated
+    Function *symbols.FunctionSymbol
+    Locals map[symbols.VariableSymbol]string
+    LocalNames map[string]bool
+    UsedLabels map[boundnodes.BoundLabel]bool
+    Regions []*boundnodes.BoundProtectStatementNode
+    LabelRegions map[boundnodes.BoundLabel]int       // innermost protected region of every label
+}
+
+// Generate a go program (package main) from the lowered files
+// ------------------------------------------------------------
+func Generate(comp *compunit.Compilation, files []*packageprocessor.CompilationFile) []byte {
+    gen := Generator{
+        Comp: comp,
+        Files: files,
+        Imports: map[string]string{
+            rtPath: "rt",
+        },
+        Spans: []span.Span{span.Internal()},
+        SpanIds: make(map[span.Span]int),
+        Containers: make(map[*symbols.ContainerSymbol]bool),
+    }
+
+    // remember which containers are ours (everything else comes from a native package)
+    for _, file := range files {
+        for _, cnt := range file.Containers {
+            gen.Containers[cnt] = true
+        }
+    }
+
+    // native packages dont have any files, but their traits still need an interface
+    // (sorted, so the output doesnt change between runs)
+    ours := make(map[*symbols.PackageSymbol]bool)
+    for _, file := range files {
+        ours[file.Package] = true
+    }
+
+    natives := []string{}
+    for name, pck := range comp.Packages {
+        if !ours[pck] {
+            natives = append(natives, name)
+        }
+    }
+
+    sort.Strings(natives)
+
+    // generate all members first, they tell us which types, spans and imports we need
+    for _, name := range natives {
+        for _, trt := range comp.Packages[name].Traits {
+            gen.generateTrait(trt)
+        }
+    }
+
+    for _, file := range files {
+        for _, trt := range file.Traits {
+            gen.generateTrait(trt)
+        }
+
+        for _, cnt := range file.Containers {
+            gen.generateContainer(cnt)
+        }
+
+        for _, glb := range file.Globals {
+            gen.generateGlobal(glb)
+        }
+
+        for _, fnc := range file.Functions {
+            body, ok := file.FunctionBodies[fnc].(*boundnodes.BoundBlockStatementNode)
+
+            if !ok {
+                comp.Report(error.NewError(error.GEN, span.Internal(), "Function '%s' has not been lowered! (this should never happen)", fnc.FuncName))
+                continue
+            }
+
+            gen.generateFunction(fnc, body)
+        }
+    }
+
+    // (this loop can find new trampolines, so no range here)
+    for i := 0; i < len(gen.Trampolines); i++ {
+        gen.generateTrampoline(gen.Trampolines[i])
+    }
+
+    gen.generateMain()
+
+    // now put everything together
+    src := gen.generateHeader() + gen.Out.String()
+
+    // make it look like a human wrote it
+    formatted, err := format.Source([]byte(src))
+    if err != nil {
+        comp.Report(error.NewError(error.GEN, span.Internal(), "Someone fucked up the generated code :) (%s)", err.Error()))
+        return []byte(src)
+    }
+
+    return formatted
+}
+
+// --------------------------------------------------------
+// Helpers
+// --------------------------------------------------------
+func (gen *Generator) line(format string, prm ...any) {
+    gen.Out.WriteString(fmt.Sprintf(format, prm...))
+    gen.Out.WriteString("\n")
+}
+
+// Get the name of an import (and make sure it gets imported)
+// ----------------------------------------------------------
+func (gen *Generator) use(path string) string {
+    if name, ok := gen.Imports[path]; ok {
+        return name
+    }
+
+    name := strings.ReplaceAll(path[strings.LastIndex(path, "/")+1:], "_", "")
+    gen.Imports[path] = name
+
+    return name
+}
+
+// Get the id of a position (so runtime errors know where they are)
+// ----------------------------------------------------------------
+func (gen *Generator) at(node boundnodes.BoundNode) int {
+    if node == nil || node.Source() == nil || node.Source().Position().Internal {
+        return 0
+    }
+
+    pos := node.Source().Position()
+    if id, ok := gen.SpanIds[pos]; ok {
+        return id
+    }
+
+    gen.Spans = append(gen.Spans, pos)
+    gen.SpanIds[pos] = len(gen.Spans) - 1
+
+    return gen.SpanIds[pos]
+}
+
+// Get the name of the type symbol variable for a type
+// ---------------------------------------------------
+func (gen *Generator) typeRef(typ *symbols.TypeSymbol) string {
+    for i, v := range gen.Types {
+        if v.TypeGroup != typ.TypeGroup {
+            continue
+        }
+
+        // containers, traits and enums from different packages can share a name
+        if (typ.TypeGroup == symbols.CONT && v.Container == typ.Container && v.Equal(typ)) ||
+           (typ.TypeGroup == symbols.TRT  && v.Trait == typ.Trait && v.Equal(typ)) ||
+           (typ.TypeGroup == symbols.ENUM && v.Enum == typ.Enum) ||
+           (typ.TypeGroup != symbols.CONT && typ.TypeGroup != symbols.TRT && typ.TypeGroup != symbols.ENUM && v.Equal(typ)) {
+            return fmt.Sprintf("typ_%d", i)
+        }
+    }
+
+    // sub types need to exist first
+    for _, v := range typ.SubTypes {
+        gen.typeRef(v)
+    }
+
+    // (and so do the generic types instances are made from)
+    if base := genericBase(typ); base != nil {
+        gen.typeRef(base)
+    }
+
+    // (and the non-nullable version of nullable types)
+    if typ.Nullable {
+        gen.typeRef(typ.NonNullable())
+    }
+
+    gen.Types = append(gen.Types, typ)
+    id := len(gen.Types) - 1
+
+    // traits get attached later on, they can mention the container itself (Comparable[Foo])
+    if typ.TypeGroup == symbols.CONT {
+        for _, v := range typ.Container.TraitTypes {
+            gen.typeRef(v)
+        }
+    }
+
+    return fmt.Sprintf("typ_%d", id)
+}
+
+// Go types of all ReRect types
+// ----------------------------
+var primitives = map[string]string{
+    "long": "int64",
+    "int": "int32",
+    "word": "int16",
+    "byte": "int8",
+    "double": "float64",
+    "float": "float32",
+    "bool": "bool",
+    "string": "string",
+    "any": "any",
+    "void": "",
+}
+
+// If this is an instance of a generic container or trait -> what was it made from?
+func genericBase(typ *symbols.TypeSymbol) *symbols.TypeSymbol {
+    var base *symbols.TypeSymbol
+    if typ.TypeGroup == symbols.CONT {
+        base = typ.Container.ContainerType
+    } else if typ.TypeGroup == symbols.TRT {
+        base = typ.Trait.TraitType
+    }
+
+    if base == nil || len(base.SubTypes) == 0 || base.Equal(typ) {
+        return nil
+    }
+
+    return base
+}
+
+func (gen *Generator) goType(typ *symbols.TypeSymbol) string {
+    // nobody knows what a type parameter is until runtime
+    if typ.TypeGroup == symbols.TPRM {
+        return "any"
+    }
+
+    if typ.TypeGroup == symbols.ARR {
+        return "*" + gen.use(evalObjectsPath) + ".ArrayInstance"
+    }
+
+    if typ.TypeGroup == symbols.MAP {
+        return "*" + gen.use(evalObjectsPath) + ".MapInstance"
+    }
+
+    if typ.TypeGroup == symbols.FUNC {
+        return "*" + gen.use(evalObjectsPath) + ".FunctionInstance"
+    }
+
+    if typ.TypeGroup == symbols.CONT {
+        // native containers are still the good old container instances
+        if !gen.Containers[typ.Container] {
+            return "*" + gen.use(evalObjectsPath) + ".ContainerInstance"
+        }
+
+        return "*" + containerName(typ.Container)
+    }
+
+    if typ.TypeGroup == symbols.TRT {
+        return traitName(typ.Trait)
+    }
+
+    // enums are just ints with names
+    if typ.TypeGroup == symbols.ENUM {
+        return "int32"
+    }
+
+    return primitives[typ.Name()]
+}
+
+// Go type of a variable (locals some lambda holds on to live in a cell)
+func (gen *Generator) varType(vari symbols.VariableSymbol) string {
+    if symbols.IsCaptured(vari) {
+        return "*rt.Cell[" + gen.goType(vari.VarType()) + "]"
+    }
+
+    return gen.goType(vari.VarType())
+}
+
+// Put a value into a fresh cell (if the variable needs one)
+func (gen *Generator) cell(vari symbols.VariableSymbol, val string) string {
+    if symbols.IsCaptured(vari) {
+        return fmt.Sprintf("&rt.Cell[%s]{V: %s}", gen.goType(vari.VarType()), val)
+    }
+
+    return val
+}
+
+// Is this type passed around by reference? (can be null)
+func isReference(typ *symbols.TypeSymbol) bool {
+    return isCollection(typ) || typ.TypeGroup == symbols.CONT || typ.TypeGroup == symbols.TRT || typ.TypeGroup == symbols.FUNC
+}
+
+// Arrays and maps are never null, they start out empty
+func isCollection(typ *symbols.TypeSymbol) bool {
+    return typ.TypeGroup == symbols.ARR || typ.TypeGroup == symbols.MAP
+}
+
+// The value a variable of this type starts out with
+// -------------------------------------------------
+func (gen *Generator) defaultValue(typ *symbols.TypeSymbol) string {
+    // arrays and maps are never null
+    if typ.TypeGroup == symbols.ARR {
+        return fmt.Sprintf("rt.EmptyArray(%s)", gen.typeRef(typ))
+    }
+
+    if typ.TypeGroup == symbols.MAP {
+        return fmt.Sprintf("rt.EmptyMap(%s)", gen.typeRef(typ))
+    }
+
+    return gen.zeroValue(typ)
+}
+
+func (gen *Generator) zeroValue(typ *symbols.TypeSymbol) string {
+    if isReference(typ) || typ.Name() == "any" || typ.TypeGroup == symbols.TPRM {
+        return "nil"
+    }
+
+    if typ.Name() == "string" {
+        return `""`
+    }
+
+    if typ.Name() == "bool" {
+        return "false"
+    }
+
+    // enums start out as their first member
+    if typ.TypeGroup == symbols.ENUM {
+        return fmt.Sprintf("int32(%d)", typ.Default)
+    }
+
+    return gen.goType(typ) + "(0)"
+}
+
+// Names
+// -----
+func containerName(cnt *symbols.ContainerSymbol) string {
+    return fmt.Sprintf("cnt_%s_%s", cnt.ParentPackage.Name(), cnt.ContainerName)
+}
+
+func traitName(trt *symbols.TraitSymbol) string {
+    return fmt.Sprintf("trt_%s_%s", trt.ParentPackage.Name(), trt.TraitName)
+}
+
+func globalName(glb *symbols.GlobalSymbol) string {
+    return fmt.Sprintf("glb_%s_%s", glb.ParentPackage.Name(), glb.GlobalName)
+}
+
+func functionName(fnc *symbols.FunctionSymbol) string {
+    // trait methods with a body are plain functions taking the trait as their instance
+    if fnc.FunctionKind == symbols.FT_METH {
+        return fmt.Sprintf("fn_%s_%s__%s", fnc.ParentPackage.Name(), fnc.MethodSource.TypeName, fnc.FuncName)
+    }
+
+    return fmt.Sprintf("fn_%s_%s", fnc.ParentPackage.Name(), fnc.FuncName)
+}
+
+func trampolineName(fnc *symbols.FunctionSymbol) string {
+    return "tr" + functionName(fnc)[2:]
+}
+
+// Name of a function like it would be written down (pkg::name or pkg::Type->name)
+func readableName(fnc *symbols.FunctionSymbol) string {
+    if fnc.FunctionKind == symbols.FT_METH {
+        return fmt.Sprintf("%s::%s->%s", fnc.ParentPackage.Name(), fnc.MethodSource.Name(), fnc.FuncName)
+    }
+
+    return fmt.Sprintf("%s::%s", fnc.ParentPackage.Name(), fnc.FuncName)
+}
+
+// Get the go name of a native function (natives are linked, not copied)
+// ---------------------------------------------------------------------
+func (gen *Generator) nativeName(fnc *symbols.FunctionSymbol) string {
+    var ptr reflect.Value
+    if fnc.FunctionKind == symbols.FT_METH {
+        ptr = reflect.ValueOf(fnc.MethodPointer)
+    } else {
+        ptr = reflect.ValueOf(fnc.FunctionPointer)
+    }
+
+    // something like "bytespace.network/rerect/go_packages.Print"
+    full := runtime.FuncForPC(ptr.Pointer()).Name()
+
+    slash := strings.LastIndex(full, "/")
+    dot := strings.Index(full[slash+1:], ".") + slash + 1
+    path, name := full[:dot], full[dot+1:]
+
+    // closures and the like dont have a name we could use
+    if strings.Contains(name, ".") {
+        gen.Comp.Report(error.NewError(error.GEN, span.Internal(), "Unable to link native function '%s'! (%s is not a plain go function)", readableName(fnc), full))
+    }
+
+    return gen.use(path) + "." + name
+}
+
+// Parameter list of a function (every function also gets told where it is called from)
+// ------------------------------------------------------------------------------------
+func (gen *Generator) parameters(fnc *symbols.FunctionSymbol, named bool) string {
+    prms := []string{"at int"}
+
+    // lambdas get their captures handed to them first
+    for _, v := range fnc.Captures {
+        prms = append(prms, gen.local(v) + " " + gen.varType(v))
+    }
+
+    for _, v := range fnc.Parameters {
+        // (captured parameters are put into a cell once we're inside)
+        if named && symbols.IsCaptured(v) {
+            prms = append(prms, "p_" + v.Name() + " " + gen.goType(v.VarType()))
+        } else if named {
+            prms = append(prms, gen.local(v) + " " + gen.goType(v.VarType()))
+        } else {
+            prms = append(prms, "p_" + v.Name() + " " + gen.goType(v.VarType()))
+        }
+    }
+
+    return strings.Join(prms, ", ")
+}
+
+// --------------------------------------------------------
+// Members
+// --------------------------------------------------------
+func (gen *Generator) generateTrait(trt *symbols.TraitSymbol) {
+    gen.line("// %s::%s", trt.ParentPackage.Name(), trt.TraitName)
+    gen.line("type %s interface {", traitName(trt))
+    gen.line("InstanceType() *%s.TypeSymbol", gen.use("bytespace.network/rerect/symbols"))
+
+    // fields can only be reached through getters and setters
+    for _, v := range trt.Fields {
+        typ := gen.goType(v.FieldType)
+        gen.line("Get_%s() %s", v.FieldName, typ)
+        gen.line("Set_%s(val %s) %s", v.FieldName, typ, typ)
+    }
+
+    for _, v := range trt.Methods {
+        gen.line("%s(%s) %s", methodName(v, trt.TraitType), gen.parameters(v, false), gen.goType(v.ReturnType))
+    }
+
+    gen.line("}")
+    gen.line("")
+}
+
+// Does the signature of a method mention any type parameters?
+func hasGenericSignature(fnc *symbols.FunctionSymbol) bool {
+    if fnc.ReturnType.IsGeneric() {
+        return true
+    }
+
+    for _, v := range fnc.Parameters {
+        if v.VarType().IsGeneric() {
+            return true
+        }
+    }
+
+    return false
+}
+
+// Go name of a method called on something of the given type
+// (declarations of generic traits are called through an adapter, every
+// implementation has its own idea of what the go types are)
+// ----------------------------------------------------------------------
+func methodName(fnc *symbols.FunctionSymbol, typ *symbols.TypeSymbol) string {
+    if typ.TypeGroup == symbols.TRT && fnc.NeedsVirtualCallToContainer && hasGenericSignature(fnc) {
+        return "V_" + fnc.FuncName
+    }
+
+    return "M_" + fnc.FuncName
+}
+
+func (gen *Generator) generateContainer(cnt *symbols.ContainerSymbol) {
+    name := containerName(cnt)
+
+    symbolsName := gen.use("bytespace.network/rerect/symbols")
+    generic := len(cnt.TypeParameters) > 0
+
+    gen.line("// %s::%s", cnt.ParentPackage.Name(), cnt.ContainerName)
+    gen.line("type %s struct {", name)
+    for _, v := range cnt.Fields {
+        gen.line("F_%s %s", v.FieldName, gen.goType(v.FieldType))
+    }
+
+    // instances of generic containers remember their type arguments
+    if generic {
+        gen.line("typ *%s.TypeSymbol", symbolsName)
+    }
+
+    gen.line("}")
+    gen.line("")
+
+    // containers need to know what they are (for casts)
+    gen.line("func (this *%s) InstanceType() *%s.TypeSymbol {", name, symbolsName)
+    if generic {
+        gen.line("return this.typ")
+    } else {
+        gen.line("return %s", gen.typeRef(cnt.ContainerType))
+    }
+    gen.line("}")
+    gen.line("")
+
+    // create an instance with all fields set to their defaults
+    if generic {
+        gen.line("func new_%s(typ *%s.TypeSymbol) *%s {", name[4:], symbolsName, name)
+        gen.line("return &%s{", name)
+        gen.line("typ: typ,")
+    } else {
+        gen.line("func new_%s() *%s {", name[4:], name)
+        gen.line("return &%s{", name)
+    }
+
+    for _, v := range cnt.Fields {
+        // (T Array only becomes an int Array once we know what T is)
+        if isCollection(v.FieldType) && v.FieldType.IsGeneric() {
+            def := fmt.Sprintf("rt.GenericDefault(%s, %s, typ)", gen.typeRef(v.FieldType), gen.typeRef(cnt.ContainerType))
+            gen.line("F_%s: %s,", v.FieldName, gen.unbox(def, v.FieldType))
+
+        } else if isCollection(v.FieldType) {
+            gen.line("F_%s: %s,", v.FieldName, gen.defaultValue(v.FieldType))
+
+        // enums start out as their first member, not as go's 0
+        } else if v.FieldType.TypeGroup == symbols.ENUM {
+            gen.line("F_%s: %s,", v.FieldName, gen.defaultValue(v.FieldType))
+        }
+    }
+    gen.line("}")
+    gen.line("}")
+    gen.line("")
+
+    // getters and setters for everything a trait might want to access
+    // (using the types the trait knows about, generic traits dont know a lot)
+    for _, v := range cnt.Fields {
+        trtFld := traitField(cnt, v)
+        if trtFld == nil {
+            continue
+        }
+
+        typ := gen.goType(trtFld.FieldType)
+        get := gen.widen("this.F_" + v.FieldName, v.FieldType, trtFld.FieldType)
+        set := gen.narrow("val", trtFld.FieldType, v.FieldType)
+
+        gen.line("func (this *%s) Get_%s() %s { return %s }", name, v.FieldName, typ, get)
+        gen.line("func (this *%s) Set_%s(val %s) %s { this.F_%s = %s; return val }", name, v.FieldName, typ, typ, v.FieldName, set)
+        gen.line("")
+    }
+
+    // adapters for declarations of generic traits
+    adapted := make(map[string]bool)
+    for _, trt := range cnt.Traits {
+        for _, decl := range trt.Methods {
+            if !decl.NeedsVirtualCallToContainer || !hasGenericSignature(decl) || adapted[decl.FuncName] {
+                continue
+            }
+
+            var impl *symbols.FunctionSymbol
+            for _, v := range cnt.Methods {
+                if v.FuncName == decl.FuncName {
+                    impl = v
+                }
+            }
+
+            if impl == nil {
+                continue
+            }
+
+            adapted[decl.FuncName] = true
+
+            args := []string{"at"}
+            for i, prm := range decl.Parameters {
+                args = append(args, gen.narrow("p_" + prm.Name(), prm.VarType(), impl.Parameters[i].VarType()))
+            }
+
+            call := fmt.Sprintf("this.M_%s(%s)", impl.FuncName, strings.Join(args, ", "))
+
+            gen.line("func (this *%s) V_%s(%s) %s {", name, decl.FuncName, gen.parameters(decl, false), gen.goType(decl.ReturnType))
+            if decl.ReturnType.Name() == "void" {
+                gen.line("%s", call)
+            } else {
+                gen.line("return %s", gen.widen(call, impl.ReturnType, decl.ReturnType))
+            }
+            gen.line("}")
+            gen.line("")
+        }
+    }
+
+    // methods implemented by a trait just redirect to the trait
+    for _, v := range cnt.Methods {
+        if !v.NeedsVirtualCallToTrait {
+            continue
+        }
+
+        args := []string{"this", "at"}
+        for _, prm := range v.Parameters {
+            args = append(args, "p_" + prm.Name())
+        }
+
+        gen.line("func (this *%s) M_%s(%s) %s {", name, v.FuncName, gen.parameters(v, false), gen.goType(v.ReturnType))
+        if v.ReturnType.Name() == "void" {
+            gen.line("%s(%s)", functionName(v.TraitSourceMethod), strings.Join(args, ", "))
+        } else {
+            gen.line("return %s(%s)", functionName(v.TraitSourceMethod), strings.Join(args, ", "))
+        }
+        gen.line("}")
+        gen.line("")
+    }
+}
+
+// Does any trait of this container know about this field?
+// (returns what the trait thinks it is)
+func traitField(cnt *symbols.ContainerSymbol, fld *symbols.FieldSymbol) *symbols.FieldSymbol {
+    for _, trt := range cnt.Traits {
+        for _, v := range trt.Fields {
+            if v.FieldName == fld.FieldName {
+                return v
+            }
+        }
+    }
+
+    return nil
+}
+
+func (gen *Generator) generateGlobal(glb *symbols.GlobalSymbol) {
+    gen.line("// %s::%s", glb.ParentPackage.Name(), glb.GlobalName)
+
+    if isCollection(glb.GlobalType) {
+        gen.line("var %s %s = %s", globalName(glb), gen.goType(glb.GlobalType), gen.defaultValue(glb.GlobalType))
+    } else {
+        gen.line("var %s %s", globalName(glb), gen.goType(glb.GlobalType))
+    }
+
+    gen.line("")
+}
+
+// The go entry point, it just calls main::main()
+// -----------------------------------------------
+func (gen *Generator) generateMain() {
+    var main *symbols.FunctionSymbol
+    for _, file := range gen.Files {
+        for _, fnc := range file.Functions {
+            if fnc.FuncName == "main" && fnc.ParentPackage.Name() == "main" {
+                main = fnc
+            }
+        }
+    }
+
+    // no entry point found
+    if main == nil {
+        gen.Comp.Report(error.NewError(error.GEN, span.Internal(), "Could not find 'main()' function! An entry point is needed for execution."))
+        return
+    }
+
+    gen.line("func main() {")
+    gen.line("rt.Init(sources, spans)")
+    gen.line("%s.Exit(rt.Run(func() {", gen.use("os"))
+    gen.line("%s(0)", functionName(main))
+    gen.line("}))")
+    gen.line("}")
+}
+
+// Package clause, imports and all the tables
+// ------------------------------------------
+func (gen *Generator) generateHeader() string {
+    // these tables always exist
+    compunitName := gen.use("bytespace.network/rerect/compunit")
+    spanName := gen.use("bytespace.network/rerect/span")
+
+    // types (has to happen before the imports, array types need eval_objects)
+    types := strings.Builder{}
+    traits := strings.Builder{}
+
+    // (this loop can find new types, so no range here)
+    for i := 0; i < len(gen.Types); i++ {
+        typ := gen.Types[i]
+
+        // a list of type references
+        refs := func(typs []*symbols.TypeSymbol) string {
+            res := []string{}
+            for _, v := range typs {
+                res = append(res, ", " + gen.typeRef(v))
+            }
+
+            return strings.Join(res, "")
+        }
+
+        if typ.Nullable {
+            types.WriteString(fmt.Sprintf("var typ_%d = rt.Nullable(%s)\n", i, gen.typeRef(typ.NonNullable())))
+
+        } else if typ.TypeGroup == symbols.ENUM {
+            names := []string{}
+            values := []string{}
+            for _, v := range typ.Enum.Members {
+                names = append(names, strconv.Quote(v.MemberName))
+                values = append(values, fmt.Sprint(v.Value))
+            }
+
+            types.WriteString(fmt.Sprintf("var typ_%d = rt.EnumType(%q, []string{%s}, []int32{%s})\n", i, typ.TypeName, strings.Join(names, ", "), strings.Join(values, ", ")))
+
+        } else if _, ok := primitives[typ.Name()]; ok && typ.TypeGroup != symbols.CONT && typ.TypeGroup != symbols.TRT {
+            types.WriteString(fmt.Sprintf("var typ_%d = %s.GlobalDataType(%q)\n", i, compunitName, typ.Name()))
+
+        } else if typ.TypeGroup == symbols.TPRM {
+            types.WriteString(fmt.Sprintf("var typ_%d = rt.TypeParameter(%q)\n", i, typ.Name()))
+
+        } else if typ.TypeGroup == symbols.ARR {
+            types.WriteString(fmt.Sprintf("var typ_%d = rt.ArrayType(%s)\n", i, gen.typeRef(typ.SubTypes[0])))
+
+        } else if typ.TypeGroup == symbols.MAP {
+            types.WriteString(fmt.Sprintf("var typ_%d = rt.MapType(%s, %s)\n", i, gen.typeRef(typ.SubTypes[0]), gen.typeRef(typ.SubTypes[1])))
+
+        } else if typ.TypeGroup == symbols.FUNC {
+            types.WriteString(fmt.Sprintf("var typ_%d = rt.FunctionType(%s%s)\n", i, gen.typeRef(typ.FunctionReturnType()), refs(typ.FunctionParameters())))
+
+        } else if genericBase(typ) != nil {
+            types.WriteString(fmt.Sprintf("var typ_%d = rt.Instance(%s%s)\n", i, gen.typeRef(genericBase(typ)), refs(typ.SubTypes)))
+
+        } else if typ.TypeGroup == symbols.TRT {
+            types.WriteString(fmt.Sprintf("var typ_%d = rt.TraitType(%q%s)\n", i, typ.TypeName, refs(typ.SubTypes)))
+
+        } else if typ.TypeGroup == symbols.CONT && gen.Containers[typ.Container] {
+            types.WriteString(fmt.Sprintf("var typ_%d = rt.ContainerType(%q%s)\n", i, typ.TypeName, refs(typ.SubTypes)))
+
+            // (traits get attached once every type exists)
+            if len(typ.Container.TraitTypes) > 0 {
+                traits.WriteString(fmt.Sprintf("rt.Implements(typ_%d%s)\n", i, refs(typ.Container.TraitTypes)))
+            }
+
+        } else if typ.TypeGroup == symbols.CONT {
+            types.WriteString(fmt.Sprintf("var typ_%d = rt.NativeType(%q, %q)\n", i, typ.Container.ParentPackage.Name(), typ.TypeName))
+        }
+    }
+
+    if traits.Len() > 0 {
+        types.WriteString("\nfunc init() {\n")
+        types.WriteString(traits.String())
+        types.WriteString("}\n")
+    }
+
+    out := strings.Builder{}
+    out.WriteString("// Code generated by rrc. DO NOT EDIT.\n\n")
+    out.WriteString("package main\n\n")
+
+    out.WriteString("import (\n")
+    for path, name := range gen.Imports {
+        out.WriteString(fmt.Sprintf("%s %q\n", name, path))
+    }
+    out.WriteString(")\n\n")
+
+    // source files (so runtime errors can still point at code)
+    out.WriteString(fmt.Sprintf("var sources = []%s.SourceFile{\n", compunitName))
+    for _, src := range gen.Comp.SourceFiles {
+        out.WriteString(fmt.Sprintf("{Path: %q, Content: %q},\n", src.Path, src.Content))
+    }
+    out.WriteString("}\n\n")
+
+    // positions
+    out.WriteString(fmt.Sprintf("var spans = []%s.Span{\n", spanName))
+    for _, pos := range gen.Spans {
+        if pos.Internal {
+            out.WriteString("{Internal: true},\n")
+            continue
+        }
+
+        out.WriteString(fmt.Sprintf("{File: %d, FromIdx: %d, ToIdx: %d},\n", pos.File, pos.FromIdx, pos.ToIdx))
+    }
+    out.WriteString("}\n\n")
+
+    out.WriteString(types.String())
+    out.WriteString("\n")
+
+    return out.String()
+}
+
+// --------------------------------------------------------
+// Functions
+// --------------------------------------------------------
+func (gen *Generator) generateFunction(fnc *symbols.FunctionSymbol, body *boundnodes.BoundBlockStatementNode) {
+    gen.Function = fnc
+    gen.Locals = make(map[symbols.VariableSymbol]string)
+    gen.LocalNames = make(map[string]bool)
+    gen.UsedLabels = make(map[boundnodes.BoundLabel]bool)
+    gen.Regions = []*boundnodes.BoundProtectStatementNode{}
+    gen.LabelRegions = make(map[boundnodes.BoundLabel]int)
+
+    ret := gen.goType(fnc.ReturnType)
+
+    // Signature
+    // ---------
+    gen.line("// %s", readableName(fnc))
+    if fnc.FunctionKind == symbols.FT_METH && fnc.MethodSource.TypeGroup == symbols.CONT {
+        gen.line("func (this %s) M_%s(%s) %s {", gen.goType(fnc.MethodSource), fnc.FuncName, gen.parameters(fnc, true), ret)
+
+    } else if fnc.FunctionKind == symbols.FT_METH {
+        gen.line("func %s(this %s, %s) %s {", functionName(fnc), gen.goType(fnc.MethodSource), gen.parameters(fnc, true), ret)
+
+    } else {
+        gen.line("func %s(%s) %s {", functionName(fnc), gen.parameters(fnc, true), ret)
+    }
+
+    gen.line("rt.Enter(at, %q)", readableName(fnc))
+
+    for _, v := range fnc.Parameters {
+        if symbols.IsCaptured(v) {
+            gen.line("%s := %s", gen.local(v), gen.cell(v, "p_" + v.Name()))
+        }
+    }
+
+    // Locals
+    // ------
+    // (all of them live at the top, gotos are not allowed to jump over declarations)
+    gen.scanFunction(body)
+
+    locals := []symbols.VariableSymbol{}
+    for _, stmt := range body.Statements {
+        if stmt.Type() == boundnodes.BT_DeclarationStmt {
+            locals = append(locals, stmt.(*boundnodes.BoundDeclarationStatementNode).Variable)
+        } else if stmt.Type() == boundnodes.BT_ProtectIStmt {
+            locals = append(locals, stmt.(*boundnodes.BoundProtectStatementNode).ErrorVariable)
+        }
+    }
+
+    for _, v := range locals {
+        if _, ok := gen.Locals[v]; ok {
+            continue
+        }
+
+        name := gen.local(v)
+        gen.line("var %s %s", name, gen.varType(v))
+        gen.line("_ = %s", name)
+    }
+
+    // Body
+    // ----
+    // no protected regions -> the body is just the body
+    if len(gen.Regions) == 0 {
+        for _, stmt := range body.Statements {
+            gen.generateStatement(stmt)
+        }
+
+        // someone forgot to return lol
+        gen.line("rt.Leave()")
+        if ret != "" {
+            gen.line("return %s", gen.zeroValue(fnc.ReturnType))
+        }
+
+        gen.line("}")
+        gen.line("")
+        return
+    }
+
+    // otherwise the body runs inside a closure that is restarted at
+    // the catch label of whichever region caught an error
+    gen.line("rgn, resume, depth := 0, 0, rt.Depth()")
+    gen.line("for {")
+
+    if ret != "" {
+        gen.line("ret, done := func() (ret %s, done bool) {", ret)
+    } else {
+        gen.line("done := func() (done bool) {")
+    }
+
+    gen.line("defer func() {")
+    gen.line("r := recover()")
+    gen.line("if r == nil {")
+    gen.line("return")
+    gen.line("}")
+    gen.line("exc := rt.Recover(r)")
+    gen.line("switch rgn {")
+    for i, v := range gen.Regions {
+        gen.line("case %d:", i+1)
+        gen.line("rt.Unwind(depth)")
+        gen.line("%s = %s", gen.local(v.ErrorVariable), gen.cell(v.ErrorVariable, "exc.Instance"))
+        gen.line("resume = %d", i+1)
+        gen.line("return")
+    }
+    gen.line("}")
+    gen.line("panic(exc)")
+    gen.line("}()")
+    gen.line("")
+
+    gen.line("switch resume {")
+    for i, v := range gen.Regions {
+        gen.line("case %d:", i+1)
+        gen.line("goto %s", labelName(v.Catch))
+    }
+    gen.line("}")
+    gen.line("")
+
+    for _, stmt := range body.Statements {
+        gen.generateStatement(stmt)
+    }
+
+    gen.line("rt.Leave()")
+    if ret != "" {
+        gen.line("return %s, true", gen.zeroValue(fnc.ReturnType))
+        gen.line("}()")
+        gen.line("")
+        gen.line("if done {")
+        gen.line("return ret")
+        gen.line("}")
+    } else {
+        gen.line("return true")
+        gen.line("}()")
+        gen.line("")
+        gen.line("if done {")
+        gen.line("return")
+        gen.line("}")
+    }
+
+    gen.line("}")
+    gen.line("}")
+    gen.line("")
+}
+
+// Functions used as values are called through a trampoline
+// (takes everything out of the anys the function instance hands us)
+// ------------------------------------------------------------------
+func (gen *Generator) generateTrampoline(fnc *symbols.FunctionSymbol) {
+    evalObjectsName := gen.use(evalObjectsPath)
+
+    // whoever invoked us already told the runtime where they are
+    args := []string{"rt.Here()"}
+    for i, v := range fnc.Captures {
+        args = append(args, fmt.Sprintf("fn.Captures[%d].(%s)", i, gen.varType(v)))
+    }
+
+    for i, v := range fnc.Parameters {
+        args = append(args, gen.unbox(fmt.Sprintf("args[%d]", i), v.VarType()))
+    }
+
+    call := fmt.Sprintf("%s(%s)", functionName(fnc), strings.Join(args, ", "))
+
+    gen.line("func %s(fn *%s.FunctionInstance, args []any) any {", trampolineName(fnc), evalObjectsName)
+    if fnc.ReturnType.Name() == "void" {
+        gen.line("%s", call)
+        gen.line("return nil")
+    } else {
+        gen.line("return %s", gen.box(call, fnc.ReturnType))
+    }
+    gen.line("}")
+    gen.line("")
+}
+
+// Find out which labels are used and where the protected regions are
+// -------------------------------------------------------------------
+func (gen *Generator) scanFunction(body *boundnodes.BoundBlockStatementNode) {
+    positions := make(map[boundnodes.BoundLabel]int)
+
+    for i, stmt := range body.Statements {
+        if stmt.Type() == boundnodes.BT_LabelIStmt {
+            positions[stmt.(*boundnodes.BoundLabelStatementNode).Label] = i
+
+        } else if stmt.Type() == boundnodes.BT_GoToIStmt {
+            gen.UsedLabels[stmt.(*boundnodes.BoundGotoStatementNode).Label] = true
+
+        } else if stmt.Type() == boundnodes.BT_GoToIfIStmt {
+            gen.UsedLabels[stmt.(*boundnodes.BoundGotoIfStatementNode).Label] = true
+
+        } else if stmt.Type() == boundnodes.BT_ProtectIStmt {
+            prt := stmt.(*boundnodes.BoundProtectStatementNode)
+            gen.Regions = append(gen.Regions, prt)
+            gen.UsedLabels[prt.Catch] = true
+        }
+    }
+
+    // nested regions always come after the ones containing them
+    // -> the last region containing a label is the innermost one
+    for lbl, pos := range positions {
+        for i, v := range gen.Regions {
+            if pos >= positions[v.Start] && pos < positions[v.End] {
+                gen.LabelRegions[lbl] = i+1
+            }
+        }
+    }
+}
+
+func labelName(lbl boundnodes.BoundLabel) string {
+    return "L_" + string(lbl)
+}
+
+// Get the go name of a local (or give it a new one)
+// -------------------------------------------------
+func (gen *Generator) local(vari symbols.VariableSymbol) string {
+    if name, ok := gen.Locals[vari]; ok {
+        return name
+    }
+
+    // locals in different scopes can have the same name
+    name := "v_" + vari.Name()
+    for i := 1; gen.LocalNames[name]; i++ {
+        name = fmt.Sprintf("v_%s_%d", vari.Name(), i)
+    }
+
+    gen.Locals[vari] = name
+    gen.LocalNames[name] = true
+
+    return name
+}
+
+// --------------------------------------------------------
+// Statements
+// --------------------------------------------------------
+func (gen *Generator) generateStatement(stmt boundnodes.BoundStatementNode) {
+    if stmt.Type() == boundnodes.BT_DeclarationStmt {
+        gen.generateDeclarationStatement(stmt.(*boundnodes.BoundDeclarationStatementNode))
+
+    } else if stmt.Type() == boundnodes.BT_ReturnStmt {
+        gen.generateReturnStatement(stmt.(*boundnodes.BoundReturnStatementNode))
+
+    } else if stmt.Type() == boundnodes.BT_ExpressionStmt {
+        gen.generateExpressionStatement(stmt.(*boundnodes.BoundExpressionStatementNode))
+
+    } else if stmt.Type() == boundnodes.BT_GoToIStmt {
+        gen.line("goto %s", labelName(stmt.(*boundnodes.BoundGotoStatementNode).Label))
+
+    } else if stmt.Type() == boundnodes.BT_GoToIfIStmt {
+        gti := stmt.(*boundnodes.BoundGotoIfStatementNode)
+        gen.line("if %s {", gen.generateExpression(gti.Condition))
+        gen.line("goto %s", labelName(gti.Label))
+        gen.line("}")
+
+    } else if stmt.Type() == boundnodes.BT_LabelIStmt {
+        lbl := stmt.(*boundnodes.BoundLabelStatementNode).Label
+
+        // go doesnt like labels nobody uses
+        if gen.UsedLabels[lbl] {
+            gen.line("%s:", labelName(lbl))
+        }
+
+        // whoever gets here is now in a different region
+        if len(gen.Regions) > 0 {
+            gen.line("rgn = %d", gen.LabelRegions[lbl])
+        }
+
+    } else if stmt.Type() == boundnodes.BT_DeleteIStmt {
+        // go takes care of that
+
+    } else if stmt.Type() == boundnodes.BT_ApproachIStmt {
+        apr := stmt.(*boundnodes.BoundApproachStatementNode)
+        name := gen.generateLoad(apr.Iterator)
+        gen.line("%s = rt.Approach(%s, %s)", name, name, gen.generateExpression(apr.Target))
+
+    } else if stmt.Type() == boundnodes.BT_ThrowStmt {
+        thr := stmt.(*boundnodes.BoundThrowStatementNode)
+        gen.line("rt.Throw(%s, %d)", gen.box(gen.generateExpression(thr.Error), thr.Error.ExprType()), gen.at(stmt))
+
+    } else if stmt.Type() == boundnodes.BT_ProtectIStmt {
+        // regions have been collected already
+
+    } else {
+        gen.Comp.Report(error.NewError(error.GEN, stmt.Source().Position(), "Statement generation not implemented! You should implement NOW! (%s)", stmt.Type()))
+    }
+}
+
+func (gen *Generator) generateDeclarationStatement(stmt *boundnodes.BoundDeclarationStatementNode) {
+    // (every declaration of a captured variable gets its own cell)
+    if stmt.HasInitializer {
+        gen.line("%s = %s", gen.local(stmt.Variable), gen.cell(stmt.Variable, gen.generateExpression(stmt.Initializer)))
+    } else {
+        gen.line("%s = %s", gen.local(stmt.Variable), gen.cell(stmt.Variable, gen.defaultValue(stmt.Variable.VarType())))
+    }
+}
+
+func (gen *Generator) generateExpressionStatement(stmt *boundnodes.BoundExpressionStatementNode) {
+    // assignments dont need to keep their value around if nobody wants it
+    if stmt.Expression.Type() == boundnodes.BT_AssignmentExpr {
+        gen.generateAssignmentStatement(stmt.Expression.(*boundnodes.BoundAssignmentExpressionNode))
+        return
+    }
+
+    expr := gen.generateExpression(stmt.Expression)
+
+    // void calls are fine on their own, everything else needs to be thrown away
+    if stmt.Expression.ExprType().Name() == "void" {
+        gen.line("%s", expr)
+    } else {
+        gen.line("_ = %s", expr)
+    }
+}
+
+func (gen *Generator) generateReturnStatement(stmt *boundnodes.BoundReturnStatementNode) {
+    done := ""
+    if len(gen.Regions) > 0 {
+        done = ", true"
+    }
+
+    if stmt.HasReturnValue && gen.Function.ReturnType.Name() != "void" {
+        // the value needs to be there before the frame is gone
+        gen.line("return rt.Return[%s](%s)%s", gen.goType(gen.Function.ReturnType), gen.generateExpression(stmt.ReturnValue), done)
+        return
+    }
+
+    gen.line("rt.Leave()")
+    if gen.Function.ReturnType.Name() != "void" {
+        gen.line("return %s%s", gen.zeroValue(gen.Function.ReturnType), done)
+    } else if len(gen.Regions) > 0 {
+        gen.line("return true")
+    } else {
+        gen.line("return")
+    }
+}
@@ -0,0 +1,681 @@
+// Codegen - expressions.go
+// --------------------------------------------------------
+// Everything that turns into a go expression
+// --------------------------------------------------------
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"bytespace.network/rerect/boundnodes"
+	"bytespace.network/rerect/error"
+	"bytespace.network/rerect/symbols"
+)
+
+// --------------------------------------------------------
+// Helpers
+// --------------------------------------------------------
+
+// Put a value into an any (references need to stay null if theyre null)
+// ---------------------------------------------------------------------
+func (gen *Generator) box(expr string, typ *symbols.TypeSymbol) string {
+    if isReference(typ) {
+        return fmt.Sprintf("rt.Box[%s](%s)", gen.goType(typ), expr)
+    }
+
+    return expr
+}
+
+// Take a value of a known type back out of an any
+// -----------------------------------------------
+func (gen *Generator) unbox(expr string, typ *symbols.TypeSymbol) string {
+    if typ.Name() == "any" || typ.Name() == "void" || typ.TypeGroup == symbols.TPRM {
+        return expr
+    }
+
+    // null is a perfectly fine value for references
+    if isReference(typ) {
+        return fmt.Sprintf("rt.As[%s](%s)", gen.goType(typ), expr)
+    }
+
+    return fmt.Sprintf("%s.(%s)", expr, gen.goType(typ))
+}
+
+// Values of generic types are anys in go land
+// (narrow takes them back out, widen puts them in)
+// ------------------------------------------------
+func (gen *Generator) narrow(expr string, from *symbols.TypeSymbol, to *symbols.TypeSymbol) string {
+    if gen.goType(from) == gen.goType(to) {
+        return expr
+    }
+
+    return gen.unbox(expr, to)
+}
+
+func (gen *Generator) widen(expr string, from *symbols.TypeSymbol, to *symbols.TypeSymbol) string {
+    if gen.goType(from) == gen.goType(to) {
+        return expr
+    }
+
+    return gen.box(expr, from)
+}
+
+func (gen *Generator) arguments(fnc *symbols.FunctionSymbol, args []boundnodes.BoundExpressionNode) []string {
+    res := []string{}
+    for i, v := range args {
+        res = append(res, gen.widen(gen.generateExpression(v), v.ExprType(), fnc.Parameters[i].VarType()))
+    }
+
+    return res
+}
+
+// Arguments for natives (always an []any)
+func (gen *Generator) nativeArguments(args []boundnodes.BoundExpressionNode) string {
+    res := []string{}
+    for _, v := range args {
+        res = append(res, gen.box(gen.generateExpression(v), v.ExprType()))
+    }
+
+    return fmt.Sprintf("[]any{%s}", strings.Join(res, ", "))
+}
+
+// Is this field part of a struct? (or only reachable through a trait)
+func (gen *Generator) isStructField(fld *symbols.FieldSymbol) bool {
+    return fld.HasParentContainer && gen.Containers[fld.ParentContainer]
+}
+
+// --------------------------------------------------------
+// Expressions
+// --------------------------------------------------------
+func (gen *Generator) generateExpression(expr boundnodes.BoundExpressionNode) string {
+    if expr.Type() == boundnodes.BT_LiteralExpr {
+        return gen.generateLiteralExpression(expr.(*boundnodes.BoundLiteralExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_AssignmentExpr {
+        return gen.generateAssignmentExpression(expr.(*boundnodes.BoundAssignmentExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_UnaryExpr {
+        return gen.generateUnaryExpression(expr.(*boundnodes.BoundUnaryExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_BinaryExpr {
+        return gen.generateBinaryExpression(expr.(*boundnodes.BoundBinaryExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_CallExpr {
+        return gen.generateCallExpression(expr.(*boundnodes.BoundCallExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_CallValueExpr {
+        return gen.generateCallValueExpression(expr.(*boundnodes.BoundCallValueExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_FunctionExpr {
+        return gen.generateFunctionExpression(expr.(*boundnodes.BoundFunctionExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_AccessCallExpr {
+        acc := expr.(*boundnodes.BoundAccessCallExpressionNode)
+
+        if acc.NullSafe {
+            call := gen.generateMethodCall(acc.Function, "inst", acc.Expression.ExprType(), false, acc.Arguments, acc.ReturnType, acc)
+            return gen.generateNullSafe(gen.generateExpression(acc.Expression), acc.Expression.ExprType(), acc.ReturnType, call)
+        }
+
+        return gen.generateMethodCall(acc.Function, gen.generateExpression(acc.Expression), acc.Expression.ExprType(), true, acc.Arguments, acc.ReturnType, acc)
+
+    } else if expr.Type() == boundnodes.BT_NameExpr {
+        return gen.generateLoad(expr.(*boundnodes.BoundNameExpressionNode).Variable)
+
+    } else if expr.Type() == boundnodes.BT_ConversionExpr {
+        return gen.generateConversionExpression(expr.(*boundnodes.BoundConversionExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_TypeCheckExpr {
+        chk := expr.(*boundnodes.BoundTypeCheckExpressionNode)
+        return fmt.Sprintf("rt.Is(%s, %s)", gen.box(gen.generateExpression(chk.Value), chk.Value.ExprType()), gen.typeRef(chk.CheckType))
+
+    } else if expr.Type() == boundnodes.BT_CheckedCastExpr {
+        cst := expr.(*boundnodes.BoundCheckedCastExpressionNode)
+        return fmt.Sprintf("rt.CheckedCast[%s](%s, %s)", gen.goType(cst.TargetType), gen.box(gen.generateExpression(cst.Value), cst.Value.ExprType()), gen.typeRef(cst.TargetType))
+
+    } else if expr.Type() == boundnodes.BT_MakeArrayExpr {
+        return gen.generateMakeArrayExpression(expr.(*boundnodes.BoundMakeArrayExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_MakeMapExpr {
+        return gen.generateMakeMapExpression(expr.(*boundnodes.BoundMakeMapExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_ArrayIndexExpr {
+        idx := expr.(*boundnodes.BoundArrayIndexExpressionNode)
+
+        if idx.SourceArray.ExprType().TypeGroup == symbols.MAP {
+            key := gen.box(gen.generateExpression(idx.Index), idx.Index.ExprType())
+            return gen.unbox(fmt.Sprintf("rt.Key(%s, %s, %d)", gen.generateExpression(idx.SourceArray), key, gen.at(expr)), expr.ExprType())
+        }
+
+        return gen.unbox(fmt.Sprintf("rt.Index(%s, %s, %d)", gen.generateExpression(idx.SourceArray), gen.generateExpression(idx.Index), gen.at(expr)), expr.ExprType())
+
+    } else if expr.Type() == boundnodes.BT_MakeExpr {
+        return gen.generateMakeExpression(expr.(*boundnodes.BoundMakeExpressionNode))
+
+    } else if expr.Type() == boundnodes.BT_AccessFieldExpr {
+        fld := expr.(*boundnodes.BoundAccessFieldExpressionNode)
+
+        if fld.NullSafe {
+            return gen.generateNullSafe(gen.generateExpression(fld.Expression), fld.Expression.ExprType(), fld.FieldType, gen.generateFieldLoad("inst", fld.Field, fld.FieldType))
+        }
+
+        return gen.generateFieldLoad(fmt.Sprintf("rt.Field(%s, %d)", gen.generateExpression(fld.Expression), gen.at(expr)), fld.Field, fld.FieldType)
+
+    } else if expr.Type() == boundnodes.BT_SequenceExpr {
+        return gen.generateSequenceExpression(expr.(*boundnodes.BoundSequenceExpressionNode))
+    }
+
+    gen.Comp.Report(error.NewError(error.GEN, expr.Source().Position(), "Expression generation not implemented! You should implement NOW! (%s)", expr.Type()))
+    return "nil"
+}
+
+func (gen *Generator) generateLiteralExpression(expr *boundnodes.BoundLiteralExpressionNode) string {
+    // numbers are handed through rt.Num, otherwise go would treat them
+    // as constants and refuse to compile anything that overflows
+    // (we want those to wrap around, just like in the vm)
+    switch v := expr.LiteralValue.(type) {
+    case int64:
+        return fmt.Sprintf("rt.Num(int64(%d))", v)
+    case int32:
+        return fmt.Sprintf("rt.Num(int32(%d))", v)
+    case int16:
+        return fmt.Sprintf("rt.Num(int16(%d))", v)
+    case int8:
+        return fmt.Sprintf("rt.Num(int8(%d))", v)
+    case float64:
+        return fmt.Sprintf("rt.Num(float64(%s))", strconv.FormatFloat(v, 'g', -1, 64))
+    case float32:
+        return fmt.Sprintf("rt.Num(float32(%s))", strconv.FormatFloat(float64(v), 'g', -1, 32))
+    case bool:
+        return strconv.FormatBool(v)
+    case string:
+        return strconv.Quote(v)
+    }
+
+    return "nil"
+}
+
+// Sequences become a little closure
+// (their locals live in there too, nobody outside of it needs them)
+// -----------------------------------------------------------------
+func (gen *Generator) generateSequenceExpression(expr *boundnodes.BoundSequenceExpressionNode) string {
+    out := strings.Builder{}
+    out.WriteString(fmt.Sprintf("func() %s {\n", gen.goType(expr.ExprType())))
+
+    for _, stmt := range expr.Statements {
+        if stmt.Type() == boundnodes.BT_DeclarationStmt {
+            decl := stmt.(*boundnodes.BoundDeclarationStatementNode)
+            name := gen.local(decl.Variable)
+
+            out.WriteString(fmt.Sprintf("var %s %s = %s\n", name, gen.varType(decl.Variable), gen.cell(decl.Variable, gen.generateExpression(decl.Initializer))))
+            out.WriteString(fmt.Sprintf("_ = %s\n", name))
+
+        } else if stmt.Type() == boundnodes.BT_ExpressionStmt {
+            out.WriteString(fmt.Sprintf("_ = %s\n", gen.generateExpression(stmt.(*boundnodes.BoundExpressionStatementNode).Expression)))
+
+        } else {
+            gen.Comp.Report(error.NewError(error.GEN, stmt.Source().Position(), "Statement generation in sequences not implemented! You should implement NOW! (%s)", stmt.Type()))
+        }
+    }
+
+    out.WriteString(fmt.Sprintf("return %s\n", gen.generateExpression(expr.Value)))
+    out.WriteString("}()")
+
+    return out.String()
+}
+
+// --------------------------------------------------------
+// Variables
+// --------------------------------------------------------
+func (gen *Generator) generateLoad(vari symbols.VariableSymbol) string {
+    if vari.Type() == symbols.ST_Global {
+        return globalName(vari.(*symbols.GlobalSymbol))
+
+    } else if vari.Type() == symbols.ST_Field {
+        return gen.generateFieldLoad("this", vari.(*symbols.FieldSymbol), vari.VarType())
+
+    } else if vari.Type() == symbols.ST_Instance {
+        return "this"
+    }
+
+    // captured variables live in a cell
+    if symbols.IsCaptured(vari) {
+        return gen.local(vari) + ".V"
+    }
+
+    return gen.local(vari)
+}
+
+// (typ is the type the value should have, generic fields dont know that)
+// ?-> only runs the access (written against "inst") if the instance isnt null
+// (otherwise we hand back the default of the result instead)
+// ---------------------------------------------------------------------------
+func (gen *Generator) generateNullSafe(instance string, typ *symbols.TypeSymbol, ret *symbols.TypeSymbol, access string) string {
+    out := strings.Builder{}
+
+    // void calls dont give anything back
+    if ret.Name() == "void" {
+        out.WriteString("func() {\n")
+        out.WriteString(fmt.Sprintf("inst := %s\n", instance))
+        out.WriteString(fmt.Sprintf("if inst != nil {\n%s\n}\n", access))
+        out.WriteString("}()")
+
+        return out.String()
+    }
+
+    out.WriteString(fmt.Sprintf("func() %s {\n", gen.goType(ret)))
+    out.WriteString(fmt.Sprintf("inst := %s\n", instance))
+    out.WriteString(fmt.Sprintf("if inst == nil {\nreturn %s\n}\n", gen.defaultValue(ret)))
+    out.WriteString(fmt.Sprintf("return %s\n", access))
+    out.WriteString("}()")
+
+    return out.String()
+}
+
+func (gen *Generator) generateFieldLoad(instance string, fld *symbols.FieldSymbol, typ *symbols.TypeSymbol) string {
+    // good old struct field
+    if gen.isStructField(fld) {
+        return gen.narrow(fmt.Sprintf("%s.F_%s", instance, fld.FieldName), fld.FieldType, typ)
+    }
+
+    // field of a native container
+    if fld.HasParentContainer {
+        return gen.unbox(fmt.Sprintf("%s.Fields[%q]", instance, fld.FieldName), typ)
+    }
+
+    // field of a trait
+    return gen.narrow(fmt.Sprintf("%s.Get_%s()", instance, fld.FieldName), fld.FieldType, typ)
+}
+
+// Store something somewhere (and hand back what was stored)
+// ---------------------------------------------------------
+func (gen *Generator) generateAssignmentExpression(expr *boundnodes.BoundAssignmentExpressionNode) string {
+    typ := gen.goType(expr.Value.ExprType())
+    val := gen.generateExpression(expr.Value)
+
+    // classic variable assignment
+    if expr.Expression.Type() == boundnodes.BT_NameExpr {
+        vari := expr.Expression.(*boundnodes.BoundNameExpressionNode).Variable
+
+        if vari.Type() == symbols.ST_Field {
+            return gen.generateFieldStore("this", vari.(*symbols.FieldSymbol), val, expr.Value.ExprType(), true)
+        }
+
+        return fmt.Sprintf("rt.Set[%s](&%s, %s)", typ, gen.generateLoad(vari), val)
+
+    // array index assignment
+    } else if expr.Expression.Type() == boundnodes.BT_ArrayIndexExpr {
+        exp := expr.Expression.(*boundnodes.BoundArrayIndexExpressionNode)
+
+        if exp.SourceArray.ExprType().TypeGroup == symbols.MAP {
+            key := gen.box(gen.generateExpression(exp.Index), exp.Index.ExprType())
+            return fmt.Sprintf("rt.StoreKey[%s](%s, %s, %s, %d)", typ, val, gen.generateExpression(exp.SourceArray), key, gen.at(expr))
+        }
+
+        return fmt.Sprintf("rt.StoreIndex[%s](%s, %s, %s, %d)", typ, val, gen.generateExpression(exp.SourceArray), gen.generateExpression(exp.Index), gen.at(expr))
+
+    // container field assignment
+    } else if expr.Expression.Type() == boundnodes.BT_AccessFieldExpr {
+        exp := expr.Expression.(*boundnodes.BoundAccessFieldExpressionNode)
+        return gen.generateFieldStore(fmt.Sprintf("rt.Assign(%s, %d)", gen.generateExpression(exp.Expression), gen.at(expr)), exp.Field, val, expr.Value.ExprType(), true)
+    }
+
+    gen.Comp.Report(error.NewError(error.GEN, expr.Source().Position(), "Assignment generation not implemented! You should implement NOW! (%s)", expr.Expression.Type()))
+    return "nil"
+}
+
+// Same thing, but as a statement (nobody wants the value)
+// -------------------------------------------------------
+func (gen *Generator) generateAssignmentStatement(expr *boundnodes.BoundAssignmentExpressionNode) {
+    val := gen.generateExpression(expr.Value)
+
+    if expr.Expression.Type() == boundnodes.BT_NameExpr {
+        vari := expr.Expression.(*boundnodes.BoundNameExpressionNode).Variable
+
+        if vari.Type() == symbols.ST_Field {
+            gen.line("%s", gen.generateFieldStore("this", vari.(*symbols.FieldSymbol), val, expr.Value.ExprType(), false))
+            return
+        }
+
+        gen.line("%s = %s", gen.generateLoad(vari), val)
+        return
+
+    } else if expr.Expression.Type() == boundnodes.BT_AccessFieldExpr {
+        exp := expr.Expression.(*boundnodes.BoundAccessFieldExpressionNode)
+        gen.line("%s", gen.generateFieldStore(fmt.Sprintf("rt.Assign(%s, %d)", gen.generateExpression(exp.Expression), gen.at(expr)), exp.Field, val, expr.Value.ExprType(), false))
+        return
+    }
+
+    // index assignments are a call anyways
+    gen.line("%s", gen.generateAssignmentExpression(expr))
+}
+
+// (valtyp is the type of the value, generic fields might only know it as an any)
+func (gen *Generator) generateFieldStore(instance string, fld *symbols.FieldSymbol, val string, valtyp *symbols.TypeSymbol, keep bool) string {
+    typ := gen.goType(fld.FieldType)
+
+    // field of a trait (setters always hand back the value)
+    if !fld.HasParentContainer {
+        set := fmt.Sprintf("%s.Set_%s(%s)", instance, fld.FieldName, gen.widen(val, valtyp, fld.FieldType))
+
+        if keep {
+            return gen.narrow(set, fld.FieldType, valtyp)
+        }
+
+        return set
+    }
+
+    // good old struct field
+    target := fmt.Sprintf("%s.F_%s", instance, fld.FieldName)
+
+    // field of a native container
+    if !gen.isStructField(fld) {
+        target = fmt.Sprintf("%s.Fields[%q]", instance, fld.FieldName)
+        val = gen.box(val, valtyp)
+        typ = "any"
+
+        if keep {
+            return gen.unbox(fmt.Sprintf("rt.SetField(%s, %q, %s)", instance, fld.FieldName, val), valtyp)
+        }
+    }
+
+    val = gen.widen(val, valtyp, fld.FieldType)
+
+    if keep {
+        return gen.narrow(fmt.Sprintf("rt.Set[%s](&%s, %s)", typ, target, val), fld.FieldType, valtyp)
+    }
+
+    return fmt.Sprintf("%s = %s", target, val)
+}
+
+// --------------------------------------------------------
+// Operators
+// --------------------------------------------------------
+func (gen *Generator) generateUnaryExpression(expr *boundnodes.BoundUnaryExpressionNode) string {
+    operand := gen.generateExpression(expr.Operand)
+
+    switch expr.Operator.Operation {
+    case boundnodes.UO_Identity:
+        return fmt.Sprintf("(%s)", operand)
+
+    case boundnodes.UO_Negation:
+        return fmt.Sprintf("(-%s)", operand)
+
+    case boundnodes.UO_LogicalNegation:
+        return fmt.Sprintf("(!%s)", operand)
+
+    case boundnodes.UO_BitwiseNegation:
+        return fmt.Sprintf("(^%s)", operand)
+    }
+
+    gen.Comp.Report(error.NewError(error.GEN, expr.Source().Position(), "Unary operator not implemented! You should implement NOW!"))
+    return "nil"
+}
+
+func (gen *Generator) generateBinaryExpression(expr *boundnodes.BoundBinaryExpressionNode) string {
+    left := gen.generateExpression(expr.Left)
+    right := gen.generateExpression(expr.Right)
+
+    // operators go can do on its own
+    operators := map[boundnodes.BinaryOperatorType]string{
+        boundnodes.BO_Addition:       "+",
+        boundnodes.BO_Subtraction:    "-",
+        boundnodes.BO_Multiplication: "*",
+        boundnodes.BO_Equal:          "==",
+        boundnodes.BO_UnEqual:        "!=",
+        boundnodes.BO_LessThan:       "<",
+        boundnodes.BO_LessEqual:      "<=",
+        boundnodes.BO_GreaterThan:    ">",
+        boundnodes.BO_GreaterEqual:   ">=",
+        boundnodes.BO_Concat:         "+",
+        boundnodes.BO_BitwiseAnd:     "&",
+        boundnodes.BO_BitwiseOr:      "|",
+        boundnodes.BO_BitwiseXor:     "^",
+    }
+
+    if op, ok := operators[expr.Operator.Operation]; ok {
+        return fmt.Sprintf("(%s %s %s)", left, op, right)
+    }
+
+    switch expr.Operator.Operation {
+    case boundnodes.BO_Division:
+        // integer division needs to know where it is (in case someone divides by zero)
+        if expr.Operator.Left.TypeGroup == symbols.INT {
+            return fmt.Sprintf("rt.Div(%s, %s, %d)", left, right, gen.at(expr))
+        }
+
+        return fmt.Sprintf("(%s / %s)", left, right)
+
+    // same deal for modulo and shifting by negative amounts
+    case boundnodes.BO_Modulo:
+        return fmt.Sprintf("rt.Mod(%s, %s, %d)", left, right, gen.at(expr))
+
+    case boundnodes.BO_ShiftLeft:
+        return fmt.Sprintf("rt.Shl(%s, %s, %d)", left, right, gen.at(expr))
+
+    case boundnodes.BO_ShiftRight:
+        return fmt.Sprintf("rt.Shr(%s, %s, %d)", left, right, gen.at(expr))
+
+    // both sides always get evaluated
+    case boundnodes.BO_LogicalAnd:
+        return fmt.Sprintf("rt.And(%s, %s)", left, right)
+
+    case boundnodes.BO_LogicalOr:
+        return fmt.Sprintf("rt.Or(%s, %s)", left, right)
+    }
+
+    gen.Comp.Report(error.NewError(error.GEN, expr.Source().Position(), "Binary operator not implemented! You should implement NOW!"))
+    return "nil"
+}
+
+func (gen *Generator) generateConversionExpression(expr *boundnodes.BoundConversionExpressionNode) string {
+    from := expr.Value.ExprType()
+    to := expr.TargetType
+    val := gen.generateExpression(expr.Value)
+
+    // numbers can just be converted by go (thats what the vm does too)
+    isNumber := func(typ *symbols.TypeSymbol) bool {
+        return typ.TypeGroup == symbols.INT || typ.TypeGroup == symbols.FLOAT
+    }
+
+    // (except for floats that dont fit into an integer, those get clamped)
+    if from.TypeGroup == symbols.FLOAT && to.TypeGroup == symbols.INT {
+        return fmt.Sprintf("rt.FloatToInt[%s](float64(%s))", gen.goType(to), val)
+    }
+
+    if isNumber(from) && isNumber(to) {
+        return fmt.Sprintf("%s(%s)", gen.goType(to), val)
+    }
+
+    // enum values only know their names through their type
+    if from.TypeGroup == symbols.ENUM && to.Name() == "string" {
+        return fmt.Sprintf("rt.EnumName(%s, %s, %d)", val, gen.typeRef(from), gen.at(expr))
+    }
+
+    // everything is an any
+    if to.Name() == "any" {
+        return gen.box(val, from)
+    }
+
+    // Foo -> Foo? is the same thing in go
+    if to.Nullable && to.NonNullable().Equal(from) {
+        return val
+    }
+
+    // everything else goes through the same conversion as in the vm
+    return fmt.Sprintf("rt.Convert[%s](%s, %s, %d)", gen.goType(to), gen.box(val, from), gen.typeRef(to), gen.at(expr))
+}
+
+// --------------------------------------------------------
+// Calls
+// --------------------------------------------------------
+func (gen *Generator) generateCallExpression(expr *boundnodes.BoundCallExpressionNode) string {
+    // is this a method? (a function call without prefix happening inside a container)
+    if expr.Function.FunctionKind == symbols.FT_METH {
+        return gen.generateMethodCall(expr.Function, "this", gen.Function.MethodSource, false, expr.Arguments, expr.ReturnType, expr)
+    }
+
+    // natives are called through the runtime (so it knows where we are)
+    if expr.Function.IsVMFunction {
+        call := fmt.Sprintf("rt.Call(%d, %s, %s)", gen.at(expr), gen.nativeName(expr.Function), gen.nativeArguments(expr.Arguments))
+        return gen.unbox(call, expr.ReturnType)
+    }
+
+    args := append([]string{strconv.Itoa(gen.at(expr))}, gen.arguments(expr.Function, expr.Arguments)...)
+    return gen.narrow(fmt.Sprintf("%s(%s)", functionName(expr.Function), strings.Join(args, ", ")), expr.Function.ReturnType, expr.ReturnType)
+}
+
+// Call whatever function a value holds
+// ------------------------------------
+func (gen *Generator) generateCallValueExpression(expr *boundnodes.BoundCallValueExpressionNode) string {
+    args := []string{strconv.Itoa(gen.at(expr)), gen.generateExpression(expr.Value)}
+    for _, v := range expr.Arguments {
+        args = append(args, gen.box(gen.generateExpression(v), v.ExprType()))
+    }
+
+    return gen.unbox(fmt.Sprintf("rt.CallValue(%s)", strings.Join(args, ", ")), expr.ExprType())
+}
+
+// Turn a function into a value
+// ----------------------------
+func (gen *Generator) generateFunctionExpression(expr *boundnodes.BoundFunctionExpressionNode) string {
+    // natives already take a bunch of anys
+    if expr.Function.IsVMFunction {
+        return fmt.Sprintf("rt.Native(%s, %s)", gen.typeRef(expr.FunctionType), gen.nativeName(expr.Function))
+    }
+
+    // everything else needs a trampoline
+    known := false
+    for _, v := range gen.Trampolines {
+        known = known || v == expr.Function
+    }
+
+    if !known {
+        gen.Trampolines = append(gen.Trampolines, expr.Function)
+    }
+
+    // (lambdas bring along the cells of whatever they captured)
+    args := []string{gen.typeRef(expr.FunctionType), trampolineName(expr.Function)}
+    for _, v := range expr.Function.Captures {
+        args = append(args, gen.local(v))
+    }
+
+    return fmt.Sprintf("rt.Closure(%s)", strings.Join(args, ", "))
+}
+
+// Call a method on an instance
+// ----------------------------
+// (ret is the type the result should have, generic methods dont know that)
+func (gen *Generator) generateMethodCall(fnc *symbols.FunctionSymbol, instance string, typ *symbols.TypeSymbol, check bool, args []boundnodes.BoundExpressionNode, ret *symbols.TypeSymbol, node boundnodes.BoundNode) string {
+    at := gen.at(node)
+
+    if fnc.IsVMFunction {
+        call := fmt.Sprintf("rt.CallMethod(%d, %s, %s, %s)", at, gen.nativeName(fnc), gen.box(instance, typ), gen.nativeArguments(args))
+        return gen.unbox(call, ret)
+    }
+
+    // if this is null -> we're doomed
+    if check {
+        instance = fmt.Sprintf("rt.Method(%s, %d)", instance, at)
+    }
+
+    prms := append([]string{strconv.Itoa(at)}, gen.arguments(fnc, args)...)
+    return gen.narrow(fmt.Sprintf("%s.%s(%s)", instance, methodName(fnc, typ), strings.Join(prms, ", ")), fnc.ReturnType, ret)
+}
+
+// --------------------------------------------------------
+// Objects
+// --------------------------------------------------------
+func (gen *Generator) generateMakeArrayExpression(expr *boundnodes.BoundMakeArrayExpressionNode) string {
+    // This is a length defined array
+    if !expr.HasInitializer {
+        return fmt.Sprintf("rt.MakeArray(%s, %s)", gen.typeRef(expr.ArrType), gen.generateExpression(expr.Length))
+    }
+
+    // This is an element defined array
+    elems := []string{gen.typeRef(expr.ArrType)}
+    for _, v := range expr.Initializer {
+        elems = append(elems, gen.box(gen.generateExpression(v), v.ExprType()))
+    }
+
+    return fmt.Sprintf("rt.ArrayFrom(%s)", strings.Join(elems, ", "))
+}
+
+func (gen *Generator) generateMakeMapExpression(expr *boundnodes.BoundMakeMapExpressionNode) string {
+    // keys and values take turns
+    entries := []string{gen.typeRef(expr.MapType)}
+    for i := range expr.Keys {
+        entries = append(entries, gen.box(gen.generateExpression(expr.Keys[i]), expr.Keys[i].ExprType()))
+        entries = append(entries, gen.box(gen.generateExpression(expr.Values[i]), expr.Values[i].ExprType()))
+    }
+
+    return fmt.Sprintf("rt.MapFrom(%s)", strings.Join(entries, ", "))
+}
+
+func (gen *Generator) generateMakeExpression(expr *boundnodes.BoundMakeExpressionNode) string {
+    cnt := expr.Container
+    native := !gen.Containers[cnt]
+
+    // how to get a fresh instance
+    create := fmt.Sprintf("new_%s()", containerName(cnt)[4:])
+    if native {
+        create = fmt.Sprintf("rt.MakeNative(%s)", gen.typeRef(expr.ContainerType))
+    } else if len(cnt.TypeParameters) > 0 {
+        create = fmt.Sprintf("new_%s(%s)", containerName(cnt)[4:], gen.typeRef(expr.ContainerType))
+    }
+
+    // generic fields start out with the default of their type argument
+    defaults := []string{}
+    for _, fld := range cnt.Fields {
+        if fld.FieldType.TypeGroup != symbols.TPRM || native {
+            continue
+        }
+
+        val := gen.defaultValue(symbols.Substitute(fld.FieldType, cnt.TypeParameters, expr.ContainerType.SubTypes))
+        if val != "nil" {
+            defaults = append(defaults, fmt.Sprintf("inst.F_%s = %s\n", fld.FieldName, val))
+        }
+    }
+
+    // nothing to do but create an instance
+    if !expr.HasConstructor && !expr.HasInitializer && len(defaults) == 0 {
+        return create
+    }
+
+    // otherwise: build it inside a little closure
+    // (go doesnt let us run statements in the middle of an expression)
+    out := strings.Builder{}
+    out.WriteString(fmt.Sprintf("func() %s {\n", gen.goType(cnt.ContainerType)))
+    out.WriteString(fmt.Sprintf("inst := %s\n", create))
+
+    for _, v := range defaults {
+        out.WriteString(v)
+    }
+
+    // are we calling a constructor?
+    if expr.HasConstructor {
+        out.WriteString(gen.generateMethodCall(cnt.Constructor, "inst", expr.ContainerType, false, expr.Arguments, cnt.Constructor.ReturnType, expr))
+        out.WriteString("\n")
+    }
+
+    // are we initializing fields ourselves like a caveman?
+    // (go through the container fields so the order is always the same)
+    if expr.HasInitializer {
+        for _, fld := range cnt.Fields {
+            v, ok := expr.Initializer[fld]
+            if !ok {
+                continue
+            }
+
+            out.WriteString(gen.generateFieldStore("inst", fld, gen.generateExpression(v), v.ExprType(), false))
+            out.WriteString("\n")
+        }
+    }
+
+    out.WriteString("return inst\n")
+    out.WriteString("}()")
+
+    return out.String()
+}
@@ -0,0 +1,400 @@
+// RT - helpers.go
+// --------------------------------------------------------
+// Everything generated code cant (or shouldnt) do inline:
+// checks that need a source position, arrays, conversions
+// and calls into native packages
+// --------------------------------------------------------
+package rt
+
+import (
+	"reflect"
+
+	evalobjects "bytespace.network/rerect/eval_objects"
+	"bytespace.network/rerect/symbols"
+)
+
+// --------------------------------------------------------
+// Null checks
+// --------------------------------------------------------
+func Field[T comparable](val T, at int) T {
+    var null T
+
+    // if this is null -> we're doomed
+    if val == null {
+        Fail(at, "Cannot access field on null! (I am literally calling the police rn)")
+    }
+
+    return val
+}
+
+func Assign[T comparable](val T, at int) T {
+    var null T
+
+    // if this is null -> we're doomed
+    if val == null {
+        Fail(at, "Cannot assign field on null! (I am literally calling the police rn)")
+    }
+
+    return val
+}
+
+func Method[T comparable](val T, at int) T {
+    var null T
+
+    // if this is null -> we're doomed
+    if val == null {
+        Fail(at, "Cannot call method on null! (I am literally calling the police rn)")
+    }
+
+    return val
+}
+
+// --------------------------------------------------------
+// Values
+// --------------------------------------------------------
+
+// Put a reference into an any (null stays null, even if go thinks otherwise)
+// --------------------------------------------------------------------------
+func Box[T comparable](val T) any {
+    var null T
+    if val == null {
+        return nil
+    }
+
+    return val
+}
+
+// Take something back out of an any (null becomes the zero value)
+// ---------------------------------------------------------------
+func As[T any](val any) T {
+    res, _ := val.(T)
+    return res
+}
+
+// Assignments that are used as values
+// -----------------------------------
+func Set[T any](ptr *T, val T) T {
+    *ptr = val
+    return val
+}
+
+// Number literals (keeps go from constant folding them)
+// ------------------------------------------------------
+func Num[T int64 | int32 | int16 | int8 | float64 | float32](val T) T {
+    return val
+}
+
+// Floats to integers (clamped, same as in the vm)
+// -----------------------------------------------
+func FloatToInt[T int64 | int32 | int16 | int8](val float64) T {
+    return evalobjects.FloatToInt[T](val)
+}
+
+// Integer division (go would panic with its own message)
+// ------------------------------------------------------
+func Div[T int64 | int32 | int16 | int8](left T, right T, at int) T {
+    if right == 0 {
+        Fail(at, "Division by zero!")
+    }
+
+    return left / right
+}
+
+func Mod[T int64 | int32 | int16 | int8](left T, right T, at int) T {
+    if right == 0 {
+        Fail(at, "Modulo by zero!")
+    }
+
+    return left % right
+}
+
+// Shifts (negative amounts make go panic as well)
+// -----------------------------------------------
+func Shl[T int64 | int32 | int16 | int8](left T, right T, at int) T {
+    if right < 0 {
+        Fail(at, "Cannot shift by a negative amount! (%v)", right)
+    }
+
+    return left << right
+}
+
+func Shr[T int64 | int32 | int16 | int8](left T, right T, at int) T {
+    if right < 0 {
+        Fail(at, "Cannot shift by a negative amount! (%v)", right)
+    }
+
+    return left >> right
+}
+
+// Logic operators evaluate both sides (just like the vm does)
+// -----------------------------------------------------------
+func And(left bool, right bool) bool {
+    return left && right
+}
+
+func Or(left bool, right bool) bool {
+    return left || right
+}
+
+// Move an iterator one step closer to its target
+// ----------------------------------------------
+func Approach(iter int32, target int32) int32 {
+    if iter < target {
+        return iter + 1
+    }
+
+    if iter > target {
+        return iter - 1
+    }
+
+    return iter
+}
+
+// Cast a value using the same rules as the vm
+// -------------------------------------------
+func Convert[T any](val any, typ *symbols.TypeSymbol, at int) T {
+    At(at)
+
+    res, ok := evalobjects.EvalConversion(val, typ)
+    if !ok {
+        // null has no go type to show
+        if val == nil {
+            Fail(at, "Unable to cast null to %s!", typ.Name())
+        }
+
+        // provide some more helpful error messages for containers
+        if cnt, ok := val.(evalobjects.TypedInstance); ok {
+            Fail(at, "Unable to cast container instance of type %s to %s!", cnt.InstanceType().Name(), typ.Name())
+        }
+
+        // ...and enums
+        if typ.TypeGroup == symbols.ENUM {
+            Fail(at, "Value %v is not a member of enum %s!", val, typ.Name())
+        }
+
+        Fail(at, "Unable to cast %s to %s!", reflect.TypeOf(val), typ.Name())
+    }
+
+    return As[T](res)
+}
+
+// Check if a value is an instance of a type
+// -----------------------------------------
+func Is(val any, typ *symbols.TypeSymbol) bool {
+    return evalobjects.EvalTypeCheck(val, typ)
+}
+
+// The value if it is an instance of a type (null otherwise)
+// ---------------------------------------------------------
+func CheckedCast[T any](val any, typ *symbols.TypeSymbol) T {
+    if !evalobjects.EvalTypeCheck(val, typ) {
+        var null T
+        return null
+    }
+
+    return As[T](val)
+}
+
+// The name of an enum member
+// --------------------------
+func EnumName(val int32, typ *symbols.TypeSymbol, at int) string {
+    At(at)
+
+    name, ok := evalobjects.EnumName(val, typ)
+    if !ok {
+        Fail(at, "Value %v is not a member of enum %s!", val, typ.Name())
+    }
+
+    return name
+}
+
+// --------------------------------------------------------
+// Arrays
+// --------------------------------------------------------
+func EmptyArray(typ *symbols.TypeSymbol) *evalobjects.ArrayInstance {
+    return &evalobjects.ArrayInstance{
+        Type: typ,
+        Elements: make([]interface{}, 0),
+    }
+}
+
+func MakeArray(typ *symbols.TypeSymbol, length int32) *evalobjects.ArrayInstance {
+    arr := EmptyArray(typ)
+
+    // fill the array with default values
+    for i := int32(0); i < length; i++ {
+        arr.Elements = append(arr.Elements, Default(typ.SubTypes[0]))
+    }
+
+    return arr
+}
+
+func ArrayFrom(typ *symbols.TypeSymbol, elements ...any) *evalobjects.ArrayInstance {
+    return &evalobjects.ArrayInstance{
+        Type: typ,
+        Elements: elements,
+    }
+}
+
+func Index(arr *evalobjects.ArrayInstance, idx int32, at int) any {
+    checkBounds(arr, idx, at)
+    return arr.Elements[idx]
+}
+
+// (the value comes first, the vm evaluates it before the array)
+func StoreIndex[T any](val T, arr *evalobjects.ArrayInstance, idx int32, at int) T {
+    checkBounds(arr, idx, at)
+    arr.Elements[idx] = val
+
+    return val
+}
+
+func checkBounds(arr *evalobjects.ArrayInstance, idx int32, at int) {
+    if idx < 0 || idx >= int32(len(arr.Elements)) {
+        Fail(at, "Index out of bounds! (index: %d, length of array: %d)", idx, len(arr.Elements))
+    }
+}
+
+// --------------------------------------------------------
+// Maps
+// --------------------------------------------------------
+func EmptyMap(typ *symbols.TypeSymbol) *evalobjects.MapInstance {
+    return evalobjects.NewMapInstance(typ)
+}
+
+// (keys and values take turns)
+func MapFrom(typ *symbols.TypeSymbol, entries ...any) *evalobjects.MapInstance {
+    mp := EmptyMap(typ)
+
+    for i := 0; i < len(entries); i += 2 {
+        mp.Set(entries[i], entries[i+1])
+    }
+
+    return mp
+}
+
+func Key(mp *evalobjects.MapInstance, key any, at int) any {
+    if !mp.Has(key) {
+        Fail(at, "Key '%v' does not exist in map of type '%s'!", key, mp.Type.Name())
+    }
+
+    return mp.Elements[key]
+}
+
+// (the value comes first, the vm evaluates it before the map)
+func StoreKey[T any](val T, mp *evalobjects.MapInstance, key any, at int) T {
+    mp.Set(key, val)
+    return val
+}
+
+// The default value of a type (as the vm would see it)
+// ----------------------------------------------------
+func Default(typ *symbols.TypeSymbol) any {
+    // Arrays need some special care because theyre reference types
+    if typ.TypeGroup == symbols.ARR {
+        return EmptyArray(typ)
+    }
+
+    if typ.TypeGroup == symbols.MAP {
+        return EmptyMap(typ)
+    }
+
+    // otherwise: return the predefined default
+    return typ.Default
+}
+
+// The default of a generic field for one specific instance
+// (T Array in a Box[int] -> int Array)
+func GenericDefault(fld *symbols.TypeSymbol, base *symbols.TypeSymbol, typ *symbols.TypeSymbol) any {
+    return Default(symbols.Substitute(fld, base.SubTypes, typ.SubTypes))
+}
+
+// --------------------------------------------------------
+// Natives
+// --------------------------------------------------------
+
+// Create an instance of a container from a native package
+// -------------------------------------------------------
+func MakeNative(typ *symbols.TypeSymbol) *evalobjects.ContainerInstance {
+    inst := &evalobjects.ContainerInstance{
+        Type: typ,
+        Fields: make(map[string]interface{}),
+    }
+
+    // create all fields
+    for _, v := range typ.Container.Fields {
+        inst.Fields[v.FieldName] = Default(v.FieldType)
+    }
+
+    return inst
+}
+
+// Assign a field of a native container (and hand back the value)
+// ---------------------------------------------------------------
+func SetField(inst *evalobjects.ContainerInstance, name string, val any) any {
+    inst.Fields[name] = val
+    return val
+}
+
+// Call a native function
+// ----------------------
+func Call(at int, fnc symbols.VMFPtr, args []any) any {
+    At(at)
+    return fnc(args)
+}
+
+// Call a native method
+// --------------------
+func CallMethod(at int, fnc symbols.VMMPtr, instance any, args []any) any {
+    At(at)
+
+    // if this is null -> we're doomed
+    if instance == nil {
+        Fail(at, "Cannot call method on null! (I am literally calling the police rn)")
+    }
+
+    return fnc(instance, args)
+}
+
+// --------------------------------------------------------
+// Function values
+// --------------------------------------------------------
+
+// A variable some lambda holds on to
+// ----------------------------------
+type Cell[T any] struct {
+    V T
+}
+
+// Create a function value (invoke is the trampoline of the function)
+// -------------------------------------------------------------------
+func Closure(typ *symbols.TypeSymbol, invoke func(fn *evalobjects.FunctionInstance, args []any) any, captures ...any) *evalobjects.FunctionInstance {
+    return &evalobjects.FunctionInstance{
+        Type: typ,
+        Captures: captures,
+        Invoke: invoke,
+    }
+}
+
+// Same thing for natives
+// ----------------------
+func Native(typ *symbols.TypeSymbol, fnc symbols.VMFPtr) *evalobjects.FunctionInstance {
+    return &evalobjects.FunctionInstance{
+        Type: typ,
+        Invoke: func(fn *evalobjects.FunctionInstance, args []any) any {
+            return fnc(args)
+        },
+    }
+}
+
+// Call a function value
+// ---------------------
+func CallValue(at int, fn *evalobjects.FunctionInstance, args ...any) any {
+    // if this is null -> we're doomed
+    if fn == nil {
+        Fail(at, "Cannot call a null function! (I am literally calling the police rn)")
+    }
+
+    At(at)
+    return fn.Invoke(fn, args)
+}
@@ -0,0 +1,383 @@
+// RT - rt.go
+// --------------------------------------------------------
+// The (very small) runtime every generated go program links
+// against: call frames, errors and the entry point
+// --------------------------------------------------------
+package rt
+
+import (
+	"fmt"
+	"strings"
+
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/error"
+	evalobjects "bytespace.network/rerect/eval_objects"
+	gopackages "bytespace.network/rerect/go_packages"
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+)
+
+// How deep calls can nest before we call it a stack overflow (same as the vm)
+const maxFrames = 100000
+
+// How many frames of a (long) stack trace are worth showing
+const traceHead = 32  // innermost
+const traceTail = 8   // outermost
+
+// One function on the call stack and where it was at
+// --------------------------------------------------
+type Frame struct {
+    Function string
+    Position int        // index into the span table
+}
+
+// Exception
+// ---------
+// A ReRect Error on its way up the call stack
+type Exception struct {
+    Instance *evalobjects.ContainerInstance // the Error instance (what catch gets to see)
+    Error error.Error                       // what gets printed if nobody catches it
+}
+
+// The state of the running program
+var sources []compunit.SourceFile
+var spans []span.Span
+var stack []Frame
+
+// The native packages (only needed for their containers)
+var natives *compunit.Compilation
+
+// --------------------------------------------------------
+// Startup
+// --------------------------------------------------------
+
+// Tell the runtime where everything in the program came from
+// -----------------------------------------------------------
+func Init(srcs []compunit.SourceFile, spns []span.Span) {
+    sources = srcs
+    spans = spns
+    stack = make([]Frame, 0, 256)
+}
+
+// Run the entry point and hand back the exit code
+// -----------------------------------------------
+func Run(main func()) (code int) {
+    // runtime errors and die() unwind everything up to here
+    defer func() {
+        r := recover()
+        if r == nil {
+            return
+        }
+
+        if exit, ok := r.(evalobjects.ExitSignal); ok {
+            code = exit.Code
+            return
+        }
+
+        // errors nobody caught (and whatever blew up in go land)
+        error.Print(toException(r).Error, lookupSource)
+        code = -1
+    }()
+
+    main()
+    return 0
+}
+
+func lookupSource(file int) (string, string) {
+    src := sources[file]
+    return src.Path, src.Content
+}
+
+// Load the native packages once someone needs them
+// ------------------------------------------------
+func nativePackages() *compunit.Compilation {
+    if natives == nil {
+        natives = compunit.NewCompilation()
+        gopackages.Load(natives)
+    }
+
+    return natives
+}
+
+// --------------------------------------------------------
+// Calls
+// --------------------------------------------------------
+
+// Create a frame for a function (at is where the caller is calling from)
+// ----------------------------------------------------------------------
+func Enter(at int, name string) {
+    if len(stack) > 0 {
+        stack[len(stack)-1].Position = at
+    }
+
+    // go would happily keep going for quite a while, the vm doesnt
+    if len(stack) >= maxFrames {
+        Fail(at, "Stack overflow! (more than %d nested calls)", maxFrames)
+    }
+
+    stack = append(stack, Frame{
+        Function: name,
+    })
+}
+
+// Destroy the frame of the current function
+// -----------------------------------------
+func Leave() {
+    stack = stack[:len(stack)-1]
+}
+
+// Leave with a value (its evaluated before the frame is gone)
+// ------------------------------------------------------------
+func Return[T any](val T) T {
+    Leave()
+    return val
+}
+
+// How many frames are there right now?
+// ------------------------------------
+func Depth() int {
+    return len(stack)
+}
+
+// Where is the current function at?
+// (function values dont get told where they're called from)
+// ----------------------------------------------------------
+func Here() int {
+    return stack[len(stack)-1].Position
+}
+
+// Remember where the current function is at
+// -----------------------------------------
+func At(at int) {
+    stack[len(stack)-1].Position = at
+}
+
+// --------------------------------------------------------
+// Errors
+// --------------------------------------------------------
+
+// Stop execution with a runtime error
+// -----------------------------------
+func Fail(at int, msg string, prm ...any) {
+    At(at)
+    panic(error.NewError(error.RNT, spans[at], msg, prm...))
+}
+
+// Throw a ReRect Error
+// --------------------
+func Throw(val any, at int) {
+    if val == nil {
+        Fail(at, "Cannot throw null! (I am literally calling the police rn)")
+    }
+
+    At(at)
+
+    inst := val.(*evalobjects.ContainerInstance)
+    msg := inst.Fields["Message"].(string)
+
+    // remember where this was thrown
+    err := createRuntimeError(error.NewError(error.RNT, spans[at], "Uncaught Error: %s", msg))
+    inst.Fields["StackTrace"] = formatTrace(err)
+
+    panic(&Exception{
+        Instance: inst,
+        Error: err,
+    })
+}
+
+// Turn whatever was recovered into an exception
+// (die() is not an error, nobody gets to catch that)
+// --------------------------------------------------
+func Recover(r any) *Exception {
+    if _, ok := r.(evalobjects.ExitSignal); ok {
+        panic(r)
+    }
+
+    return toException(r)
+}
+
+// Throw away everything that was called from inside a protected region
+// ---------------------------------------------------------------------
+func Unwind(depth int) {
+    stack = stack[:depth]
+}
+
+func toException(r any) *Exception {
+    // already is one
+    if exc, ok := r.(*Exception); ok {
+        return exc
+    }
+
+    // runtime errors (from the runtime or from natives)
+    var err error.Error
+    if e, ok := r.(error.Error); ok {
+        err = createRuntimeError(e)
+
+    // something in go land blew up (bad conversion, nil pointer, ...)
+    } else {
+        err = createRuntimeError(error.NewError(error.RNT, span.Internal(), "%v", r))
+    }
+
+    return &Exception{
+        Instance: newError(err.Message, formatTrace(err)),
+        Error: err,
+    }
+}
+
+// Attach the current call stack to an error
+// -----------------------------------------
+func createRuntimeError(err error.Error) error.Error {
+    // errors without a position happened wherever we were last
+    if err.Position.Internal && len(stack) > 0 {
+        err.Position = spans[stack[len(stack)-1].Position]
+    }
+
+    // build a printable trace (innermost call first)
+    err.Trace = []error.TraceFrame{}
+    for i := len(stack)-1; i >= 0; i-- {
+        // nobody wants to read a hundred thousand frames of recursion
+        // -> only keep the innermost and outermost ones
+        skipped := len(stack) - traceHead - traceTail
+        if skipped > 0 && i == len(stack) - traceHead - 1 {
+            err.Trace = append(err.Trace, error.TraceFrame{
+                Function: fmt.Sprintf("... (%d more calls)", skipped),
+                Position: span.Internal(),
+            })
+
+            i -= skipped - 1
+            continue
+        }
+
+        err.Trace = append(err.Trace, error.TraceFrame{
+            Function: stack[i].Function,
+            Position: spans[stack[i].Position],
+        })
+    }
+
+    return err
+}
+
+// Create a new instance of the internal Error container
+// -----------------------------------------------------
+func newError(msg string, trace string) *evalobjects.ContainerInstance {
+    inst := MakeNative(NativeType("internal", "Error"))
+    inst.Fields["Message"] = msg
+    inst.Fields["StackTrace"] = trace
+
+    return inst
+}
+
+// Format a stack trace for humans
+// -------------------------------
+func formatTrace(err error.Error) string {
+    lines := []string{}
+
+    for _, frm := range err.Trace {
+        if frm.Position.Internal {
+            lines = append(lines, fmt.Sprintf("at %s", frm.Function))
+            continue
+        }
+
+        path, content := lookupSource(frm.Position.File)
+        line, col := frm.Position.GetLineAndCol(content)
+
+        lines = append(lines, fmt.Sprintf("at %s (%s, L:%d, C:%d)", frm.Function, path, line, col))
+    }
+
+    return strings.Join(lines, "\n")
+}
+
+// --------------------------------------------------------
+// Types
+// --------------------------------------------------------
+
+// The type of an array of something
+// ---------------------------------
+func ArrayType(sub *symbols.TypeSymbol) *symbols.TypeSymbol {
+    return symbols.NewTypeSymbol(sub.Name() + " Array", []*symbols.TypeSymbol{sub}, symbols.ARR, 0, nil)
+}
+
+// The type of a map from something to something
+// ----------------------------------------------
+func MapType(key *symbols.TypeSymbol, val *symbols.TypeSymbol) *symbols.TypeSymbol {
+    return symbols.NewTypeSymbol(key.Name() + " " + val.Name() + " Map", []*symbols.TypeSymbol{key, val}, symbols.MAP, 0, nil)
+}
+
+// The type of a function value
+// -----------------------------
+func FunctionType(ret *symbols.TypeSymbol, prms ...*symbols.TypeSymbol) *symbols.TypeSymbol {
+    return symbols.NewFunctionType(prms, ret)
+}
+
+// The type of a trait (and its type parameters)
+// ----------------------------------------------
+func TraitType(name string, prms ...*symbols.TypeSymbol) *symbols.TypeSymbol {
+    typ := symbols.NewTypeSymbol(name, prms, symbols.TRT, 0, nil)
+    symbols.NewTraitSymbol(nil, name, typ)
+
+    return typ
+}
+
+// The type of a container (and its type parameters)
+// --------------------------------------------------
+func ContainerType(name string, prms ...*symbols.TypeSymbol) *symbols.TypeSymbol {
+    typ := symbols.NewTypeSymbol(name, prms, symbols.CONT, 0, nil)
+    symbols.NewContainerSymbol(nil, name, typ)
+
+    return typ
+}
+
+// Tell a container which traits it implements
+// (with whatever type arguments it gave them)
+// --------------------------------------------
+func Implements(typ *symbols.TypeSymbol, traits ...*symbols.TypeSymbol) {
+    cnt := typ.Container
+
+    for _, v := range traits {
+        cnt.Traits = append(cnt.Traits, v.Trait)
+        cnt.TraitTypes = append(cnt.TraitTypes, v)
+    }
+}
+
+// The type of an enum (and all of its members)
+// --------------------------------------------
+func EnumType(name string, names []string, values []int32) *symbols.TypeSymbol {
+    typ := symbols.NewTypeSymbol(name, []*symbols.TypeSymbol{}, symbols.ENUM, 32, int32(0))
+    enm := symbols.NewEnumSymbol(nil, name, typ)
+
+    for i, v := range names {
+        enm.AddMember(v, values[i], "")
+    }
+
+    return typ
+}
+
+// A type parameter of a generic container, trait or function
+// -----------------------------------------------------------
+func TypeParameter(name string) *symbols.TypeSymbol {
+    return symbols.NewTypeSymbol(name, []*symbols.TypeSymbol{}, symbols.TPRM, 0, nil)
+}
+
+// A generic container or trait with its type arguments filled in
+// ---------------------------------------------------------------
+func Instance(base *symbols.TypeSymbol, args ...*symbols.TypeSymbol) *symbols.TypeSymbol {
+    return symbols.NewInstanceType(base, args)
+}
+
+// A container or trait that is allowed to be null (Foo?)
+// -----------------------------------------------------
+func Nullable(typ *symbols.TypeSymbol) *symbols.TypeSymbol {
+    return symbols.NewNullableType(typ)
+}
+
+// The type of a container living in a native package
+// ---------------------------------------------------
+func NativeType(pck string, name string) *symbols.TypeSymbol {
+    for _, v := range nativePackages().GetPackage(pck).Containers {
+        if v.ContainerName == name {
+            return v.ContainerType
+        }
+    }
+
+    panic(fmt.Sprintf("Could not find native container '%s::%s'!", pck, name))
+}
@@ -1,208 +1,213 @@
 package compctl
 
 import (
-	"fmt"
-	"slices"
-
 	"bytespace.network/rerect/binder"
-	"bytespace.network/rerect/boundnodes"
+	"bytespace.network/rerect/bytecode"
 	"bytespace.network/rerect/compunit"
-	"bytespace.network/rerect/error"
 	"bytespace.network/rerect/lexer"
 	"bytespace.network/rerect/lowerer"
-	"bytespace.network/rerect/package_processor"
+	packageprocessor "bytespace.network/rerect/package_processor"
 	"bytespace.network/rerect/parser"
-	"bytespace.network/rerect/symbols"
 	"bytespace.network/rerect/syntaxnodes"
 )
 
+// Compilation stages
+// ------------------
+type CompilationStage int
+const (
+    STG_Lex   CompilationStage = 1 // only lex the source files
+    STG_Parse CompilationStage = 2 // lex and parse
+    STG_Bind  CompilationStage = 3 // everything up until the bound trees
+    STG_Lower CompilationStage = 4 // everything up until the lowered trees
+    STG_Emit  CompilationStage = 5 // everything (the full compilation, down to bytecode)
+)
+
 type CompilationResult struct {
     Ok bool
-    Functions map[*symbols.FunctionSymbol]*boundnodes.BoundBlockStatementNode
-    Globals []*symbols.GlobalSymbol
+    Comp *compunit.Compilation
+
+    // intermediate results of each stage
+    Tokens [][]lexer.Token
+    Members [][]syntaxnodes.MemberNode
+    Files []*packageprocessor.CompilationFile
+
+    // final result
+    Program *bytecode.Program
 }
 
-func compFailed() *CompilationResult {
-    return &CompilationResult{
-        Ok: false,
-    }
+func compFailed(res *CompilationResult) *CompilationResult {
+    res.Comp.OutputErrors()
+
+    res.Ok = false
+    return res
 }
 
-const DBG = false
+// Run the full compilation
+// ------------------------
+func Compile(comp *compunit.Compilation, srcFiles []string) *CompilationResult {
+    return CompileUntil(comp, srcFiles, STG_Emit)
+}
+
+// Run the compilation up until (and including) the given stage
+// ------------------------------------------------------------
+func CompileUntil(comp *compunit.Compilation, srcFiles []string, stage CompilationStage) *CompilationResult {
+    res := &CompilationResult{
+        Ok: true,
+        Comp: comp,
+    }
 
-func Compile(srcFiles []string) *CompilationResult {
     // Lexing
     // ------
-    fileTokens := [][]lexer.Token{}
-
     for _, file := range srcFiles {
-        tokens := lexer.LexFile(file)
-
-        if DBG {
-            fmt.Println("\nLexer output:")
-            for _, v := range tokens {
-                fmt.Printf("%s, %s, '%s'\n", v.Type, v.Position.Format(), v.Buffer)
-            }
-        }
-
-        fileTokens = append(fileTokens, tokens)
+        tokens := lexer.LexFile(comp, file)
+        res.Tokens = append(res.Tokens, tokens)
     }
 
     // if there are errors -> output them and stop execution
-    if error.HasErrors() {
-        error.Output()
-        return compFailed()
+    if comp.HasErrors() {
+        return compFailed(res)
+    }
+
+    if stage == STG_Lex {
+        return res
     }
 
     // Parsing
     // -------
-    fileMembers := [][]syntaxnodes.MemberNode{}
-
-    for _, tokens := range fileTokens {
-        members := parser.Parse(tokens)
-
-        if DBG {
-            fmt.Println("\nParser output:")
-            for _, v := range members {
-                fmt.Printf("%s\n", v.Type())
-            }
-        }
-
-        fileMembers = append(fileMembers, members)
+    for _, tokens := range res.Tokens {
+        members := parser.Parse(comp, tokens)
+        res.Members = append(res.Members, members)
     }
 
     // if there are errors -> output them and stop execution
-    if error.HasErrors() {
-        error.Output()
-        return compFailed()
+    if comp.HasErrors() {
+        return compFailed(res)
+    }
+
+    if stage == STG_Parse {
+        return res
     }
 
     // Package processing
     // ------------------
-    packageprocessor.Init()
-    files := packageprocessor.Process(fileMembers)
+    packageprocessor.Init(comp)
+    files := packageprocessor.Process(comp, res.Members)
+    res.Files = files
 
-    if DBG {
-        fmt.Println("\nPackage processor output:")
-        for _, v := range compunit.PackagesRegister {
-            fmt.Printf("Package: %s\n", v.PackName)
-            fmt.Println(" Loads:")
+    // if there are errors -> output them and stop execution
+    if comp.HasErrors() {
+        return compFailed(res)
+    }
 
-            for k, _ := range v.LoadedPackages {
-                fmt.Printf("  - %s ", k)
+    // Binding
+    // -------
 
-                if slices.Contains(v.IncludedPackages, k) {
-                    fmt.Println("(included)")
-                } else {
-                    fmt.Println()
-                }
-            }
-        }
+    // The very Firstest: Index all enums (they dont depend on anything, but everything else can use them)
+    for _, file := range files {
+        binder.IndexEnums(comp, file)
     }
 
     // if there are errors -> output them and stop execution
-    if error.HasErrors() {
-        error.Output()
-        return compFailed()
+    if comp.HasErrors() {
+        return compFailed(res)
     }
 
-    // Binding
-    // -------
-
     // Event EVEN Firsterer: Index all trait datatypes (this NEEDS to be done before containers!!! otherwise the container cant look up what traits its based on)
     for _, file := range files {
-        binder.IndexTraitTypes(file)
+        binder.IndexTraitTypes(comp, file)
     }
 
     // if there are errors -> output them and stop execution
-    if error.HasErrors() {
-        error.Output()
-        return compFailed()
+    if comp.HasErrors() {
+        return compFailed(res)
     }
 
     // Even Firsterer: Index all container datatypes (this NEEDS to be done first!!!! otherwise stuff cant be linked correctly!)
     for _, file := range files {
-        binder.IndexContainerTypes(file)
+        binder.IndexContainerTypes(comp, file)
     }
 
     // if there are errors -> output them and stop execution
-    if error.HasErrors() {
-        error.Output()
-        return compFailed()
+    if comp.HasErrors() {
+        return compFailed(res)
     }
 
     // A slight bit Firsterer: Index all trait fields and methods
     for _, file := range files {
-        binder.IndexTraitContents(file)
+        binder.IndexTraitContents(comp, file)
     }
 
     // if there are errors -> output them and stop execution
-    if error.HasErrors() {
-        error.Output()
-        return compFailed()
+    if comp.HasErrors() {
+        return compFailed(res)
     }
 
     // A little Firster: Index all container fields and methods
     for _, file := range files {
-        binder.IndexContainerContents(file)
+        binder.IndexContainerContents(comp, file)
     }
 
     // if there are errors -> output them and stop execution
-    if error.HasErrors() {
-        error.Output()
-        return compFailed()
+    if comp.HasErrors() {
+        return compFailed(res)
     }
 
     // First: Index all functions and globals
     for _, file := range files {
-        binder.IndexFunctions(file)
-        binder.IndexGlobals(file)
+        binder.IndexFunctions(comp, file)
+        binder.IndexGlobals(comp, file)
     }
 
     // if there are errors -> output them and stop execution
-    if error.HasErrors() {
-        error.Output()
-        return compFailed()
+    if comp.HasErrors() {
+        return compFailed(res)
+    }
+
+    // Almost second: figure out the values of all constants
+    for _, file := range files {
+        binder.BindConstants(comp, file)
+    }
+
+    // if there are errors -> output them and stop execution
+    if comp.HasErrors() {
+        return compFailed(res)
     }
 
     // Second: bind all function bodies
     for _, file := range files {
-        binder.BindFunctions(file)
+        binder.BindFunctions(comp, file)
     }
 
     // if there are errors -> output them and stop execution
-    if error.HasErrors() {
-        error.Output()
-        return compFailed()
+    if comp.HasErrors() {
+        return compFailed(res)
+    }
+
+    if stage == STG_Bind {
+        return res
     }
 
     // Lowering
     // --------
     for _, file := range files {
-        lowerer.Lower(file)
+        lowerer.Lower(comp, file)
     }
 
-    if error.HasErrors() {
-        error.Output()
-        return compFailed()
+    if comp.HasErrors() {
+        return compFailed(res)
     }
 
-    // Bring the compilation result into a usable format
-    // -------------------------------------------------
-    res := &CompilationResult{
-        Ok: true,
-        Functions: make(map[*symbols.FunctionSymbol]*boundnodes.BoundBlockStatementNode),
-        Globals: make([]*symbols.GlobalSymbol, 0),
+    if stage == STG_Lower {
+        return res
     }
 
-    for _, file := range files {
-        for k, v := range file.FunctionBodies {
-            res.Functions[k] = v.(*boundnodes.BoundBlockStatementNode)
-        }
+    // Bytecode
+    // --------
+    res.Program = bytecode.NewProgram()
+    bytecode.Compile(comp, res.Program, files)
 
-        for _, v := range file.Globals {
-            res.Globals = append(res.Globals, v)
-        }
+    if comp.HasErrors() {
+        return compFailed(res)
     }
 
     return res
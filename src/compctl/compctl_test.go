@@ -0,0 +1,127 @@
+package compctl_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"bytespace.network/rerect/compctl"
+	"bytespace.network/rerect/compunit"
+)
+
+// two programs in the same package, that share nothing but their names
+var programs = map[string]string{
+    "apple": `package main;
+
+function main() {
+    var f <- function(x int) int: x + 1;
+    Apple(f(1));
+}
+
+function Apple(x int) {}
+
+container Basket {
+    Apples int;
+}
+`,
+    "banana": `package main;
+
+function main() {
+    var f <- function(x int) int: x - 1;
+    Banana(f(1));
+}
+
+function Banana(x int) {}
+
+container Bunch {
+    Bananas int;
+}
+`,
+}
+
+// everything each program should end up with (and nothing else)
+var expected = map[string][]string{
+    "apple":  {"main", "Apple", "Basket"},
+    "banana": {"main", "Banana", "Bunch"},
+}
+
+func writePrograms(t *testing.T) map[string]string {
+    dir := t.TempDir()
+    files := make(map[string]string)
+
+    for name, src := range programs {
+        files[name] = filepath.Join(dir, name + ".rr")
+        if err := os.WriteFile(files[name], []byte(src), 0644); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    return files
+}
+
+// names of all functions and containers in package main
+func mainSymbols(comp *compunit.Compilation) []string {
+    pck := comp.Packages["main"]
+    if pck == nil {
+        return nil
+    }
+
+    names := []string{}
+    for _, v := range pck.Functions {
+        names = append(names, v.FuncName)
+    }
+
+    for _, v := range pck.Containers {
+        names = append(names, v.ContainerName)
+    }
+
+    return names
+}
+
+// compile a program and make sure nothing of the other one is in there
+func checkProgram(t *testing.T, name string, file string) {
+    res := compctl.Compile(compunit.NewCompilation(), []string{file})
+    if !res.Ok {
+        t.Errorf("%s did not compile: %v", name, res.Comp.Errors)
+        return
+    }
+
+    got := mainSymbols(res.Comp)
+    if !slices.Equal(got, expected[name]) {
+        t.Errorf("%s should have exactly %v, got %v", name, expected[name], got)
+    }
+
+    // the lambda counter lives in the package too (so both get to be __lambda1)
+    if cnt := res.Comp.Packages["main"].LambdaCount; cnt != 1 {
+        t.Errorf("%s should have counted 1 lambda, got %d", name, cnt)
+    }
+}
+
+func TestSequentialCompilations(t *testing.T) {
+    files := writePrograms(t)
+
+    for i := 0; i < 3; i++ {
+        checkProgram(t, "apple", files["apple"])
+        checkProgram(t, "banana", files["banana"])
+    }
+}
+
+// run this one with -race to make sure nothing is shared between compilations
+func TestConcurrentCompilations(t *testing.T) {
+    files := writePrograms(t)
+
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i++ {
+        for name, file := range files {
+            wg.Add(1)
+            go func(name string, file string) {
+                defer wg.Done()
+                checkProgram(t, name, file)
+            }(name, file)
+        }
+    }
+
+    wg.Wait()
+}
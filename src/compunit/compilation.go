@@ -0,0 +1,120 @@
+// CompUnit - compilation.go
+// ---------------------------------------------------------
+// Everything one compilation knows about lives in here,
+// so any number of them can exist next to each other
+// ---------------------------------------------------------
+package compunit
+
+import (
+	"bytespace.network/rerect/error"
+	"bytespace.network/rerect/symbols"
+)
+
+// Compilation struct
+// ------------------
+type Compilation struct {
+    SourceFiles []SourceFile                       // all source files read by this compilation
+    Packages map[string]*symbols.PackageSymbol     // all known packages
+    Errors []error.Error                           // all reported errors
+
+    LabelCounter int                               // used by the lowerer to generate unique labels
+}
+
+func NewCompilation() *Compilation {
+    return &Compilation{
+        SourceFiles: make([]SourceFile, 0),
+        Packages: make(map[string]*symbols.PackageSymbol),
+        Errors: make([]error.Error, 0),
+    }
+}
+
+// --------------------------------------------------------
+// Source files
+// --------------------------------------------------------
+func (comp *Compilation) RegisterSource(file string, content string) int {
+    comp.SourceFiles = append(comp.SourceFiles, SourceFile{
+        Path: file,
+        Content: content,
+    })
+
+    return len(comp.SourceFiles) - 1
+}
+
+// --------------------------------------------------------
+// Packages
+// --------------------------------------------------------
+func (comp *Compilation) GetPackage(name string) *symbols.PackageSymbol {
+    pck, ok := comp.Packages[name]
+
+    if !ok {
+        return nil
+    }
+
+    return pck
+}
+
+func (comp *Compilation) CreatePackage(name string) *symbols.PackageSymbol {
+    _, ok := comp.Packages[name]
+
+    if ok {
+        return nil
+    }
+
+    comp.Packages[name] = symbols.NewPackageSymbol(name, make([]*symbols.FunctionSymbol, 0))
+    return comp.Packages[name]
+}
+
+func (comp *Compilation) GetPackageAtAllCosts(name string) *symbols.PackageSymbol {
+    pck := comp.GetPackage(name)
+
+    if pck != nil {
+        return pck
+    }
+
+    return comp.CreatePackage(name)
+}
+
+// --------------------------------------------------------
+// Errors
+// --------------------------------------------------------
+
+// Add an error to the collection
+// ------------------------------
+func (comp *Compilation) Report(err error.Error) {
+    comp.Errors = append(comp.Errors, err)
+}
+
+// Are there errors?
+// -----------------
+func (comp *Compilation) HasErrors() bool {
+    return len(comp.Errors) > 0
+}
+
+// Forget all reported errors
+// --------------------------
+func (comp *Compilation) ClearErrors() {
+    comp.Errors = make([]error.Error, 0)
+}
+
+// Output all reported errors
+// --------------------------
+func (comp *Compilation) OutputErrors() {
+    for _, err := range comp.Errors {
+        comp.OutputError(err)
+    }
+}
+
+// Output a single error (doesnt need to be reported)
+// --------------------------------------------------
+func (comp *Compilation) OutputError(err error.Error) {
+    error.Print(err, comp.lookupSource)
+}
+
+func (comp *Compilation) lookupSource(file int) (string, string) {
+    if file < 0 || file >= len(comp.SourceFiles) {
+        return "<unknown>", ""
+    }
+
+    src := comp.SourceFiles[file]
+    return src.Path, src.Content
+}
@@ -0,0 +1,400 @@
+// Docgen - docgen.go
+// --------------------------------------------------------
+// Turns everything the binder knows about a package into
+// readable documentation pages (markdown or html)
+// --------------------------------------------------------
+package docgen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/error"
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+)
+
+// A single generated file
+// -----------------------
+type Page struct {
+    File string
+    Content string
+}
+
+// Generate one page per known package (plus an index)
+// ---------------------------------------------------
+func Generate(comp *compunit.Compilation, html bool) []Page {
+    // keep the order stable
+    names := []string{}
+    for name := range comp.Packages {
+        names = append(names, name)
+    }
+
+    sort.Strings(names)
+
+    pages := []Page{}
+
+    // the index just links to all packages
+    idx := newWriter(html)
+    idx.Heading(1, "Packages")
+
+    items := []string{}
+    for _, name := range names {
+        items = append(items, idx.Link(name, name + idx.Extension()))
+    }
+
+    idx.List(items)
+    pages = append(pages, Page{"index" + idx.Extension(), idx.Finish("Packages")})
+
+    // and then every package gets its own page
+    for _, name := range names {
+        wrt := newWriter(html)
+        documentPackage(wrt, comp.Packages[name])
+        pages = append(pages, Page{name + wrt.Extension(), wrt.Finish(name)})
+    }
+
+    return pages
+}
+
+// Write all pages into the given directory
+// ----------------------------------------
+func Write(comp *compunit.Compilation, pages []Page, dir string) {
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        comp.Report(error.NewError(error.FIO, span.Internal(), "Unable to create output directory '%s'! (%s)", dir, err.Error()))
+        return
+    }
+
+    for _, page := range pages {
+        if err := os.WriteFile(filepath.Join(dir, page.File), []byte(page.Content), 0644); err != nil {
+            comp.Report(error.NewError(error.FIO, span.Internal(), "Unable to write '%s'! (%s)", page.File, err.Error()))
+            return
+        }
+    }
+}
+
+// --------------------------------------------------------
+// Packages
+// --------------------------------------------------------
+func documentPackage(wrt writer, pck *symbols.PackageSymbol) {
+    wrt.Heading(1, "Package " + pck.Name())
+
+    // Functions
+    // ---------
+    fncs := []*symbols.FunctionSymbol{}
+    for _, fnc := range pck.Functions {
+        if fnc.FunctionKind == symbols.FT_FUNC {
+            fncs = append(fncs, fnc)
+        }
+    }
+
+    if len(fncs) > 0 {
+        wrt.Heading(2, "Functions")
+
+        for _, fnc := range fncs {
+            wrt.Heading(3, fnc.FuncName)
+            wrt.Code(signature(fnc, nil, nil))
+            wrt.Text(fnc.Doc)
+        }
+    }
+
+    // Globals
+    // -------
+    if len(pck.Globals) > 0 {
+        wrt.Heading(2, "Globals")
+
+        for _, glb := range pck.Globals {
+            wrt.Heading(3, glb.GlobalName)
+            kw := "var"
+            if glb.IsConst {
+                kw = "const"
+            }
+
+            wrt.Code(fmt.Sprintf("%s %s %s", kw, glb.GlobalName, typeName(glb.GlobalType)))
+            wrt.Text(glb.Doc)
+        }
+    }
+
+    // Containers
+    // ----------
+    if len(pck.Containers) > 0 {
+        wrt.Heading(2, "Containers")
+
+        for _, cnt := range pck.Containers {
+            documentContainer(wrt, cnt)
+        }
+    }
+
+    // Traits
+    // ------
+    if len(pck.Traits) > 0 {
+        wrt.Heading(2, "Traits")
+
+        for _, trt := range pck.Traits {
+            documentTrait(wrt, trt)
+        }
+    }
+
+    // Enums
+    // -----
+    if len(pck.Enums) > 0 {
+        wrt.Heading(2, "Enums")
+
+        for _, enm := range pck.Enums {
+            documentEnum(wrt, enm)
+        }
+    }
+
+    // Methods on built in types
+    // (natives like Length() on arrays, these dont belong to any container or trait)
+    // ------------------------------------------------------------------------------
+    meths := []*symbols.FunctionSymbol{}
+    for _, fnc := range pck.Functions {
+        if fnc.FunctionKind == symbols.FT_METH && fnc.MethodSource.Container == nil && fnc.MethodSource.Trait == nil {
+            meths = append(meths, fnc)
+        }
+    }
+
+    if len(meths) > 0 {
+        wrt.Heading(2, "Methods")
+
+        for _, fnc := range meths {
+            wrt.Heading(3, fmt.Sprintf("%s->%s", typeGroupName(fnc), fnc.FuncName))
+            wrt.Code(signature(fnc, nil, nil))
+            wrt.Text(fnc.Doc)
+        }
+    }
+}
+
+func documentContainer(wrt writer, cnt *symbols.ContainerSymbol) {
+    wrt.Heading(3, cnt.ContainerName)
+
+    decl := "container " + cnt.ContainerName + typeParameters(cnt.TypeParameters)
+    if len(cnt.TraitTypes) > 0 {
+        trts := []string{}
+        for _, v := range cnt.TraitTypes {
+            trts = append(trts, typeName(v))
+        }
+
+        decl += fmt.Sprintf(" (%s)", strings.Join(trts, ", "))
+    }
+
+    wrt.Code(decl)
+    wrt.Text(cnt.Doc)
+
+    documentFields(wrt, cnt.Fields)
+
+    if cnt.Constructor != nil {
+        wrt.Heading(4, "Constructor")
+        wrt.Code(signature(cnt.Constructor, nil, nil))
+        wrt.Text(cnt.Constructor.Doc)
+    }
+
+    // the constructor was already listed above
+    meths := []*symbols.FunctionSymbol{}
+    for _, meth := range cnt.Methods {
+        if meth != cnt.Constructor {
+            meths = append(meths, meth)
+        }
+    }
+
+    if len(meths) > 0 {
+        wrt.Heading(4, "Methods")
+
+        for _, meth := range meths {
+            // methods of our own can just be printed
+            if meth.SourceTrait == nil {
+                wrt.Code(signature(meth, nil, nil))
+                wrt.Text(meth.Doc)
+                continue
+            }
+
+            // methods that came from a trait are written in terms of the traits type parameters
+            args := []*symbols.TypeSymbol{}
+            for i, trt := range cnt.Traits {
+                if trt == meth.SourceTrait {
+                    args = cnt.TraitTypes[i].SubTypes
+                }
+            }
+
+            wrt.Code(signature(meth, meth.SourceTrait.TypeParameters, args))
+            wrt.Text(strings.TrimSpace(fmt.Sprintf("(from trait %s)\n%s", meth.SourceTrait.TraitName, meth.Doc)))
+        }
+    }
+}
+
+func documentTrait(wrt writer, trt *symbols.TraitSymbol) {
+    wrt.Heading(3, trt.TraitName)
+    wrt.Code("trait " + trt.TraitName + typeParameters(trt.TypeParameters))
+    wrt.Text(trt.Doc)
+
+    documentFields(wrt, trt.Fields)
+
+    // declarations have to be implemented by every container,
+    // everything else comes for free
+    required := []*symbols.FunctionSymbol{}
+    provided := []*symbols.FunctionSymbol{}
+
+    for _, meth := range trt.Methods {
+        if meth.NeedsVirtualCallToContainer {
+            required = append(required, meth)
+        } else {
+            provided = append(provided, meth)
+        }
+    }
+
+    if len(required) > 0 {
+        wrt.Heading(4, "Required methods")
+
+        for _, meth := range required {
+            wrt.Code(signature(meth, nil, nil))
+            wrt.Text(meth.Doc)
+        }
+    }
+
+    if len(provided) > 0 {
+        wrt.Heading(4, "Provided methods")
+
+        for _, meth := range provided {
+            wrt.Code(signature(meth, nil, nil))
+            wrt.Text(meth.Doc)
+        }
+    }
+}
+
+func documentEnum(wrt writer, enm *symbols.EnumSymbol) {
+    wrt.Heading(3, enm.EnumName)
+    wrt.Code("enum " + enm.EnumName)
+    wrt.Text(enm.Doc)
+
+    wrt.Heading(4, "Members")
+
+    items := []string{}
+    for _, mem := range enm.Members {
+        item := wrt.Inline(fmt.Sprintf("%s <- %d", mem.MemberName, mem.Value))
+
+        if mem.Doc != "" {
+            item += " - " + wrt.Escape(strings.ReplaceAll(mem.Doc, "\n", " "))
+        }
+
+        items = append(items, item)
+    }
+
+    wrt.List(items)
+}
+
+func documentFields(wrt writer, fields []*symbols.FieldSymbol) {
+    if len(fields) == 0 {
+        return
+    }
+
+    wrt.Heading(4, "Fields")
+
+    items := []string{}
+    for _, fld := range fields {
+        decl := fmt.Sprintf("%s %s", fld.FieldName, typeName(fld.FieldType))
+        if fld.IsReadonly {
+            decl = "readonly " + decl
+        }
+
+        item := wrt.Inline(decl)
+
+        if fld.Doc != "" {
+            item += " - " + wrt.Escape(strings.ReplaceAll(fld.Doc, "\n", " "))
+        }
+
+        items = append(items, item)
+    }
+
+    wrt.List(items)
+}
+
+// --------------------------------------------------------
+// Helpers
+// --------------------------------------------------------
+
+// Format a function like it would be declared in source
+// (methods taken from generic traits get the containers type arguments filled in)
+// -------------------------------------------------------------------------------
+func signature(fnc *symbols.FunctionSymbol, prms []*symbols.TypeSymbol, args []*symbols.TypeSymbol) string {
+    params := []string{}
+    for _, v := range fnc.Parameters {
+        params = append(params, fmt.Sprintf("%s %s", v.Name(), typeName(symbols.Substitute(v.VarType(), prms, args))))
+    }
+
+    txt := fmt.Sprintf("function %s%s(%s)", fnc.FuncName, typeParameters(fnc.TypeParameters), strings.Join(params, " "))
+
+    // void functions dont spell out their return type
+    ret := symbols.Substitute(fnc.ReturnType, prms, args)
+    if ret.Name() != "void" {
+        txt += " " + typeName(ret)
+    }
+
+    return txt
+}
+
+// Format a type like it would be written in source
+// ------------------------------------------------
+func typeName(typ *symbols.TypeSymbol) string {
+    if typ.TypeGroup == symbols.ARR {
+        return fmt.Sprintf("array[%s]", typeName(typ.SubTypes[0]))
+    }
+
+    if typ.TypeGroup == symbols.MAP {
+        return fmt.Sprintf("map[%s, %s]", typeName(typ.SubTypes[0]), typeName(typ.SubTypes[1]))
+    }
+
+    if typ.TypeGroup == symbols.FUNC {
+        names := []string{}
+        for _, v := range typ.FunctionParameters() {
+            names = append(names, typeName(v))
+        }
+
+        name := strings.Join(names, ", ")
+
+        // (void functions dont mention their return type)
+        if ret := typ.FunctionReturnType(); ret.Name() != "void" {
+            name = strings.TrimLeft(name + " -> " + typeName(ret), " ")
+        }
+
+        return fmt.Sprintf("func[%s]", name)
+    }
+
+    if (typ.TypeGroup == symbols.CONT || typ.TypeGroup == symbols.TRT) && len(typ.SubTypes) > 0 {
+        name := typ.TypeName + typeParameters(typ.SubTypes)
+        if typ.Nullable {
+            name += "?"
+        }
+
+        return name
+    }
+
+    return typ.Name()
+}
+
+func typeParameters(prms []*symbols.TypeSymbol) string {
+    if len(prms) == 0 {
+        return ""
+    }
+
+    names := []string{}
+    for _, v := range prms {
+        names = append(names, typeName(v))
+    }
+
+    return "[" + strings.Join(names, ", ") + "]"
+}
+
+// What kind of types does a native method work on?
+// ------------------------------------------------
+func typeGroupName(fnc *symbols.FunctionSymbol) string {
+    if fnc.MethodKind == symbols.MT_ALL {
+        return "any"
+    }
+
+    // group methods are written against a dummy type named after their group (array, map)
+    return fnc.MethodSource.TypeName
+}
@@ -0,0 +1,140 @@
+// Docgen - writer.go
+// --------------------------------------------------------
+// The actual output formats, both only know how to put
+// headings, code and text on a page
+// --------------------------------------------------------
+package docgen
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+type writer interface {
+    Heading(level int, txt string)
+    Code(src string)
+    Text(txt string)
+    List(items []string)
+
+    Inline(src string) string           // inline code (for use in list items)
+    Link(txt string, target string) string
+    Escape(txt string) string
+
+    Extension() string
+    Finish(title string) string
+}
+
+func newWriter(useHtml bool) writer {
+    if useHtml {
+        return &htmlWriter{}
+    }
+
+    return &markdownWriter{}
+}
+
+// --------------------------------------------------------
+// Markdown
+// --------------------------------------------------------
+type markdownWriter struct {
+    out strings.Builder
+}
+
+func (wrt *markdownWriter) Heading(level int, txt string) {
+    fmt.Fprintf(&wrt.out, "%s %s\n\n", strings.Repeat("#", level), txt)
+}
+
+func (wrt *markdownWriter) Code(src string) {
+    fmt.Fprintf(&wrt.out, "```rerect\n%s\n```\n\n", src)
+}
+
+func (wrt *markdownWriter) Text(txt string) {
+    if txt == "" {
+        return
+    }
+
+    // doc comments are already markdown, so just pass them through
+    fmt.Fprintf(&wrt.out, "%s\n\n", txt)
+}
+
+func (wrt *markdownWriter) List(items []string) {
+    for _, item := range items {
+        fmt.Fprintf(&wrt.out, "- %s\n", item)
+    }
+
+    wrt.out.WriteString("\n")
+}
+
+func (wrt *markdownWriter) Inline(src string) string {
+    return "`" + src + "`"
+}
+
+func (wrt *markdownWriter) Link(txt string, target string) string {
+    return fmt.Sprintf("[%s](%s)", txt, target)
+}
+
+func (wrt *markdownWriter) Escape(txt string) string {
+    return txt
+}
+
+func (wrt *markdownWriter) Extension() string {
+    return ".md"
+}
+
+func (wrt *markdownWriter) Finish(title string) string {
+    return strings.TrimRight(wrt.out.String(), "\n") + "\n"
+}
+
+// --------------------------------------------------------
+// HTML
+// --------------------------------------------------------
+type htmlWriter struct {
+    out strings.Builder
+}
+
+func (wrt *htmlWriter) Heading(level int, txt string) {
+    fmt.Fprintf(&wrt.out, "<h%d>%s</h%d>\n", level, html.EscapeString(txt), level)
+}
+
+func (wrt *htmlWriter) Code(src string) {
+    fmt.Fprintf(&wrt.out, "<pre><code>%s</code></pre>\n", html.EscapeString(src))
+}
+
+func (wrt *htmlWriter) Text(txt string) {
+    if txt == "" {
+        return
+    }
+
+    // keep the line breaks of the original comment
+    fmt.Fprintf(&wrt.out, "<p>%s</p>\n", strings.ReplaceAll(html.EscapeString(txt), "\n", "<br>\n"))
+}
+
+func (wrt *htmlWriter) List(items []string) {
+    wrt.out.WriteString("<ul>\n")
+
+    for _, item := range items {
+        fmt.Fprintf(&wrt.out, "<li>%s</li>\n", item)
+    }
+
+    wrt.out.WriteString("</ul>\n")
+}
+
+func (wrt *htmlWriter) Inline(src string) string {
+    return "<code>" + html.EscapeString(src) + "</code>"
+}
+
+func (wrt *htmlWriter) Link(txt string, target string) string {
+    return fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(target), html.EscapeString(txt))
+}
+
+func (wrt *htmlWriter) Escape(txt string) string {
+    return html.EscapeString(txt)
+}
+
+func (wrt *htmlWriter) Extension() string {
+    return ".html"
+}
+
+func (wrt *htmlWriter) Finish(title string) string {
+    return fmt.Sprintf("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n%s</body>\n</html>\n", html.EscapeString(title), wrt.out.String())
+}
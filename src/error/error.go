@@ -16,6 +16,15 @@ type Error struct {
     Unit     CompUnit
     Position span.Span
     Message  string
+
+    Trace []TraceFrame // call stack of runtime errors (innermost first)
+}
+
+// One frame of a runtime stack trace
+// ----------------------------------
+type TraceFrame struct {
+    Function string
+    Position span.Span
 }
 
 func NewError(unit CompUnit, pos span.Span, msg string, prm ...any) Error {
@@ -37,5 +46,7 @@ const (
     GOP CompUnit = "GoNativePackages"
     BND CompUnit = "Binder"
     LWR CompUnit = "Lowerer"
+    BTC CompUnit = "Bytecode"
+    GEN CompUnit = "Codegen"
     RNT CompUnit = "Runtime"
 )
@@ -6,10 +6,9 @@ package error
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
-	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/span"
 )
 
 // ANSI color constants
@@ -22,56 +21,70 @@ const (
     RST = "\033[0m"
 )
 
-// Error collection
-// ----------------
-var errors []Error
+// Source lookup
+// -------------
+// (the error package doesnt know about any files, so whoever prints has to tell us)
+type SourceLookup func(file int) (path string, content string)
 
-// Add an error to the collection
-// ------------------------------
-func Report(err Error) {
-    errors = append(errors, err)
+// Print a single error
+// --------------------
+func Print(err Error, source SourceLookup) {
+    fmt.Print(RED)
+
+    if !err.Position.Internal {
+        _, content := source(err.Position.File)
+        line, col := err.Position.GetLineAndCol(content)
+
+        fmt.Printf("[%s][L:%d, C:%d]: %s\n", err.Unit, line, col, err.Message)
+        fmt.Print(RST)
+        printUnderline(err.Position, content, "")
+    } else {
+        fmt.Printf("[%s][Internal]: %s\n", err.Unit, err.Message)
+        fmt.Print(RST)
+    }
 
-    // if error occoured in runtime -> this is bad :)
-    if err.Unit == RNT {
-        Output()
-        os.Exit(-1)
+    // runtime errors also tell us how we got there
+    if len(err.Trace) > 0 {
+        fmt.Println("Stack trace:")
     }
-} 
-
-// Output all reported errors
-// --------------------------
-func Output() {
-    for _, err := range errors {
-        fmt.Print(RED)
-   
-        if !err.Position.Internal {
-            // figure out where the error happened
-            line, col := err.Position.GetLineAndCol()
-
-            // get the text from that line
-            errline := strings.Split(compunit.SourceFileRegister[err.Position.File].Content, "\n")[line - 1]
-            errline = strings.Replace(errline, "\t", " ", -1)
-
-            // calculate length of error underline
-            underlineLen := err.Position.ToIdx - err.Position.FromIdx
-            if underlineLen == 0 {
-                underlineLen = 1
-            }
-
-            fmt.Printf("[%s][L:%d, C:%d]: %s\n", err.Unit, line, col, err.Message)
-            fmt.Print(RST)
-            fmt.Println(errline)
-            fmt.Printf("%s%s%s%s\n", RED, strings.Repeat(" ", col-1), strings.Repeat("^", underlineLen), RST)
-        } else {
-            fmt.Printf("[%s][Internal]: %s\n", err.Unit, err.Message)
-            fmt.Print(RST)
+
+    for i, frm := range err.Trace {
+        if frm.Position.Internal {
+            fmt.Printf("  at %s\n", frm.Function)
+            continue
         }
-        fmt.Println()
+
+        path, content := source(frm.Position.File)
+        line, col := frm.Position.GetLineAndCol(content)
+
+        fmt.Printf("  at %s (%s, L:%d, C:%d)\n", frm.Function, path, line, col)
+
+        // no need to show the line the error is in twice
+        if i == 0 && frm.Position.Equal(err.Position) {
+            continue
+        }
+
+        printUnderline(frm.Position, content, "    ")
     }
+
+    fmt.Println()
 }
 
-// Are there errors?
-// -----------------
-func HasErrors() bool {
-    return len(errors) > 0
+// Print the line a span is in and underline the span
+// --------------------------------------------------
+func printUnderline(pos span.Span, content string, indent string) {
+    line, col := pos.GetLineAndCol(content)
+
+    // get the text from that line
+    errline := strings.Split(content, "\n")[line - 1]
+    errline = strings.Replace(errline, "\t", " ", -1)
+
+    // calculate length of error underline
+    underlineLen := pos.ToIdx - pos.FromIdx
+    if underlineLen <= 0 {
+        underlineLen = 1
+    }
+
+    fmt.Printf("%s%s\n", indent, errline)
+    fmt.Printf("%s%s%s%s%s\n", indent, RED, strings.Repeat(" ", col-1), strings.Repeat("^", underlineLen), RST)
 }
@@ -8,3 +8,14 @@ type ContainerInstance struct {
     Type *symbols.TypeSymbol
     Fields map[string]interface{}
 }
+
+// Anything that knows which container it is an instance of
+// (this struct, but also containers compiled to go structs)
+// ---------------------------------------------------------
+type TypedInstance interface {
+    InstanceType() *symbols.TypeSymbol
+}
+
+func (inst *ContainerInstance) InstanceType() *symbols.TypeSymbol {
+    return inst.Type
+}
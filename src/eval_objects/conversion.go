@@ -9,13 +9,24 @@ import (
 )
 
 func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool) {
+    // null stays null (as long as it's allowed to)
+    if val == nil && to.Nullable {
+        return nil, true
+    }
+
     // Casting anything to 'any'
-    if to.Equal(compunit.GlobalDataTypeRegister["any"]) {
+    if to.Equal(compunit.GlobalDataType("any")) {
         return interface{}(val), true
     }
 
+    // Casting to a type parameter
+    // (values of generic types are the same no matter what their type arguments are)
+    if to.TypeGroup == symbols.TPRM {
+        return val, true
+    }
+
     // Casting to long
-    if to.Equal(compunit.GlobalDataTypeRegister["long"]) {
+    if to.Equal(compunit.GlobalDataType("long")) {
         switch v := val.(type) {
 
         // Up / Down casts
@@ -35,10 +46,10 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
         // Cross cast
         // ----------
         case float64:
-            return int64(v), true
+            return FloatToInt[int64](float64(v)), true
 
         case float32:
-            return int64(v), true
+            return FloatToInt[int64](float64(v)), true
 
         // From string
         // -----------
@@ -53,7 +64,7 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
     }
 
     // Casting to int
-    if to.Equal(compunit.GlobalDataTypeRegister["int"]) {
+    if to.Equal(compunit.GlobalDataType("int")) {
         switch v := val.(type) {
 
         // Up / Down casts
@@ -73,10 +84,10 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
         // Cross cast
         // ----------
         case float64:
-            return int32(v), true
+            return FloatToInt[int32](float64(v)), true
 
         case float32:
-            return int32(v), true
+            return FloatToInt[int32](float64(v)), true
 
         // From string
         // -----------
@@ -91,7 +102,7 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
     }
     
     // Casting to word
-    if to.Equal(compunit.GlobalDataTypeRegister["word"]) {
+    if to.Equal(compunit.GlobalDataType("word")) {
         switch v := val.(type) {
 
         // Up / Down casts
@@ -111,10 +122,10 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
         // Cross cast
         // ----------
         case float64:
-            return int16(v), true
+            return FloatToInt[int16](float64(v)), true
 
         case float32:
-            return int16(v), true
+            return FloatToInt[int16](float64(v)), true
 
         // From string
         // -----------
@@ -129,7 +140,7 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
     }
     
     // Casting to byte
-    if to.Equal(compunit.GlobalDataTypeRegister["byte"]) {
+    if to.Equal(compunit.GlobalDataType("byte")) {
         switch v := val.(type) {
 
         // Up / Down casts
@@ -149,10 +160,10 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
         // Cross cast
         // ----------
         case float64:
-            return int8(v), true
+            return FloatToInt[int8](float64(v)), true
 
         case float32:
-            return int8(v), true
+            return FloatToInt[int8](float64(v)), true
 
         // From string
         // -----------
@@ -167,7 +178,7 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
     }
     
     // Casting to double
-    if to.Equal(compunit.GlobalDataTypeRegister["double"]) {
+    if to.Equal(compunit.GlobalDataType("double")) {
         switch v := val.(type) {
 
         // Up / Down casts
@@ -205,7 +216,7 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
     }
 
     // Casting to float
-    if to.Equal(compunit.GlobalDataTypeRegister["float"]) {
+    if to.Equal(compunit.GlobalDataType("float")) {
         switch v := val.(type) {
 
         // Up / Down casts
@@ -243,7 +254,7 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
     }
 
     // Casting to bool
-    if to.Equal(compunit.GlobalDataTypeRegister["bool"]) {
+    if to.Equal(compunit.GlobalDataType("bool")) {
         switch v := val.(type) {
         case bool:
             return v, true
@@ -259,7 +270,7 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
     }
 
     // Casting to string
-    if to.Equal(compunit.GlobalDataTypeRegister["string"]) {
+    if to.Equal(compunit.GlobalDataType("string")) {
         switch v := val.(type) {
 
         // Integers
@@ -296,6 +307,12 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
         case *ArrayInstance:
             return fmt.Sprintf("[%s]", v.Type.Name()), true
 
+        case *MapInstance:
+            return fmt.Sprintf("[%s]", v.Type.Name()), true
+
+        case *FunctionInstance:
+            return fmt.Sprintf("[%s]", v.Type.Name()), true
+
         // Strings
         // -------
         case string:
@@ -303,23 +320,80 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
         }
     }
 
+    // Casting to enum
+    // (only values that actually belong to a member make it through)
+    if to.TypeGroup == symbols.ENUM {
+        var mem *symbols.EnumMember
+
+        switch v := val.(type) {
+        case int64:
+            if int64(int32(v)) == v {
+                mem = to.Enum.LookupValue(int32(v))
+            }
+
+        case int32:
+            mem = to.Enum.LookupValue(v)
+
+        case int16:
+            mem = to.Enum.LookupValue(int32(v))
+
+        case int8:
+            mem = to.Enum.LookupValue(int32(v))
+
+        // From string (by name)
+        // ---------------------
+        case string:
+            mem = to.Enum.LookupMember(v)
+        }
+
+        if mem != nil {
+            return mem.Value, true
+        }
+    }
+
     // Casting to array
     if to.TypeGroup == symbols.ARR {
         switch v := val.(type) {
         case *ArrayInstance:
             // only cast when the internal types match
-            if v.Type.Equal(to) {
+            if v.Type.Matches(to) {
+                return v, true
+            }
+        }
+    }
+
+    // Casting to map
+    if to.TypeGroup == symbols.MAP {
+        switch v := val.(type) {
+        case *MapInstance:
+            // only cast when the internal types match
+            if v.Type.Matches(to) {
+                return v, true
+            }
+        }
+    }
+
+    // Casting to function
+    if to.TypeGroup == symbols.FUNC {
+        switch v := val.(type) {
+        case *FunctionInstance:
+            // only cast when the signatures match
+            if v.Type.Matches(to) {
                 return v, true
             }
+
+        case nil:
+            // functions nobody assigned yet are null
+            return nil, true
         }
     }
 
     // Casting to container
     if to.TypeGroup == symbols.CONT {
         switch v := val.(type) {
-        case *ContainerInstance:
+        case TypedInstance:
             // only cast when the internal types match
-            if v.Type.Equal(to) {
+            if v.InstanceType().Matches(to) {
                 return v, true
             }
         }
@@ -328,10 +402,11 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
     // Casting to trait 
     if to.TypeGroup == symbols.TRT {
         switch v := val.(type) {
-        case *ContainerInstance:
+        case TypedInstance:
             // only cast if the container implements the trait
-            for _, t := range v.Type.Container.Traits {
-                if t.TraitType.Equal(to) {
+            cnt := v.InstanceType().Container
+            for i := range cnt.Traits {
+                if cnt.TraitTypeFor(i, v.InstanceType()).Matches(to) {
                     return v, true
                 }
             }
@@ -340,3 +415,74 @@ func EvalConversion(val interface{}, to *symbols.TypeSymbol) (interface{}, bool)
 
     return nil, false
 }
+
+// Type checks
+// -----------
+// (uses the same rules as casting to containers and traits,
+// but null is never an instance of anything)
+func EvalTypeCheck(val interface{}, typ *symbols.TypeSymbol) bool {
+    if val == nil {
+        return false
+    }
+
+    if typ.TypeGroup != symbols.CONT && typ.TypeGroup != symbols.TRT {
+        return false
+    }
+
+    _, ok := EvalConversion(val, typ)
+    return ok
+}
+
+// Enum values to their names
+// --------------------------
+// (the value alone doesnt know it belongs to an enum, so this
+// cant be done by EvalConversion)
+func EnumName(val interface{}, typ *symbols.TypeSymbol) (string, bool) {
+    v, ok := val.(int32)
+    if !ok {
+        return "", false
+    }
+
+    mem := typ.Enum.LookupValue(v)
+    if mem == nil {
+        return "", false
+    }
+
+    return mem.MemberName, true
+}
+
+// Floats to integers
+// ------------------
+// (go leaves values that dont fit up to the platform, we
+// always clamp them to the closest value the integer can hold
+// instead. NaN becomes zero)
+func FloatToInt[T int64 | int32 | int16 | int8](val float64) T {
+    var bits uint
+    switch any(T(0)).(type) {
+    case int64:
+        bits = 64
+    case int32:
+        bits = 32
+    case int16:
+        bits = 16
+    case int8:
+        bits = 8
+    }
+
+    max := int64(uint64(1) << (bits - 1) - 1)
+    min := -max - 1
+
+    if val != val {
+        return 0
+    }
+
+    if val >= float64(max) {
+        return T(max)
+    }
+
+    if val <= float64(min) {
+        return T(min)
+    }
+
+    return T(val)
+}
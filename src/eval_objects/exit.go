@@ -0,0 +1,9 @@
+package evalobjects
+
+// Exit signal
+// -----------
+// Thrown (as a panic) by die() to unwind the vm
+// without taking the whole host process down with it
+type ExitSignal struct {
+    Code int
+}
@@ -0,0 +1,28 @@
+package evalobjects
+
+import (
+	"bytespace.network/rerect/error"
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+)
+
+// Function values
+// ---------------
+// (named functions, natives and lambdas all look the same once they're a value,
+// whoever creates one decides what calling it actually does)
+type FunctionInstance struct {
+    Type *symbols.TypeSymbol
+    Captures []interface{}  // variables a lambda brought along (in whatever box the backend likes)
+    Function *symbols.FunctionSymbol // (the vm uses this to call its own functions without a detour through Invoke)
+
+    Invoke func(fn *FunctionInstance, args []interface{}) interface{}
+}
+
+func (fn *FunctionInstance) Call(args ...interface{}) interface{} {
+    // if this is null -> we're doomed
+    if fn == nil {
+        panic(error.NewError(error.RNT, span.Internal(), "Cannot call a null function! (I am literally calling the police rn)"))
+    }
+
+    return fn.Invoke(fn, args)
+}
@@ -0,0 +1,50 @@
+package evalobjects
+
+import "bytespace.network/rerect/symbols"
+
+// Implementations for the map type
+// --------------------------------
+type MapInstance struct {
+    Type *symbols.TypeSymbol
+    Keys []interface{}                     // every key, in the order they were added
+    Elements map[interface{}]interface{}
+}
+
+func NewMapInstance(typ *symbols.TypeSymbol) *MapInstance {
+    return &MapInstance{
+        Type: typ,
+        Keys: make([]interface{}, 0),
+        Elements: make(map[interface{}]interface{}),
+    }
+}
+
+func (m *MapInstance) Has(key interface{}) bool {
+    _, ok := m.Elements[key]
+    return ok
+}
+
+func (m *MapInstance) Set(key interface{}, val interface{}) {
+    // new keys go to the back of the line
+    if !m.Has(key) {
+        m.Keys = append(m.Keys, key)
+    }
+
+    m.Elements[key] = val
+}
+
+func (m *MapInstance) Remove(key interface{}) bool {
+    if !m.Has(key) {
+        return false
+    }
+
+    delete(m.Elements, key)
+
+    for i, v := range m.Keys {
+        if v == key {
+            m.Keys = append(m.Keys[:i], m.Keys[i+1:]...)
+            break
+        }
+    }
+
+    return true
+}
@@ -12,11 +12,11 @@ import (
 )
 
 // LoadExample must be added to load() in load.go (and recompile)
-func LoadExample() {
+func LoadExample(comp *compunit.Compilation) {
 	packName := "example"
-	example := registerPackage("example")
+	example := registerPackage(comp, "example")
 
-	registerFunction(packName, symbols.NewVMFunctionSymbol(
+	registerFunction(comp, packName, symbols.NewVMFunctionSymbol(
 		// The package this function belongs to
 		example,
 
@@ -24,12 +24,12 @@ func LoadExample() {
 		"Add",
 
 		// Return type
-		compunit.GlobalDataTypeRegister["int"],
+		compunit.GlobalDataType("int"),
 
 		// Function parameters
 		[]*symbols.ParameterSymbol{
-			symbols.NewParameterSymbol("a", 0, compunit.GlobalDataTypeRegister["int"]),
-			symbols.NewParameterSymbol("b", 0, compunit.GlobalDataTypeRegister["int"]),
+			symbols.NewParameterSymbol("a", 0, compunit.GlobalDataType("int")),
+			symbols.NewParameterSymbol("b", 0, compunit.GlobalDataType("int")),
 		},
 
 		// Pointer to function
@@ -40,43 +40,43 @@ func LoadExample() {
 	hotdogContainer := symbols.NewContainerSymbol(example, "Hotdog", hotdogTypeSymbol)
 	hotdogTypeSymbol.Container = hotdogContainer // "doubly linked" more like "doubly ludicrous" >:(
 
-	registerContainer(packName, hotdogContainer)
+	registerContainer(comp, packName, hotdogContainer)
 
-	hotdogContainer.Fields = append(hotdogContainer.Fields, symbols.NewFieldSymbol(hotdogContainer, "name", compunit.GlobalDataTypeRegister["string"]))
+	hotdogContainer.Fields = append(hotdogContainer.Fields, symbols.NewFieldSymbol(hotdogContainer, "name", compunit.GlobalDataType("string")))
 	hotdogContainer.Constructor = symbols.NewVMMethodSymbol(
 		example,
 		symbols.MT_STRICT,
 		hotdogTypeSymbol,
 		"Construct",
-		compunit.GlobalDataTypeRegister["void"],
+		compunit.GlobalDataType("void"),
 		[]*symbols.ParameterSymbol{
-			symbols.NewParameterSymbol("name", 0, compunit.GlobalDataTypeRegister["string"]),
+			symbols.NewParameterSymbol("name", 0, compunit.GlobalDataType("string")),
 		},
 		Hotdog_Constructor,
 	)
-	registerFunction(packName, hotdogContainer.Constructor)
+	registerFunction(comp, packName, hotdogContainer.Constructor)
 
-	registerFunction(
+	registerFunction(comp,
 		packName,
 		symbols.NewVMMethodSymbol(
 			example,
 			symbols.MT_STRICT,
 			hotdogTypeSymbol,
 			"Dance",
-			compunit.GlobalDataTypeRegister["void"],
+			compunit.GlobalDataType("void"),
 			[]*symbols.ParameterSymbol{},
 			Hotdog_Dance,
 		),
 	)
 
-	registerFunction(
+	registerFunction(comp,
 		packName,
 		symbols.NewVMMethodSymbol(
 			example,
 			symbols.MT_STRICT,
 			hotdogTypeSymbol,
 			"Debug",
-			compunit.GlobalDataTypeRegister["string"],
+			compunit.GlobalDataType("string"),
 			[]*symbols.ParameterSymbol{},
 			Hotdog_Debug,
 		),
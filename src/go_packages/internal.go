@@ -1,8 +1,8 @@
 package gopackages
 
 import (
-	"os"
 	"reflect"
+	"sort"
 
 	"bytespace.network/rerect/compunit"
 	"bytespace.network/rerect/error"
@@ -12,22 +12,125 @@ import (
 )
 
 // Some very cool internal functions
-func LoadInternal() {
-    pack := registerPackage("internal")
+func LoadInternal(comp *compunit.Compilation) {
+    pack := registerPackage(comp, "internal")
     
     // create a dummy array type symbol
-    arr := symbols.NewTypeSymbol("array", []*symbols.TypeSymbol{}, symbols.ARR, 0, nil)
+    // (just like maps, the methods are written against its element type)
+    elem := symbols.NewTypeSymbol("T", []*symbols.TypeSymbol{}, symbols.TPRM, 0, nil)
+    arr  := symbols.NewTypeSymbol("array", []*symbols.TypeSymbol{elem}, symbols.ARR, 0, nil)
+
+    // whatever Map() and Reduce() turn the elements into
+    res  := symbols.NewTypeSymbol("U", []*symbols.TypeSymbol{}, symbols.TPRM, 0, nil)
+
+    elems   := symbols.NewTypeSymbol("T Array", []*symbols.TypeSymbol{elem}, symbols.ARR, 0, nil)
+    results := symbols.NewTypeSymbol("U Array", []*symbols.TypeSymbol{res}, symbols.ARR, 0, nil)
+
+    mapper    := symbols.NewFunctionType([]*symbols.TypeSymbol{elem}, res)
+    predicate := symbols.NewFunctionType([]*symbols.TypeSymbol{elem}, compunit.GlobalDataType("bool"))
+    reducer   := symbols.NewFunctionType([]*symbols.TypeSymbol{res, elem}, res)
+    less      := symbols.NewFunctionType([]*symbols.TypeSymbol{elem, elem}, compunit.GlobalDataType("bool"))
 
     // Array methods
-    registerFunction("internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP , arr, "Length", compunit.GlobalDataTypeRegister["int"] , []*symbols.ParameterSymbol{}, Array_Length))
-    registerFunction("internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP , arr, "Push"  , compunit.GlobalDataTypeRegister["void"], []*symbols.ParameterSymbol{symbols.NewParameterSymbol("Element", 0, compunit.GlobalDataTypeRegister["any"]) }, Array_Push))
-    registerFunction("internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP , arr, "Pop"   , compunit.GlobalDataTypeRegister["any"] , []*symbols.ParameterSymbol{}, Array_Pop))
+    registerFunction(comp, "internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP , arr, "Length", compunit.GlobalDataType("int") , []*symbols.ParameterSymbol{}, Array_Length))
+    registerFunction(comp, "internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP , arr, "Push"  , compunit.GlobalDataType("void"), []*symbols.ParameterSymbol{symbols.NewParameterSymbol("Element", 0, compunit.GlobalDataType("any")) }, Array_Push))
+    registerFunction(comp, "internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP , arr, "Pop"   , compunit.GlobalDataType("any") , []*symbols.ParameterSymbol{}, Array_Pop))
+
+    // Higher order array methods (these call back into whoever is running us)
+    arrMap := symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP, arr, "Map", results, []*symbols.ParameterSymbol{symbols.NewParameterSymbol("Mapper", 0, mapper)}, Array_Map)
+    arrMap.TypeParameters = []*symbols.TypeSymbol{res}
+
+    arrReduce := symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP, arr, "Reduce", res, []*symbols.ParameterSymbol{symbols.NewParameterSymbol("Initial", 0, res), symbols.NewParameterSymbol("Reducer", 1, reducer)}, Array_Reduce)
+    arrReduce.TypeParameters = []*symbols.TypeSymbol{res}
+
+    registerFunction(comp, "internal", arrMap)
+    registerFunction(comp, "internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP, arr, "Filter", elems, []*symbols.ParameterSymbol{symbols.NewParameterSymbol("Predicate", 0, predicate)}, Array_Filter))
+    registerFunction(comp, "internal", arrReduce)
+    registerFunction(comp, "internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP, arr, "Sort", compunit.GlobalDataType("void"), []*symbols.ParameterSymbol{symbols.NewParameterSymbol("Less", 0, less)}, Array_Sort))
+
+    // create a dummy map type symbol
+    // (the methods are written against its key and value types, the binder fills in the real ones)
+    key := symbols.NewTypeSymbol("K", []*symbols.TypeSymbol{}, symbols.TPRM, 0, nil)
+    val := symbols.NewTypeSymbol("V", []*symbols.TypeSymbol{}, symbols.TPRM, 0, nil)
+    mp  := symbols.NewTypeSymbol("map", []*symbols.TypeSymbol{key, val}, symbols.MAP, 0, nil)
+
+    keys := symbols.NewTypeSymbol("K Array", []*symbols.TypeSymbol{key}, symbols.ARR, 0, nil)
+    vals := symbols.NewTypeSymbol("V Array", []*symbols.TypeSymbol{val}, symbols.ARR, 0, nil)
+
+    // Map methods
+    registerFunction(comp, "internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP, mp, "Length", compunit.GlobalDataType("int") , []*symbols.ParameterSymbol{}, Map_Length))
+    registerFunction(comp, "internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP, mp, "Has"   , compunit.GlobalDataType("bool"), []*symbols.ParameterSymbol{symbols.NewParameterSymbol("Key", 0, key)}, Map_Has))
+    registerFunction(comp, "internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP, mp, "Remove", compunit.GlobalDataType("bool"), []*symbols.ParameterSymbol{symbols.NewParameterSymbol("Key", 0, key)}, Map_Remove))
+    registerFunction(comp, "internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP, mp, "Keys"  , keys, []*symbols.ParameterSymbol{}, Map_Keys))
+    registerFunction(comp, "internal", symbols.NewVMMethodSymbol(pack, symbols.MT_GROUP, mp, "Values", vals, []*symbols.ParameterSymbol{}, Map_Values))
 
     // String methods
-    registerFunction("internal", symbols.NewVMMethodSymbol(pack, symbols.MT_STRICT, compunit.GlobalDataTypeRegister["string"], "Length", compunit.GlobalDataTypeRegister["int"], []*symbols.ParameterSymbol{}, String_Length))
+    registerFunction(comp, "internal", symbols.NewVMMethodSymbol(pack, symbols.MT_STRICT, compunit.GlobalDataType("string"), "Length", compunit.GlobalDataType("int"), []*symbols.ParameterSymbol{}, String_Length))
+
+    chars := symbols.NewTypeSymbol("string Array", []*symbols.TypeSymbol{compunit.GlobalDataType("string")}, symbols.ARR, 0, nil)
+    registerFunction(comp, "internal", symbols.NewVMMethodSymbol(pack, symbols.MT_STRICT, compunit.GlobalDataType("string"), "Chars", chars, []*symbols.ParameterSymbol{}, String_Chars))
+
+    // The Iterable trait (anything a for-in loop can walk through)
+    // (there is no implementation here, containers bring their own)
+    item    := symbols.NewTypeSymbol("T", []*symbols.TypeSymbol{}, symbols.TPRM, 0, nil)
+    itType  := symbols.NewTypeSymbol("Iterable", []*symbols.TypeSymbol{item}, symbols.TRT, 0, nil)
+    iterTrt := symbols.NewTraitSymbol(pack, "Iterable", itType)
+    registerTrait(comp, "internal", iterTrt)
+
+    itLength := symbols.NewMethodSymbol(pack, itType, "Length", compunit.GlobalDataType("int"), []*symbols.ParameterSymbol{})
+    itGet    := symbols.NewMethodSymbol(pack, itType, "Get", item, []*symbols.ParameterSymbol{symbols.NewParameterSymbol("Index", 0, compunit.GlobalDataType("int"))})
+
+    for _, mth := range []*symbols.FunctionSymbol{itLength, itGet} {
+        mth.NeedsVirtualCallToContainer = true
+        iterTrt.Methods = append(iterTrt.Methods, mth)
+        iterTrt.Symbols = append(iterTrt.Symbols, mth.FuncName)
+        registerFunction(comp, "internal", mth)
+    }
 
     // Global functions
-    registerFunction("internal", symbols.NewVMFunctionSymbol(pack, "die", compunit.GlobalDataTypeRegister["void"], []*symbols.ParameterSymbol{symbols.NewParameterSymbol("exitcode", 0, compunit.GlobalDataTypeRegister["int"])}, Die))
+    registerFunction(comp, "internal", symbols.NewVMFunctionSymbol(pack, "die", compunit.GlobalDataType("void"), []*symbols.ParameterSymbol{symbols.NewParameterSymbol("exitcode", 0, compunit.GlobalDataType("int"))}, Die))
+
+    // The Error container (what gets thrown around by try, catch and throw)
+    errType := symbols.NewTypeSymbol("Error", []*symbols.TypeSymbol{}, symbols.CONT, 0, nil)
+    errCont := symbols.NewContainerSymbol(pack, "Error", errType)
+    registerContainer(comp, "internal", errCont)
+
+    errCont.Fields = append(errCont.Fields, symbols.NewFieldSymbol(errCont, "Message", compunit.GlobalDataType("string")))
+    errCont.Fields = append(errCont.Fields, symbols.NewFieldSymbol(errCont, "StackTrace", compunit.GlobalDataType("string")))
+
+    errCont.Constructor = symbols.NewVMMethodSymbol(pack, symbols.MT_STRICT, errType, "Constructor", compunit.GlobalDataType("void"), []*symbols.ParameterSymbol{symbols.NewParameterSymbol("message", 0, compunit.GlobalDataType("string"))}, Error_Constructor)
+    registerFunction(comp, "internal", errCont.Constructor)
+
+    // Documentation (for rrc doc and the language server)
+    // ---------------------------------------------------
+    document(pack, "array->Length", "The amount of elements in this array")
+    document(pack, "array->Push"  , "Appends an element to the end of this array")
+    document(pack, "array->Pop"   , "Removes the last element of this array and returns it")
+    document(pack, "array->Map"   , "Creates a new array with the given function applied to every element")
+    document(pack, "array->Filter", "Creates a new array with only the elements the given function returns true for")
+    document(pack, "array->Reduce", "Combines all elements into one value, starting with Initial")
+    document(pack, "array->Sort"  , "Sorts this array in place, Less tells whether its first argument goes before its second")
+
+    document(pack, "map->Length", "The amount of entries in this map")
+    document(pack, "map->Has"   , "Whether this map contains the given key")
+    document(pack, "map->Remove", "Removes the given key from this map, returns false if it wasnt there")
+    document(pack, "map->Keys"  , "All keys of this map")
+    document(pack, "map->Values", "All values of this map")
+
+    document(pack, "string->Length", "The amount of bytes in this string")
+    document(pack, "string->Chars" , "All characters (not bytes) of this string")
+
+    document(pack, "Iterable->Length", "The amount of elements a for-in loop will walk through")
+    document(pack, "Iterable->Get"   , "The element at the given index")
+
+    document(pack, "die", "Stops the program immediately with the given exit code")
+
+    iterTrt.Doc = "Anything a for-in loop can walk through"
+
+    errCont.Doc = "What gets thrown around by throw and caught by try/catch"
+    errCont.Fields[0].Doc = "What went wrong"
+    errCont.Fields[1].Doc = "Where it went wrong (filled in when thrown)"
+    errCont.Constructor.Doc = "Creates a new error with the given message"
 }
 
 func String_Length(instance any, args []any) any {
@@ -40,6 +143,24 @@ func String_Length(instance any, args []any) any {
     return int32(len(instance.(string)))
 }
 
+func String_Chars(instance any, args []any) any {
+    // make sure the instance isnt null
+    if instance == nil {
+        return nil
+    }
+
+    // split the string by runes, not bytes
+    elems := []any{}
+    for _, v := range instance.(string) {
+        elems = append(elems, string(v))
+    }
+
+    return &evalobjects.ArrayInstance{
+        Type: symbols.NewTypeSymbol("string Array", []*symbols.TypeSymbol{compunit.GlobalDataType("string")}, symbols.ARR, 0, nil),
+        Elements: elems,
+    }
+}
+
 func Array_Length(instance any, args []any) any {
     // make sure the instance isnt null
     if instance == nil {
@@ -69,8 +190,9 @@ func Array_Push(instance any, args []any) any {
             typ = reflect.TypeOf(elem).Name()
         }
 
-        error.Report(error.NewError(error.RNT, span.Internal(), "Cannot Push() element of type '%s' into array of type '%s'!", typ, arr.Type.SubTypes[0].Name()))
-        return nil
+        // natives dont know about the compilation they're running in
+        // -> the vm catches this and reports it for us
+        panic(error.NewError(error.RNT, span.Internal(), "Cannot Push() element of type '%s' into array of type '%s'!", typ, arr.Type.SubTypes[0].Name()))
     }
 
     // append the new element
@@ -95,11 +217,178 @@ func Array_Pop(instance any, args []any) any {
     return elem
 }
 
+// functions handed to Map(), Filter() and friends
+// (same error as calling a null function directly, even if the array is empty)
+func callbackArg(arg any) *evalobjects.FunctionInstance {
+    fn, ok := arg.(*evalobjects.FunctionInstance)
+    if !ok || fn == nil {
+        panic(error.NewError(error.RNT, span.Internal(), "Cannot call a null function! (I am literally calling the police rn)"))
+    }
+
+    return fn
+}
+
+func Array_Map(instance any, args []any) any {
+    // make sure the instance isnt null
+    if instance == nil {
+        return nil
+    }
+
+    arr := instance.(*evalobjects.ArrayInstance)
+    fn := callbackArg(args[0])
+
+    // the new array holds whatever the function gives back
+    elems := make([]any, len(arr.Elements))
+    for i, v := range arr.Elements {
+        elems[i] = fn.Call(v)
+    }
+
+    ret := fn.Type.FunctionReturnType()
+    return &evalobjects.ArrayInstance{
+        Type: symbols.NewTypeSymbol(ret.Name() + " Array", []*symbols.TypeSymbol{ret}, symbols.ARR, 0, nil),
+        Elements: elems,
+    }
+}
+
+func Array_Filter(instance any, args []any) any {
+    // make sure the instance isnt null
+    if instance == nil {
+        return nil
+    }
+
+    arr := instance.(*evalobjects.ArrayInstance)
+    fn := callbackArg(args[0])
+
+    elems := make([]any, 0)
+    for _, v := range arr.Elements {
+        if fn.Call(v).(bool) {
+            elems = append(elems, v)
+        }
+    }
+
+    return &evalobjects.ArrayInstance{
+        Type: arr.Type,
+        Elements: elems,
+    }
+}
+
+func Array_Reduce(instance any, args []any) any {
+    // make sure the instance isnt null
+    if instance == nil {
+        return args[0]
+    }
+
+    arr := instance.(*evalobjects.ArrayInstance)
+    fn := callbackArg(args[1])
+
+    acc := args[0]
+    for _, v := range arr.Elements {
+        acc = fn.Call(acc, v)
+    }
+
+    return acc
+}
+
+func Array_Sort(instance any, args []any) any {
+    // make sure the instance isnt null
+    if instance == nil {
+        return nil
+    }
+
+    arr := instance.(*evalobjects.ArrayInstance)
+    fn := callbackArg(args[0])
+
+    // equal elements keep their order
+    sort.SliceStable(arr.Elements, func(i, j int) bool {
+        return fn.Call(arr.Elements[i], arr.Elements[j]).(bool)
+    })
+
+    return nil
+}
+
+func Map_Length(instance any, args []any) any {
+    // make sure the instance isnt null
+    if instance == nil {
+        return 0
+    }
+
+    return int32(len(instance.(*evalobjects.MapInstance).Keys))
+}
+
+func Map_Has(instance any, args []any) any {
+    // make sure the instance isnt null
+    if instance == nil {
+        return false
+    }
+
+    return instance.(*evalobjects.MapInstance).Has(args[0])
+}
+
+func Map_Remove(instance any, args []any) any {
+    // make sure the instance isnt null
+    if instance == nil {
+        return false
+    }
+
+    return instance.(*evalobjects.MapInstance).Remove(args[0])
+}
+
+func Map_Keys(instance any, args []any) any {
+    // make sure the instance isnt null
+    if instance == nil {
+        return nil
+    }
+
+    mp := instance.(*evalobjects.MapInstance)
+
+    // hand out a copy, nobody should mess with our insides
+    keys := make([]any, len(mp.Keys))
+    copy(keys, mp.Keys)
+
+    return &evalobjects.ArrayInstance{
+        Type: symbols.NewTypeSymbol(mp.Type.SubTypes[0].Name() + " Array", []*symbols.TypeSymbol{mp.Type.SubTypes[0]}, symbols.ARR, 0, nil),
+        Elements: keys,
+    }
+}
+
+func Map_Values(instance any, args []any) any {
+    // make sure the instance isnt null
+    if instance == nil {
+        return nil
+    }
+
+    mp := instance.(*evalobjects.MapInstance)
+
+    // values come in the same order as the keys
+    vals := make([]any, 0, len(mp.Keys))
+    for _, k := range mp.Keys {
+        vals = append(vals, mp.Elements[k])
+    }
+
+    return &evalobjects.ArrayInstance{
+        Type: symbols.NewTypeSymbol(mp.Type.SubTypes[1].Name() + " Array", []*symbols.TypeSymbol{mp.Type.SubTypes[1]}, symbols.ARR, 0, nil),
+        Elements: vals,
+    }
+}
+
+// Error->Constructor(message string)
+func Error_Constructor(instance any, args []any) any {
+    if instance == nil {
+        return nil
+    }
+
+    // the stack trace gets filled in once this is thrown
+    con := instance.(*evalobjects.ContainerInstance)
+    con.Fields["Message"] = args[0].(string)
+
+    return nil
+}
+
 // die(exitcode int)
 func Die(args []any) any {
     // get the exit code
     code := args[0].(int32)
-    os.Exit(int(code))
 
-    return nil
+    // let the vm unwind everything and stop
+    panic(evalobjects.ExitSignal{Code: int(code)})
 }
@@ -11,40 +11,69 @@ import (
 	"bytespace.network/rerect/symbols"
 )
 
-func Load() {
+func Load(comp *compunit.Compilation) {
     // Load the sys package
-    LoadInternal()
-    LoadSys()
-	// LoadExample()
+    LoadInternal(comp)
+    LoadSys(comp)
+	// LoadExample(comp)
 }
 
 // A few helpers
 // -------------
-func registerPackage(name string) *symbols.PackageSymbol {
-    pck := compunit.GetPackage(name)
+func registerPackage(comp *compunit.Compilation, name string) *symbols.PackageSymbol {
+    pck := comp.GetPackage(name)
     if pck != nil {
-        error.Report(error.NewError(error.GOP, span.Internal(), "Unable to register package '%s'! A package with that name already exists!", name))
+        comp.Report(error.NewError(error.GOP, span.Internal(), "Unable to register package '%s'! A package with that name already exists!", name))
     }
 
-    return compunit.CreatePackage(name)
+    return comp.CreatePackage(name)
 }
 
-func registerFunction(pack string, fnc *symbols.FunctionSymbol) {
-    pck := compunit.GetPackage(pack)
+func registerFunction(comp *compunit.Compilation, pack string, fnc *symbols.FunctionSymbol) {
+    pck := comp.GetPackage(pack)
     
     if pck == nil {
-        error.Report(error.NewError(error.GOP, span.Internal(), "Unable to register function '%s' in package '%s'! No package called '%s' could be found!", fnc.FuncName, pack, pack))
+        comp.Report(error.NewError(error.GOP, span.Internal(), "Unable to register function '%s' in package '%s'! No package called '%s' could be found!", fnc.FuncName, pack, pack))
     }
 
     pck.Functions = append(pck.Functions, fnc)
 }
 
-func registerContainer(pack string, con *symbols.ContainerSymbol) {
-	pck := compunit.GetPackage(pack)
+func registerContainer(comp *compunit.Compilation, pack string, con *symbols.ContainerSymbol) {
+	pck := comp.GetPackage(pack)
 
 	if pck == nil {
-		error.Report(error.NewError(error.GOP, span.Internal(), "Unable to register container '%s' in package '%s'! No package called '%s' could be found!", con.ContainerName, pack, pack))
+		comp.Report(error.NewError(error.GOP, span.Internal(), "Unable to register container '%s' in package '%s'! No package called '%s' could be found!", con.ContainerName, pack, pack))
 	}
 
 	pck.Containers = append(pck.Containers, con)
 }
+
+func registerTrait(comp *compunit.Compilation, pack string, trt *symbols.TraitSymbol) {
+	pck := comp.GetPackage(pack)
+
+	if pck == nil {
+		comp.Report(error.NewError(error.GOP, span.Internal(), "Unable to register trait '%s' in package '%s'! No package called '%s' could be found!", trt.TraitName, pack, pack))
+	}
+
+	pck.Traits = append(pck.Traits, trt)
+}
+
+// Attach documentation to an already registered function
+// (methods on built in types are named like "array->Length")
+// ---------------------------------------------------------
+func document(pck *symbols.PackageSymbol, name string, doc string) {
+    for _, fnc := range pck.Functions {
+        fncName := fnc.FuncName
+        if fnc.FunctionKind == symbols.FT_METH {
+            fncName = fnc.MethodSource.TypeName + "->" + fnc.FuncName
+        }
+
+        if fncName == name {
+            fnc.Doc = doc
+            return
+        }
+    }
+
+    panic("Unable to document '" + name + "' in package '" + pck.Name() + "'! No such function exists!")
+}
@@ -15,9 +15,9 @@ import (
 	"bytespace.network/rerect/symbols"
 )
 
-func LoadSys() {
-    sys := registerPackage("sys")
-    registerFunction("sys", symbols.NewVMFunctionSymbol(
+func LoadSys(comp *compunit.Compilation) {
+    sys := registerPackage(comp, "sys")
+    registerFunction(comp, "sys", symbols.NewVMFunctionSymbol(
         // The package this function belongs to
         sys,
 
@@ -25,23 +25,33 @@ func LoadSys() {
         "Print",
 
         // Return type
-        compunit.GlobalDataTypeRegister["void"],
+        compunit.GlobalDataType("void"),
 
         // Function parameters
         []*symbols.ParameterSymbol {
-            symbols.NewParameterSymbol("msg", 0, compunit.GlobalDataTypeRegister["string"]),
+            symbols.NewParameterSymbol("msg", 0, compunit.GlobalDataType("string")),
         },
 
         // Pointer to function
         Print,
     ))
 
-    /* sys::Write() */ registerFunction("sys", symbols.NewVMFunctionSymbol(sys, "Write", compunit.GlobalDataTypeRegister["void"]  , []*symbols.ParameterSymbol{symbols.NewParameterSymbol("msg", 0, compunit.GlobalDataTypeRegister["string"])}, Write))
-    /* sys::Input() */ registerFunction("sys", symbols.NewVMFunctionSymbol(sys, "Input", compunit.GlobalDataTypeRegister["string"], []*symbols.ParameterSymbol{}, Input))
-    /* sys::Clear() */ registerFunction("sys", symbols.NewVMFunctionSymbol(sys, "Clear", compunit.GlobalDataTypeRegister["void"]  , []*symbols.ParameterSymbol{}, Clear))
-    /* sys::Sleep() */ registerFunction("sys", symbols.NewVMFunctionSymbol(sys, "Sleep", compunit.GlobalDataTypeRegister["void"]  , []*symbols.ParameterSymbol{symbols.NewParameterSymbol("mills", 0, compunit.GlobalDataTypeRegister["long"])}, Sleep))
-    /* sys::Now()   */ registerFunction("sys", symbols.NewVMFunctionSymbol(sys, "Now"  , compunit.GlobalDataTypeRegister["long"]  , []*symbols.ParameterSymbol{}, Now))
-    /* sys::Char()  */ registerFunction("sys", symbols.NewVMFunctionSymbol(sys, "Char" , compunit.GlobalDataTypeRegister["string"], []*symbols.ParameterSymbol{symbols.NewParameterSymbol("ascii", 0, compunit.GlobalDataTypeRegister["int"])}, Char))
+    /* sys::Write() */ registerFunction(comp, "sys", symbols.NewVMFunctionSymbol(sys, "Write", compunit.GlobalDataType("void")  , []*symbols.ParameterSymbol{symbols.NewParameterSymbol("msg", 0, compunit.GlobalDataType("string"))}, Write))
+    /* sys::Input() */ registerFunction(comp, "sys", symbols.NewVMFunctionSymbol(sys, "Input", compunit.GlobalDataType("string"), []*symbols.ParameterSymbol{}, Input))
+    /* sys::Clear() */ registerFunction(comp, "sys", symbols.NewVMFunctionSymbol(sys, "Clear", compunit.GlobalDataType("void")  , []*symbols.ParameterSymbol{}, Clear))
+    /* sys::Sleep() */ registerFunction(comp, "sys", symbols.NewVMFunctionSymbol(sys, "Sleep", compunit.GlobalDataType("void")  , []*symbols.ParameterSymbol{symbols.NewParameterSymbol("mills", 0, compunit.GlobalDataType("long"))}, Sleep))
+    /* sys::Now()   */ registerFunction(comp, "sys", symbols.NewVMFunctionSymbol(sys, "Now"  , compunit.GlobalDataType("long")  , []*symbols.ParameterSymbol{}, Now))
+    /* sys::Char()  */ registerFunction(comp, "sys", symbols.NewVMFunctionSymbol(sys, "Char" , compunit.GlobalDataType("string"), []*symbols.ParameterSymbol{symbols.NewParameterSymbol("ascii", 0, compunit.GlobalDataType("int"))}, Char))
+
+    // Documentation (for rrc doc and the language server)
+    // ---------------------------------------------------
+    document(sys, "Print", "Prints the given message, followed by a new line")
+    document(sys, "Write", "Prints the given message without a new line")
+    document(sys, "Input", "Reads a line from the console (without the line break)")
+    document(sys, "Clear", "Clears the console")
+    document(sys, "Sleep", "Pauses the program for the given amount of milliseconds")
+    document(sys, "Now"  , "The current unix time in milliseconds")
+    document(sys, "Char" , "Turns an ascii code into a single character string")
 }
 
 // sys::Print(msg string)
@@ -6,8 +6,10 @@ package lexer
 
 import (
 	"os"
+	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"bytespace.network/rerect/compunit"
 	"bytespace.network/rerect/error"
@@ -17,6 +19,8 @@ import (
 // Lexer struct
 // ------------
 type Lexer struct {
+    Comp *compunit.Compilation
+
     SourceStr    string
     Source       []rune
     SourceFileId int
@@ -25,6 +29,9 @@ type Lexer struct {
     Length int
 
     Tokens []Token
+
+    // doc comment lines waiting for the next token
+    Docs []string
 }
 
 const EOF rune = '\004'
@@ -45,10 +52,21 @@ func (lxr *Lexer) peek(offset int) rune {
 }
 
 func (lxr *Lexer) append(tok Token) {
+    // doc comments stick to whatever comes after them
+    if tok.Type == TT_DocComment {
+        lxr.Docs = append(lxr.Docs, tok.Buffer)
+        return
+    }
+
     if tok.Type == TT_WhiteSpace || tok.Type == TT_Comment {
         return
     }
 
+    if len(lxr.Docs) > 0 {
+        tok.Doc = strings.Join(lxr.Docs, "\n")
+        lxr.Docs = nil
+    }
+
     lxr.Tokens = append(lxr.Tokens, tok)
 } 
 
@@ -70,12 +88,12 @@ func (lxr *Lexer) step(size int) {
 
 // Lex a given file
 // ----------------
-func LexFile(file string) []Token {
+func LexFile(comp *compunit.Compilation, file string) []Token {
     txt, err := os.ReadFile(file)
 
     // Report IO Errors
     if err != nil {
-         error.Report(
+         comp.Report(
             error.NewError(error.FIO, span.Internal(), err.Error()),
          )
 
@@ -83,22 +101,26 @@ func LexFile(file string) []Token {
     }
 
     // Remember this file
-    idx := compunit.RegisterSource(file, string(txt))
+    idx := comp.RegisterSource(file, string(txt))
     
-    return LexString(string(txt), idx)
+    return LexString(comp, string(txt), idx)
 }
 
 // Lex a given string
 // ------------------
-func LexString(code string, srcidx int) []Token {
+func LexString(comp *compunit.Compilation, code string, srcidx int) []Token {
    // Instantiate a new lexer
    // -----------------------
+   source := []rune(code)
+
    lex := Lexer {
-       Source: []rune(code),
+       Comp: comp,
+
+       Source: source,
        SourceStr: code,
        SourceFileId: srcidx,
 
-       Length: len(code),
+       Length: len(source), // (in runes, not bytes)
 
        Tokens: make([]Token, 0),
    }
@@ -109,44 +131,8 @@ func LexString(code string, srcidx int) []Token {
 }
 
 func (lxr *Lexer) lex() {
-    for true {
-        current := lxr.current()
- 
-        // We have reached the end of file
-        if current == EOF {
-            break
-        }
-       
-        // Space, Tab, Newline, Carriage Return -> Whitespace
-        if current == ' ' || current == '\t' || current == '\n' || current == '\r' {
-            lxr.append(Token{
-                Type: TT_WhiteSpace,
-                Buffer: string(current),
-                Position: lxr.currentSpan(),
-            })
-
-            lxr.step(1)
-
-        // Double slash -> Comment
-        } else if current == '/' && lxr.peek(1) == '/' {
-            lxr.lexComment()
-
-        // Quotes -> String
-        } else if current == '"' {
-            lxr.lexString()
-
-        // Digit -> Number
-        } else if unicode.IsDigit(current) {
-            lxr.lexNumber()
-
-        // Letter -> Keyword or Identifier
-        } else if unicode.IsLetter(current) {
-            lxr.lexWord()
-        
-        // Otherwise -> probably some symbol or operator
-        } else {
-            lxr.lexSymbol()
-        }
+    for lxr.current() != EOF {
+        lxr.lexToken()
     }
 
     // Append EOF token at the end of the token list
@@ -156,6 +142,51 @@ func (lxr *Lexer) lex() {
     })
 }
 
+// Lex whatever token comes next
+// -----------------------------
+func (lxr *Lexer) lexToken() {
+    current := lxr.current()
+       
+    // Space, Tab, Newline, Carriage Return -> Whitespace
+    if current == ' ' || current == '\t' || current == '\n' || current == '\r' {
+        lxr.append(Token{
+            Type: TT_WhiteSpace,
+            Buffer: string(current),
+            Position: lxr.currentSpan(),
+        })
+
+        lxr.step(1)
+
+    // Double slash -> Comment
+    } else if current == '/' && lxr.peek(1) == '/' {
+        lxr.lexComment()
+
+    // Slash star -> Block comment
+    } else if current == '/' && lxr.peek(1) == '*' {
+        lxr.lexBlockComment()
+
+    // Quotes -> String
+    } else if current == '"' {
+        lxr.lexString()
+
+    // Backticks -> Raw string
+    } else if current == '`' {
+        lxr.lexRawString()
+
+    // Digit -> Number
+    } else if unicode.IsDigit(current) {
+        lxr.lexNumber()
+
+    // Letter -> Keyword or Identifier
+    } else if unicode.IsLetter(current) {
+        lxr.lexWord()
+    
+    // Otherwise -> probably some symbol or operator
+    } else {
+        lxr.lexSymbol()
+    }
+}
+
 // String lexing
 // -------------
 func (lxr *Lexer) lexString() {
@@ -167,8 +198,41 @@ func (lxr *Lexer) lexString() {
     // the string content buffer
     buffer := ""
 
-    // As long as we dont find the EOF or a closing qoute
-    for lxr.current() != EOF && lxr.current() != '"' {
+    // where the current piece of the string started
+    // (interpolations split a string into multiple pieces)
+    piecePos := startPos
+
+    // As long as we dont find a closing qoute
+    for lxr.current() != '"' {
+        // we ran out of file -> somebody forgot a qoute
+        if lxr.current() == EOF {
+            lxr.Comp.Report(error.NewError(error.LEX, startPos.SpanBetween(lxr.currentSpan()), "Unterminated string literal!"))
+            break
+        }
+
+        // backslash -> escape sequence
+        if lxr.current() == '\\' {
+            buffer += lxr.lexEscapeSequence()
+            continue
+        }
+
+        // ${ -> interpolation
+        if lxr.current() == '$' && lxr.peek(1) == '{' {
+            // store everything up until here
+            lxr.append(Token {
+                Type: TT_String,
+                Buffer: buffer,
+                Position: piecePos.SpanBetween(lxr.currentSpan()),
+            })
+
+            lxr.lexInterpolation()
+
+            // start a new piece
+            buffer = ""
+            piecePos = lxr.currentSpan()
+            continue
+        }
+
         buffer += string(lxr.current())
         lxr.step(1)
     }
@@ -177,7 +241,7 @@ func (lxr *Lexer) lexString() {
     tok := Token {
         Type: TT_String,
         Buffer: buffer,
-        Position: startPos.SpanBetween(lxr.currentSpan()),
+        Position: piecePos.SpanBetween(lxr.currentSpan()),
     }
 
     // store the token
@@ -187,16 +251,218 @@ func (lxr *Lexer) lexString() {
     lxr.step(1)
 }
 
+// Escape sequences (\n, \t, \", \\, \u{...} and so on)
+// ----------------------------------------------------
+func (lxr *Lexer) lexEscapeSequence() string {
+    startPos := lxr.currentSpan()
+
+    // step over the backslash
+    lxr.step(1)
+
+    // simple ones
+    simple := map[rune]string {
+        'n':  "\n",
+        't':  "\t",
+        'r':  "\r",
+        '0':  "\x00",
+        '"':  "\"",
+        '\\': "\\",
+        '$':  "$",
+        '`':  "`",
+    }
+
+    if val, ok := simple[lxr.current()]; ok {
+        lxr.step(1)
+        return val
+    }
+
+    // unicode code points (\u{1F32D})
+    if lxr.current() == 'u' && lxr.peek(1) == '{' {
+        lxr.step(2)
+
+        digits := ""
+        for lxr.current() != '}' && lxr.current() != '"' && lxr.current() != EOF {
+            digits += string(lxr.current())
+            lxr.step(1)
+        }
+
+        pos := startPos.SpanBetween(lxr.currentSpan())
+
+        if lxr.current() != '}' {
+            lxr.Comp.Report(error.NewError(error.LEX, pos, "Unterminated unicode escape sequence!"))
+            return ""
+        }
+
+        // step over the '}'
+        lxr.step(1)
+
+        code, err := strconv.ParseUint(digits, 16, 32)
+        if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+            lxr.Comp.Report(error.NewError(error.LEX, pos, "Invalid unicode code point '%s'!", digits))
+            return ""
+        }
+
+        return string(rune(code))
+    }
+
+    // the string just ended (the string lexer will complain about that)
+    if lxr.current() == EOF {
+        return ""
+    }
+
+    lxr.Comp.Report(error.NewError(error.LEX, startPos.SpanBetween(lxr.currentSpan()), "Unknown escape sequence '\\%c'!", lxr.current()))
+    lxr.step(1)
+
+    return ""
+}
+
+// Interpolations inside of strings ("Hello ${name}!")
+// ---------------------------------------------------
+// (these just lex everything between the braces like normal code
+// and leave it to the parser to make sense of it)
+func (lxr *Lexer) lexInterpolation() {
+    lxr.append(Token {
+        Type: TT_InterpolationStart,
+        Position: lxr.currentSpan().SpanBetween(span.Span{File: lxr.SourceFileId, FromIdx: lxr.Index + 1, ToIdx: lxr.Index + 1}),
+    })
+
+    // step over the '${'
+    lxr.step(2)
+
+    // braces inside of the interpolation dont end it
+    depth := 0
+
+    for lxr.current() != EOF {
+        if lxr.current() == '{' {
+            depth++
+        }
+
+        if lxr.current() == '}' {
+            if depth == 0 {
+                break
+            }
+
+            depth--
+        }
+
+        lxr.lexToken()
+    }
+
+    // (if we ran out of file, the string lexer will report it)
+    if lxr.current() == EOF {
+        return
+    }
+
+    lxr.append(Token {
+        Type: TT_InterpolationEnd,
+        Position: lxr.currentSpan(),
+    })
+
+    // step over the '}'
+    lxr.step(1)
+}
+
+// Raw string lexing (`no escapes in here`)
+// ----------------------------------------
+func (lxr *Lexer) lexRawString() {
+    startPos := lxr.currentSpan()
+    
+    // step over the leading backtick
+    lxr.step(1)
+
+    // the string content buffer
+    buffer := ""
+
+    for lxr.current() != '`' {
+        if lxr.current() == EOF {
+            lxr.Comp.Report(error.NewError(error.LEX, startPos.SpanBetween(lxr.currentSpan()), "Unterminated string literal!"))
+            break
+        }
+
+        buffer += string(lxr.current())
+        lxr.step(1)
+    }
+
+    // assemble the token
+    tok := Token {
+        Type: TT_String,
+        Buffer: buffer,
+        Position: startPos.SpanBetween(lxr.currentSpan()),
+    }
+
+    // store the token
+    lxr.append(tok)
+    
+    // step over the trailing backtick
+    lxr.step(1)
+}
+
 // Comment lexing
 // --------------
 func (lxr *Lexer) lexComment() {
     startPos := lxr.currentSpan()
 
+    // three slashes (but not four) -> doc comment
+    isDoc := lxr.peek(2) == '/' && lxr.peek(3) != '/'
+    start := lxr.Index
+
     // Step forward until we hit an EOF or an end of line
     for lxr.current() != EOF && lxr.current() != '\n' {
         lxr.step(1)
     }
 
+    // assemble the token
+    tok := Token {
+        Type: TT_Comment,
+        Position: startPos.SpanBetween(lxr.currentSpan()),
+    }
+
+    // doc comments keep their text (minus the slashes and one space)
+    if isDoc {
+        text := string(lxr.Source[start+3 : lxr.Index])
+        text = strings.TrimPrefix(text, " ")
+        text = strings.TrimRight(text, "\r")
+
+        tok.Type = TT_DocComment
+        tok.Buffer = text
+    }
+
+    // store the token
+    lxr.append(tok)
+}
+
+// Block comment lexing (these can be nested /* like /* this */ */)
+// ----------------------------------------------------------------
+func (lxr *Lexer) lexBlockComment() {
+    startPos := lxr.currentSpan()
+    depth := 0
+
+    for lxr.current() != EOF {
+        // another comment starts
+        if lxr.current() == '/' && lxr.peek(1) == '*' {
+            depth++
+            lxr.step(2)
+            continue
+        }
+
+        // a comment ends
+        if lxr.current() == '*' && lxr.peek(1) == '/' {
+            depth--
+            lxr.step(2)
+
+            if depth == 0 {
+                break
+            }
+
+            continue
+        }
+
+        lxr.step(1)
+    }
+
+    if depth > 0 {
+        lxr.Comp.Report(error.NewError(error.LEX, startPos.SpanBetween(lxr.currentSpan()), "Unterminated block comment!"))
+    }
 
     // assemble the token
     tok := Token {
@@ -210,6 +476,8 @@ func (lxr *Lexer) lexComment() {
 
 // Number lexing
 // -------------
+// (the buffer keeps prefixes and suffixes around, the binder
+// is the one figuring out what kind of number this actually is)
 func (lxr *Lexer) lexNumber() {
     // remember the start of this number
     startPos := lxr.currentSpan()
@@ -220,28 +488,74 @@ func (lxr *Lexer) lexNumber() {
     // is this a floating point number?
     hasDecimal := false
 
-    // Fill buffer as long as we conitnue to read digits or decimal points
-    for unicode.IsDigit(lxr.current()) || lxr.current() == '.' || lxr.current() == '_' {
-        // Skip underscores (they are just for visual clarity)
-        if lxr.current() == '_' {
+    // which digits are allowed after which prefix?
+    prefixDigits := map[rune]string{'x': "0123456789abcdefABCDEF", 'b': "01", 'o': "01234567"}
+    digits := prefixDigits[unicode.ToLower(lxr.peek(1))]
+
+    // 0x, 0b and 0o -> hex, binary and octal integers
+    // (only if a digit follows, 0b on its own is a zero byte)
+    if lxr.current() == '0' && digits != "" && strings.ContainsRune(digits, lxr.peek(2)) {
+        buffer += string(lxr.current()) + string(unicode.ToLower(lxr.peek(1)))
+        lxr.step(2)
+
+        for strings.ContainsRune(digits, lxr.current()) || lxr.current() == '_' {
+            if lxr.current() != '_' {
+                buffer += string(lxr.current())
+            }
+
             lxr.step(1)
-            continue
         }
 
-        // If we found a decimal point -> this is a floating point number
-        if lxr.current() == '.' {
-            
-            // We already found a decimal point (multiple decimal points are illegal)
-            if hasDecimal {
-                error.Report(error.NewError(error.LEX, lxr.currentSpan(), "Illegal decimal point!"))
-                return
+    } else {
+        // Fill buffer as long as we conitnue to read digits or decimal points
+        for unicode.IsDigit(lxr.current()) || lxr.current() == '.' || lxr.current() == '_' {
+            // Skip underscores (they are just for visual clarity)
+            if lxr.current() == '_' {
+                lxr.step(1)
+                continue
+            }
+
+            // If we found a decimal point -> this is a floating point number
+            if lxr.current() == '.' {
+                
+                // We already found a decimal point (multiple decimal points are illegal)
+                if hasDecimal {
+                    lxr.Comp.Report(error.NewError(error.LEX, lxr.currentSpan(), "Illegal decimal point!"))
+                    return
+                }
+
+                // otherwise...
+                hasDecimal = true
+            }
+
+            // add digit to buffer
+            buffer += string(lxr.current())
+            lxr.step(1)
+        }
+
+        // exponents (1e9, 2.5e-3) -> also a floating point number
+        if (lxr.current() == 'e' || lxr.current() == 'E') && (unicode.IsDigit(lxr.peek(1)) ||
+           ((lxr.peek(1) == '+' || lxr.peek(1) == '-') && unicode.IsDigit(lxr.peek(2)))) {
+
+            buffer += "e" + string(lxr.peek(1))
+            lxr.step(2)
+
+            for unicode.IsDigit(lxr.current()) {
+                buffer += string(lxr.current())
+                lxr.step(1)
             }
 
-            // otherwise...
             hasDecimal = true
         }
 
-        // add digit to buffer
+        // f and d suffixes make this a floating point number too
+        if (lxr.current() == 'f' || lxr.current() == 'd') && !unicode.IsLetter(lxr.peek(1)) && !unicode.IsDigit(lxr.peek(1)) {
+            hasDecimal = true
+        }
+    }
+
+    // type suffix (10L, 3b, 2.5d, ...)
+    for unicode.IsLetter(lxr.current()) || unicode.IsDigit(lxr.current()) {
         buffer += string(lxr.current())
         lxr.step(1)
     }
@@ -372,7 +686,7 @@ func (lxr *Lexer) lexSymbol() {
         }
 
         // if not -> theres no token that matches the input
-        error.Report(error.NewError(error.LEX, startPos.SpanBetween(lxr.currentSpan()), "Unknown symbol!"))
+        lxr.Comp.Report(error.NewError(error.LEX, startPos.SpanBetween(lxr.currentSpan()), "Unknown symbol!"))
 
         // step over the char
         lxr.step(1)
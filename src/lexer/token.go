@@ -12,6 +12,8 @@ type Token struct {
     Type TokenType
     Buffer string
     Position span.Span
+
+    Doc string // any /// comments right before this token
 }
 
 func newToken(typ TokenType, buf string, pos span.Span) Token {
@@ -30,6 +32,7 @@ const (
     TT_WhiteSpace              TokenType = "TT_WhiteSpace"
     TT_EOF                     TokenType = "TT_EOF"
     TT_Comment                 TokenType = "TT_Comment"
+    TT_DocComment              TokenType = "TT_DocComment"
 
     // Punctuation
     TT_Semicolon               TokenType = "TT_Semicolon"
@@ -45,6 +48,8 @@ const (
     // Operators 
     TT_LeftArrow               TokenType = "TT_LeftArrow"
     TT_RightArrow              TokenType = "TT_RightArrow"
+    TT_QuestionArrow           TokenType = "TT_QuestionArrow"
+    TT_QuestionMark            TokenType = "TT_QuestionMark"
     TT_Package                 TokenType = "TT_Package"
     
     // Math operators
@@ -61,19 +66,46 @@ const (
     TT_GreaterEqual            TokenType = "TT_GreaterEqual"
     TT_Ampersands              TokenType = "TT_Ampersands"
     TT_Pipes                   TokenType = "TT_Pipes"
+    TT_Percent                 TokenType = "TT_Percent"
+    TT_Ampersand               TokenType = "TT_Ampersand"
+    TT_Pipe                    TokenType = "TT_Pipe"
+    TT_Caret                   TokenType = "TT_Caret"
+    TT_Tilde                   TokenType = "TT_Tilde"
+    TT_ShiftLeft               TokenType = "TT_ShiftLeft"
+    TT_ShiftRight              TokenType = "TT_ShiftRight"
+    TT_PlusPlus                TokenType = "TT_PlusPlus"
+    TT_MinusMinus              TokenType = "TT_MinusMinus"
+
+    // Compound assignment operators
+    TT_PlusAssign              TokenType = "TT_PlusAssign"
+    TT_MinusAssign             TokenType = "TT_MinusAssign"
+    TT_StarAssign              TokenType = "TT_StarAssign"
+    TT_SlashAssign             TokenType = "TT_SlashAssign"
+    TT_PercentAssign           TokenType = "TT_PercentAssign"
+    TT_AmpersandAssign         TokenType = "TT_AmpersandAssign"
+    TT_PipeAssign              TokenType = "TT_PipeAssign"
+    TT_CaretAssign             TokenType = "TT_CaretAssign"
+    TT_ShiftLeftAssign         TokenType = "TT_ShiftLeftAssign"
+    TT_ShiftRightAssign        TokenType = "TT_ShiftRightAssign"
 
     // Literals
     TT_String                  TokenType = "TT_String" 
     TT_Integer                 TokenType = "TT_Integer" 
     TT_Float                   TokenType = "TT_Float" 
 
+    // String interpolation ("${" and "}" inside of a string)
+    TT_InterpolationStart      TokenType = "TT_InterpolationStart"
+    TT_InterpolationEnd        TokenType = "TT_InterpolationEnd"
+
     // Keywords
     TT_KW_Load                 TokenType = "TT_KW_Load"
     TT_KW_Include              TokenType = "TT_KW_Include"
     TT_KW_Package              TokenType = "TT_KW_Package"
     TT_KW_Function             TokenType = "TT_KW_Function"
     TT_KW_Var                  TokenType = "TT_KW_Var"
-    TT_KW_Return               TokenType = "TT_KW_Const"
+    TT_KW_Const                TokenType = "TT_KW_Const"
+    TT_KW_Readonly             TokenType = "TT_KW_Readonly"
+    TT_KW_Return               TokenType = "TT_KW_Return"
     TT_KW_While                TokenType = "TT_KW_While"
     TT_KW_From                 TokenType = "TT_KW_From"
     TT_KW_To                   TokenType = "TT_KW_To"
@@ -85,11 +117,19 @@ const (
     TT_KW_Else                 TokenType = "TT_KW_Else"
     TT_KW_True                 TokenType = "TT_KW_True"
     TT_KW_False                TokenType = "TT_KW_False"
+    TT_KW_Null                 TokenType = "TT_KW_Null"
     TT_KW_Make                 TokenType = "TT_KW_Make"
     TT_KW_Container            TokenType = "TT_KW_Container"
     TT_KW_Constructor          TokenType = "TT_KW_Constructor"
     TT_KW_This                 TokenType = "TT_KW_This"
     TT_KW_Trait                TokenType = "TT_KW_Trait"
+    TT_KW_Enum                 TokenType = "TT_KW_Enum"
+    TT_KW_Try                  TokenType = "TT_KW_Try"
+    TT_KW_Catch                TokenType = "TT_KW_Catch"
+    TT_KW_Throw                TokenType = "TT_KW_Throw"
+    TT_KW_Switch               TokenType = "TT_KW_Switch"
+    TT_KW_Case                 TokenType = "TT_KW_Case"
+    TT_KW_Default              TokenType = "TT_KW_Default"
 
     // Identifiers
     TT_Identifier              TokenType = "TT_Identifier"
@@ -101,6 +141,8 @@ var Keywords = map[string]TokenType {
     "package":     TT_KW_Package,
     "function":    TT_KW_Function,
     "var":         TT_KW_Var,
+    "const":       TT_KW_Const,
+    "readonly":    TT_KW_Readonly,
     "return":      TT_KW_Return,
     "while":       TT_KW_While,
     "from":        TT_KW_From,
@@ -111,12 +153,20 @@ var Keywords = map[string]TokenType {
     "continue":    TT_KW_Continue,
     "true":        TT_KW_True,
     "false":       TT_KW_False,
+    "null":        TT_KW_Null,
     "if":          TT_KW_If,
     "else":        TT_KW_Else,
     "make":        TT_KW_Make,
     "container":   TT_KW_Container,
     "Constructor": TT_KW_Constructor,
     "trait":       TT_KW_Trait,
+    "enum":        TT_KW_Enum,
+    "try":         TT_KW_Try,
+    "catch":       TT_KW_Catch,
+    "throw":       TT_KW_Throw,
+    "switch":      TT_KW_Switch,
+    "case":        TT_KW_Case,
+    "default":     TT_KW_Default,
 }
 
 var Symbols = map[string]TokenType {
@@ -133,9 +183,31 @@ var Symbols = map[string]TokenType {
     ">=": TT_GreaterEqual,
     "<-": TT_LeftArrow,
     "->": TT_RightArrow,
+    "?->": TT_QuestionArrow,
+    "?" : TT_QuestionMark,
     "::": TT_Package,
     "&&": TT_Ampersands,
     "||": TT_Pipes,
+    "%" : TT_Percent,
+    "&" : TT_Ampersand,
+    "|" : TT_Pipe,
+    "^" : TT_Caret,
+    "~" : TT_Tilde,
+    "<<": TT_ShiftLeft,
+    ">>": TT_ShiftRight,
+    "++": TT_PlusPlus,
+    "--": TT_MinusMinus,
+
+    "+<-" : TT_PlusAssign,
+    "-<-" : TT_MinusAssign,
+    "*<-" : TT_StarAssign,
+    "/<-" : TT_SlashAssign,
+    "%<-" : TT_PercentAssign,
+    "&<-" : TT_AmpersandAssign,
+    "|<-" : TT_PipeAssign,
+    "^<-" : TT_CaretAssign,
+    "<<<-": TT_ShiftLeftAssign,
+    ">><-": TT_ShiftRightAssign,
 
     "(" : TT_OpenParenthesis,
     ")" : TT_CloseParenthesis,
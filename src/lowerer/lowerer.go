@@ -15,8 +15,17 @@ import (
 	"bytespace.network/rerect/symbols"
 )
 
+// Lowerer struct
+// --------------
+type Lowerer struct {
+    Comp *compunit.Compilation
+}
+
 // Lower function - outside wrapper
-func Lower(file *packageprocessor.CompilationFile) {
+func Lower(comp *compunit.Compilation, file *packageprocessor.CompilationFile) {
+    lwr := Lowerer{
+        Comp: comp,
+    }
 
     for _, sym := range file.Functions {
         stmt := file.FunctionBodies[sym]
@@ -27,7 +36,7 @@ func Lower(file *packageprocessor.CompilationFile) {
         }
 
         // rewrite the body (simplify statements)
-        stmt = rewriteStatement(stmt)
+        stmt = lwr.rewriteStatement(stmt)
 
         // flatten the body into one long list of statements (instead of nested blocks)
         stmt = flatten(stmt.(*boundnodes.BoundBlockStatementNode))
@@ -106,42 +115,53 @@ func flatten(stmt *boundnodes.BoundBlockStatementNode) *boundnodes.BoundBlockSta
 // --------------------------------------------------------
 // Helpers
 // --------------------------------------------------------
-var labelCounter int = 0
-func generateLabel() boundnodes.BoundLabel {
-    labelCounter++
-    return boundnodes.BoundLabel(fmt.Sprintf("label%d", labelCounter))
+func (lwr *Lowerer) generateLabel() boundnodes.BoundLabel {
+    lwr.Comp.LabelCounter++
+    return boundnodes.BoundLabel(fmt.Sprintf("label%d", lwr.Comp.LabelCounter))
 }
 
 // --------------------------------------------------------
 // Statements
 // --------------------------------------------------------
-func rewriteStatement(stmt boundnodes.BoundStatementNode) boundnodes.BoundStatementNode {
+func (lwr *Lowerer) rewriteStatement(stmt boundnodes.BoundStatementNode) boundnodes.BoundStatementNode {
     if stmt.Type() == boundnodes.BT_DeclarationStmt {
-        return rewriteDeclarationStatement(stmt.(*boundnodes.BoundDeclarationStatementNode))
+        return lwr.rewriteDeclarationStatement(stmt.(*boundnodes.BoundDeclarationStatementNode))
 
     } else if stmt.Type() == boundnodes.BT_ReturnStmt {
-        return rewriteReturnStatement(stmt.(*boundnodes.BoundReturnStatementNode))
+        return lwr.rewriteReturnStatement(stmt.(*boundnodes.BoundReturnStatementNode))
 
     } else if stmt.Type() == boundnodes.BT_WhileStmt {
-        return rewriteWhileStatement(stmt.(*boundnodes.BoundWhileStatementNode))
+        return lwr.rewriteWhileStatement(stmt.(*boundnodes.BoundWhileStatementNode))
 
     } else if stmt.Type() == boundnodes.BT_FromToStmt {
-        return rewriteFromToStatement(stmt.(*boundnodes.BoundFromToStatementNode))
+        return lwr.rewriteFromToStatement(stmt.(*boundnodes.BoundFromToStatementNode))
 
     } else if stmt.Type() == boundnodes.BT_ForStmt {
-        return rewriteForStatement(stmt.(*boundnodes.BoundForStatementNode))
+        return lwr.rewriteForStatement(stmt.(*boundnodes.BoundForStatementNode))
+
+    } else if stmt.Type() == boundnodes.BT_ForEachStmt {
+        return lwr.rewriteForEachStatement(stmt.(*boundnodes.BoundForEachStatementNode))
 
     } else if stmt.Type() == boundnodes.BT_LoopStmt {
-        return rewriteLoopStatement(stmt.(*boundnodes.BoundLoopStatementNode))
+        return lwr.rewriteLoopStatement(stmt.(*boundnodes.BoundLoopStatementNode))
 
     } else if stmt.Type() == boundnodes.BT_BlockStmt {
-        return rewriteBlockStatement(stmt.(*boundnodes.BoundBlockStatementNode))
+        return lwr.rewriteBlockStatement(stmt.(*boundnodes.BoundBlockStatementNode))
 
     } else if stmt.Type() == boundnodes.BT_ExpressionStmt {
-        return rewriteExpressionStatement(stmt.(*boundnodes.BoundExpressionStatementNode))
+        return lwr.rewriteExpressionStatement(stmt.(*boundnodes.BoundExpressionStatementNode))
 
     } else if stmt.Type() == boundnodes.BT_IfStmt {
-        return rewriteIfStatement(stmt.(*boundnodes.BoundIfStatementNode))
+        return lwr.rewriteIfStatement(stmt.(*boundnodes.BoundIfStatementNode))
+
+    } else if stmt.Type() == boundnodes.BT_TryStmt {
+        return lwr.rewriteTryStatement(stmt.(*boundnodes.BoundTryStatementNode))
+
+    } else if stmt.Type() == boundnodes.BT_ThrowStmt {
+        return lwr.rewriteThrowStatement(stmt.(*boundnodes.BoundThrowStatementNode))
+
+    } else if stmt.Type() == boundnodes.BT_SwitchStmt {
+        return lwr.rewriteSwitchStatement(stmt.(*boundnodes.BoundSwitchStatementNode))
 
     } else if stmt.Type() == boundnodes.BT_LabelIStmt {
         return stmt
@@ -158,31 +178,34 @@ func rewriteStatement(stmt boundnodes.BoundStatementNode) boundnodes.BoundStatem
     } else if stmt.Type() == boundnodes.BT_ApproachIStmt {
         return stmt
 
+    } else if stmt.Type() == boundnodes.BT_ProtectIStmt {
+        return stmt
+
     } else {
-        error.Report(error.NewError(error.LWR, stmt.Source().Position(), "Unable to rewrite statement '%s', no rewriter implemented! You should implement NOW!", stmt.Type()))
+        lwr.Comp.Report(error.NewError(error.LWR, stmt.Source().Position(), "Unable to rewrite statement '%s', no rewriter implemented! You should implement NOW!", stmt.Type()))
         return stmt
     }
 }
 
-func rewriteDeclarationStatement(stmt *boundnodes.BoundDeclarationStatementNode) *boundnodes.BoundDeclarationStatementNode {
+func (lwr *Lowerer) rewriteDeclarationStatement(stmt *boundnodes.BoundDeclarationStatementNode) *boundnodes.BoundDeclarationStatementNode {
     if !stmt.HasInitializer {
         return stmt // nothing to rewrite
     }
 
-    init := rewriteExpression(stmt.Initializer)
+    init := lwr.rewriteExpression(stmt.Initializer)
     return boundnodes.NewBoundDeclarationStatementNode(stmt.Source(), stmt.Variable, init, true)
 }
 
-func rewriteReturnStatement(stmt *boundnodes.BoundReturnStatementNode) *boundnodes.BoundReturnStatementNode {
+func (lwr *Lowerer) rewriteReturnStatement(stmt *boundnodes.BoundReturnStatementNode) *boundnodes.BoundReturnStatementNode {
     if !stmt.HasReturnValue {
         return stmt // nothing to rewrite
     }
 
-    val := rewriteExpression(stmt.ReturnValue)
+    val := lwr.rewriteExpression(stmt.ReturnValue)
     return boundnodes.NewBoundReturnStatementNode(stmt.Source(), val, true)
 }
 
-func rewriteWhileStatement(stmt *boundnodes.BoundWhileStatementNode) boundnodes.BoundStatementNode {
+func (lwr *Lowerer) rewriteWhileStatement(stmt *boundnodes.BoundWhileStatementNode) boundnodes.BoundStatementNode {
     // while (<cond>) { <body> }
     // -------------------------
     // goto .continue
@@ -192,19 +215,19 @@ func rewriteWhileStatement(stmt *boundnodes.BoundWhileStatementNode) boundnodes.
     // gotoif <condition> .body
     // .break:
     stmts := []boundnodes.BoundStatementNode{}
-    bodyLabel := generateLabel()
+    bodyLabel := lwr.generateLabel()
 
     stmts = append(stmts, boundnodes.NewBoundGotoStatementNode(stmt.Source(), stmt.ContinueLabel()))
     stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.Source(), bodyLabel))
-    stmts = append(stmts, rewriteStatement(stmt.Body))
+    stmts = append(stmts, lwr.rewriteStatement(stmt.Body))
     stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.Source(), stmt.ContinueLabel()))
-    stmts = append(stmts, boundnodes.NewBoundGotoIfStatementNode(stmt.Source(), bodyLabel, rewriteExpression(stmt.Condtion)))
+    stmts = append(stmts, boundnodes.NewBoundGotoIfStatementNode(stmt.Source(), bodyLabel, lwr.rewriteExpression(stmt.Condtion)))
     stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.Source(), stmt.BreakLabel()))
 
     return boundnodes.NewBoundBlockStatementNode(stmt.Source(), stmts)
 }
 
-func rewriteFromToStatement(stmt *boundnodes.BoundFromToStatementNode) boundnodes.BoundStatementNode {
+func (lwr *Lowerer) rewriteFromToStatement(stmt *boundnodes.BoundFromToStatementNode) boundnodes.BoundStatementNode {
     // from <var> <- <lb> to <ub> { <body> }
     // -------------------------------------
     // var <ub> <- <ub>
@@ -212,19 +235,32 @@ func rewriteFromToStatement(stmt *boundnodes.BoundFromToStatementNode) boundnode
     //      <body>
     // }
     // delete <ub>
+    //
+    // if a lambda captures <var>, every round gets its own copy of it:
+    // for (var <it> <- <lb>; <it> != <ub>; { <it> <- <var>; approach <it> <ub> }) {
+    //      var <var> <- <it>
+    //      <body>
+    // }
 
     stmts := []boundnodes.BoundStatementNode{}
-    inttyp := compunit.GlobalDataTypeRegister["int"]
+    inttyp := compunit.GlobalDataType("int")
 
     // rewrite lower bound value
-    lowerBound := rewriteExpression(stmt.LowerBound)
+    lowerBound := lwr.rewriteExpression(stmt.LowerBound)
+
+    // the variable actually driving the loop
+    counter := symbols.VariableSymbol(stmt.Iterator)
+    captured := symbols.IsCaptured(stmt.Iterator)
+    if captured {
+        counter = symbols.NewLocalSymbol("__iterator", inttyp)
+    }
 
     // create lb variable declaration, deletion and access
-    iteratorDeclaration := boundnodes.NewBoundDeclarationStatementNode(stmt.Source(), stmt.Iterator, lowerBound, true)
-    iteratorExpression := boundnodes.NewBoundNameExpressionNode(stmt.Source(), stmt.Iterator)
+    iteratorDeclaration := boundnodes.NewBoundDeclarationStatementNode(stmt.Source(), counter, lowerBound, true)
+    iteratorExpression := boundnodes.NewBoundNameExpressionNode(stmt.Source(), counter)
 
     // rewrite upper bound value
-    upperBound := rewriteExpression(stmt.UpperBound)
+    upperBound := lwr.rewriteExpression(stmt.UpperBound)
 
     // create a new variable symbol for this
     upperBoundVar := symbols.NewLocalSymbol("__upperBound", inttyp)
@@ -238,29 +274,47 @@ func rewriteFromToStatement(stmt *boundnodes.BoundFromToStatementNode) boundnode
     // lb != ub
     condition := boundnodes.NewBoundBinaryExpressionNode(
         stmt.Source(),
-        boundnodes.NewBoundBinaryOperator(boundnodes.BO_UnEqual, inttyp, inttyp, compunit.GlobalDataTypeRegister["bool"]),
+        boundnodes.NewBoundBinaryOperator(boundnodes.BO_UnEqual, inttyp, inttyp, compunit.GlobalDataType("bool")),
         iteratorExpression,
         upperBoundExpression,
     )
 
     // rewrite the original loop body
-    body := rewriteStatement(stmt.Body)
+    body := lwr.rewriteStatement(stmt.Body)
 
     // create approch statement
-    approach := boundnodes.NewBoundApproachStatementNode(stmt.Source(), stmt.Iterator, upperBoundExpression)
+    var approach boundnodes.BoundStatementNode = boundnodes.NewBoundApproachStatementNode(stmt.Source(), counter, upperBoundExpression)
+
+    // captured -> fresh variable every round
+    // (whatever the body did to it still counts for the next one)
+    if captured {
+        body = boundnodes.NewBoundBlockStatementNode(stmt.Source(), []boundnodes.BoundStatementNode{
+            boundnodes.NewBoundDeclarationStatementNode(stmt.Source(), stmt.Iterator, iteratorExpression, true),
+            body,
+        })
+
+        approach = boundnodes.NewBoundBlockStatementNode(stmt.Source(), []boundnodes.BoundStatementNode{
+            boundnodes.NewBoundExpressionStatementNode(stmt.Source(), boundnodes.NewBoundAssignmentExpressionNode(
+                stmt.Source(),
+                iteratorExpression,
+                boundnodes.NewBoundNameExpressionNode(stmt.Source(), stmt.Iterator),
+            )),
+            approach,
+        })
+    }
 
     // create internal while statement
     forstmt := boundnodes.NewBoundForStatementNode(stmt.Source(), iteratorDeclaration, condition, approach, body, stmt.BreakLbl, stmt.ContinueLbl)
 
     // assemble it all
     stmts = append(stmts, upperBoundDeclaration)
-    stmts = append(stmts, rewriteStatement(forstmt))
+    stmts = append(stmts, lwr.rewriteStatement(forstmt))
     stmts = append(stmts, upperBoundDeletion)
 
     return boundnodes.NewBoundBlockStatementNode(stmt.Source(), stmts)
 }
 
-func rewriteForStatement(stmt *boundnodes.BoundForStatementNode) boundnodes.BoundStatementNode {
+func (lwr *Lowerer) rewriteForStatement(stmt *boundnodes.BoundForStatementNode) boundnodes.BoundStatementNode {
     // for (<declaration>; <condition>; <action>) { <body> }
     // -----------------------------------------------------
     // <declaration>
@@ -272,12 +326,12 @@ func rewriteForStatement(stmt *boundnodes.BoundForStatementNode) boundnodes.Boun
     stmts := []boundnodes.BoundStatementNode{}
 
     // rewrite the original loop declaration
-    decl := rewriteStatement(stmt.Initializer)
-    cond := rewriteExpression(stmt.Condition)
-    act  := rewriteStatement(stmt.Action)
+    decl := lwr.rewriteStatement(stmt.Initializer)
+    cond := lwr.rewriteExpression(stmt.Condition)
+    act  := lwr.rewriteStatement(stmt.Action)
 
     // rewrite the original loop body
-    body := rewriteStatement(stmt.Body)
+    body := lwr.rewriteStatement(stmt.Body)
 
     // create internal while statement
     whilestmt := boundnodes.NewBoundWhileStatementNode(stmt.Source(), cond, boundnodes.NewBoundBlockStatementNode(
@@ -288,13 +342,13 @@ func rewriteForStatement(stmt *boundnodes.BoundForStatementNode) boundnodes.Boun
             act,
         }),
         stmt.BreakLbl,
-        generateLabel(),
+        lwr.generateLabel(),
     )
 
 
     // assemble it all
     stmts = append(stmts, decl)
-    stmts = append(stmts, rewriteStatement(whilestmt))
+    stmts = append(stmts, lwr.rewriteStatement(whilestmt))
 
     // delete variable if decl was used for declaration
     if decl.Type() == boundnodes.BT_DeclarationStmt {
@@ -307,7 +361,100 @@ func rewriteForStatement(stmt *boundnodes.BoundForStatementNode) boundnodes.Boun
     return boundnodes.NewBoundBlockStatementNode(stmt.Source(), stmts)
 }
 
-func rewriteLoopStatement(stmt *boundnodes.BoundLoopStatementNode) boundnodes.BoundStatementNode {
+func (lwr *Lowerer) rewriteForEachStatement(stmt *boundnodes.BoundForEachStatementNode) boundnodes.BoundStatementNode {
+    // for <i>, <x> in <collection> { <body> }
+    // ---------------------------------------
+    // var <collection> <- <collection>
+    // var <length> <- <collection>->Length()
+    // for (var <index> <- 0; <index> < <length>; <index> <- <index> + 1) {
+    //      var <i> <- <index>
+    //      var <x> <- <collection>[<index>]   (or <collection>->Get(<index>))
+    //      <body>
+    // }
+    // delete <length>
+    // delete <collection>
+
+    stmts := []boundnodes.BoundStatementNode{}
+    inttyp := compunit.GlobalDataType("int")
+
+    // rewrite the collection
+    collection := lwr.rewriteExpression(stmt.Collection)
+
+    // create a new variable symbol for it (so it only gets evaluated once)
+    collectionVar := symbols.NewLocalSymbol("__collection", collection.ExprType())
+
+    // create collection variable declaration, deletion and access
+    collectionDeclaration := boundnodes.NewBoundDeclarationStatementNode(stmt.Source(), collectionVar, collection, true)
+    collectionDeletion := boundnodes.NewBoundDeleteStatementNode(stmt.Source(), collectionVar)
+    collectionExpression := boundnodes.NewBoundNameExpressionNode(stmt.Source(), collectionVar)
+
+    // the length only gets asked for once
+    // (so pushing onto an array while going through it doesnt go on forever)
+    lengthVar := symbols.NewLocalSymbol("__length", inttyp)
+    lengthDeclaration := boundnodes.NewBoundDeclarationStatementNode(stmt.Source(), lengthVar,
+        boundnodes.NewBoundAccessCallExpressionNode(stmt.Source(), collectionExpression, stmt.LengthMethod, []boundnodes.BoundExpressionNode{}, inttyp, false), true)
+    lengthDeletion := boundnodes.NewBoundDeleteStatementNode(stmt.Source(), lengthVar)
+    lengthExpression := boundnodes.NewBoundNameExpressionNode(stmt.Source(), lengthVar)
+
+    // create the hidden index
+    indexVar := symbols.NewLocalSymbol("__index", inttyp)
+    indexDeclaration := boundnodes.NewBoundDeclarationStatementNode(stmt.Source(), indexVar, boundnodes.NewBoundLiteralExpressionNode(stmt.Source(), inttyp, int32(0)), true)
+    indexExpression := boundnodes.NewBoundNameExpressionNode(stmt.Source(), indexVar)
+
+    // create the loop condition
+    // index < length
+    condition := boundnodes.NewBoundBinaryExpressionNode(
+        stmt.Source(),
+        boundnodes.NewBoundBinaryOperator(boundnodes.BO_LessThan, inttyp, inttyp, compunit.GlobalDataType("bool")),
+        indexExpression,
+        lengthExpression,
+    )
+
+    // create the loop action
+    // index <- index + 1
+    action := boundnodes.NewBoundExpressionStatementNode(stmt.Source(), boundnodes.NewBoundAssignmentExpressionNode(
+        stmt.Source(),
+        indexExpression,
+        boundnodes.NewBoundBinaryExpressionNode(
+            stmt.Source(),
+            boundnodes.NewBoundBinaryOperator(boundnodes.BO_Addition, inttyp, inttyp, inttyp),
+            indexExpression,
+            boundnodes.NewBoundLiteralExpressionNode(stmt.Source(), inttyp, int32(1)),
+        ),
+    ))
+
+    // fetch the current element
+    var element boundnodes.BoundExpressionNode
+    if stmt.GetMethod == nil {
+        element = boundnodes.NewBoundArrayIndexExpressionNode(stmt.Source(), collectionExpression, indexExpression)
+    } else {
+        element = boundnodes.NewBoundAccessCallExpressionNode(stmt.Source(), collectionExpression, stmt.GetMethod, []boundnodes.BoundExpressionNode{indexExpression}, stmt.Variable.VarType(), false)
+    }
+
+    // the loop variables get declared fresh every round
+    // (changing them doesnt mess with the loop and lambdas get their own copy)
+    body := []boundnodes.BoundStatementNode{}
+    if stmt.HasIndex {
+        body = append(body, boundnodes.NewBoundDeclarationStatementNode(stmt.Source(), stmt.Index, indexExpression, true))
+    }
+
+    body = append(body, boundnodes.NewBoundDeclarationStatementNode(stmt.Source(), stmt.Variable, element, true))
+    body = append(body, stmt.Body)
+
+    // create internal for statement
+    forstmt := boundnodes.NewBoundForStatementNode(stmt.Source(), indexDeclaration, condition, action, boundnodes.NewBoundBlockStatementNode(stmt.Source(), body), stmt.BreakLbl, stmt.ContinueLbl)
+
+    // assemble it all
+    stmts = append(stmts, collectionDeclaration)
+    stmts = append(stmts, lengthDeclaration)
+    stmts = append(stmts, lwr.rewriteStatement(forstmt))
+    stmts = append(stmts, lengthDeletion)
+    stmts = append(stmts, collectionDeletion)
+
+    return boundnodes.NewBoundBlockStatementNode(stmt.Source(), stmts)
+}
+
+func (lwr *Lowerer) rewriteLoopStatement(stmt *boundnodes.BoundLoopStatementNode) boundnodes.BoundStatementNode {
     // loop(<amount>) { <body> } 
     // -------------------------
     // from <i> <- 0 to <amount> {
@@ -315,39 +462,63 @@ func rewriteLoopStatement(stmt *boundnodes.BoundLoopStatementNode) boundnodes.Bo
     // }
 
     // create iterator
-    iterator := symbols.NewLocalSymbol("__iterator", compunit.GlobalDataTypeRegister["int"])
+    iterator := symbols.NewLocalSymbol("__iterator", compunit.GlobalDataType("int"))
 
     // create zero initializer
-    zeroLit := boundnodes.NewBoundLiteralExpressionNode(stmt.Source(), compunit.GlobalDataTypeRegister["int"], int32(0))
+    zeroLit := boundnodes.NewBoundLiteralExpressionNode(stmt.Source(), compunit.GlobalDataType("int"), int32(0))
 
     // rewrite original upper bound
-    upperBound := rewriteExpression(stmt.Amount)
+    upperBound := lwr.rewriteExpression(stmt.Amount)
 
     // rewrite original body
-    body := rewriteStatement(stmt.Body)
+    body := lwr.rewriteStatement(stmt.Body)
 
     // create from-to statement
     fromtostmt := boundnodes.NewBoundFromToStatementNode(stmt.Source(), iterator, zeroLit, upperBound, body, stmt.BreakLabel(), stmt.ContinueLabel())
 
-    return rewriteStatement(fromtostmt)
+    return lwr.rewriteStatement(fromtostmt)
 }
 
-func rewriteBlockStatement(stmt *boundnodes.BoundBlockStatementNode) boundnodes.BoundStatementNode {
+func (lwr *Lowerer) rewriteBlockStatement(stmt *boundnodes.BoundBlockStatementNode) boundnodes.BoundStatementNode {
     stmts := []boundnodes.BoundStatementNode{}
 
     for _, v := range stmt.Statements {
-        stmts = append(stmts, rewriteStatement(v))
+        stmts = append(stmts, lwr.rewriteStatement(v))
     }
 
     return boundnodes.NewBoundBlockStatementNode(stmt.Source(), stmts)
 }
 
-func rewriteExpressionStatement(stmt *boundnodes.BoundExpressionStatementNode) boundnodes.BoundStatementNode {
-    expr := rewriteExpression(stmt.Expression)
+func (lwr *Lowerer) rewriteExpressionStatement(stmt *boundnodes.BoundExpressionStatementNode) boundnodes.BoundStatementNode {
+    // compound assignments get special treatment when nobody wants their value
+    if stmt.Expression.Type() == boundnodes.BT_CompoundExpr {
+        return lwr.rewriteCompoundAssignmentStatement(stmt.Expression.(*boundnodes.BoundCompoundAssignmentExpressionNode))
+    }
+
+    expr := lwr.rewriteExpression(stmt.Expression)
     return boundnodes.NewBoundExpressionStatementNode(stmt.Source(), expr)
 }
 
-func rewriteIfStatement(stmt *boundnodes.BoundIfStatementNode) boundnodes.BoundStatementNode {
+func (lwr *Lowerer) rewriteCompoundAssignmentStatement(expr *boundnodes.BoundCompoundAssignmentExpressionNode) boundnodes.BoundStatementNode {
+    // <arr>[<idx>] +<- <val>
+    // ----------------------
+    // var __target <- <arr>
+    // var __index <- <idx>
+    // __target[__index] <- __target[__index] + <val>
+    // delete __target, __index
+    //
+    // (same for field accesses, that way side effects only happen once)
+    stmts := []boundnodes.BoundStatementNode{}
+    target := lwr.hoistCompoundTarget(expr, &stmts)
+
+    // the old value isnt needed here, so x++ is the same as ++x
+    assignment := lwr.lowerCompoundAssignment(expr, target, target, lwr.rewriteExpression(expr.Value))
+    stmts = append(stmts, boundnodes.NewBoundExpressionStatementNode(expr.Source(), assignment))
+
+    return boundnodes.NewBoundBlockStatementNode(expr.Source(), stmts)
+}
+
+func (lwr *Lowerer) rewriteIfStatement(stmt *boundnodes.BoundIfStatementNode) boundnodes.BoundStatementNode {
     stmts := []boundnodes.BoundStatementNode{}
 
     // if (<cond>) {
@@ -361,13 +532,13 @@ func rewriteIfStatement(stmt *boundnodes.BoundIfStatementNode) boundnodes.BoundS
     // .end:
     if !stmt.HasElse {
 
-        inner := generateLabel()
-        end := generateLabel()
+        inner := lwr.generateLabel()
+        end := lwr.generateLabel()
 
         stmts = append(stmts, boundnodes.NewBoundGotoIfStatementNode(stmt.Source(), inner, stmt.Condition))
         stmts = append(stmts, boundnodes.NewBoundGotoStatementNode(stmt.Source(), end))
         stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.Source(), inner))
-        stmts = append(stmts, rewriteStatement(stmt.Body))
+        stmts = append(stmts, lwr.rewriteStatement(stmt.Body))
         stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.SourceNode, end))
 
     // if (<cond>) {
@@ -386,17 +557,17 @@ func rewriteIfStatement(stmt *boundnodes.BoundIfStatementNode) boundnodes.BoundS
     // .end:
     } else {
 
-        inner := generateLabel()
-        els := generateLabel()
-        end := generateLabel()
+        inner := lwr.generateLabel()
+        els := lwr.generateLabel()
+        end := lwr.generateLabel()
 
         stmts = append(stmts, boundnodes.NewBoundGotoIfStatementNode(stmt.Source(), inner, stmt.Condition))
         stmts = append(stmts, boundnodes.NewBoundGotoStatementNode(stmt.Source(), els))
         stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.Source(), inner))
-        stmts = append(stmts, rewriteStatement(stmt.Body))
+        stmts = append(stmts, lwr.rewriteStatement(stmt.Body))
         stmts = append(stmts, boundnodes.NewBoundGotoStatementNode(stmt.Source(), end))
         stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.Source(), els))
-        stmts = append(stmts, rewriteStatement(stmt.ElseBody))
+        stmts = append(stmts, lwr.rewriteStatement(stmt.ElseBody))
         stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.SourceNode, end))
 
     }
@@ -404,136 +575,401 @@ func rewriteIfStatement(stmt *boundnodes.BoundIfStatementNode) boundnodes.BoundS
     return boundnodes.NewBoundBlockStatementNode(stmt.Source(), stmts)
 }
 
+func (lwr *Lowerer) rewriteTryStatement(stmt *boundnodes.BoundTryStatementNode) boundnodes.BoundStatementNode {
+    // try { <body> } catch (<var> Error) { <catch body> }
+    // ---------------------------------------------------
+    // protect .start .end -> .catch <var>
+    // .start:
+    // <body>
+    // .end:
+    // goto .done
+    // .catch:
+    // <catch body>
+    // .done:
+    stmts := []boundnodes.BoundStatementNode{}
+
+    start := lwr.generateLabel()
+    end := lwr.generateLabel()
+    catch := lwr.generateLabel()
+    done := lwr.generateLabel()
+
+    stmts = append(stmts, boundnodes.NewBoundProtectStatementNode(stmt.Source(), start, end, catch, stmt.ErrorVariable))
+    stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.Source(), start))
+    stmts = append(stmts, lwr.rewriteStatement(stmt.Body))
+    stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.Source(), end))
+    stmts = append(stmts, boundnodes.NewBoundGotoStatementNode(stmt.Source(), done))
+    stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.Source(), catch))
+    stmts = append(stmts, lwr.rewriteStatement(stmt.CatchBody))
+    stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.Source(), done))
+
+    return boundnodes.NewBoundBlockStatementNode(stmt.Source(), stmts)
+}
+
+func (lwr *Lowerer) rewriteThrowStatement(stmt *boundnodes.BoundThrowStatementNode) boundnodes.BoundStatementNode {
+    err := lwr.rewriteExpression(stmt.Error)
+    return boundnodes.NewBoundThrowStatementNode(stmt.Source(), err)
+}
+
+func (lwr *Lowerer) rewriteSwitchStatement(stmt *boundnodes.BoundSwitchStatementNode) boundnodes.BoundStatementNode {
+    // switch (<subject>) { case <a>, <b>: <body> case <var> <T>: <body> default: <body> }
+    // ----------------------------------------------------------------------------------
+    // var __subject <- <subject>
+    // gotoif __subject = <a> .case1
+    // gotoif __subject = <b> .case1
+    // gotoif __subject is <T> .case2
+    // goto .default (or .end if there is none)
+    // .case1:
+    // <body>
+    // goto .end
+    // .case2:
+    // var <var> <T> <- <T>(__subject)
+    // <body>
+    // goto .end
+    // .default:
+    // <body>
+    // .end:
+    // delete __subject
+    stmts := []boundnodes.BoundStatementNode{}
+
+    // evaluate the subject only once
+    subjectVar := symbols.NewLocalSymbol("__subject", stmt.Subject.ExprType())
+    subjectDeclaration := boundnodes.NewBoundDeclarationStatementNode(stmt.Source(), subjectVar, lwr.rewriteExpression(stmt.Subject), true)
+    subjectDeletion := boundnodes.NewBoundDeleteStatementNode(stmt.Source(), subjectVar)
+    subjectExpression := boundnodes.NewBoundNameExpressionNode(stmt.Source(), subjectVar)
+
+    stmts = append(stmts, subjectDeclaration)
+
+    end := lwr.generateLabel()
+    labels := []boundnodes.BoundLabel{}
+
+    // jump to the first case that matches
+    for _, cse := range stmt.Cases {
+        lbl := lwr.generateLabel()
+        labels = append(labels, lbl)
+
+        if cse.IsPattern {
+            check := boundnodes.NewBoundTypeCheckExpressionNode(cse.SourceNode, subjectExpression, cse.PatternType)
+            stmts = append(stmts, boundnodes.NewBoundGotoIfStatementNode(cse.SourceNode, lbl, check))
+            continue
+        }
+
+        for _, v := range cse.Values {
+            cond := boundnodes.NewBoundBinaryExpressionNode(v.Source(), stmt.Operator, subjectExpression, lwr.rewriteExpression(v))
+            stmts = append(stmts, boundnodes.NewBoundGotoIfStatementNode(v.Source(), lbl, cond))
+        }
+    }
+
+    // nothing matched
+    dflt := end
+    if stmt.HasDefault {
+        dflt = lwr.generateLabel()
+    }
+
+    stmts = append(stmts, boundnodes.NewBoundGotoStatementNode(stmt.Source(), dflt))
+
+    // all the case bodies
+    for i, cse := range stmt.Cases {
+        stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(cse.SourceNode, labels[i]))
+
+        body := lwr.rewriteStatement(cse.Body)
+
+        // patterns get their narrowed variable
+        if cse.IsPattern {
+            narrowed := boundnodes.NewBoundConversionExpressionNode(cse.SourceNode, subjectExpression, cse.PatternType)
+            body = boundnodes.NewBoundBlockStatementNode(cse.SourceNode, []boundnodes.BoundStatementNode{
+                boundnodes.NewBoundDeclarationStatementNode(cse.SourceNode, cse.PatternVariable, narrowed, true),
+                body,
+            })
+        }
+
+        stmts = append(stmts, body)
+        stmts = append(stmts, boundnodes.NewBoundGotoStatementNode(cse.SourceNode, end))
+    }
+
+    if stmt.HasDefault {
+        stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.DefaultBody.Source(), dflt))
+        stmts = append(stmts, lwr.rewriteStatement(stmt.DefaultBody))
+    }
+
+    stmts = append(stmts, boundnodes.NewBoundLabelStatementNode(stmt.Source(), end))
+    stmts = append(stmts, subjectDeletion)
+
+    return boundnodes.NewBoundBlockStatementNode(stmt.Source(), stmts)
+}
+
 // --------------------------------------------------------
 // Expressions
 // --------------------------------------------------------
-func rewriteExpression(expr boundnodes.BoundExpressionNode) boundnodes.BoundExpressionNode {
+func (lwr *Lowerer) rewriteExpression(expr boundnodes.BoundExpressionNode) boundnodes.BoundExpressionNode {
     // god please end my suffering
     if expr.Type() == boundnodes.BT_LiteralExpr {
-        return rewriteLiteralExpression(expr.(*boundnodes.BoundLiteralExpressionNode))
+        return lwr.rewriteLiteralExpression(expr.(*boundnodes.BoundLiteralExpressionNode))
     } else if expr.Type() == boundnodes.BT_AssignmentExpr {
-        return rewriteAssignmentExpression(expr.(*boundnodes.BoundAssignmentExpressionNode))
+        return lwr.rewriteAssignmentExpression(expr.(*boundnodes.BoundAssignmentExpressionNode))
+    } else if expr.Type() == boundnodes.BT_CompoundExpr {
+        return lwr.rewriteCompoundAssignmentExpression(expr.(*boundnodes.BoundCompoundAssignmentExpressionNode))
     } else if expr.Type() == boundnodes.BT_UnaryExpr {
-        return rewriteUnaryExpression(expr.(*boundnodes.BoundUnaryExpressionNode))
+        return lwr.rewriteUnaryExpression(expr.(*boundnodes.BoundUnaryExpressionNode))
     } else if expr.Type() == boundnodes.BT_BinaryExpr {
-        return rewriteBinaryExpression(expr.(*boundnodes.BoundBinaryExpressionNode))
+        return lwr.rewriteBinaryExpression(expr.(*boundnodes.BoundBinaryExpressionNode))
     } else if expr.Type() == boundnodes.BT_CallExpr {
-        return rewriteCallExpression(expr.(*boundnodes.BoundCallExpressionNode))
+        return lwr.rewriteCallExpression(expr.(*boundnodes.BoundCallExpressionNode))
     } else if expr.Type() == boundnodes.BT_NameExpr {
-        return rewriteNameExpression(expr.(*boundnodes.BoundNameExpressionNode))
+        return lwr.rewriteNameExpression(expr.(*boundnodes.BoundNameExpressionNode))
     } else if expr.Type() == boundnodes.BT_ConversionExpr {
-        return rewriteConversionExpression(expr.(*boundnodes.BoundConversionExpressionNode))
+        return lwr.rewriteConversionExpression(expr.(*boundnodes.BoundConversionExpressionNode))
     } else if expr.Type() == boundnodes.BT_MakeArrayExpr {
-        return rewriteMakeArrayExpression(expr.(*boundnodes.BoundMakeArrayExpressionNode))
+        return lwr.rewriteMakeArrayExpression(expr.(*boundnodes.BoundMakeArrayExpressionNode))
+    } else if expr.Type() == boundnodes.BT_MakeMapExpr {
+        return lwr.rewriteMakeMapExpression(expr.(*boundnodes.BoundMakeMapExpressionNode))
     } else if expr.Type() == boundnodes.BT_ArrayIndexExpr {
-        return rewriteArrayIndexExpression(expr.(*boundnodes.BoundArrayIndexExpressionNode))
+        return lwr.rewriteArrayIndexExpression(expr.(*boundnodes.BoundArrayIndexExpressionNode))
     } else if expr.Type() == boundnodes.BT_AccessCallExpr {
-        return rewriteAccessCallExpression(expr.(*boundnodes.BoundAccessCallExpressionNode))
+        return lwr.rewriteAccessCallExpression(expr.(*boundnodes.BoundAccessCallExpressionNode))
     } else if expr.Type() == boundnodes.BT_MakeExpr {
-        return rewriteMakeExpression(expr.(*boundnodes.BoundMakeExpressionNode))
+        return lwr.rewriteMakeExpression(expr.(*boundnodes.BoundMakeExpressionNode))
     } else if expr.Type() == boundnodes.BT_AccessFieldExpr {
-        return rewriteAccessFieldExpression(expr.(*boundnodes.BoundAccessFieldExpressionNode))
+        return lwr.rewriteAccessFieldExpression(expr.(*boundnodes.BoundAccessFieldExpressionNode))
+    } else if expr.Type() == boundnodes.BT_TypeCheckExpr {
+        return lwr.rewriteTypeCheckExpression(expr.(*boundnodes.BoundTypeCheckExpressionNode))
+    } else if expr.Type() == boundnodes.BT_FunctionExpr {
+        return lwr.rewriteFunctionExpression(expr.(*boundnodes.BoundFunctionExpressionNode))
+    } else if expr.Type() == boundnodes.BT_CallValueExpr {
+        return lwr.rewriteCallValueExpression(expr.(*boundnodes.BoundCallValueExpressionNode))
+    } else if expr.Type() == boundnodes.BT_CheckedCastExpr {
+        return lwr.rewriteCheckedCastExpression(expr.(*boundnodes.BoundCheckedCastExpressionNode))
 
     } else {
-        error.Report(error.NewError(error.LWR, expr.Source().Position(), "Unable to rewrite expression '%s', no rewriter implemented! You should implement NOW!", expr.Type()))
+        lwr.Comp.Report(error.NewError(error.LWR, expr.Source().Position(), "Unable to rewrite expression '%s', no rewriter implemented! You should implement NOW!", expr.Type()))
         return expr
     }
 }
 
-func rewriteLiteralExpression(expr *boundnodes.BoundLiteralExpressionNode) boundnodes.BoundExpressionNode {
+func (lwr *Lowerer) rewriteLiteralExpression(expr *boundnodes.BoundLiteralExpressionNode) boundnodes.BoundExpressionNode {
     return expr // no way
 }
 
-func rewriteAssignmentExpression(expr *boundnodes.BoundAssignmentExpressionNode) boundnodes.BoundExpressionNode {
-    exp := rewriteExpression(expr.Expression)
-    val := rewriteExpression(expr.Value)
+func (lwr *Lowerer) rewriteAssignmentExpression(expr *boundnodes.BoundAssignmentExpressionNode) boundnodes.BoundExpressionNode {
+    exp := lwr.rewriteExpression(expr.Expression)
+    val := lwr.rewriteExpression(expr.Value)
     return boundnodes.NewBoundAssignmentExpressionNode(expr.Source(), exp, val)
 }
 
-func rewriteUnaryExpression(expr *boundnodes.BoundUnaryExpressionNode) boundnodes.BoundExpressionNode {
-    operand := rewriteExpression(expr.Operand)
+func (lwr *Lowerer) rewriteCompoundAssignmentExpression(expr *boundnodes.BoundCompoundAssignmentExpressionNode) boundnodes.BoundExpressionNode {
+    // <arr>[<idx>] +<- <val>
+    // ----------------------
+    // (var __target <- <arr>,
+    //  var __index <- <idx>
+    //  => __target[__index] <- __target[__index] + <val>)
+    //
+    // <arr>[<idx>]++
+    // --------------
+    // (var __target <- <arr>,
+    //  var __index <- <idx>,
+    //  var __old <- __target[__index],
+    //  __target[__index] <- __old + 1
+    //  => __old)
+    stmts := []boundnodes.BoundStatementNode{}
+    target := lwr.hoistCompoundTarget(expr, &stmts)
+    val := lwr.rewriteExpression(expr.Value)
+
+    // postfix operators hand out the old value
+    if expr.IsPostfix {
+        old := symbols.NewLocalSymbol("__old", target.ExprType())
+        oldExpr := boundnodes.NewBoundNameExpressionNode(expr.Source(), old)
+
+        stmts = append(stmts, boundnodes.NewBoundDeclarationStatementNode(expr.Source(), old, target, true))
+        stmts = append(stmts, boundnodes.NewBoundExpressionStatementNode(expr.Source(), lwr.lowerCompoundAssignment(expr, target, oldExpr, val)))
+
+        return boundnodes.NewBoundSequenceExpressionNode(expr.Source(), stmts, oldExpr)
+    }
+
+    assignment := lwr.lowerCompoundAssignment(expr, target, target, val)
+
+    // nothing had to be remembered -> no need for a sequence
+    if len(stmts) == 0 {
+        return assignment
+    }
+
+    return boundnodes.NewBoundSequenceExpressionNode(expr.Source(), stmts, assignment)
+}
+
+// Stores the parts of a compound target in hidden locals
+// (arrays, indices and field owners should only be evaluated once)
+// ----------------------------------------------------------------
+func (lwr *Lowerer) hoistCompoundTarget(expr *boundnodes.BoundCompoundAssignmentExpressionNode, stmts *[]boundnodes.BoundStatementNode) boundnodes.BoundExpressionNode {
+    // stores an expression in a hidden local if its not a simple one
+    hoist := func(name string, exp boundnodes.BoundExpressionNode) boundnodes.BoundExpressionNode {
+        if exp.Type() == boundnodes.BT_NameExpr || exp.Type() == boundnodes.BT_LiteralExpr {
+            return exp
+        }
+
+        vari := symbols.NewLocalSymbol(name, exp.ExprType())
+        *stmts = append(*stmts, boundnodes.NewBoundDeclarationStatementNode(expr.Source(), vari, exp, true))
+        return boundnodes.NewBoundNameExpressionNode(expr.Source(), vari)
+    }
+
+    target := lwr.rewriteExpression(expr.Expression)
+
+    if target.Type() == boundnodes.BT_ArrayIndexExpr {
+        idx := target.(*boundnodes.BoundArrayIndexExpressionNode)
+        src := hoist("__target", idx.SourceArray)
+        index := hoist("__index", idx.Index)
+        target = boundnodes.NewBoundArrayIndexExpressionNode(idx.Source(), src, index)
+
+    } else if target.Type() == boundnodes.BT_AccessFieldExpr {
+        fld := target.(*boundnodes.BoundAccessFieldExpressionNode)
+        src := hoist("__target", fld.Expression)
+        target = boundnodes.NewBoundAccessFieldExpressionNode(fld.Source(), src, fld.Field, fld.FieldType, fld.NullSafe)
+    }
+
+    return target
+}
+
+// builds the actual assignment for a compound: <target> <- <current> <op> <val>
+// (current is whatever holds the value of the target right now, usually the target itself)
+func (lwr *Lowerer) lowerCompoundAssignment(expr *boundnodes.BoundCompoundAssignmentExpressionNode, target boundnodes.BoundExpressionNode, current boundnodes.BoundExpressionNode, val boundnodes.BoundExpressionNode) boundnodes.BoundExpressionNode {
+    op := expr.Operator
+    typ := target.ExprType()
+
+    // load the current value (and widen it if the operator wants that)
+    var left boundnodes.BoundExpressionNode = current
+    if !op.Left.Equal(typ) {
+        left = boundnodes.NewBoundConversionExpressionNode(expr.Source(), left, op.Left)
+    }
+
+    // do the math
+    var result boundnodes.BoundExpressionNode = boundnodes.NewBoundBinaryExpressionNode(expr.Source(), op, left, val)
+
+    // squeeze the result back into the target type
+    if !op.Result.Equal(typ) {
+        result = boundnodes.NewBoundConversionExpressionNode(expr.Source(), result, typ)
+    }
+
+    return boundnodes.NewBoundAssignmentExpressionNode(expr.Source(), target, result)
+}
+
+func (lwr *Lowerer) rewriteUnaryExpression(expr *boundnodes.BoundUnaryExpressionNode) boundnodes.BoundExpressionNode {
+    operand := lwr.rewriteExpression(expr.Operand)
     return boundnodes.NewBoundUnaryExpressionNode(expr.Source(), expr.Operator, operand)
 }
 
-func rewriteBinaryExpression(expr *boundnodes.BoundBinaryExpressionNode) boundnodes.BoundExpressionNode {
-    left := rewriteExpression(expr.Left)
-    right := rewriteExpression(expr.Right)
+func (lwr *Lowerer) rewriteBinaryExpression(expr *boundnodes.BoundBinaryExpressionNode) boundnodes.BoundExpressionNode {
+    left := lwr.rewriteExpression(expr.Left)
+    right := lwr.rewriteExpression(expr.Right)
 
     return boundnodes.NewBoundBinaryExpressionNode(expr.Source(), expr.Operator, left, right)
 }
 
-func rewriteCallExpression(expr *boundnodes.BoundCallExpressionNode) boundnodes.BoundExpressionNode {
+func (lwr *Lowerer) rewriteCallExpression(expr *boundnodes.BoundCallExpressionNode) boundnodes.BoundExpressionNode {
     args := []boundnodes.BoundExpressionNode{}
 
     for _, v := range expr.Arguments {
-        args = append(args, rewriteExpression(v))
+        args = append(args, lwr.rewriteExpression(v))
     }
 
-    return boundnodes.NewBoundCallExpressionNode(expr.Source(), expr.Function, args)
+    return boundnodes.NewBoundCallExpressionNode(expr.Source(), expr.Function, args, expr.ReturnType)
 }
 
-func rewriteNameExpression(expr *boundnodes.BoundNameExpressionNode) boundnodes.BoundExpressionNode {
+func (lwr *Lowerer) rewriteNameExpression(expr *boundnodes.BoundNameExpressionNode) boundnodes.BoundExpressionNode {
     return expr
 }
 
-func rewriteConversionExpression(expr *boundnodes.BoundConversionExpressionNode) boundnodes.BoundExpressionNode {
-    val := rewriteExpression(expr.Value)
+func (lwr *Lowerer) rewriteConversionExpression(expr *boundnodes.BoundConversionExpressionNode) boundnodes.BoundExpressionNode {
+    val := lwr.rewriteExpression(expr.Value)
     return boundnodes.NewBoundConversionExpressionNode(expr.Source(), val, expr.TargetType)
 }
 
-func rewriteMakeArrayExpression(expr *boundnodes.BoundMakeArrayExpressionNode) boundnodes.BoundExpressionNode {
+func (lwr *Lowerer) rewriteMakeArrayExpression(expr *boundnodes.BoundMakeArrayExpressionNode) boundnodes.BoundExpressionNode {
     if !expr.HasInitializer {
-        length := rewriteExpression(expr.Length)
+        length := lwr.rewriteExpression(expr.Length)
         return boundnodes.NewBoundMakeArrayExpressionNode(expr.Source(), expr.ArrType, length, expr.Initializer, expr.HasInitializer)
     } else {
         initializers := []boundnodes.BoundExpressionNode{}
 
         for _, v := range expr.Initializer {
-            initializers = append(initializers, rewriteExpression(v))
+            initializers = append(initializers, lwr.rewriteExpression(v))
         }
 
         return boundnodes.NewBoundMakeArrayExpressionNode(expr.Source(), expr.ArrType, expr.Length, initializers, expr.HasInitializer)
      }
 }
 
-func rewriteArrayIndexExpression(expr *boundnodes.BoundArrayIndexExpressionNode) boundnodes.BoundExpressionNode {
-    src := rewriteExpression(expr.SourceArray)
-    idx := rewriteExpression(expr.Index)
+func (lwr *Lowerer) rewriteMakeMapExpression(expr *boundnodes.BoundMakeMapExpressionNode) boundnodes.BoundExpressionNode {
+    keys := []boundnodes.BoundExpressionNode{}
+    vals := []boundnodes.BoundExpressionNode{}
+
+    for i := range expr.Keys {
+        keys = append(keys, lwr.rewriteExpression(expr.Keys[i]))
+        vals = append(vals, lwr.rewriteExpression(expr.Values[i]))
+    }
+
+    return boundnodes.NewBoundMakeMapExpressionNode(expr.Source(), expr.MapType, keys, vals)
+}
+
+func (lwr *Lowerer) rewriteArrayIndexExpression(expr *boundnodes.BoundArrayIndexExpressionNode) boundnodes.BoundExpressionNode {
+    src := lwr.rewriteExpression(expr.SourceArray)
+    idx := lwr.rewriteExpression(expr.Index)
 
     return boundnodes.NewBoundArrayIndexExpressionNode(expr.Source(), src, idx)
 }
 
-func rewriteAccessCallExpression(expr *boundnodes.BoundAccessCallExpressionNode) boundnodes.BoundExpressionNode {
-    src := rewriteExpression(expr.Expression)
+func (lwr *Lowerer) rewriteAccessCallExpression(expr *boundnodes.BoundAccessCallExpressionNode) boundnodes.BoundExpressionNode {
+    src := lwr.rewriteExpression(expr.Expression)
     args := []boundnodes.BoundExpressionNode{}
 
     for _, v := range expr.Arguments {
-        args = append(args, rewriteExpression(v))
+        args = append(args, lwr.rewriteExpression(v))
     } 
 
-    return boundnodes.NewBoundAccessCallExpressionNode(expr.Source(), src, expr.Function, args)
+    return boundnodes.NewBoundAccessCallExpressionNode(expr.Source(), src, expr.Function, args, expr.ReturnType, expr.NullSafe)
 }
 
-func rewriteMakeExpression(expr *boundnodes.BoundMakeExpressionNode) boundnodes.BoundExpressionNode {
+func (lwr *Lowerer) rewriteMakeExpression(expr *boundnodes.BoundMakeExpressionNode) boundnodes.BoundExpressionNode {
     initializer := expr.Initializer
     args := expr.Arguments
 
     if expr.HasInitializer {
         for k, v := range initializer {
-            initializer[k] = rewriteExpression(v)
+            initializer[k] = lwr.rewriteExpression(v)
         }
     }
 
     if expr.HasConstructor {
         for i, v := range args {
-            args[i] = rewriteExpression(v)
+            args[i] = lwr.rewriteExpression(v)
         }
     }
 
-    return boundnodes.NewBoundMakeExpressionNode(expr.Source(), expr.Container, initializer, expr.HasInitializer, args, expr.HasConstructor)
+    return boundnodes.NewBoundMakeExpressionNode(expr.Source(), expr.Container, expr.ContainerType, initializer, expr.HasInitializer, args, expr.HasConstructor)
+}
+
+func (lwr *Lowerer) rewriteAccessFieldExpression(expr *boundnodes.BoundAccessFieldExpressionNode) boundnodes.BoundExpressionNode {
+    src := lwr.rewriteExpression(expr.Expression)
+    return boundnodes.NewBoundAccessFieldExpressionNode(expr.SourceNode,src, expr.Field, expr.FieldType, expr.NullSafe)
+}
+
+func (lwr *Lowerer) rewriteTypeCheckExpression(expr *boundnodes.BoundTypeCheckExpressionNode) boundnodes.BoundExpressionNode {
+    val := lwr.rewriteExpression(expr.Value)
+    return boundnodes.NewBoundTypeCheckExpressionNode(expr.Source(), val, expr.CheckType)
+}
+
+func (lwr *Lowerer) rewriteCheckedCastExpression(expr *boundnodes.BoundCheckedCastExpressionNode) boundnodes.BoundExpressionNode {
+    val := lwr.rewriteExpression(expr.Value)
+    return boundnodes.NewBoundCheckedCastExpressionNode(expr.Source(), val, expr.TargetType)
+}
+
+func (lwr *Lowerer) rewriteFunctionExpression(expr *boundnodes.BoundFunctionExpressionNode) boundnodes.BoundExpressionNode {
+    return expr // lambda bodies get lowered on their own
 }
 
-func rewriteAccessFieldExpression(expr *boundnodes.BoundAccessFieldExpressionNode) boundnodes.BoundExpressionNode {
-    src := rewriteExpression(expr.Expression)
-    return boundnodes.NewBoundAccessFieldExpressionNode(expr.SourceNode,src, expr.Field)
+func (lwr *Lowerer) rewriteCallValueExpression(expr *boundnodes.BoundCallValueExpressionNode) boundnodes.BoundExpressionNode {
+    val := lwr.rewriteExpression(expr.Value)
+    args := []boundnodes.BoundExpressionNode{}
+
+    for _, v := range expr.Arguments {
+        args = append(args, lwr.rewriteExpression(v))
+    }
+
+    return boundnodes.NewBoundCallValueExpressionNode(expr.Source(), val, args)
 }
@@ -0,0 +1,404 @@
+// LSP - analysis.go
+// --------------------------------------------------------
+// Runs open documents through the compiler (up until the
+// binder) and remembers what it learned
+// --------------------------------------------------------
+package lsp
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bytespace.network/rerect/binder"
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/error"
+	"bytespace.network/rerect/lexer"
+	packageprocessor "bytespace.network/rerect/package_processor"
+	"bytespace.network/rerect/parser"
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Document struct
+// ---------------
+type Document struct {
+    URI string
+    Path string
+    Text string
+    Version int
+
+    Analysis *Analysis  // analysis of the current text
+    LastBound *Analysis // last analysis that made it through the binder (positions might be slightly outdated)
+}
+
+// Analysis struct
+// ---------------
+type Analysis struct {
+    Comp *compunit.Compilation
+    Source int                                  // index of the document in Comp.SourceFiles
+    Text string                                 // the text this was made from (all positions are rune indices into it)
+
+    Members []syntaxnodes.MemberNode            // members of the document (if it could be parsed)
+    File *packageprocessor.CompilationFile      // the bound document (nil if it never got to the binder)
+    Errors []error.Error                        // errors in this document
+
+    References []Reference                      // every name in the document and what it refers to
+    Declarations map[symbols.Symbol]span.Span   // where things have been declared
+    FunctionSrc map[*symbols.FunctionSymbol]*syntaxnodes.FunctionNode
+}
+
+// --------------------------------------------------------
+// Analyzing
+// --------------------------------------------------------
+func (srv *Server) analyze(doc *Document) (res *Analysis) {
+    comp := compunit.NewCompilation()
+
+    res = &Analysis{
+        Comp: comp,
+        Text: doc.Text,
+        Declarations: make(map[symbols.Symbol]span.Span),
+        FunctionSrc: make(map[*symbols.FunctionSymbol]*syntaxnodes.FunctionNode),
+    }
+
+    // only errors from the first stage that failed get reported (just like rrc check would)
+    reported := -1
+    stageDone := func() bool {
+        if comp.HasErrors() && reported < 0 {
+            reported = len(comp.Errors)
+        }
+
+        return comp.HasErrors()
+    }
+
+    // collect all errors that belong to this document
+    defer func() {
+        // the compiler isnt exactly used to half written code -> dont let it take us down
+        if r := recover(); r != nil {
+            fmt.Fprintf(os.Stderr, "rrc lsp: analysis of '%s' crashed: %v\n", doc.Path, r)
+            res.File = nil
+        }
+
+        errs := comp.Errors
+        if reported >= 0 {
+            errs = errs[:reported]
+        }
+
+        for _, err := range errs {
+            if err.Position.Internal || err.Position.File == res.Source {
+                res.Errors = append(res.Errors, err)
+            }
+        }
+    }()
+
+    // Lexing
+    // ------
+    res.Source = comp.RegisterSource(doc.Path, doc.Text)
+    tokens := lexer.LexString(comp, doc.Text, res.Source)
+
+    if stageDone() {
+        return
+    }
+
+    // Parsing
+    // -------
+    res.Members = parser.Parse(comp, tokens)
+    members := [][]syntaxnodes.MemberNode{res.Members}
+
+    // anything this document loads needs to be around too
+    for _, src := range srv.dependencies(doc, res.Members) {
+        idx := comp.RegisterSource(src.Path, src.Content)
+        members = append(members, parser.Parse(comp, lexer.LexString(comp, src.Content, idx)))
+    }
+
+    // (binding a broken tree is just asking for trouble)
+    if stageDone() {
+        return
+    }
+
+    // Package processing
+    // ------------------
+    packageprocessor.Init(comp)
+    files := packageprocessor.Process(comp, members)
+    stageDone()
+
+    // Binding
+    // -------
+    // (we keep going even if there are errors, the more we know the better)
+    for _, file := range files {
+        binder.IndexEnums(comp, file)
+    }
+    stageDone()
+
+    for _, file := range files {
+        binder.IndexTraitTypes(comp, file)
+    }
+    stageDone()
+
+    for _, file := range files {
+        binder.IndexContainerTypes(comp, file)
+    }
+    stageDone()
+
+    for _, file := range files {
+        binder.IndexTraitContents(comp, file)
+    }
+    stageDone()
+
+    for _, file := range files {
+        binder.IndexContainerContents(comp, file)
+    }
+    stageDone()
+
+    for _, file := range files {
+        binder.IndexFunctions(comp, file)
+        binder.IndexGlobals(comp, file)
+    }
+    stageDone()
+
+    for _, file := range files {
+        binder.BindConstants(comp, file)
+    }
+    stageDone()
+
+    for _, file := range files {
+        binder.BindFunctions(comp, file)
+    }
+    stageDone()
+
+    // remember what everything is
+    res.File = files[0]
+    res.index(files)
+
+    return
+}
+
+// Find the sources of all packages a document loads
+// (other open documents or files right next to it)
+// --------------------------------------------------
+func (srv *Server) dependencies(doc *Document, members []syntaxnodes.MemberNode) []compunit.SourceFile {
+    // everything that could be a dependency
+    candidates := []compunit.SourceFile{}
+    seen := map[string]bool{doc.Path: true}
+
+    for _, v := range srv.Documents {
+        if !seen[v.Path] {
+            candidates = append(candidates, compunit.SourceFile{Path: v.Path, Content: v.Text})
+            seen[v.Path] = true
+        }
+    }
+
+    paths, _ := filepath.Glob(filepath.Join(filepath.Dir(doc.Path), "*.rr"))
+    for _, path := range paths {
+        if seen[path] {
+            continue
+        }
+
+        txt, err := os.ReadFile(path)
+        if err == nil {
+            candidates = append(candidates, compunit.SourceFile{Path: path, Content: string(txt)})
+            seen[path] = true
+        }
+    }
+
+    // what packages do the candidates declare and load?
+    packages := make([]string, len(candidates))
+    loads := make([][]string, len(candidates))
+    for i, v := range candidates {
+        packages[i], loads[i] = scanSource(v.Content)
+    }
+
+    // follow the loads
+    needed := []string{}
+    for _, v := range members {
+        if v.Type() == syntaxnodes.NT_Load {
+            needed = append(needed, v.(*syntaxnodes.LoadNode).Library.Buffer)
+        }
+    }
+
+    deps := []compunit.SourceFile{}
+    used := make([]bool, len(candidates))
+    natives := nativePackages()
+
+    for len(needed) > 0 {
+        name := needed[0]
+        needed = needed[1:]
+
+        // natives are always there
+        if natives[name] {
+            continue
+        }
+
+        for i, v := range candidates {
+            if used[i] || packages[i] != name {
+                continue
+            }
+
+            used[i] = true
+            deps = append(deps, v)
+            needed = append(needed, loads[i]...)
+        }
+    }
+
+    return deps
+}
+
+// What package is a source in and what does it load?
+// ---------------------------------------------------
+func scanSource(content string) (string, []string) {
+    comp := compunit.NewCompilation()
+    tokens := lexer.LexString(comp, content, comp.RegisterSource("", content))
+
+    pck := "main"
+    loads := []string{}
+
+    for i := 0; i + 1 < len(tokens); i++ {
+        if tokens[i+1].Type != lexer.TT_Identifier {
+            continue
+        }
+
+        if tokens[i].Type == lexer.TT_KW_Package {
+            pck = tokens[i+1].Buffer
+        }
+
+        if tokens[i].Type == lexer.TT_KW_Load {
+            loads = append(loads, tokens[i+1].Buffer)
+        }
+    }
+
+    return pck, loads
+}
+
+// Names of all native packages
+// ----------------------------
+var natives map[string]bool
+
+func nativePackages() map[string]bool {
+    if natives == nil {
+        comp := compunit.NewCompilation()
+        packageprocessor.Init(comp)
+
+        natives = make(map[string]bool)
+        for name := range comp.Packages {
+            natives[name] = true
+        }
+    }
+
+    return natives
+}
+
+// --------------------------------------------------------
+// Positions
+// --------------------------------------------------------
+// Spans count runes, the protocol counts lines and utf-16 code units
+
+// Turn a span into a range
+// ------------------------
+func toRange(content string, spn span.Span) Range {
+    // internal spans dont point anywhere -> just use the start of the file
+    if spn.Internal {
+        return Range{}
+    }
+
+    src := []rune(content)
+    return Range{
+        Start: toPosition(src, spn.FromIdx),
+        End: toPosition(src, spn.ToIdx),
+    }
+}
+
+func toPosition(src []rune, idx int) Position {
+    pos := Position{}
+
+    for i := 0; i < idx && i < len(src); i++ {
+        if src[i] == '\n' {
+            pos.Line++
+            pos.Character = 0
+            continue
+        }
+
+        pos.Character += utf16Length(src[i])
+    }
+
+    return pos
+}
+
+// Turn a position into an index into the source
+// ---------------------------------------------
+func toIndex(src []rune, pos Position) int {
+    line := 0
+    char := 0
+
+    for i, r := range src {
+        if line == pos.Line && char >= pos.Character {
+            return i
+        }
+
+        if r == '\n' {
+            // the position is past the end of its line -> clamp it
+            if line == pos.Line {
+                return i
+            }
+
+            line++
+            char = 0
+            continue
+        }
+
+        if line == pos.Line {
+            char += utf16Length(r)
+        }
+    }
+
+    return len(src)
+}
+
+func utf16Length(r rune) int {
+    if r > 0xFFFF {
+        return 2
+    }
+
+    return 1
+}
+
+// --------------------------------------------------------
+// URIs
+// --------------------------------------------------------
+func uriToPath(uri string) string {
+    u, err := url.Parse(uri)
+    if err != nil || u.Scheme != "file" {
+        return uri
+    }
+
+    return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+    // not a file -> probably was an uri in the first place
+    if strings.Contains(path, "://") {
+        return path
+    }
+
+    abs, err := filepath.Abs(path)
+    if err == nil {
+        path = abs
+    }
+
+    u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+    return u.String()
+}
+
+// Where does a source live? (open documents keep the uri the client gave us)
+// ---------------------------------------------------------------------------
+func (srv *Server) sourceURI(path string) string {
+    for _, v := range srv.Documents {
+        if v.Path == path {
+            return v.URI
+        }
+    }
+
+    return pathToURI(path)
+}
@@ -0,0 +1,916 @@
+// LSP - features.go
+// --------------------------------------------------------
+// Hover, go to definition, document symbols and completion
+// --------------------------------------------------------
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"bytespace.network/rerect/binder"
+	"bytespace.network/rerect/printer"
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// --------------------------------------------------------
+// Hover
+// --------------------------------------------------------
+func (srv *Server) hover(params json.RawMessage) (any, *responseError) {
+    var prm TextDocumentPositionParams
+    if err := decode(params, &prm); err != nil {
+        return nil, err
+    }
+
+    doc, ref := srv.referenceAt(prm)
+    if ref == nil {
+        return nil, nil
+    }
+
+    value := fmt.Sprintf("```rerect\n%s\n```", describe(ref.Symbol))
+
+    // doc comments go below the declaration
+    if doc := documentation(ref.Symbol); doc != "" {
+        value += "\n\n" + doc
+    }
+
+    return Hover{
+        Contents: MarkupContent{
+            Kind: "markdown",
+            Value: value,
+        },
+        Range: toRange(doc.Text, ref.Position),
+    }, nil
+}
+
+// What does a symbol look like? (roughly how it would be declared)
+// -----------------------------------------------------------------
+func describe(sym symbols.Symbol) string {
+    if sym.Type() == symbols.ST_Function {
+        return printer.FunctionSignature(sym.(*symbols.FunctionSymbol))
+
+    } else if sym.Type() == symbols.ST_Local {
+        loc := sym.(*symbols.LocalSymbol)
+        return fmt.Sprintf("%s %s %s", declKeyword(loc.IsConst), loc.LocalName, loc.LocalType.Name())
+
+    } else if sym.Type() == symbols.ST_Parameter {
+        prm := sym.(*symbols.ParameterSymbol)
+        return fmt.Sprintf("(parameter) %s %s", prm.ParameterName, prm.ParameterType.Name())
+
+    } else if sym.Type() == symbols.ST_Global {
+        glb := sym.(*symbols.GlobalSymbol)
+        return fmt.Sprintf("%s %s::%s %s", declKeyword(glb.IsConst), glb.ParentPackage.Name(), glb.GlobalName, glb.GlobalType.Name())
+
+    } else if sym.Type() == symbols.ST_Field {
+        fld := sym.(*symbols.FieldSymbol)
+
+        owner := ""
+        if fld.HasParentContainer {
+            owner = fmt.Sprintf("%s::%s", fld.ParentContainer.ParentPackage.Name(), fld.ParentContainer.ContainerName)
+        } else {
+            owner = fmt.Sprintf("%s::%s", fld.ParentTrait.ParentPackage.Name(), fld.ParentTrait.TraitName)
+        }
+
+        if fld.IsReadonly {
+            return fmt.Sprintf("(field) readonly %s->%s %s", owner, fld.FieldName, fld.FieldType.Name())
+        }
+
+        return fmt.Sprintf("(field) %s->%s %s", owner, fld.FieldName, fld.FieldType.Name())
+
+    } else if sym.Type() == symbols.ST_Instance {
+        return fmt.Sprintf("this %s", sym.(*symbols.InstanceSymbol).InstanceType.Name())
+
+    } else if sym.Type() == symbols.ST_Container {
+        cnt := sym.(*symbols.ContainerSymbol)
+        txt := fmt.Sprintf("container %s::%s", cnt.ParentPackage.Name(), cnt.ContainerName)
+
+        if len(cnt.Traits) > 0 {
+            trts := []string{}
+            for _, v := range cnt.Traits {
+                trts = append(trts, v.TraitName)
+            }
+
+            txt += fmt.Sprintf(" (%s)", strings.Join(trts, ", "))
+        }
+
+        return txt
+
+    } else if sym.Type() == symbols.ST_Trait {
+        trt := sym.(*symbols.TraitSymbol)
+        return fmt.Sprintf("trait %s::%s", trt.ParentPackage.Name(), trt.TraitName)
+
+    } else if sym.Type() == symbols.ST_Enum {
+        enm := sym.(*symbols.EnumSymbol)
+        return fmt.Sprintf("enum %s::%s", enm.ParentPackage.Name(), enm.EnumName)
+
+    } else if sym.Type() == symbols.ST_Type {
+        return fmt.Sprintf("type %s", sym.Name())
+
+    } else if sym.Type() == symbols.ST_Package {
+        return fmt.Sprintf("package %s", sym.Name())
+    }
+
+    return sym.Name()
+}
+
+// 'var' or 'const'?
+func declKeyword(isConst bool) string {
+    if isConst {
+        return "const"
+    }
+
+    return "var"
+}
+
+// Whatever /// comments a symbol was declared with
+// -------------------------------------------------
+func documentation(sym symbols.Symbol) string {
+    switch s := sym.(type) {
+    case *symbols.FunctionSymbol:
+        return s.Doc
+    case *symbols.GlobalSymbol:
+        return s.Doc
+    case *symbols.FieldSymbol:
+        return s.Doc
+    case *symbols.ContainerSymbol:
+        return s.Doc
+    case *symbols.TraitSymbol:
+        return s.Doc
+    case *symbols.EnumSymbol:
+        return s.Doc
+    }
+
+    return ""
+}
+
+// --------------------------------------------------------
+// Go to definition
+// --------------------------------------------------------
+func (srv *Server) definition(params json.RawMessage) (any, *responseError) {
+    var prm TextDocumentPositionParams
+    if err := decode(params, &prm); err != nil {
+        return nil, err
+    }
+
+    doc, ref := srv.referenceAt(prm)
+    if ref == nil {
+        return nil, nil
+    }
+
+    spn, ok := doc.Analysis.Declarations[origin(ref.Symbol)]
+
+    // natives (and other internal things) dont live anywhere
+    if !ok || spn.Internal {
+        return nil, nil
+    }
+
+    src := doc.Analysis.Comp.SourceFiles[spn.File]
+    return Location{
+        URI: srv.sourceURI(src.Path),
+        Range: toRange(src.Content, spn),
+    }, nil
+}
+
+// Find the reference under the cursor
+// -----------------------------------
+func (srv *Server) referenceAt(prm TextDocumentPositionParams) (*Document, *Reference) {
+    doc, ok := srv.Documents[prm.TextDocument.URI]
+
+    // only bound documents know what their names mean
+    if !ok || doc.Analysis == nil || doc.Analysis.File == nil {
+        return nil, nil
+    }
+
+    idx := toIndex([]rune(doc.Text), prm.Position)
+
+    // (if references overlap the smallest one wins)
+    var found *Reference
+    for i, v := range doc.Analysis.References {
+        if idx < v.Position.FromIdx || idx > v.Position.ToIdx {
+            continue
+        }
+
+        if found == nil || v.Position.ToIdx - v.Position.FromIdx < found.Position.ToIdx - found.Position.FromIdx {
+            found = &doc.Analysis.References[i]
+        }
+    }
+
+    return doc, found
+}
+
+// --------------------------------------------------------
+// Document symbols
+// --------------------------------------------------------
+func (srv *Server) documentSymbol(params json.RawMessage) (any, *responseError) {
+    var prm DocumentSymbolParams
+    if err := decode(params, &prm); err != nil {
+        return nil, err
+    }
+
+    doc, ok := srv.Documents[prm.TextDocument.URI]
+    if !ok || doc.Analysis == nil {
+        return []DocumentSymbol{}, nil
+    }
+
+    // (this only needs the syntax tree, so it works even if binding failed)
+    syms := []DocumentSymbol{}
+    for _, v := range doc.Analysis.Members {
+        if v.Type() == syntaxnodes.NT_Function {
+            syms = append(syms, functionSymbol(doc.Text, v.(*syntaxnodes.FunctionNode), SK_Function))
+
+        } else if v.Type() == syntaxnodes.NT_Global {
+            node := v.(*syntaxnodes.GlobalNode)
+            syms = append(syms, DocumentSymbol{
+                Name: node.GlobalName.Buffer,
+                Detail: typeClause(node.VarType),
+                Kind: SK_Variable,
+                Range: toRange(doc.Text, node.Position()),
+                SelectionRange: toRange(doc.Text, node.GlobalName.Position),
+            })
+
+        } else if v.Type() == syntaxnodes.NT_Container {
+            node := v.(*syntaxnodes.ContainerNode)
+
+            trts := []string{}
+            for _, t := range node.Traits {
+                trts = append(trts, t.TraitName.Buffer)
+            }
+
+            syms = append(syms, DocumentSymbol{
+                Name: node.ContainerName.Buffer,
+                Detail: strings.Join(trts, ", "),
+                Kind: SK_Class,
+                Range: toRange(doc.Text, node.Position()),
+                SelectionRange: toRange(doc.Text, node.ContainerName.Position),
+                Children: memberSymbols(doc.Text, node.Fields, node.Methods),
+            })
+
+        } else if v.Type() == syntaxnodes.NT_Trait {
+            node := v.(*syntaxnodes.TraitNode)
+
+            syms = append(syms, DocumentSymbol{
+                Name: node.TraitName.Buffer,
+                Kind: SK_Interface,
+                Range: toRange(doc.Text, node.Position()),
+                SelectionRange: toRange(doc.Text, node.TraitName.Position),
+                Children: memberSymbols(doc.Text, node.Fields, node.Methods),
+            })
+
+        } else if v.Type() == syntaxnodes.NT_Enum {
+            node := v.(*syntaxnodes.EnumNode)
+
+            mems := []DocumentSymbol{}
+            for _, mem := range node.Members {
+                mems = append(mems, DocumentSymbol{
+                    Name: mem.MemberName.Buffer,
+                    Kind: SK_EnumMember,
+                    Range: toRange(doc.Text, mem.Position()),
+                    SelectionRange: toRange(doc.Text, mem.MemberName.Position),
+                })
+            }
+
+            syms = append(syms, DocumentSymbol{
+                Name: node.EnumName.Buffer,
+                Kind: SK_Enum,
+                Range: toRange(doc.Text, node.Position()),
+                SelectionRange: toRange(doc.Text, node.EnumName.Position),
+                Children: mems,
+            })
+        }
+    }
+
+    return syms, nil
+}
+
+func memberSymbols(text string, fields []*syntaxnodes.FieldClauseNode, methods []*syntaxnodes.FunctionNode) []DocumentSymbol {
+    syms := []DocumentSymbol{}
+
+    for _, v := range fields {
+        syms = append(syms, DocumentSymbol{
+            Name: v.FieldName.Buffer,
+            Detail: typeClause(v.FieldType),
+            Kind: SK_Field,
+            Range: toRange(text, v.Position()),
+            SelectionRange: toRange(text, v.FieldName.Position),
+        })
+    }
+
+    for _, v := range methods {
+        kind := SK_Method
+        if v.IsConstructor {
+            kind = SK_Constructor
+        }
+
+        syms = append(syms, functionSymbol(text, v, kind))
+    }
+
+    return syms
+}
+
+func functionSymbol(text string, node *syntaxnodes.FunctionNode, kind int) DocumentSymbol {
+    prms := []string{}
+    for _, v := range node.Parameters {
+        prms = append(prms, fmt.Sprintf("%s %s", v.ParameterName.Buffer, typeClause(v.ParameterType)))
+    }
+
+    detail := fmt.Sprintf("(%s)", strings.Join(prms, ", "))
+    if node.HasReturnType {
+        detail += " " + typeClause(node.ReturnType)
+    }
+
+    return DocumentSymbol{
+        Name: node.FunctionName.Buffer,
+        Detail: detail,
+        Kind: kind,
+        Range: toRange(text, node.Position()),
+        SelectionRange: toRange(text, node.FunctionName.Position),
+    }
+}
+
+// A type clause the way it was written
+// ------------------------------------
+func typeClause(typ *syntaxnodes.TypeClauseNode) string {
+    if typ == nil {
+        return ""
+    }
+
+    name := typ.TypeName.Buffer
+    if typ.HasPackageName {
+        name = typ.PackageName.Buffer + "::" + name
+    }
+
+    if len(typ.SubTypes) > 0 {
+        subs := []string{}
+        for _, v := range typ.SubTypes {
+            subs = append(subs, typeClause(v))
+        }
+
+        name += "[" + strings.Join(subs, ", ") + "]"
+    }
+
+    if typ.IsNullable {
+        name += "?"
+    }
+
+    return name
+}
+
+// --------------------------------------------------------
+// Completion
+// --------------------------------------------------------
+// While somebody is typing "x->" the document usually
+// doesnt parse, so all of this works on the raw text and
+// the last analysis that did make it through the binder
+
+// Completion context
+// ------------------
+type completer struct {
+    Res *Analysis
+    Pck *symbols.PackageSymbol
+    Fnc *symbols.FunctionSymbol // function the cursor is in (if any)
+    Src []rune
+}
+
+func (srv *Server) completion(params json.RawMessage) (any, *responseError) {
+    var prm TextDocumentPositionParams
+    if err := decode(params, &prm); err != nil {
+        return nil, err
+    }
+
+    list := CompletionList{Items: []CompletionItem{}}
+
+    doc, ok := srv.Documents[prm.TextDocument.URI]
+    if !ok || doc.LastBound == nil {
+        return list, nil
+    }
+
+    src := []rune(doc.Text)
+    idx := toIndex(src, prm.Position)
+
+    // the last bound analysis might be a couple of edits behind
+    // -> find out where the cursor would have been back then
+    old := doc.LastBound.indexInText(src, idx)
+
+    fnc := doc.LastBound.functionAt(old)
+    if fnc == nil {
+        fnc = doc.LastBound.functionBefore(old)
+    }
+
+    cmp := &completer{
+        Res: doc.LastBound,
+        Pck: doc.LastBound.File.Package,
+        Fnc: fnc,
+        Src: src,
+    }
+
+    // skip whatever part of a name has been typed already
+    // (the client does the filtering)
+    start := identStart(src, idx)
+
+    if op := operatorBefore(src, start, "->"); op >= 0 {
+        typ := cmp.typeOf(op)
+        if typ != nil {
+            list.Items = cmp.members(typ)
+        }
+
+    } else if op := operatorBefore(src, start, "::"); op >= 0 {
+        // enums use the same syntax as packages (Color::Red)
+        if enm := cmp.enumOf(op); enm != nil {
+            list.Items = enumMembers(enm)
+        } else if pck := cmp.packageOf(op); pck != nil {
+            list.Items = packageMembers(pck)
+        }
+    }
+
+    return list, nil
+}
+
+// What can be accessed on a value of this type?
+// ---------------------------------------------
+func (cmp *completer) members(typ *symbols.TypeSymbol) []CompletionItem {
+    typ = typ.NonNullable()
+    items := []CompletionItem{}
+    seen := map[string]bool{}
+
+    // Fields
+    // ------
+    fields := []*symbols.FieldSymbol{}
+    if typ.Container != nil {
+        fields = typ.Container.Fields
+    } else if typ.Trait != nil {
+        fields = typ.Trait.Fields
+    }
+
+    for _, v := range fields {
+        seen[v.FieldName] = true
+        items = append(items, CompletionItem{
+            Label: v.FieldName,
+            Kind: CK_Field,
+            Detail: v.FieldType.Name(),
+            Documentation: v.Doc,
+        })
+    }
+
+    // Methods
+    // -------
+    // (same places the binder would look)
+    packs := []*symbols.PackageSymbol{cmp.Pck}
+    for _, v := range cmp.Pck.LoadedPackages {
+        packs = append(packs, v)
+    }
+
+    for _, pck := range packs {
+        for _, v := range pck.Functions {
+            if v.FunctionKind != symbols.FT_METH || seen[v.FuncName] || !binder.MethodAppliesTo(v, typ) {
+                continue
+            }
+
+            // constructors only get called by make
+            if v.FuncName == "Constructor" {
+                continue
+            }
+
+            seen[v.FuncName] = true
+            items = append(items, CompletionItem{
+                Label: v.FuncName,
+                Kind: CK_Method,
+                Detail: printer.FunctionSignature(v),
+                Documentation: v.Doc,
+            })
+        }
+    }
+
+    return items
+}
+
+// What can be accessed through a package?
+// ---------------------------------------
+func packageMembers(pck *symbols.PackageSymbol) []CompletionItem {
+    items := []CompletionItem{}
+
+    for _, v := range pck.Functions {
+        if v.FunctionKind == symbols.FT_FUNC {
+            items = append(items, CompletionItem{Label: v.FuncName, Kind: CK_Function, Detail: printer.FunctionSignature(v), Documentation: v.Doc})
+        }
+    }
+
+    for _, v := range pck.Containers {
+        items = append(items, CompletionItem{Label: v.ContainerName, Kind: CK_Class, Detail: describe(v), Documentation: v.Doc})
+    }
+
+    for _, v := range pck.Traits {
+        items = append(items, CompletionItem{Label: v.TraitName, Kind: CK_Interface, Detail: describe(v), Documentation: v.Doc})
+    }
+
+    for _, v := range pck.Enums {
+        items = append(items, CompletionItem{Label: v.EnumName, Kind: CK_Enum, Detail: describe(v), Documentation: v.Doc})
+    }
+
+    for _, v := range pck.Globals {
+        items = append(items, CompletionItem{Label: v.GlobalName, Kind: CK_Variable, Detail: describe(v), Documentation: v.Doc})
+    }
+
+    return items
+}
+
+// What members does an enum have?
+// -------------------------------
+func enumMembers(enm *symbols.EnumSymbol) []CompletionItem {
+    items := []CompletionItem{}
+
+    for _, v := range enm.Members {
+        items = append(items, CompletionItem{Label: v.MemberName, Kind: CK_EnumMember, Detail: fmt.Sprintf("%s::%s = %d", enm.EnumName, v.MemberName, v.Value), Documentation: v.Doc})
+    }
+
+    return items
+}
+
+// Figure out the type of the expression ending at end
+// ---------------------------------------------------
+// (walks backwards through things like "a->b()[1]->c")
+func (cmp *completer) typeOf(end int) *symbols.TypeSymbol {
+    src := cmp.Src
+    end = skipSpaceBack(src, end)
+    if end <= 0 {
+        return nil
+    }
+
+    // Indexing
+    // --------
+    if src[end-1] == ']' {
+        open := matchBack(src, end-1, '[', ']')
+        if open < 0 {
+            return nil
+        }
+
+        arr := cmp.typeOf(open)
+        if arr == nil || len(arr.SubTypes) == 0 {
+            return nil
+        }
+
+        // maps hand out their values
+        if arr.TypeGroup == symbols.MAP {
+            return arr.SubTypes[1]
+        }
+
+        if arr.TypeGroup != symbols.ARR {
+            return nil
+        }
+
+        return arr.SubTypes[0]
+    }
+
+    // Calls (and parentheses)
+    // -----------------------
+    if src[end-1] == ')' {
+        open := matchBack(src, end-1, '(', ')')
+        if open < 0 {
+            return nil
+        }
+
+        nameEnd := skipSpaceBack(src, open)
+        nameStart := identStart(src, nameEnd)
+        name := string(src[nameStart:nameEnd])
+
+        // no name -> just some parentheses
+        if name == "" {
+            return cmp.typeOf(end - 1)
+        }
+
+        // a method call
+        if op := operatorBefore(src, nameStart, "->"); op >= 0 {
+            recv := cmp.typeOf(op)
+            if recv == nil {
+                return nil
+            }
+
+            if meth := cmp.method(name, recv); meth != nil {
+                return meth.ReturnType
+            }
+
+            return nil
+        }
+
+        // something from a package
+        pck := cmp.Pck
+        prefixStart := nameStart
+        if op := operatorBefore(src, nameStart, "::"); op >= 0 {
+            pck = cmp.packageOf(op)
+            if pck == nil {
+                return nil
+            }
+
+            prefixStart = identStart(src, skipSpaceBack(src, op))
+        }
+
+        // "make Thing()" -> a new thing
+        if cmp.wordBefore(prefixStart) == "make" {
+            if cnt := binder.LookupContainer(name, pck); cnt != nil {
+                return cnt.ContainerType
+            }
+
+            return nil
+        }
+
+        // a function call
+        if fnc := lookupFunction(name, pck); fnc != nil {
+            return fnc.ReturnType
+        }
+
+        // a cast (string(x)) -> its type
+        return binder.LookupType(cmp.Res.Comp, name, span.Internal(), pck, true)
+    }
+
+    // Names
+    // -----
+    start := identStart(src, end)
+    if start == end {
+        return nil
+    }
+
+    name := string(src[start:end])
+
+    // a field
+    if op := operatorBefore(src, start, "->"); op >= 0 {
+        recv := cmp.typeOf(op)
+        if recv == nil {
+            return nil
+        }
+
+        var fld *symbols.FieldSymbol
+        if recv.Container != nil {
+            fld = binder.LookupFieldInContainer(name, recv.Container)
+        } else if recv.Trait != nil {
+            fld = binder.LookupFieldInTrait(name, recv.Trait)
+        }
+
+        if fld == nil {
+            return nil
+        }
+
+        return fld.FieldType
+    }
+
+    // a global in some package (or an enum member)
+    if op := operatorBefore(src, start, "::"); op >= 0 {
+        if enm := cmp.enumOf(op); enm != nil {
+            return enm.EnumType
+        }
+
+        pck := cmp.packageOf(op)
+        if pck == nil {
+            return nil
+        }
+
+        return lookupGlobal(name, pck)
+    }
+
+    // just a variable
+    return cmp.variable(name)
+}
+
+// Figure out which package the name ending at end refers to
+// ---------------------------------------------------------
+func (cmp *completer) packageOf(end int) *symbols.PackageSymbol {
+    end = skipSpaceBack(cmp.Src, end)
+    name := string(cmp.Src[identStart(cmp.Src, end):end])
+
+    if name == "" {
+        return nil
+    }
+
+    if pck := binder.LookupPackageInPackage(cmp.Pck, name); pck != nil {
+        return pck
+    }
+
+    // (might not be loaded yet, but we still know it)
+    return cmp.Res.Comp.GetPackage(name)
+}
+
+// Is the name ending at end an enum? (nil if not)
+// -----------------------------------------------
+func (cmp *completer) enumOf(end int) *symbols.EnumSymbol {
+    end = skipSpaceBack(cmp.Src, end)
+    name := string(cmp.Src[identStart(cmp.Src, end):end])
+
+    if name == "" {
+        return nil
+    }
+
+    return binder.LookupEnum(name, cmp.Pck)
+}
+
+// Find a variable by name (the same way the binder would)
+// -------------------------------------------------------
+func (cmp *completer) variable(name string) *symbols.TypeSymbol {
+    if cmp.Fnc != nil {
+        // this
+        if name == "this" && cmp.Fnc.FunctionKind == symbols.FT_METH {
+            return cmp.Fnc.MethodSource
+        }
+
+        // locals
+        for _, v := range cmp.Res.References {
+            if v.Function != cmp.Fnc || v.Symbol.Type() != symbols.ST_Local || v.Symbol.Name() != name {
+                continue
+            }
+
+            return v.Symbol.(*symbols.LocalSymbol).LocalType
+        }
+
+        // parameters
+        for _, v := range cmp.Fnc.Parameters {
+            if v.ParameterName == name {
+                return v.ParameterType
+            }
+        }
+
+        // fields of whatever were in
+        if cmp.Fnc.FunctionKind == symbols.FT_METH && cmp.Fnc.MethodSource.Container != nil {
+            if fld := binder.LookupFieldInContainer(name, cmp.Fnc.MethodSource.Container); fld != nil {
+                return fld.FieldType
+            }
+        }
+    }
+
+    // globals
+    return lookupGlobal(name, cmp.Pck)
+}
+
+func (cmp *completer) method(name string, typ *symbols.TypeSymbol) *symbols.FunctionSymbol {
+    typ = typ.NonNullable()
+
+    if meth := binder.LookupMethodInPackage(cmp.Pck, name, typ); meth != nil {
+        return meth
+    }
+
+    for _, v := range cmp.Pck.LoadedPackages {
+        if meth := binder.LookupMethodInPackage(v, name, typ); meth != nil {
+            return meth
+        }
+    }
+
+    return nil
+}
+
+// The word right in front of a position
+func (cmp *completer) wordBefore(idx int) string {
+    end := skipSpaceBack(cmp.Src, idx)
+    return string(cmp.Src[identStart(cmp.Src, end):end])
+}
+
+func lookupFunction(name string, pck *symbols.PackageSymbol) *symbols.FunctionSymbol {
+    if fnc := binder.LookupFunctionInPackage(pck, name); fnc != nil {
+        return fnc
+    }
+
+    for _, v := range pck.IncludedPackages {
+        if fnc := binder.LookupFunctionInPackage(pck.LoadedPackages[v], name); fnc != nil {
+            return fnc
+        }
+    }
+
+    return nil
+}
+
+func lookupGlobal(name string, pck *symbols.PackageSymbol) *symbols.TypeSymbol {
+    for _, v := range pck.Globals {
+        if v.GlobalName == name {
+            return v.GlobalType
+        }
+    }
+
+    return nil
+}
+
+// Which function of the document contains the given position?
+// -----------------------------------------------------------
+func (res *Analysis) functionAt(idx int) *symbols.FunctionSymbol {
+    var found *symbols.FunctionSymbol
+    size := -1
+
+    for fnc, node := range res.FunctionSrc {
+        spn := node.Position()
+        if spn.File != res.Source || idx < spn.FromIdx || idx > spn.ToIdx {
+            continue
+        }
+
+        // (innermost one wins)
+        if size < 0 || spn.ToIdx - spn.FromIdx < size {
+            found = fnc
+            size = spn.ToIdx - spn.FromIdx
+        }
+    }
+
+    return found
+}
+
+// Which function starts closest before the given position?
+// ---------------------------------------------------------
+// (when somebody types past the end of a function, the old
+//  analysis doesnt know its body got any longer)
+func (res *Analysis) functionBefore(idx int) *symbols.FunctionSymbol {
+    var found *symbols.FunctionSymbol
+    start := -1
+
+    for fnc, node := range res.FunctionSrc {
+        spn := node.Position()
+        if spn.File != res.Source || spn.FromIdx > idx || spn.FromIdx <= start {
+            continue
+        }
+
+        found = fnc
+        start = spn.FromIdx
+    }
+
+    return found
+}
+
+// Map an index into the current text to the text this analysis was made from
+// ---------------------------------------------------------------------------
+// (anything in front of the edit stays where it is, anything after it moves
+//  along with it and anything inside of it ends up where the edit starts)
+func (res *Analysis) indexInText(src []rune, idx int) int {
+    old := []rune(res.Text)
+
+    // how much stayed the same at the front...
+    front := 0
+    for front < len(old) && front < len(src) && old[front] == src[front] {
+        front++
+    }
+
+    // ...and at the back
+    back := 0
+    for back < len(old) - front && back < len(src) - front && old[len(old)-1-back] == src[len(src)-1-back] {
+        back++
+    }
+
+    if idx <= front {
+        return idx
+    }
+
+    if idx >= len(src) - back {
+        return idx - len(src) + len(old)
+    }
+
+    return front
+}
+
+// Text helpers
+// ------------
+func isIdentChar(r rune) bool {
+    return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+func identStart(src []rune, end int) int {
+    for end > 0 && isIdentChar(src[end-1]) {
+        end--
+    }
+
+    return end
+}
+
+func skipSpaceBack(src []rune, end int) int {
+    for end > 0 && (src[end-1] == ' ' || src[end-1] == '\t' || src[end-1] == '\n' || src[end-1] == '\r') {
+        end--
+    }
+
+    return end
+}
+
+// Where does the operator (-> or ::) right before a position start? (-1 if there is none)
+func operatorBefore(src []rune, idx int, op string) int {
+    idx = skipSpaceBack(src, idx)
+    if idx < 2 || string(src[idx-2:idx]) != op {
+        return -1
+    }
+
+    // ?-> is just a -> that doesnt mind nulls
+    if op == "->" && idx >= 3 && src[idx-3] == '?' {
+        return idx - 3
+    }
+
+    return idx - 2
+}
+
+// Find the opening bracket for the closing one at idx
+func matchBack(src []rune, idx int, open rune, close rune) int {
+    depth := 0
+
+    for i := idx; i >= 0; i-- {
+        if src[i] == close {
+            depth++
+        } else if src[i] == open {
+            depth--
+        }
+
+        if depth == 0 {
+            return i
+        }
+    }
+
+    return -1
+}
@@ -0,0 +1,117 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+const completionDoc = `package main;
+
+// ä, ö and 😀 take up more than one byte
+container Thing {
+    Name string;
+    Size int;
+}
+
+function Other() {
+    var g <- 1;
+}
+
+function main() {
+    var f <- make Thing { Name <- "größer 😀" };
+    f->Size <- 2;
+}
+`
+
+func newTestServer() *Server {
+    return &Server{
+        Out: io.Discard,
+        Documents: make(map[string]*Document),
+    }
+}
+
+func params(t *testing.T, v any) json.RawMessage {
+    raw, err := json.Marshal(v)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    return raw
+}
+
+func (srv *Server) testChange(t *testing.T, uri string, text string) {
+    prm := DidChangeTextDocumentParams{}
+    prm.TextDocument.URI = uri
+    prm.TextDocument.Version = 2
+    prm.ContentChanges = append(prm.ContentChanges, struct {
+        Text string `json:"text"`
+    }{text})
+
+    srv.didChange(params(t, prm))
+}
+
+func (srv *Server) testComplete(t *testing.T, uri string, line int, char int) []string {
+    res, err := srv.completion(params(t, TextDocumentPositionParams{
+        TextDocument: TextDocumentIdentifier{URI: uri},
+        Position: Position{Line: line, Character: char},
+    }))
+
+    if err != nil {
+        t.Fatalf("completion failed: %s", err.Message)
+    }
+
+    labels := []string{}
+    for _, v := range res.(CompletionList).Items {
+        labels = append(labels, v.Label)
+    }
+
+    return labels
+}
+
+// typing "f->" at the very end of a function body
+// (the last bound analysis doesnt know that line even exists)
+func TestCompletionAtEndOfFunction(t *testing.T) {
+    srv := newTestServer()
+    uri := "file:///tmp/completion.rr"
+
+    srv.didOpen(params(t, DidOpenTextDocumentParams{
+        TextDocument: TextDocumentItem{URI: uri, LanguageId: "rerect", Version: 1, Text: completionDoc},
+    }))
+
+    if srv.Documents[uri].LastBound == nil {
+        t.Fatalf("document did not bind: %v", srv.Documents[uri].Analysis.Errors)
+    }
+
+    edited := strings.Replace(completionDoc, "    f->Size <- 2;\n}", "    f->Size <- 2;\n    f->\n}", 1)
+    srv.testChange(t, uri, edited)
+
+    if srv.Documents[uri].Analysis.File != nil {
+        t.Fatal("edited document should not bind")
+    }
+
+    labels := srv.testComplete(t, uri, 15, 7)
+    if strings.Join(labels, ",") != "Name,Size" {
+        t.Fatalf("expected fields of Thing, got %v", labels)
+    }
+}
+
+// same thing, but with a new line of code in front of the function
+func TestCompletionAfterEditAbove(t *testing.T) {
+    srv := newTestServer()
+    uri := "file:///tmp/completion.rr"
+
+    srv.didOpen(params(t, DidOpenTextDocumentParams{
+        TextDocument: TextDocumentItem{URI: uri, LanguageId: "rerect", Version: 1, Text: completionDoc},
+    }))
+
+    edited := strings.Replace(completionDoc, "    var g <- 1;\n", "    var g <- 1;\n    var h <- \"😀😀😀\";\n    var i <- 3;\n", 1)
+    edited = strings.Replace(edited, "    f->Size <- 2;\n}", "    f->Size <- 2;\n    f->\n}", 1)
+    srv.testChange(t, uri, edited)
+
+    labels := srv.testComplete(t, uri, 17, 7)
+    if strings.Join(labels, ",") != "Name,Size" {
+        t.Fatalf("expected fields of Thing, got %v", labels)
+    }
+}
@@ -0,0 +1,629 @@
+// LSP - index.go
+// --------------------------------------------------------
+// Figures out which name in a document refers to which
+// symbol (and where all those symbols were declared)
+// --------------------------------------------------------
+package lsp
+
+import (
+	"bytespace.network/rerect/binder"
+	"bytespace.network/rerect/boundnodes"
+	packageprocessor "bytespace.network/rerect/package_processor"
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// Reference struct
+// ----------------
+type Reference struct {
+    Position span.Span
+    Symbol symbols.Symbol
+    Function *symbols.FunctionSymbol // function this reference is in (nil outside of function bodies)
+}
+
+// Index everything (declarations of all files, references of the document itself)
+// --------------------------------------------------------------------------------
+func (res *Analysis) index(files []*packageprocessor.CompilationFile) {
+    for _, file := range files {
+        res.indexDeclarations(file)
+    }
+
+    file := files[0]
+
+    // names in member declarations
+    res.indexMembers(file)
+
+    // names in function bodies
+    for _, fnc := range file.Functions {
+        res.indexStatement(fnc, file.FunctionBodies[fnc])
+    }
+}
+
+// Remember where a symbol was declared (declarations count as references too)
+// ---------------------------------------------------------------------------
+func (res *Analysis) declare(sym symbols.Symbol, pos span.Span, fnc *symbols.FunctionSymbol) {
+    res.Declarations[sym] = pos
+    res.reference(sym, pos, fnc)
+}
+
+func (res *Analysis) reference(sym symbols.Symbol, pos span.Span, fnc *symbols.FunctionSymbol) {
+    // only keep things that are actually in this document
+    if pos.Internal || pos.File != res.Source {
+        return
+    }
+
+    res.References = append(res.References, Reference{
+        Position: pos,
+        Symbol: sym,
+        Function: fnc,
+    })
+}
+
+// --------------------------------------------------------
+// Declarations
+// --------------------------------------------------------
+func (res *Analysis) indexDeclarations(file *packageprocessor.CompilationFile) {
+    // Enums
+    // -----
+    for _, enm := range file.Enums {
+        res.declare(enm, file.EnumSrc[enm].EnumName.Position, nil)
+    }
+
+    // Traits
+    // ------
+    for _, trt := range file.Traits {
+        src := file.TraitSrc[trt]
+        res.declare(trt, src.TraitName.Position, nil)
+
+        for _, fld := range trt.Fields {
+            if node := findField(src.Fields, fld.FieldName); node != nil {
+                res.declare(fld, node.FieldName.Position, nil)
+            }
+        }
+
+        for _, meth := range trt.Methods {
+            if node := findFunction(src.Methods, meth.FuncName); node != nil {
+                res.declareFunction(meth, node)
+            }
+        }
+    }
+
+    // Containers
+    // ----------
+    for _, cnt := range file.Containers {
+        src := file.ContainerSrc[cnt]
+        res.declare(cnt, src.ContainerName.Position, nil)
+
+        for _, fld := range cnt.Fields {
+            if node := findField(src.Fields, fld.FieldName); node != nil {
+                res.declare(fld, node.FieldName.Position, nil)
+            }
+        }
+
+        for _, meth := range cnt.Methods {
+            // (methods from traits are declared in their trait)
+            if meth.NeedsVirtualCallToTrait {
+                continue
+            }
+
+            if node := findFunction(src.Methods, meth.FuncName); node != nil {
+                res.declareFunction(meth, node)
+            }
+        }
+    }
+
+    // Functions and globals
+    // ---------------------
+    for _, v := range file.Members {
+        if v.Type() == syntaxnodes.NT_Function {
+            node := v.(*syntaxnodes.FunctionNode)
+            fnc := binderFunction(file, node.FunctionName.Buffer)
+
+            if fnc != nil {
+                res.declareFunction(fnc, node)
+            }
+
+        } else if v.Type() == syntaxnodes.NT_Global {
+            node := v.(*syntaxnodes.GlobalNode)
+
+            for _, glb := range file.Globals {
+                if glb.GlobalName == node.GlobalName.Buffer {
+                    res.declare(glb, node.GlobalName.Position, nil)
+                }
+            }
+        }
+    }
+}
+
+func (res *Analysis) declareFunction(fnc *symbols.FunctionSymbol, node *syntaxnodes.FunctionNode) {
+    res.FunctionSrc[fnc] = node
+    res.declare(fnc, node.FunctionName.Position, nil)
+
+    for i, prm := range fnc.Parameters {
+        if i < len(node.Parameters) {
+            res.declare(prm, node.Parameters[i].ParameterName.Position, fnc)
+        }
+    }
+}
+
+// Types mentioned in member declarations
+// --------------------------------------
+func (res *Analysis) indexMembers(file *packageprocessor.CompilationFile) {
+    pck := file.Package
+
+    for fnc, node := range res.FunctionSrc {
+        if fnc.ParentPackage != pck {
+            continue
+        }
+
+        for _, prm := range node.Parameters {
+            res.indexType(prm.ParameterType, pck)
+        }
+
+        res.indexType(node.ReturnType, pck)
+    }
+
+    for _, v := range file.Members {
+        if v.Type() == syntaxnodes.NT_Global {
+            if node := v.(*syntaxnodes.GlobalNode); node.HasExplicitType {
+                res.indexType(node.VarType, pck)
+            }
+
+        } else if v.Type() == syntaxnodes.NT_Container {
+            node := v.(*syntaxnodes.ContainerNode)
+
+            for _, fld := range node.Fields {
+                res.indexType(fld.FieldType, pck)
+            }
+
+            for _, trt := range node.Traits {
+                for _, sub := range trt.SubTypes {
+                    res.indexType(sub, pck)
+                }
+
+                var sym *symbols.TraitSymbol
+
+                if trt.HasPackage {
+                    if pack := binder.LookupPackageInPackage(pck, trt.Package.Buffer); pack != nil {
+                        sym = binder.LookupTraitInPackage(trt.TraitName.Buffer, pack)
+                    }
+                } else {
+                    sym = binder.LookupTrait(trt.TraitName.Buffer, pck)
+                }
+
+                if sym != nil {
+                    res.reference(sym, trt.TraitName.Position, nil)
+                }
+            }
+
+        } else if v.Type() == syntaxnodes.NT_Trait {
+            for _, fld := range v.(*syntaxnodes.TraitNode).Fields {
+                res.indexType(fld.FieldType, pck)
+            }
+        }
+    }
+}
+
+// Type clauses that name a container, trait or enum
+// -------------------------------------------
+func (res *Analysis) indexType(typ *syntaxnodes.TypeClauseNode, pck *symbols.PackageSymbol) {
+    if typ == nil {
+        return
+    }
+
+    for _, v := range typ.SubTypes {
+        res.indexType(v, pck)
+    }
+
+    // (func[int -> Foo])
+    if typ.IsFunctionType {
+        res.indexType(typ.ReturnType, pck)
+        return
+    }
+
+    // look in the given package or everywhere we can see
+    var trt *symbols.TraitSymbol
+    var cnt *symbols.ContainerSymbol
+    var enm *symbols.EnumSymbol
+
+    if typ.HasPackageName {
+        pack := binder.LookupPackageInPackage(pck, typ.PackageName.Buffer)
+        if pack == nil {
+            return
+        }
+
+        trt = binder.LookupTraitInPackage(typ.TypeName.Buffer, pack)
+        cnt = binder.LookupContainerInPackage(typ.TypeName.Buffer, pack)
+        enm = binder.LookupEnumInPackage(typ.TypeName.Buffer, pack)
+    } else {
+        trt = binder.LookupTrait(typ.TypeName.Buffer, pck)
+        cnt = binder.LookupContainer(typ.TypeName.Buffer, pck)
+        enm = binder.LookupEnum(typ.TypeName.Buffer, pck)
+    }
+
+    if trt != nil {
+        res.reference(trt, typ.TypeName.Position, nil)
+    } else if cnt != nil {
+        res.reference(cnt, typ.TypeName.Position, nil)
+    } else if enm != nil {
+        res.reference(enm, typ.TypeName.Position, nil)
+    }
+}
+
+// --------------------------------------------------------
+// Function bodies
+// --------------------------------------------------------
+func (res *Analysis) indexStatement(fnc *symbols.FunctionSymbol, stmt boundnodes.BoundStatementNode) {
+    if stmt == nil {
+        return
+    }
+
+    pck := fnc.ParentPackage
+
+    if stmt.Type() == boundnodes.BT_BlockStmt {
+        for _, v := range stmt.(*boundnodes.BoundBlockStatementNode).Statements {
+            res.indexStatement(fnc, v)
+        }
+
+    } else if stmt.Type() == boundnodes.BT_DeclarationStmt {
+        node := stmt.(*boundnodes.BoundDeclarationStatementNode)
+
+        if src, ok := node.Source().(*syntaxnodes.DeclarationStatementNode); ok {
+            res.declare(node.Variable, src.VarName.Position, fnc)
+
+            if src.HasExplicitType {
+                res.indexType(src.VarType, pck)
+            }
+        }
+
+        if node.HasInitializer {
+            res.indexExpression(fnc, node.Initializer)
+        }
+
+    } else if stmt.Type() == boundnodes.BT_ReturnStmt {
+        node := stmt.(*boundnodes.BoundReturnStatementNode)
+
+        if node.HasReturnValue {
+            res.indexExpression(fnc, node.ReturnValue)
+        }
+
+    } else if stmt.Type() == boundnodes.BT_WhileStmt {
+        node := stmt.(*boundnodes.BoundWhileStatementNode)
+        res.indexExpression(fnc, node.Condtion)
+        res.indexStatement(fnc, node.Body)
+
+    } else if stmt.Type() == boundnodes.BT_FromToStmt {
+        node := stmt.(*boundnodes.BoundFromToStatementNode)
+
+        if src, ok := node.Source().(*syntaxnodes.FromToStatementNode); ok {
+            res.declare(node.Iterator, src.Iterator.Position, fnc)
+        }
+
+        res.indexExpression(fnc, node.LowerBound)
+        res.indexExpression(fnc, node.UpperBound)
+        res.indexStatement(fnc, node.Body)
+
+    } else if stmt.Type() == boundnodes.BT_ForStmt {
+        node := stmt.(*boundnodes.BoundForStatementNode)
+        res.indexStatement(fnc, node.Initializer)
+        res.indexExpression(fnc, node.Condition)
+        res.indexStatement(fnc, node.Action)
+        res.indexStatement(fnc, node.Body)
+
+    } else if stmt.Type() == boundnodes.BT_ForEachStmt {
+        node := stmt.(*boundnodes.BoundForEachStatementNode)
+
+        if src, ok := node.Source().(*syntaxnodes.ForEachStatementNode); ok {
+            if node.HasIndex {
+                res.declare(node.Index, src.Index.Position, fnc)
+            }
+
+            res.declare(node.Variable, src.Variable.Position, fnc)
+        }
+
+        res.indexExpression(fnc, node.Collection)
+        res.indexStatement(fnc, node.Body)
+
+    } else if stmt.Type() == boundnodes.BT_LoopStmt {
+        node := stmt.(*boundnodes.BoundLoopStatementNode)
+        res.indexExpression(fnc, node.Amount)
+        res.indexStatement(fnc, node.Body)
+
+    } else if stmt.Type() == boundnodes.BT_IfStmt {
+        node := stmt.(*boundnodes.BoundIfStatementNode)
+        res.indexExpression(fnc, node.Condition)
+        res.indexStatement(fnc, node.Body)
+
+        if node.HasElse {
+            res.indexStatement(fnc, node.ElseBody)
+        }
+
+    } else if stmt.Type() == boundnodes.BT_ExpressionStmt {
+        res.indexExpression(fnc, stmt.(*boundnodes.BoundExpressionStatementNode).Expression)
+
+    } else if stmt.Type() == boundnodes.BT_TryStmt {
+        node := stmt.(*boundnodes.BoundTryStatementNode)
+        res.indexStatement(fnc, node.Body)
+
+        if src, ok := node.Source().(*syntaxnodes.TryStatementNode); ok {
+            res.declare(node.ErrorVariable, src.ErrorName.Position, fnc)
+            res.indexType(src.ErrorType, pck)
+        }
+
+        res.indexStatement(fnc, node.CatchBody)
+
+    } else if stmt.Type() == boundnodes.BT_ThrowStmt {
+        res.indexExpression(fnc, stmt.(*boundnodes.BoundThrowStatementNode).Error)
+
+    } else if stmt.Type() == boundnodes.BT_SwitchStmt {
+        node := stmt.(*boundnodes.BoundSwitchStatementNode)
+        res.indexExpression(fnc, node.Subject)
+
+        for _, cse := range node.Cases {
+            if src, ok := cse.SourceNode.(*syntaxnodes.SwitchCaseClauseNode); ok && cse.IsPattern {
+                res.declare(cse.PatternVariable, src.PatternName.Position, fnc)
+                res.indexType(src.PatternType, pck)
+            }
+
+            for _, v := range cse.Values {
+                res.indexExpression(fnc, v)
+            }
+
+            res.indexStatement(fnc, cse.Body)
+        }
+
+        if node.HasDefault {
+            res.indexStatement(fnc, node.DefaultBody)
+        }
+    }
+
+    // (everything else doesnt mention any names)
+}
+
+func (res *Analysis) indexExpression(fnc *symbols.FunctionSymbol, expr boundnodes.BoundExpressionNode) {
+    if expr == nil {
+        return
+    }
+
+    if expr.Type() == boundnodes.BT_NameExpr {
+        node := expr.(*boundnodes.BoundNameExpressionNode)
+
+        if src, ok := node.Source().(*syntaxnodes.NameExpressionNode); ok {
+            res.reference(node.Variable, src.Identifier.Position, fnc)
+        }
+
+    } else if expr.Type() == boundnodes.BT_LiteralExpr {
+        // enum members and constants end up as literals (Color::Red)
+        node := expr.(*boundnodes.BoundLiteralExpressionNode)
+
+        if src, ok := node.Source().(*syntaxnodes.NameExpressionNode); ok && node.LiteralType.Enum != nil {
+            res.reference(node.LiteralType.Enum, src.PackageName.Position, fnc)
+
+        } else if ok && fnc != nil {
+            for _, glb := range fnc.ParentPackage.Globals {
+                if glb.IsConst && glb.GlobalName == src.Identifier.Buffer {
+                    res.reference(glb, src.Identifier.Position, fnc)
+                }
+            }
+        }
+
+    } else if expr.Type() == boundnodes.BT_CallExpr {
+        node := expr.(*boundnodes.BoundCallExpressionNode)
+
+        if src, ok := node.Source().(*syntaxnodes.CallExpressionNode); ok {
+            res.reference(node.Function, src.Identifier.Position, fnc)
+        }
+
+        for _, v := range node.Arguments {
+            res.indexExpression(fnc, v)
+        }
+
+    } else if expr.Type() == boundnodes.BT_CallValueExpr {
+        node := expr.(*boundnodes.BoundCallValueExpressionNode)
+        res.indexExpression(fnc, node.Value)
+
+        for _, v := range node.Arguments {
+            res.indexExpression(fnc, v)
+        }
+
+    } else if expr.Type() == boundnodes.BT_FunctionExpr {
+        node := expr.(*boundnodes.BoundFunctionExpressionNode)
+
+        // named functions used as values
+        if src, ok := node.Source().(*syntaxnodes.NameExpressionNode); ok {
+            res.reference(node.Function, src.Identifier.Position, fnc)
+        }
+
+        // lambdas declare their own parameters (their bodies are indexed like any other function)
+        if src, ok := node.Source().(*syntaxnodes.LambdaExpressionNode); ok {
+            for i, prm := range node.Function.Parameters {
+                if i < len(src.Parameters) {
+                    res.declare(prm, src.Parameters[i].ParameterName.Position, node.Function)
+                    res.indexType(src.Parameters[i].ParameterType, fnc.ParentPackage)
+                }
+            }
+
+            if src.HasReturnType {
+                res.indexType(src.ReturnType, fnc.ParentPackage)
+            }
+        }
+
+    } else if expr.Type() == boundnodes.BT_AccessCallExpr {
+        node := expr.(*boundnodes.BoundAccessCallExpressionNode)
+        res.indexExpression(fnc, node.Expression)
+
+        if src, ok := node.Source().(*syntaxnodes.AccessExpressionNode); ok {
+            res.reference(node.Function, src.Identifier.Position, fnc)
+        }
+
+        for _, v := range node.Arguments {
+            res.indexExpression(fnc, v)
+        }
+
+    } else if expr.Type() == boundnodes.BT_AccessFieldExpr {
+        node := expr.(*boundnodes.BoundAccessFieldExpressionNode)
+        res.indexExpression(fnc, node.Expression)
+
+        if src, ok := node.Source().(*syntaxnodes.AccessExpressionNode); ok {
+            res.reference(node.Field, src.Identifier.Position, fnc)
+        }
+
+    } else if expr.Type() == boundnodes.BT_AssignmentExpr {
+        node := expr.(*boundnodes.BoundAssignmentExpressionNode)
+        res.indexExpression(fnc, node.Expression)
+        res.indexExpression(fnc, node.Value)
+
+    } else if expr.Type() == boundnodes.BT_CompoundExpr {
+        node := expr.(*boundnodes.BoundCompoundAssignmentExpressionNode)
+        res.indexExpression(fnc, node.Expression)
+        res.indexExpression(fnc, node.Value)
+
+    } else if expr.Type() == boundnodes.BT_UnaryExpr {
+        res.indexExpression(fnc, expr.(*boundnodes.BoundUnaryExpressionNode).Operand)
+
+    } else if expr.Type() == boundnodes.BT_BinaryExpr {
+        node := expr.(*boundnodes.BoundBinaryExpressionNode)
+        res.indexExpression(fnc, node.Left)
+        res.indexExpression(fnc, node.Right)
+
+    } else if expr.Type() == boundnodes.BT_ConversionExpr {
+        res.indexExpression(fnc, expr.(*boundnodes.BoundConversionExpressionNode).Value)
+
+    } else if expr.Type() == boundnodes.BT_TypeCheckExpr {
+        node := expr.(*boundnodes.BoundTypeCheckExpressionNode)
+        res.indexExpression(fnc, node.Value)
+
+        if src, ok := node.Source().(*syntaxnodes.TypeCheckExpressionNode); ok {
+            res.indexType(src.TestType, fnc.ParentPackage)
+        }
+
+    } else if expr.Type() == boundnodes.BT_CheckedCastExpr {
+        node := expr.(*boundnodes.BoundCheckedCastExpressionNode)
+        res.indexExpression(fnc, node.Value)
+
+        if src, ok := node.Source().(*syntaxnodes.TypeCheckExpressionNode); ok {
+            res.indexType(src.TestType, fnc.ParentPackage)
+        }
+
+    } else if expr.Type() == boundnodes.BT_ArrayIndexExpr {
+        node := expr.(*boundnodes.BoundArrayIndexExpressionNode)
+        res.indexExpression(fnc, node.SourceArray)
+        res.indexExpression(fnc, node.Index)
+
+    } else if expr.Type() == boundnodes.BT_MakeMapExpr {
+        node := expr.(*boundnodes.BoundMakeMapExpressionNode)
+
+        if src, ok := node.Source().(*syntaxnodes.MakeMapExpressionNode); ok {
+            res.indexType(src.KeyType, fnc.ParentPackage)
+            res.indexType(src.ValueType, fnc.ParentPackage)
+        }
+
+        for i := range node.Keys {
+            res.indexExpression(fnc, node.Keys[i])
+            res.indexExpression(fnc, node.Values[i])
+        }
+
+    } else if expr.Type() == boundnodes.BT_MakeArrayExpr {
+        node := expr.(*boundnodes.BoundMakeArrayExpressionNode)
+
+        if src, ok := node.Source().(*syntaxnodes.MakeArrayExpressionNode); ok {
+            res.indexType(src.ArrType, fnc.ParentPackage)
+        }
+
+        res.indexExpression(fnc, node.Length)
+        for _, v := range node.Initializer {
+            res.indexExpression(fnc, v)
+        }
+
+    } else if expr.Type() == boundnodes.BT_MakeExpr {
+        node := expr.(*boundnodes.BoundMakeExpressionNode)
+
+        if src, ok := node.Source().(*syntaxnodes.MakeExpressionNode); ok {
+            res.reference(node.Container, src.Container.Position, fnc)
+
+            for _, v := range src.TypeArguments {
+                res.indexType(v, fnc.ParentPackage)
+            }
+
+            for _, v := range src.Initializer {
+                if fld := binder.LookupFieldInContainer(v.FieldName.Buffer, node.Container); fld != nil {
+                    res.reference(fld, v.FieldName.Position, fnc)
+                }
+            }
+        }
+
+        for _, v := range node.Initializer {
+            res.indexExpression(fnc, v)
+        }
+
+        for _, v := range node.Arguments {
+            res.indexExpression(fnc, v)
+        }
+    }
+
+    // (literals and errors dont mention any names)
+}
+
+// --------------------------------------------------------
+// Helpers
+// --------------------------------------------------------
+func findField(fields []*syntaxnodes.FieldClauseNode, name string) *syntaxnodes.FieldClauseNode {
+    for _, v := range fields {
+        if v.FieldName.Buffer == name {
+            return v
+        }
+    }
+
+    return nil
+}
+
+func findFunction(fncs []*syntaxnodes.FunctionNode, name string) *syntaxnodes.FunctionNode {
+    for _, v := range fncs {
+        if v.FunctionName.Buffer == name {
+            return v
+        }
+    }
+
+    return nil
+}
+
+// The (non-method) function of a file with the given name
+func binderFunction(file *packageprocessor.CompilationFile, name string) *symbols.FunctionSymbol {
+    for _, v := range file.Functions {
+        if v.FunctionKind == symbols.FT_FUNC && v.FuncName == name {
+            return v
+        }
+    }
+
+    return nil
+}
+
+// Where a symbol actually comes from
+// ----------------------------------
+func origin(sym symbols.Symbol) symbols.Symbol {
+    // methods copied over from a trait
+    if fnc, ok := sym.(*symbols.FunctionSymbol); ok && fnc.NeedsVirtualCallToTrait {
+        return fnc.TraitSourceMethod
+    }
+
+    // fields copied over from a trait
+    if fld, ok := sym.(*symbols.FieldSymbol); ok && fld.HasParentContainer && fld.HasParentTrait {
+        if trtFld := binder.LookupFieldInTrait(fld.FieldName, fld.ParentTrait); trtFld != nil {
+            return trtFld
+        }
+    }
+
+    // "this" is whatever its type is
+    if inst, ok := sym.(*symbols.InstanceSymbol); ok {
+        if inst.InstanceType.Container != nil {
+            return inst.InstanceType.Container
+        }
+
+        if inst.InstanceType.Trait != nil {
+            return inst.InstanceType.Trait
+        }
+    }
+
+    return sym
+}
@@ -0,0 +1,340 @@
+// LSP - lsp.go
+// --------------------------------------------------------
+// A language server for ReRect (speaks the language server
+// protocol over stdin and stdout)
+// --------------------------------------------------------
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Server struct
+// -------------
+type Server struct {
+    In  *bufio.Reader
+    Out io.Writer
+
+    Documents map[string]*Document // all open documents (by uri)
+    ShuttingDown bool
+}
+
+// Handlers
+// --------
+type requestHandler func(srv *Server, params json.RawMessage) (any, *responseError)
+type notificationHandler func(srv *Server, params json.RawMessage)
+
+var requests map[string]requestHandler
+var notifications map[string]notificationHandler
+
+func init() {
+    requests = map[string]requestHandler {
+        "initialize"                : (*Server).initialize,
+        "shutdown"                  : (*Server).shutdown,
+        "textDocument/hover"        : (*Server).hover,
+        "textDocument/definition"   : (*Server).definition,
+        "textDocument/documentSymbol": (*Server).documentSymbol,
+        "textDocument/completion"   : (*Server).completion,
+    }
+
+    notifications = map[string]notificationHandler {
+        "textDocument/didOpen"  : (*Server).didOpen,
+        "textDocument/didChange": (*Server).didChange,
+        "textDocument/didClose" : (*Server).didClose,
+    }
+}
+
+// --------------------------------------------------------
+// Running
+// --------------------------------------------------------
+func Run() int {
+    srv := Server{
+        In: bufio.NewReader(os.Stdin),
+        Out: os.Stdout,
+        Documents: make(map[string]*Document),
+    }
+
+    for {
+        msg, err := srv.read()
+
+        // the client is gone -> so are we
+        if err == io.EOF {
+            return 1
+        }
+
+        // garbage in -> complain (stdout belongs to the client, so use stderr)
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "rrc lsp: %s\n", err.Error())
+            continue
+        }
+
+        // the only clean way out is shutdown -> exit
+        if msg.Method == "exit" {
+            if srv.ShuttingDown {
+                return 0
+            }
+
+            return 1
+        }
+
+        srv.handle(msg)
+    }
+}
+
+// Handle one incoming message
+// ---------------------------
+func (srv *Server) handle(msg *message) {
+    isRequest := len(msg.Id) > 0
+
+    // if a handler blows up -> dont take the whole server with it
+    defer func() {
+        r := recover()
+        if r == nil {
+            return
+        }
+
+        fmt.Fprintf(os.Stderr, "rrc lsp: %s crashed: %v\n", msg.Method, r)
+
+        if isRequest {
+            srv.respond(msg.Id, nil, &responseError{ERR_Internal, fmt.Sprintf("%v", r)})
+        }
+    }()
+
+    // Notifications
+    // -------------
+    if !isRequest {
+        handler, ok := notifications[msg.Method]
+
+        // unknown notifications can just be ignored
+        if ok {
+            handler(srv, msg.Params)
+        }
+
+        return
+    }
+
+    // Requests
+    // --------
+    handler, ok := requests[msg.Method]
+
+    if !ok {
+        srv.respond(msg.Id, nil, &responseError{ERR_MethodNotFound, fmt.Sprintf("Method '%s' is not supported!", msg.Method)})
+        return
+    }
+
+    // nothing but shutting down is allowed after a shutdown
+    if srv.ShuttingDown {
+        srv.respond(msg.Id, nil, &responseError{ERR_InvalidRequest, "Server is shutting down!"})
+        return
+    }
+
+    result, err := handler(srv, msg.Params)
+    srv.respond(msg.Id, result, err)
+}
+
+// --------------------------------------------------------
+// Lifecycle
+// --------------------------------------------------------
+func (srv *Server) initialize(params json.RawMessage) (any, *responseError) {
+    return InitializeResult{
+        Capabilities: ServerCapabilities{
+            TextDocumentSync: SYNC_Full,
+            HoverProvider: true,
+            DefinitionProvider: true,
+            DocumentSymbolProvider: true,
+            CompletionProvider: CompletionOptions{
+                TriggerCharacters: []string{">", ":"},
+            },
+        },
+        ServerInfo: ServerInfo{
+            Name: "rrc",
+        },
+    }, nil
+}
+
+func (srv *Server) shutdown(params json.RawMessage) (any, *responseError) {
+    srv.ShuttingDown = true
+    return nil, nil
+}
+
+// --------------------------------------------------------
+// Documents
+// --------------------------------------------------------
+func (srv *Server) didOpen(params json.RawMessage) {
+    var prm DidOpenTextDocumentParams
+    if decode(params, &prm) != nil {
+        return
+    }
+
+    doc := &Document{
+        URI: prm.TextDocument.URI,
+        Path: uriToPath(prm.TextDocument.URI),
+        Text: prm.TextDocument.Text,
+        Version: prm.TextDocument.Version,
+    }
+
+    srv.Documents[doc.URI] = doc
+    srv.update(doc)
+}
+
+func (srv *Server) didChange(params json.RawMessage) {
+    var prm DidChangeTextDocumentParams
+    if decode(params, &prm) != nil {
+        return
+    }
+
+    doc, ok := srv.Documents[prm.TextDocument.URI]
+    if !ok || len(prm.ContentChanges) == 0 {
+        return
+    }
+
+    // we only do full syncs -> the last change has everything
+    doc.Text = prm.ContentChanges[len(prm.ContentChanges)-1].Text
+    doc.Version = prm.TextDocument.Version
+
+    srv.update(doc)
+}
+
+func (srv *Server) didClose(params json.RawMessage) {
+    var prm DidCloseTextDocumentParams
+    if decode(params, &prm) != nil {
+        return
+    }
+
+    delete(srv.Documents, prm.TextDocument.URI)
+
+    // closed documents dont get to keep their diagnostics
+    srv.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+        URI: prm.TextDocument.URI,
+        Diagnostics: []Diagnostic{},
+    })
+}
+
+// Re-analyze a document and tell the client what we found
+// --------------------------------------------------------
+func (srv *Server) update(doc *Document) {
+    doc.Analysis = srv.analyze(doc)
+
+    // remember the last analysis that made it through the binder
+    // (completion needs it while the current text doesnt even parse)
+    if doc.Analysis.File != nil {
+        doc.LastBound = doc.Analysis
+    }
+
+    diagnostics := []Diagnostic{}
+    for _, err := range doc.Analysis.Errors {
+        diagnostics = append(diagnostics, Diagnostic{
+            Range: toRange(doc.Text, err.Position),
+            Severity: SEV_Error,
+            Source: string(err.Unit),
+            Message: err.Message,
+        })
+    }
+
+    srv.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+        URI: doc.URI,
+        Version: doc.Version,
+        Diagnostics: diagnostics,
+    })
+}
+
+// --------------------------------------------------------
+// Transport
+// --------------------------------------------------------
+
+// Read one message (a few headers, an empty line and some json)
+// --------------------------------------------------------------
+func (srv *Server) read() (*message, error) {
+    length := -1
+
+    for {
+        line, err := srv.In.ReadString('\n')
+        if err != nil {
+            return nil, err
+        }
+
+        line = strings.TrimRight(line, "\r\n")
+
+        // empty line -> headers are done
+        if line == "" {
+            break
+        }
+
+        // the only header we care about is the length
+        name, value, ok := strings.Cut(line, ":")
+        if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+            length, err = strconv.Atoi(strings.TrimSpace(value))
+            if err != nil {
+                return nil, fmt.Errorf("invalid Content-Length '%s'", strings.TrimSpace(value))
+            }
+        }
+    }
+
+    if length < 0 {
+        return nil, fmt.Errorf("message without Content-Length")
+    }
+
+    body := make([]byte, length)
+    if _, err := io.ReadFull(srv.In, body); err != nil {
+        return nil, err
+    }
+
+    msg := &message{}
+    if err := json.Unmarshal(body, msg); err != nil {
+        return nil, fmt.Errorf("invalid message (%s)", err.Error())
+    }
+
+    return msg, nil
+}
+
+// Write one message
+// -----------------
+func (srv *Server) write(msg any) {
+    body, err := json.Marshal(msg)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "rrc lsp: unable to encode message (%s)\n", err.Error())
+        return
+    }
+
+    fmt.Fprintf(srv.Out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (srv *Server) respond(id json.RawMessage, result any, err *responseError) {
+    if err != nil {
+        srv.write(errorResponse{
+            JsonRPC: "2.0",
+            Id: id,
+            Error: err,
+        })
+
+        return
+    }
+
+    srv.write(response{
+        JsonRPC: "2.0",
+        Id: id,
+        Result: result,
+    })
+}
+
+func (srv *Server) notify(method string, params any) {
+    srv.write(notification{
+        JsonRPC: "2.0",
+        Method: method,
+        Params: params,
+    })
+}
+
+func decode(params json.RawMessage, v any) *responseError {
+    if err := json.Unmarshal(params, v); err != nil {
+        return &responseError{ERR_InvalidParams, err.Error()}
+    }
+
+    return nil
+}
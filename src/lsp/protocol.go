@@ -0,0 +1,225 @@
+// LSP - protocol.go
+// --------------------------------------------------------
+// The (small) part of the language server protocol we
+// actually speak
+// --------------------------------------------------------
+package lsp
+
+import (
+	"encoding/json"
+)
+
+// --------------------------------------------------------
+// JSON-RPC
+// --------------------------------------------------------
+type message struct {
+    JsonRPC string          `json:"jsonrpc"`
+    Id      json.RawMessage `json:"id,omitempty"`
+    Method  string          `json:"method,omitempty"`
+    Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+    JsonRPC string          `json:"jsonrpc"`
+    Id      json.RawMessage `json:"id"`
+    Result  any             `json:"result"`
+}
+
+// (a failed request must not have a result, not even null)
+type errorResponse struct {
+    JsonRPC string          `json:"jsonrpc"`
+    Id      json.RawMessage `json:"id"`
+    Error   *responseError  `json:"error"`
+}
+
+type responseError struct {
+    Code    int    `json:"code"`
+    Message string `json:"message"`
+}
+
+type notification struct {
+    JsonRPC string `json:"jsonrpc"`
+    Method  string `json:"method"`
+    Params  any    `json:"params"`
+}
+
+// Error codes
+// -----------
+const (
+    ERR_ParseError     = -32700
+    ERR_InvalidParams  = -32602
+    ERR_MethodNotFound = -32601
+    ERR_InvalidRequest = -32600
+    ERR_Internal       = -32603
+)
+
+// --------------------------------------------------------
+// Basic structures
+// --------------------------------------------------------
+type Position struct {
+    Line      int `json:"line"`
+    Character int `json:"character"`
+}
+
+type Range struct {
+    Start Position `json:"start"`
+    End   Position `json:"end"`
+}
+
+type Location struct {
+    URI   string `json:"uri"`
+    Range Range  `json:"range"`
+}
+
+type TextDocumentIdentifier struct {
+    URI string `json:"uri"`
+}
+
+type TextDocumentItem struct {
+    URI        string `json:"uri"`
+    LanguageId string `json:"languageId"`
+    Version    int    `json:"version"`
+    Text       string `json:"text"`
+}
+
+type TextDocumentPositionParams struct {
+    TextDocument TextDocumentIdentifier `json:"textDocument"`
+    Position     Position               `json:"position"`
+}
+
+type MarkupContent struct {
+    Kind  string `json:"kind"`
+    Value string `json:"value"`
+}
+
+// --------------------------------------------------------
+// Lifecycle
+// --------------------------------------------------------
+type InitializeResult struct {
+    Capabilities ServerCapabilities `json:"capabilities"`
+    ServerInfo   ServerInfo         `json:"serverInfo"`
+}
+
+type ServerInfo struct {
+    Name string `json:"name"`
+}
+
+type ServerCapabilities struct {
+    TextDocumentSync       int               `json:"textDocumentSync"`
+    HoverProvider          bool              `json:"hoverProvider"`
+    DefinitionProvider     bool              `json:"definitionProvider"`
+    DocumentSymbolProvider bool              `json:"documentSymbolProvider"`
+    CompletionProvider     CompletionOptions `json:"completionProvider"`
+}
+
+type CompletionOptions struct {
+    TriggerCharacters []string `json:"triggerCharacters"`
+}
+
+// Document sync kinds
+const (
+    SYNC_Full = 1
+)
+
+// --------------------------------------------------------
+// Documents
+// --------------------------------------------------------
+type DidOpenTextDocumentParams struct {
+    TextDocument TextDocumentItem `json:"textDocument"`
+}
+
+type DidChangeTextDocumentParams struct {
+    TextDocument struct {
+        URI     string `json:"uri"`
+        Version int    `json:"version"`
+    } `json:"textDocument"`
+
+    // (we only do full syncs, so the last change is the whole document)
+    ContentChanges []struct {
+        Text string `json:"text"`
+    } `json:"contentChanges"`
+}
+
+type DidCloseTextDocumentParams struct {
+    TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// --------------------------------------------------------
+// Diagnostics
+// --------------------------------------------------------
+type PublishDiagnosticsParams struct {
+    URI         string       `json:"uri"`
+    Version     int          `json:"version,omitempty"`
+    Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+type Diagnostic struct {
+    Range    Range  `json:"range"`
+    Severity int    `json:"severity"`
+    Source   string `json:"source"`
+    Message  string `json:"message"`
+}
+
+// Diagnostic severities
+const (
+    SEV_Error = 1
+)
+
+// --------------------------------------------------------
+// Language features
+// --------------------------------------------------------
+type Hover struct {
+    Contents MarkupContent `json:"contents"`
+    Range    Range         `json:"range"`
+}
+
+type DocumentSymbolParams struct {
+    TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type DocumentSymbol struct {
+    Name           string           `json:"name"`
+    Detail         string           `json:"detail,omitempty"`
+    Kind           int              `json:"kind"`
+    Range          Range            `json:"range"`
+    SelectionRange Range            `json:"selectionRange"`
+    Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
+// Symbol kinds
+const (
+    SK_Namespace   = 3
+    SK_Class       = 5
+    SK_Method      = 6
+    SK_Field       = 8
+    SK_Constructor = 9
+    SK_Enum        = 10
+    SK_Interface   = 11
+    SK_Function    = 12
+    SK_Variable    = 13
+    SK_EnumMember  = 22
+)
+
+type CompletionList struct {
+    IsIncomplete bool             `json:"isIncomplete"`
+    Items        []CompletionItem `json:"items"`
+}
+
+type CompletionItem struct {
+    Label  string `json:"label"`
+    Kind   int    `json:"kind"`
+    Detail string `json:"detail,omitempty"`
+    Documentation string `json:"documentation,omitempty"`
+}
+
+// Completion item kinds
+const (
+    CK_Method     = 2
+    CK_Function   = 3
+    CK_Field      = 5
+    CK_Variable   = 6
+    CK_Class      = 7
+    CK_Interface  = 8
+    CK_Enum       = 13
+    CK_EnumMember = 20
+)
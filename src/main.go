@@ -3,35 +3,390 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"bytespace.network/rerect/bytecode"
+	"bytespace.network/rerect/codegen"
 	"bytespace.network/rerect/compctl"
-	"bytespace.network/rerect/evaluator"
-	"bytespace.network/rerect/error"
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/docgen"
+	"bytespace.network/rerect/lsp"
+	packageprocessor "bytespace.network/rerect/package_processor"
+	"bytespace.network/rerect/printer"
+	"bytespace.network/rerect/repl"
+	"bytespace.network/rerect/vm"
 )
 
+// Subcommands
+// -----------
+type command struct {
+    Description string
+    Run func(files []string) int
+}
+
+var commands map[string]command
+
+func init() {
+    commands = map[string]command {
+        "run"     : {"Compile and run the given source files", runCommand},
+        "check"   : {"Compile the given source files without running them", checkCommand},
+        "tokens"  : {"Print the tokens of each source file", tokensCommand},
+        "ast"     : {"Print the syntax tree of each source file", astCommand},
+        "bound"   : {"Print the bound tree of each function", boundCommand},
+        "lowered" : {"Print the lowered tree of each function", loweredCommand},
+        "bytecode": {"Print the compiled instructions of each function", bytecodeCommand},
+        "build"   : {"Compile the given source files into a module (-o <file>)", buildCommand},
+        "exec"    : {"Run a compiled module", execCommand},
+        "go"      : {"Print the go code generated for the given source files", goCommand},
+        "native"  : {"Compile the given source files into an executable (-o <file>)", nativeCommand},
+        "doc"     : {"Generate documentation for all packages (-o <dir>, --html)", docCommand},
+        "lsp"     : {"Start a language server (over stdin and stdout)", lspCommand},
+        "repl"    : {"Start an interactive session", replCommand},
+        "help"    : {"Show this list", helpCommand},
+    }
+}
+
 func main() {
 
+    // no arguments given -> go interactive
     if len(os.Args) < 2 {
-        fmt.Println("At least one source file required!")
-        return
+        os.Exit(replCommand(nil))
+    }
+
+    cmd, ok := commands[os.Args[1]]
+
+    // not a subcommand -> assume these are source files to run (like in the good old days)
+    if !ok {
+        os.Exit(runCommand(os.Args[1:]))
+    }
+
+    // anything but the REPL and the help page needs some source files
+    if len(os.Args) < 3 && os.Args[1] != "repl" && os.Args[1] != "lsp" && os.Args[1] != "doc" && os.Args[1] != "help" {
+        fmt.Printf("At least one source file required! (usage: rrc %s <files...>)\n", os.Args[1])
+        os.Exit(1)
+    }
+
+    os.Exit(cmd.Run(os.Args[2:]))
+}
+
+// --------------------------------------------------------
+// Commands
+// --------------------------------------------------------
+func runCommand(files []string) int {
+    // Compile
+    // -------
+    prg := compctl.Compile(compunit.NewCompilation(), files)
+
+    if !prg.Ok {
+        return 1
+    }
+
+    // Evaluate
+    // --------
+    res := vm.Evaluate(prg.Comp, prg.Program)
+
+    // if something went wrong -> tell the user about it
+    if res.Error != nil {
+        prg.Comp.OutputError(res.Error.Error)
+    }
+
+    return res.ExitCode
+}
+
+func checkCommand(files []string) int {
+    prg := compctl.Compile(compunit.NewCompilation(), files)
+
+    if !prg.Ok {
+        return 1
+    }
+
+    fmt.Println("No errors found.")
+    return 0
+}
+
+func tokensCommand(files []string) int {
+    prg := compctl.CompileUntil(compunit.NewCompilation(), files, compctl.STG_Lex)
+
+    if !prg.Ok {
+        return 1
+    }
+
+    for i, tokens := range prg.Tokens {
+        fmt.Printf("Tokens of '%s':\n", files[i])
+        printer.PrintTokens(prg.Comp, tokens)
+
+        if i != len(prg.Tokens) - 1 {
+            fmt.Println()
+        }
+    }
+
+    return 0
+}
+
+func astCommand(files []string) int {
+    prg := compctl.CompileUntil(compunit.NewCompilation(), files, compctl.STG_Parse)
+
+    if !prg.Ok {
+        return 1
+    }
+
+    for i, members := range prg.Members {
+        fmt.Printf("Syntax tree of '%s':\n", files[i])
+
+        for _, mem := range members {
+            printer.PrintTree(mem)
+        }
+
+        if i != len(prg.Members) - 1 {
+            fmt.Println()
+        }
+    }
+
+    return 0
+}
+
+func boundCommand(files []string) int {
+    return printFunctions(files, compctl.STG_Bind)
+}
+
+func loweredCommand(files []string) int {
+    return printFunctions(files, compctl.STG_Lower)
+}
+
+func bytecodeCommand(files []string) int {
+    prg := compctl.Compile(compunit.NewCompilation(), files)
+
+    if !prg.Ok {
+        return 1
+    }
+
+    printer.PrintBytecode(prg.Program)
+    return 0
+}
+
+func buildCommand(args []string) int {
+    // look for an output path
+    out := ""
+    files := []string{}
+
+    for i := 0; i < len(args); i++ {
+        if args[i] == "-o" && i + 1 < len(args) {
+            out = args[i + 1]
+            i++
+            continue
+        }
+
+        files = append(files, args[i])
+    }
+
+    if len(files) == 0 {
+        fmt.Println("No source files given!")
+        return 1
+    }
+
+    // no output given -> just name it after the first file
+    if out == "" {
+        out = strings.TrimSuffix(files[0], filepath.Ext(files[0])) + ".rrx"
+    }
+
+    // Compile
+    // -------
+    prg := compctl.Compile(compunit.NewCompilation(), files)
+
+    if !prg.Ok {
+        return 1
+    }
+
+    // Save
+    // ----
+    bytecode.Save(prg.Comp, prg.Program, out)
+
+    if prg.Comp.HasErrors() {
+        prg.Comp.OutputErrors()
+        return 1
+    }
+
+    return 0
+}
+
+func execCommand(files []string) int {
+    if len(files) != 1 {
+        fmt.Println("Exactly one module needs to be given!")
+        return 1
+    }
+
+    comp := compunit.NewCompilation()
+
+    // natives need to be there before the module can link against them
+    packageprocessor.Init(comp)
+
+    // Load
+    // ----
+    prg := bytecode.Load(comp, files[0])
+
+    if prg == nil {
+        comp.OutputErrors()
+        return 1
+    }
+
+    // Evaluate
+    // --------
+    res := vm.Evaluate(comp, prg)
+
+    // if something went wrong -> tell the user about it
+    if res.Error != nil {
+        comp.OutputError(res.Error.Error)
     }
 
+    return res.ExitCode
+}
+
+func goCommand(files []string) int {
+    prg := compctl.CompileUntil(compunit.NewCompilation(), files, compctl.STG_Lower)
+
+    if !prg.Ok {
+        return 1
+    }
+
+    src := codegen.Generate(prg.Comp, prg.Files)
+
+    if prg.Comp.HasErrors() {
+        prg.Comp.OutputErrors()
+        return 1
+    }
+
+    fmt.Print(string(src))
+    return 0
+}
+
+func nativeCommand(args []string) int {
+    // look for an output path
+    out := ""
+    files := []string{}
+
+    for i := 0; i < len(args); i++ {
+        if args[i] == "-o" && i + 1 < len(args) {
+            out = args[i + 1]
+            i++
+            continue
+        }
+
+        files = append(files, args[i])
+    }
+
+    if len(files) == 0 {
+        fmt.Println("No source files given!")
+        return 1
+    }
+
+    // no output given -> just name it after the first file
+    if out == "" {
+        out = strings.TrimSuffix(files[0], filepath.Ext(files[0]))
+    }
 
     // Compile
     // -------
-    prg := compctl.Compile(os.Args[1:])
+    prg := compctl.CompileUntil(compunit.NewCompilation(), files, compctl.STG_Lower)
 
     if !prg.Ok {
-        return
+        return 1
     }
 
-    // Evaluate 
+    // Generate
     // --------
-    evaluator.Evaluate(prg)
+    src := codegen.Generate(prg.Comp, prg.Files)
 
-    // if there are errors -> output them and stop execution
-    if error.HasErrors() {
-        error.Output()
-        return
+    if !prg.Comp.HasErrors() {
+        codegen.Build(prg.Comp, src, out)
     }
+
+    if prg.Comp.HasErrors() {
+        prg.Comp.OutputErrors()
+        return 1
+    }
+
+    return 0
+}
+
+func docCommand(args []string) int {
+    // look for an output directory and format
+    out := "docs"
+    html := false
+    files := []string{}
+
+    for i := 0; i < len(args); i++ {
+        if args[i] == "-o" && i + 1 < len(args) {
+            out = args[i + 1]
+            i++
+            continue
+        }
+
+        if args[i] == "--html" {
+            html = true
+            continue
+        }
+
+        files = append(files, args[i])
+    }
+
+    // Compile
+    // (no files is fine too, then only the natives get documented)
+    // ------------------------------------------------------------
+    prg := compctl.CompileUntil(compunit.NewCompilation(), files, compctl.STG_Bind)
+
+    if !prg.Ok {
+        return 1
+    }
+
+    // Generate
+    // --------
+    docgen.Write(prg.Comp, docgen.Generate(prg.Comp, html), out)
+
+    if prg.Comp.HasErrors() {
+        prg.Comp.OutputErrors()
+        return 1
+    }
+
+    return 0
+}
+
+func printFunctions(files []string, stage compctl.CompilationStage) int {
+    prg := compctl.CompileUntil(compunit.NewCompilation(), files, stage)
+
+    if !prg.Ok {
+        return 1
+    }
+
+    for _, file := range prg.Files {
+        for _, fnc := range file.Functions {
+            printer.PrintFunction(fnc, file.FunctionBodies[fnc])
+        }
+    }
+
+    return 0
+}
+
+func lspCommand(files []string) int {
+    return lsp.Run()
+}
+
+func replCommand(files []string) int {
+    repl.Run()
+    return 0
+}
+
+func helpCommand(files []string) int {
+    fmt.Println("Usage: rrc [command] <files...>")
+    fmt.Println()
+    fmt.Println("Commands:")
+
+    // keep the order stable
+    for _, name := range []string{"run", "check", "tokens", "ast", "bound", "lowered", "bytecode", "build", "exec", "go", "native", "doc", "lsp", "repl", "help"} {
+        fmt.Printf("  %-8s %s\n", name, commands[name].Description)
+    }
+
+    fmt.Println()
+    fmt.Println("Without a command the given files are run, without any arguments the REPL is started.")
+    return 0
 }
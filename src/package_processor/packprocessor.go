@@ -5,6 +5,8 @@
 package packageprocessor
 
 import (
+	"slices"
+
 	"bytespace.network/rerect/boundnodes"
 	"bytespace.network/rerect/compunit"
 	"bytespace.network/rerect/error"
@@ -22,25 +24,29 @@ type CompilationFile struct {
     FunctionBodies    map[*symbols.FunctionSymbol]boundnodes.BoundStatementNode
 
     Globals []*symbols.GlobalSymbol
+    GlobalSrc map[*symbols.GlobalSymbol]*syntaxnodes.GlobalNode
 
     Containers []*symbols.ContainerSymbol
     ContainerSrc map[*symbols.ContainerSymbol]*syntaxnodes.ContainerNode
 
     Traits []*symbols.TraitSymbol
     TraitSrc map[*symbols.TraitSymbol]*syntaxnodes.TraitNode
+
+    Enums []*symbols.EnumSymbol
+    EnumSrc map[*symbols.EnumSymbol]*syntaxnodes.EnumNode
 }
 
-func Init() {
+func Init(comp *compunit.Compilation) {
     // load all native packages
-    gopackages.Load()
+    gopackages.Load(comp)
 }
 
-func Process(mems [][]syntaxnodes.MemberNode) []*CompilationFile {
+func Process(comp *compunit.Compilation, mems [][]syntaxnodes.MemberNode) []*CompilationFile {
     files := []*CompilationFile{}
 
     // Register all names first
     for _, mem := range mems {
-        p := register(mem)
+        p := register(comp, mem)
         //fmt.Println(p.PackName)
 
         files = append(files, &CompilationFile{
@@ -52,6 +58,9 @@ func Process(mems [][]syntaxnodes.MemberNode) []*CompilationFile {
             FunctionBodiesSrc: make(map[*symbols.FunctionSymbol]syntaxnodes.StatementNode),
             FunctionBodies: make(map[*symbols.FunctionSymbol]boundnodes.BoundStatementNode),
 
+            // globals too (constants need their values)
+            GlobalSrc: make(map[*symbols.GlobalSymbol]*syntaxnodes.GlobalNode),
+
             // here too :)
             Containers: []*symbols.ContainerSymbol{},
             ContainerSrc: make(map[*symbols.ContainerSymbol]*syntaxnodes.ContainerNode),
@@ -59,18 +68,22 @@ func Process(mems [][]syntaxnodes.MemberNode) []*CompilationFile {
             // here even more so
             Traits: []*symbols.TraitSymbol{},
             TraitSrc: make(map[*symbols.TraitSymbol]*syntaxnodes.TraitNode),
+
+            // and enums
+            Enums: []*symbols.EnumSymbol{},
+            EnumSrc: make(map[*symbols.EnumSymbol]*syntaxnodes.EnumNode),
         })
     }
     
     // Link up the packages
     for _, file := range files {
-        link(file.Package, file.Members)
+        link(comp, file.Package, file.Members)
     }
 
     return files
 }
 
-func register(mem []syntaxnodes.MemberNode) *symbols.PackageSymbol {
+func register(comp *compunit.Compilation, mem []syntaxnodes.MemberNode) *symbols.PackageSymbol {
     packageName := "main"
 
     // search through all members
@@ -86,16 +99,20 @@ func register(mem []syntaxnodes.MemberNode) *symbols.PackageSymbol {
     }
 
     // get or create the package of that name
-    pack := compunit.GetPackageAtAllCosts(packageName)
+    pack := comp.GetPackageAtAllCosts(packageName)
     return pack
 }
 
-func link(pck *symbols.PackageSymbol, mem []syntaxnodes.MemberNode) {
+func link(comp *compunit.Compilation, pck *symbols.PackageSymbol, mem []syntaxnodes.MemberNode) {
     // always link the "internal" package
     // -> it contains important core functions and methods like string.Length(), array.Length(), etc 
-    internal := compunit.GetPackage("internal")
+    internal := comp.GetPackage("internal")
     pck.LoadedPackages["internal"] = internal
-    pck.IncludedPackages = append(pck.IncludedPackages, "internal")
+
+    // (packages can be linked more than once, e.g. in the REPL -> dont include things twice)
+    if !slices.Contains(pck.IncludedPackages, "internal") {
+        pck.IncludedPackages = append(pck.IncludedPackages, "internal")
+    }
 
     // search through all members
     for _, nd := range mem {
@@ -109,11 +126,11 @@ func link(pck *symbols.PackageSymbol, mem []syntaxnodes.MemberNode) {
         packageName := node.Library.Buffer
 
         // look the package up and add a reference
-        ref := compunit.GetPackage(packageName)
+        ref := comp.GetPackage(packageName)
 
         // lookup failed
         if ref == nil {
-            error.Report(error.NewError(error.PCK, node.Position(), "Could not find package '%s'!", packageName))
+            comp.Report(error.NewError(error.PCK, node.Position(), "Could not find package '%s'!", packageName))
             continue
         }
 
@@ -121,7 +138,7 @@ func link(pck *symbols.PackageSymbol, mem []syntaxnodes.MemberNode) {
         pck.LoadedPackages[packageName] = ref
 
         // is the package included? -> if so: add it to the list
-        if node.Included {
+        if node.Included && !slices.Contains(pck.IncludedPackages, packageName) {
             pck.IncludedPackages = append(pck.IncludedPackages, packageName)
         }
     }
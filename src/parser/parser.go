@@ -5,6 +5,7 @@
 package parser
 
 import (
+	"bytespace.network/rerect/compunit"
 	"bytespace.network/rerect/error"
 	"bytespace.network/rerect/lexer"
 	"bytespace.network/rerect/span"
@@ -14,6 +15,8 @@ import (
 // Parser struct
 // -------------
 type Parser struct {
+    Comp *compunit.Compilation
+
     Source []lexer.Token
     SourceFileIdx int
 
@@ -55,7 +58,7 @@ func (prs *Parser) consume(typ lexer.TokenType) lexer.Token {
     // token doesnt match the expected type
     if prs.current().Type != typ {
         // report this error
-        error.Report(error.NewError(error.PRS, prs.current().Position, "Expected token '%s', instead got: '%s'!", typ, prs.current().Type))
+        prs.Comp.Report(error.NewError(error.PRS, prs.current().Position, "Expected token '%s', instead got: '%s'!", typ, prs.current().Type))
        
         //prs.step(1)
 
@@ -77,7 +80,7 @@ func (prs *Parser) consumeWord(word string) lexer.Token {
     // make sure it matches our word
     if id.Buffer != word {
         // report this error
-        error.Report(error.NewError(error.PRS, prs.current().Position, "Expected keyword '%s', instead got: '%s'!", word, id.Buffer))
+        prs.Comp.Report(error.NewError(error.PRS, prs.current().Position, "Expected keyword '%s', instead got: '%s'!", word, id.Buffer))
        
         // rewind
         prs.step(-1)
@@ -105,7 +108,7 @@ func (prs *Parser) rewind(tok lexer.Token) {
 // --------------------------------------------------------
 // Parsing
 // --------------------------------------------------------
-func Parse(tokens []lexer.Token) []syntaxnodes.MemberNode {
+func Parse(comp *compunit.Compilation, tokens []lexer.Token) []syntaxnodes.MemberNode {
     // if theres no tokens, theres nothing to parse
     if len(tokens) == 0 {
         return make([]syntaxnodes.MemberNode, 0)
@@ -113,6 +116,7 @@ func Parse(tokens []lexer.Token) []syntaxnodes.MemberNode {
 
     // create parser instance
     prs := Parser {
+        Comp: comp,
         Source: tokens,
         SourceFileIdx: tokens[0].Position.File,
         Length: len(tokens),
@@ -124,6 +128,57 @@ func Parse(tokens []lexer.Token) []syntaxnodes.MemberNode {
     return prs.Members
 }
 
+// Parse a script (a mix of members and loose statements, used by the REPL)
+// ------------------------------------------------------------------------
+func ParseScript(comp *compunit.Compilation, tokens []lexer.Token) ([]syntaxnodes.MemberNode, []syntaxnodes.StatementNode) {
+    stmts := make([]syntaxnodes.StatementNode, 0)
+
+    // if theres no tokens, theres nothing to parse
+    if len(tokens) == 0 {
+        return make([]syntaxnodes.MemberNode, 0), stmts
+    }
+
+    // create parser instance
+    prs := Parser {
+        Comp: comp,
+        Source: tokens,
+        SourceFileIdx: tokens[0].Position.File,
+        Length: len(tokens),
+        Members: make([]syntaxnodes.MemberNode, 0),
+    }
+
+    for prs.current().Type != lexer.TT_EOF {
+        // stray semicolons dont hurt anyone here
+        if prs.current().Type == lexer.TT_Semicolon {
+            prs.step(1)
+            continue
+        }
+
+        // functions, containers, traits, enums and loads are members
+        // (a 'var' is treated like a statement here, the REPL turns it into a global later)
+        if prs.current().Type == lexer.TT_KW_Load     ||
+           prs.current().Type == lexer.TT_KW_Package  ||
+           prs.current().Type == lexer.TT_KW_Function ||
+           prs.current().Type == lexer.TT_KW_Container ||
+           prs.current().Type == lexer.TT_KW_Trait    ||
+           prs.current().Type == lexer.TT_KW_Enum {
+            prs.parseMember()
+
+        // everything else is a statement
+        } else {
+            start := prs.Index
+            stmts = append(stmts, prs.parseStatement())
+
+            // make sure we always move forward, even if the statement was garbage
+            if prs.Index == start {
+                prs.step(1)
+            }
+        }
+    }
+
+    return prs.Members, stmts
+}
+
 // --------------------------------------------------------
 // Members
 // --------------------------------------------------------
@@ -149,7 +204,8 @@ func (prs *Parser) parseMember() {
         mem = prs.parseFunctionMember()
 
     // var <varname> <type>
-    } else if prs.current().Type == lexer.TT_KW_Var {
+    // const <varname> [type] <- <value>
+    } else if prs.current().Type == lexer.TT_KW_Var || prs.current().Type == lexer.TT_KW_Const {
         mem = prs.parseGlobalMember()
 
     // container <containername> (<traits>) { ... }
@@ -160,9 +216,13 @@ func (prs *Parser) parseMember() {
     } else if prs.current().Type == lexer.TT_KW_Trait {
         mem = prs.parseTraitMember()
 
+    // enum <enumname> { ... }
+    } else if prs.current().Type == lexer.TT_KW_Enum {
+        mem = prs.parseEnumMember()
+
     // anything else -> error
     } else {
-        error.Report(error.NewError(error.PRS, prs.current().Position, "Expected member, instead got: '%s'!", prs.current().Type))
+        prs.Comp.Report(error.NewError(error.PRS, prs.current().Position, "Expected member, instead got: '%s'!", prs.current().Type))
         prs.step(1)
 
         return
@@ -171,6 +231,7 @@ func (prs *Parser) parseMember() {
     // if this isnt a function -> require a semicolon
     if mem.Type() != syntaxnodes.NT_Function &&
        mem.Type() != syntaxnodes.NT_Trait    &&
+       mem.Type() != syntaxnodes.NT_Enum     &&
        mem.Type() != syntaxnodes.NT_Container {
         prs.consume(lexer.TT_Semicolon)
     }
@@ -224,27 +285,11 @@ func (prs *Parser) parseFunctionMember() *syntaxnodes.FunctionNode {
         id = prs.consume(lexer.TT_Identifier)
     }
 
-    // consume '('
-    prs.consume(lexer.TT_OpenParenthesis)
-
-    // parse parameters
-    var params []*syntaxnodes.ParameterClauseNode
-    for prs.current().Type != lexer.TT_CloseParenthesis {
-        params = append(params, prs.parseParameterClause())
-    }
+    // are there any type parameters?
+    typprms := prs.parseTypeParameters()
 
-    // consume ')'
-    prs.consume(lexer.TT_CloseParenthesis)
-   
-    // parse return type
-    var retType *syntaxnodes.TypeClauseNode
-    hasReturnType := false
-    if prs.current().Type != lexer.TT_OpenBraces &&
-       prs.current().Type != lexer.TT_Semicolon &&
-       prs.current().Type != lexer.TT_Colon {
-        retType = prs.parseTypeClause()
-        hasReturnType = true
-    }
+    // parse parameters and return type
+    params, retType, hasReturnType := prs.parseSignature()
 
     // parse the body
     var body syntaxnodes.StatementNode
@@ -269,21 +314,81 @@ func (prs *Parser) parseFunctionMember() *syntaxnodes.FunctionNode {
         body = prs.parseBlockStatement()
     }
 
-    return syntaxnodes.NewFunctionNode(kw, id, isConstructor, params, retType, hasReturnType, body, hasBody, closing)
+    return syntaxnodes.NewFunctionNode(kw, id, isConstructor, typprms, params, retType, hasReturnType, body, hasBody, closing, kw.Doc)
+}
+
+// Parameters and return type of functions and lambdas
+// (a, b int) int
+// ---------------------------------------------------
+func (prs *Parser) parseSignature() ([]*syntaxnodes.ParameterClauseNode, *syntaxnodes.TypeClauseNode, bool) {
+    // consume '('
+    prs.consume(lexer.TT_OpenParenthesis)
+
+    // parse parameters
+    var params []*syntaxnodes.ParameterClauseNode
+    for prs.current().Type != lexer.TT_CloseParenthesis &&
+        prs.current().Type != lexer.TT_EOF {
+
+        start := prs.Index
+        params = append(params, prs.parseParameterClause())
+
+        // make sure we always move forward, even if the parameter was garbage
+        if prs.Index == start {
+            prs.step(1)
+        }
+    }
+
+    // consume ')'
+    prs.consume(lexer.TT_CloseParenthesis)
+   
+    // parse return type
+    var retType *syntaxnodes.TypeClauseNode
+    hasReturnType := false
+    if prs.current().Type != lexer.TT_OpenBraces &&
+       prs.current().Type != lexer.TT_Semicolon &&
+       prs.current().Type != lexer.TT_Colon {
+        retType = prs.parseTypeClause()
+        hasReturnType = true
+    }
+
+    return params, retType, hasReturnType
 }
 
 func (prs *Parser) parseGlobalMember() *syntaxnodes.GlobalNode {
-    // consume 'var' keyword
-    kw := prs.consume(lexer.TT_KW_Var)
+    // consume 'var' or 'const' keyword
+    var kw lexer.Token
+    if prs.current().Type == lexer.TT_KW_Const {
+        kw = prs.consume(lexer.TT_KW_Const)
+    } else {
+        kw = prs.consume(lexer.TT_KW_Var)
+    }
 
     // consume variable name
     id := prs.consume(lexer.TT_Identifier)
 
-    // consume variable type 
-    typ := prs.parseTypeClause()
+    // normal globals just have a type
+    if kw.Type == lexer.TT_KW_Var {
+        typ := prs.parseTypeClause()
+        return syntaxnodes.NewGlobalNode(kw, id, typ, true, nil, false, kw.Doc)
+    }
+
+    // constants need a value, the type is optional
+    var typ *syntaxnodes.TypeClauseNode
+    hasExplicitType := false
+
+    if prs.current().Type == lexer.TT_Identifier {
+        typ = prs.parseTypeClause()
+        hasExplicitType = true
+    }
+
+    // consume assignment arrow
+    prs.consume(lexer.TT_LeftArrow)
+
+    // consume the value
+    initializer := prs.parseExpression()
 
     // create a new member node
-    return syntaxnodes.NewGlobalNode(kw, id, typ)
+    return syntaxnodes.NewGlobalNode(kw, id, typ, hasExplicitType, initializer, true, kw.Doc)
 }
 
 func (prs *Parser) parseContainerMember() *syntaxnodes.ContainerNode {
@@ -293,6 +398,9 @@ func (prs *Parser) parseContainerMember() *syntaxnodes.ContainerNode {
     // consume container name 
     id := prs.consume(lexer.TT_Identifier)
 
+    // are there any type parameters?
+    typprms := prs.parseTypeParameters()
+
     // do we have some cool traits?
     traits := []*syntaxnodes.TraitClauseNode{}
     if prs.current().Type == lexer.TT_OpenParenthesis {
@@ -326,7 +434,7 @@ func (prs *Parser) parseContainerMember() *syntaxnodes.ContainerNode {
     // consume '}'
     cls := prs.consume(lexer.TT_CloseBraces)
 
-    return syntaxnodes.NewContainerNode(kw, id, fields, methods, traits, cls)
+    return syntaxnodes.NewContainerNode(kw, id, typprms, fields, methods, traits, cls, kw.Doc)
 }
 
 func (prs *Parser) parseTraitMember() *syntaxnodes.TraitNode {
@@ -336,6 +444,9 @@ func (prs *Parser) parseTraitMember() *syntaxnodes.TraitNode {
     // consume trait name 
     id := prs.consume(lexer.TT_Identifier)
 
+    // are there any type parameters?
+    typprms := prs.parseTypeParameters()
+
     // consume '{'
     prs.consume(lexer.TT_OpenBraces)
 
@@ -345,7 +456,46 @@ func (prs *Parser) parseTraitMember() *syntaxnodes.TraitNode {
     // consume '}'
     cls := prs.consume(lexer.TT_CloseBraces)
 
-    return syntaxnodes.NewTraitNode(kw, id, fields, methods, cls)
+    return syntaxnodes.NewTraitNode(kw, id, typprms, fields, methods, cls, kw.Doc)
+}
+
+func (prs *Parser) parseEnumMember() *syntaxnodes.EnumNode {
+    // consume 'enum' keyword
+    kw := prs.consume(lexer.TT_KW_Enum)
+
+    // consume enum name
+    id := prs.consume(lexer.TT_Identifier)
+
+    // consume '{'
+    prs.consume(lexer.TT_OpenBraces)
+
+    // parse the members
+    members := []*syntaxnodes.EnumMemberClauseNode{}
+    for prs.current().Type != lexer.TT_CloseBraces &&
+        prs.current().Type != lexer.TT_EOF {
+
+        start := prs.Index
+        members = append(members, prs.parseEnumMemberClause())
+
+        // make sure we always move forward, even if the member was garbage
+        if prs.Index == start {
+            prs.step(1)
+        }
+
+        // if theres a comma -> consume it
+        if prs.current().Type == lexer.TT_Comma {
+            prs.consume(lexer.TT_Comma)
+
+        // otherwise -> assume end of list
+        } else {
+            break
+        }
+    }
+
+    // consume '}'
+    cls := prs.consume(lexer.TT_CloseBraces)
+
+    return syntaxnodes.NewEnumNode(kw, id, members, cls, kw.Doc)
 }
 
 func (prs *Parser) parseContainerOrTraitMembers() ([]*syntaxnodes.FieldClauseNode, []*syntaxnodes.FunctionNode) {
@@ -355,6 +505,8 @@ func (prs *Parser) parseContainerOrTraitMembers() ([]*syntaxnodes.FieldClauseNod
     for prs.current().Type != lexer.TT_CloseBraces && 
         prs.current().Type != lexer.TT_EOF {
         
+        start := prs.Index
+
         // is this a method?
         if prs.current().Type == lexer.TT_KW_Function {
             methods = append(methods, prs.parseFunctionMember())
@@ -363,6 +515,11 @@ func (prs *Parser) parseContainerOrTraitMembers() ([]*syntaxnodes.FieldClauseNod
         } else {
             fields = append(fields, prs.parseFieldClause())
         }
+
+        // make sure we always move forward, even if the member was garbage
+        if prs.Index == start {
+            prs.step(1)
+        }
     }
 
     return fields, methods
@@ -382,6 +539,15 @@ func (prs *Parser) parseParameterClause() *syntaxnodes.ParameterClauseNode {
 }
 
 func (prs *Parser) parseFieldClause() *syntaxnodes.FieldClauseNode {
+    // (optional) consume 'readonly' keyword
+    var kw lexer.Token
+    isReadonly := false
+
+    if prs.current().Type == lexer.TT_KW_Readonly {
+        kw = prs.consume(lexer.TT_KW_Readonly)
+        isReadonly = true
+    }
+
     // consume param name 
     id := prs.consume(lexer.TT_Identifier)
 
@@ -391,7 +557,98 @@ func (prs *Parser) parseFieldClause() *syntaxnodes.FieldClauseNode {
     // consume a semicolon
     prs.consume(lexer.TT_Semicolon)
 
-    return syntaxnodes.NewFieldClauseNode(id, typ)
+    // the doc comment sits on whatever came first
+    doc := id.Doc
+    if isReadonly {
+        doc = kw.Doc
+    }
+
+    return syntaxnodes.NewFieldClauseNode(kw, isReadonly, id, typ, doc)
+}
+
+func (prs *Parser) parseEnumMemberClause() *syntaxnodes.EnumMemberClauseNode {
+    // consume member name
+    id := prs.consume(lexer.TT_Identifier)
+
+    // is there an explicit value?
+    var minus, val lexer.Token
+    hasValue := false
+    isNegative := false
+
+    if prs.current().Type == lexer.TT_LeftArrow {
+        prs.consume(lexer.TT_LeftArrow)
+        hasValue = true
+
+        // negative numbers are allowed too
+        if prs.current().Type == lexer.TT_Minus {
+            minus = prs.consume(lexer.TT_Minus)
+            isNegative = true
+        }
+
+        val = prs.consume(lexer.TT_Integer)
+    }
+
+    return syntaxnodes.NewEnumMemberClauseNode(id, hasValue, minus, isNegative, val, id.Doc)
+}
+
+func (prs *Parser) parseSwitchCaseClause() *syntaxnodes.SwitchCaseClauseNode {
+    var kw, name lexer.Token
+    var typ *syntaxnodes.TypeClauseNode
+    values := []syntaxnodes.ExpressionNode{}
+    isDefault := false
+    isPattern := false
+
+    // default:
+    if prs.current().Type == lexer.TT_KW_Default {
+        kw = prs.consume(lexer.TT_KW_Default)
+        isDefault = true
+
+    // case <name> <type>:
+    // (two names in a row can never be an expression)
+    } else if prs.peek(1).Type == lexer.TT_Identifier && prs.peek(2).Type == lexer.TT_Identifier {
+        kw = prs.consume(lexer.TT_KW_Case)
+        name = prs.consume(lexer.TT_Identifier)
+        typ = prs.parseTypeClause()
+        isPattern = true
+
+    // case <value>, <value>:
+    } else {
+        kw = prs.consume(lexer.TT_KW_Case)
+
+        for {
+            values = append(values, prs.parseExpression())
+
+            // if theres a comma -> there's more
+            if prs.current().Type == lexer.TT_Comma {
+                prs.consume(lexer.TT_Comma)
+
+            // otherwise -> assume end of list
+            } else {
+                break
+            }
+        }
+    }
+
+    // consume ':'
+    colon := prs.consume(lexer.TT_Colon)
+
+    // everything up to the next case belongs to this one
+    stmts := []syntaxnodes.StatementNode{}
+    for prs.current().Type != lexer.TT_KW_Case &&
+        prs.current().Type != lexer.TT_KW_Default &&
+        prs.current().Type != lexer.TT_CloseBraces &&
+        prs.current().Type != lexer.TT_EOF {
+
+        start := prs.Index
+        stmts = append(stmts, prs.parseStatement())
+
+        // make sure we always move forward, even if the statement was garbage
+        if prs.Index == start {
+            prs.step(1)
+        }
+    }
+
+    return syntaxnodes.NewSwitchCaseClauseNode(kw, isDefault, values, isPattern, name, typ, colon, stmts)
 }
 
 func (prs *Parser) parseTypeClause() *syntaxnodes.TypeClauseNode {
@@ -410,32 +667,63 @@ func (prs *Parser) parseTypeClause() *syntaxnodes.TypeClauseNode {
     // consume type name
     id := prs.consume(lexer.TT_Identifier)
 
-    var subtypes []*syntaxnodes.TypeClauseNode
+    // function types get their own little syntax
+    if !hasPackage && id.Buffer == "func" && prs.current().Type == lexer.TT_OpenBrackets {
+        return prs.parseFunctionTypeClause(id)
+    }
 
-    // check if theres are subtypes (look for '[')
-    if prs.current().Type == lexer.TT_OpenBrackets {
-        // consume '['
-        prs.consume(lexer.TT_OpenBrackets)
+    // check if theres are subtypes
+    subtypes := prs.parseTypeArguments()
 
-        for prs.current().Type != lexer.TT_CloseBrackets {
-            subtypes = append(subtypes, prs.parseTypeClause())
+    // is this type nullable? (Foo?)
+    var marker lexer.Token
+    isNullable := false
 
-            // if we find a comma -> absorb it
-            if prs.current().Type == lexer.TT_Comma {
-                prs.consume(lexer.TT_Comma)
+    if prs.current().Type == lexer.TT_QuestionMark {
+        marker = prs.consume(lexer.TT_QuestionMark)
+        isNullable = true
+    }
 
-            // otherwise -> break
-            } else {
-                break
-            }
+    // create new clause
+    return syntaxnodes.NewTypeClauseNode(pack, hasPackage, id, subtypes, marker, isNullable)
+}
+
+// Function types
+// func[int, int -> bool]
+// ----------------------
+func (prs *Parser) parseFunctionTypeClause(id lexer.Token) *syntaxnodes.TypeClauseNode {
+    // consume '['
+    prs.consume(lexer.TT_OpenBrackets)
+
+    // parameter types
+    var params []*syntaxnodes.TypeClauseNode
+    for prs.current().Type != lexer.TT_CloseBrackets &&
+        prs.current().Type != lexer.TT_RightArrow &&
+        prs.current().Type != lexer.TT_EOF {
+
+        params = append(params, prs.parseTypeClause())
+
+        // if we find a comma -> absorb it
+        if prs.current().Type == lexer.TT_Comma {
+            prs.consume(lexer.TT_Comma)
+
+        // otherwise -> break
+        } else {
+            break
         }
+    }
 
-        // consume ']'
-        prs.consume(lexer.TT_CloseBrackets)
+    // is there a return type?
+    var ret *syntaxnodes.TypeClauseNode
+    if prs.current().Type == lexer.TT_RightArrow {
+        prs.consume(lexer.TT_RightArrow)
+        ret = prs.parseTypeClause()
     }
 
-    // create new clause
-    return syntaxnodes.NewTypeClauseNode(pack, hasPackage, id, subtypes)
+    // consume ']'
+    cls := prs.consume(lexer.TT_CloseBrackets)
+
+    return syntaxnodes.NewFunctionTypeClauseNode(id, params, ret, cls)
 }
 
 func (prs *Parser) parseFieldAssignmentClause() *syntaxnodes.FieldAssignmentClauseNode {
@@ -465,7 +753,80 @@ func (prs *Parser) parseTraitClause() *syntaxnodes.TraitClauseNode {
 
     id := prs.consume(lexer.TT_Identifier)
 
-    return syntaxnodes.NewTraitClauseNode(pack, hasPackage, id)
+    // generic traits need their type arguments
+    subtypes := prs.parseTypeArguments()
+
+    return syntaxnodes.NewTraitClauseNode(pack, hasPackage, id, subtypes)
+}
+
+// Type parameters of a generic member
+// [T, U]
+// -----------------------------------
+func (prs *Parser) parseTypeParameters() []lexer.Token {
+    prms := []lexer.Token{}
+
+    // no '[' -> no type parameters
+    if prs.current().Type != lexer.TT_OpenBrackets {
+        return prms
+    }
+
+    // consume '['
+    prs.consume(lexer.TT_OpenBrackets)
+
+    for prs.current().Type != lexer.TT_CloseBrackets &&
+        prs.current().Type != lexer.TT_EOF {
+
+        prms = append(prms, prs.consume(lexer.TT_Identifier))
+
+        // if we find a comma -> absorb it
+        if prs.current().Type == lexer.TT_Comma {
+            prs.consume(lexer.TT_Comma)
+
+        // otherwise -> break
+        } else {
+            break
+        }
+    }
+
+    // consume ']'
+    prs.consume(lexer.TT_CloseBrackets)
+
+    return prms
+}
+
+// Subtypes / type arguments of a type
+// [int, string]
+// -----------------------------------
+func (prs *Parser) parseTypeArguments() []*syntaxnodes.TypeClauseNode {
+    var args []*syntaxnodes.TypeClauseNode
+
+    // no '[' -> no type arguments
+    if prs.current().Type != lexer.TT_OpenBrackets {
+        return args
+    }
+
+    // consume '['
+    prs.consume(lexer.TT_OpenBrackets)
+
+    for prs.current().Type != lexer.TT_CloseBrackets &&
+        prs.current().Type != lexer.TT_EOF {
+
+        args = append(args, prs.parseTypeClause())
+
+        // if we find a comma -> absorb it
+        if prs.current().Type == lexer.TT_Comma {
+            prs.consume(lexer.TT_Comma)
+
+        // otherwise -> break
+        } else {
+            break
+        }
+    }
+
+    // consume ']'
+    prs.consume(lexer.TT_CloseBrackets)
+
+    return args
 }
 
 // --------------------------------------------------------
@@ -477,7 +838,8 @@ func (prs *Parser) parseStatement() syntaxnodes.StatementNode {
     
 
     // var <name> [type] [<- <initializer>] 
-    if prs.current().Type == lexer.TT_KW_Var {
+    // const <name> [type] <- <initializer>
+    if prs.current().Type == lexer.TT_KW_Var || prs.current().Type == lexer.TT_KW_Const {
         stmt = prs.parseDeclarationStatement()
     
     // return [val]
@@ -493,8 +855,12 @@ func (prs *Parser) parseStatement() syntaxnodes.StatementNode {
         stmt = prs.parseFromToStatement()
     
     // For(<decl>; <cond>; <action>) {...}
-    } else if prs.current().Type == lexer.TT_KW_For {
+    } else if prs.current().Type == lexer.TT_KW_For && prs.peek(1).Type == lexer.TT_OpenParenthesis {
         stmt = prs.parseForStatement()
+
+    // For [<index>,] <variable> in <collection> {...}
+    } else if prs.current().Type == lexer.TT_KW_For {
+        stmt = prs.parseForEachStatement()
     
     // Loop(<amount>) { ... }
     } else if prs.current().Type == lexer.TT_KW_Loop {
@@ -512,6 +878,18 @@ func (prs *Parser) parseStatement() syntaxnodes.StatementNode {
     } else if prs.current().Type == lexer.TT_KW_If {
         stmt = prs.parseIfStatement()
 
+    // try { ... } catch (<name> <type>) { ... }
+    } else if prs.current().Type == lexer.TT_KW_Try {
+        stmt = prs.parseTryStatement()
+
+    // throw <error>;
+    } else if prs.current().Type == lexer.TT_KW_Throw {
+        stmt = prs.parseThrowStatement()
+
+    // switch (<value>) { case <values>: ... default: ... }
+    } else if prs.current().Type == lexer.TT_KW_Switch {
+        stmt = prs.parseSwitchStatement()
+
     // { [statements] }
     } else if prs.current().Type == lexer.TT_OpenBraces {
         stmt = prs.parseBlockStatement()
@@ -526,6 +904,7 @@ func (prs *Parser) parseStatement() syntaxnodes.StatementNode {
        stmt.Type() == syntaxnodes.NT_DeclarationStmt || 
        stmt.Type() == syntaxnodes.NT_BreakStmt || 
        stmt.Type() == syntaxnodes.NT_ContinueStmt || 
+       stmt.Type() == syntaxnodes.NT_ThrowStmt || 
        stmt.Type() == syntaxnodes.NT_ExpressionStmt  {
         // require a semicolon
         prs.consume(lexer.TT_Semicolon)
@@ -535,8 +914,13 @@ func (prs *Parser) parseStatement() syntaxnodes.StatementNode {
 }
 
 func (prs *Parser) parseDeclarationStatement() *syntaxnodes.DeclarationStatementNode {
-    // consume 'var' keyword
-    kw := prs.consume(lexer.TT_KW_Var)
+    // consume 'var' or 'const' keyword
+    var kw lexer.Token
+    if prs.current().Type == lexer.TT_KW_Const {
+        kw = prs.consume(lexer.TT_KW_Const)
+    } else {
+        kw = prs.consume(lexer.TT_KW_Var)
+    }
 
     // consume variable name
     id := prs.consume(lexer.TT_Identifier)
@@ -650,6 +1034,33 @@ func (prs *Parser) parseForStatement() *syntaxnodes.ForStatementNode {
     return syntaxnodes.NewForStatementNode(kw, init, cond, action, body) 
 }
 
+func (prs *Parser) parseForEachStatement() *syntaxnodes.ForEachStatementNode {
+    // consume 'for' keyword
+    kw := prs.consume(lexer.TT_KW_For)
+
+    // the first name is either the element or the index
+    vari := prs.consume(lexer.TT_Identifier)
+
+    // if theres a comma -> that was the index, the element comes now
+    index := vari
+    hasIndex := false
+    if prs.current().Type == lexer.TT_Comma {
+        prs.consume(lexer.TT_Comma)
+        vari = prs.consume(lexer.TT_Identifier)
+        hasIndex = true
+    }
+
+    // consume 'in'
+    prs.consumeWord("in")
+
+    coll := prs.parseExpression()
+
+    // parse loop body
+    body := prs.parseStatement()
+
+    return syntaxnodes.NewForEachStatementNode(kw, hasIndex, index, vari, coll, body)
+}
+
 func (prs *Parser) parseLoopStatement() *syntaxnodes.LoopStatementNode {
     // consume 'loop' keyword
     kw := prs.consume(lexer.TT_KW_Loop)
@@ -682,6 +1093,42 @@ func (prs *Parser) parseContinueStatement() *syntaxnodes.ContinueStatementNode {
     return syntaxnodes.NewContinueStatementNode(kw)
 }
 
+func (prs *Parser) parseTryStatement() *syntaxnodes.TryStatementNode {
+    // consume 'try' keyword
+    kw := prs.consume(lexer.TT_KW_Try)
+
+    // parse the protected body
+    body := prs.parseStatement()
+
+    // consume 'catch' keyword
+    catchKw := prs.consume(lexer.TT_KW_Catch)
+
+    // consume '('
+    prs.consume(lexer.TT_OpenParenthesis)
+
+    // consume the error variable and its type
+    name := prs.consume(lexer.TT_Identifier)
+    typ := prs.parseTypeClause()
+
+    // consume ')'
+    prs.consume(lexer.TT_CloseParenthesis)
+
+    // parse the catch body
+    catchBody := prs.parseStatement()
+
+    return syntaxnodes.NewTryStatementNode(kw, body, catchKw, name, typ, catchBody)
+}
+
+func (prs *Parser) parseThrowStatement() *syntaxnodes.ThrowStatementNode {
+    // consume 'throw' keyword
+    kw := prs.consume(lexer.TT_KW_Throw)
+
+    // parse the error that is being thrown
+    expr := prs.parseExpression()
+
+    return syntaxnodes.NewThrowStatementNode(kw, expr)
+}
+
 func (prs *Parser) parseIfStatement() *syntaxnodes.IfStatementNode {
     // consume 'if' keyword
     kw := prs.consume(lexer.TT_KW_If)
@@ -713,14 +1160,58 @@ func (prs *Parser) parseIfStatement() *syntaxnodes.IfStatementNode {
     return syntaxnodes.NewIfStatementNode(kw, cond, body, elseBody, hasElse)
 }
 
+func (prs *Parser) parseSwitchStatement() *syntaxnodes.SwitchStatementNode {
+    // consume 'switch' keyword
+    kw := prs.consume(lexer.TT_KW_Switch)
+
+    // consume '('
+    prs.consume(lexer.TT_OpenParenthesis)
+
+    // parse the value we're switching on
+    expr := prs.parseExpression()
+
+    // consume ')'
+    prs.consume(lexer.TT_CloseParenthesis)
+
+    // consume '{'
+    prs.consume(lexer.TT_OpenBraces)
+
+    // parse all cases
+    cases := []*syntaxnodes.SwitchCaseClauseNode{}
+    for prs.current().Type != lexer.TT_CloseBraces &&
+        prs.current().Type != lexer.TT_EOF {
+
+        start := prs.Index
+        cases = append(cases, prs.parseSwitchCaseClause())
+
+        // make sure we always move forward, even if the case was garbage
+        if prs.Index == start {
+            prs.step(1)
+        }
+    }
+
+    // consume '}'
+    cls := prs.consume(lexer.TT_CloseBraces)
+
+    return syntaxnodes.NewSwitchStatementNode(kw, expr, cases, cls)
+}
+
 func (prs *Parser) parseBlockStatement() *syntaxnodes.BlockStatementNode {
     // consume '{'
     op := prs.consume(lexer.TT_OpenBraces)
 
     var stmts []syntaxnodes.StatementNode
-    for prs.current().Type != lexer.TT_CloseBraces {
+    for prs.current().Type != lexer.TT_CloseBraces &&
+        prs.current().Type != lexer.TT_EOF {
+
         // parse statements
+        start := prs.Index
         stmts = append(stmts, prs.parseStatement())
+
+        // make sure we always move forward, even if the statement was garbage
+        if prs.Index == start {
+            prs.step(1)
+        }
     }
 
     // consume '}'
@@ -754,34 +1245,71 @@ func (prs *Parser) parseBinaryExpression(lastPrecedence int) syntaxnodes.Express
         operand := prs.parseBinaryExpression(unaryPrecendence)
 
         // create new unary node
-        return syntaxnodes.NewUnaryExpressionNode(operand, op)
+        // (and keep going, there might be more operators after it)
+        left = syntaxnodes.NewUnaryExpressionNode(operand, op)
+
+    // is this a prefix increment? (++x / --x)
+    } else if prs.current().Type == lexer.TT_PlusPlus || prs.current().Type == lexer.TT_MinusMinus {
+        op := prs.consume(prs.current().Type)
+        operand := prs.parseBinaryExpression(syntaxnodes.GetUnaryOperatorPrecedence(lexer.TT_Minus))
+
+        left = syntaxnodes.NewIncrementExpressionNode(operand, op, false)
         
     // otherwise: parse left side
     } else {
         left = prs.parsePrimaryExpression()
 
         for prs.current().Type == lexer.TT_OpenBrackets ||
+            prs.current().Type == lexer.TT_OpenParenthesis ||
             prs.current().Type == lexer.TT_LeftArrow    ||
-            prs.current().Type == lexer.TT_RightArrow   {
+            prs.current().Type == lexer.TT_RightArrow   ||
+            prs.current().Type == lexer.TT_QuestionArrow ||
+            prs.current().Type == lexer.TT_PlusPlus     ||
+            prs.current().Type == lexer.TT_MinusMinus   ||
+            isCompoundAssignment(prs.current().Type)    {
 
             // Is this actually an array index?
             if prs.current().Type == lexer.TT_OpenBrackets {
                 left = prs.parseArrayIndexExpression(left)
             }
 
+            // Or a call of whatever we got so far? (fns[0](), f()(), ...)
+            if prs.current().Type == lexer.TT_OpenParenthesis {
+                left = prs.parseCallValueExpression(left)
+            }
+
             // Is this actually an assignment?
             if prs.current().Type == lexer.TT_LeftArrow {
                 left = prs.parseAssignmentExpression(left)
             }
 
-            // Is this actually an access?
-            if prs.current().Type == lexer.TT_RightArrow {
+            // Or a compound assignment? (+<-, -<-, ...)
+            if isCompoundAssignment(prs.current().Type) {
+                op := prs.consume(prs.current().Type)
+                left = syntaxnodes.NewCompoundAssignmentExpressionNode(left, op, prs.parseExpression())
+            }
+
+            // Or a postfix increment? (x++ / x--)
+            if prs.current().Type == lexer.TT_PlusPlus || prs.current().Type == lexer.TT_MinusMinus {
+                left = syntaxnodes.NewIncrementExpressionNode(left, prs.consume(prs.current().Type), true)
+            }
+
+            // Is this actually an access? (or a null safe one)
+            if prs.current().Type == lexer.TT_RightArrow || prs.current().Type == lexer.TT_QuestionArrow {
                 left = prs.parseAccessExpression(left)
             }
         }
     }
 
     for {
+        // is this a type check? (x is Foo / x as Foo)
+        typeCheckPrecedence := syntaxnodes.GetTypeCheckPrecedence(prs.current())
+        if typeCheckPrecedence != 0 && typeCheckPrecedence > lastPrecedence {
+            kw := prs.consume(lexer.TT_Identifier)
+            left = syntaxnodes.NewTypeCheckExpressionNode(left, kw, prs.parseTypeClause())
+            continue
+        }
+
         precedence := syntaxnodes.GetBinaryOperatorPrecedence(prs.current().Type)
 
         // if this isnt an operator or has less precedence
@@ -800,13 +1328,24 @@ func (prs *Parser) parseBinaryExpression(lastPrecedence int) syntaxnodes.Express
     return left
 }
 
+func isCompoundAssignment(tok lexer.TokenType) bool {
+    _, ok := syntaxnodes.GetCompoundOperator(tok)
+    return ok
+}
+
 func (prs *Parser) parsePrimaryExpression() syntaxnodes.ExpressionNode {
     // Literals
     if prs.current().Type == lexer.TT_String  || 
        prs.current().Type == lexer.TT_Integer ||
        prs.current().Type == lexer.TT_Float   ||
        prs.current().Type == lexer.TT_KW_True ||
-       prs.current().Type == lexer.TT_KW_False {
+       prs.current().Type == lexer.TT_KW_False ||
+       prs.current().Type == lexer.TT_KW_Null {
+
+        // strings with ${...} in them
+        if prs.current().Type == lexer.TT_String && prs.peek(1).Type == lexer.TT_InterpolationStart {
+            return prs.parseInterpolatedString()
+        }
 
         return prs.parseLiteralExpression()
     
@@ -830,15 +1369,51 @@ func (prs *Parser) parsePrimaryExpression() syntaxnodes.ExpressionNode {
     } else if prs.current().Type == lexer.TT_KW_Make {
         return prs.parseMakeExpression()
 
+    // Lambdas
+    } else if prs.current().Type == lexer.TT_KW_Function {
+        return prs.parseLambdaExpression()
+
     // Dude i have no idea
     } else {
-        error.Report(error.NewError(error.PRS, prs.current().Position, "Expected expression, got '%s'!", prs.current().Type))
+        prs.Comp.Report(error.NewError(error.PRS, prs.current().Position, "Expected expression, got '%s'!", prs.current().Type))
         prs.step(1)
 
         return syntaxnodes.NewErrorExpressionNode(prs.current().Position)
     }
 }
 
+func (prs *Parser) parseLambdaExpression() *syntaxnodes.LambdaExpressionNode {
+    // consume 'function'
+    kw := prs.consume(lexer.TT_KW_Function)
+
+    // parse parameters and return type
+    params, retType, hasReturnType := prs.parseSignature()
+
+    // the body is either a single expression
+    // function(x int) int: x * 2
+    var body syntaxnodes.StatementNode
+    if prs.current().Type == lexer.TT_Colon {
+        colon := prs.consume(lexer.TT_Colon)
+        expr := prs.parseExpression()
+
+        // if we return something -> return the expression
+        if hasReturnType {
+            body = syntaxnodes.NewReturnStatementNode(colon, expr, true)
+
+        // otherwise just evaluate it
+        } else {
+            body = syntaxnodes.NewExpressionStatementNode(expr)
+        }
+
+    // or a block
+    // function(x int) int { ... }
+    } else {
+        body = prs.parseBlockStatement()
+    }
+
+    return syntaxnodes.NewLambdaExpressionNode(kw, params, retType, hasReturnType, body)
+}
+
 func (prs *Parser) parseLiteralExpression() *syntaxnodes.LiteralExpressionNode {
     // consume the literal
     lit := prs.consume(prs.current().Type)
@@ -846,6 +1421,43 @@ func (prs *Parser) parseLiteralExpression() *syntaxnodes.LiteralExpressionNode {
     return syntaxnodes.NewLiteralExpressionNode(lit)
 }
 
+func (prs *Parser) parseInterpolatedString() syntaxnodes.ExpressionNode {
+    // "Hello ${name}!"
+    // ----------------
+    // "Hello " + string(name) + "!"
+    var str syntaxnodes.ExpressionNode = prs.parseLiteralExpression()
+
+    for prs.current().Type == lexer.TT_InterpolationStart {
+        // consume '${'
+        start := prs.consume(lexer.TT_InterpolationStart)
+
+        // parse the value
+        val := prs.parseExpression()
+
+        // consume '}'
+        end := prs.consume(lexer.TT_InterpolationEnd)
+
+        // turn it into a string (just like a normal cast would)
+        cast := syntaxnodes.NewCallExpressionNode(
+            lexer.Token{ Type: lexer.TT_Identifier, Buffer: "string", Position: start.Position },
+            lexer.Token{},
+            false,
+            []syntaxnodes.ExpressionNode{val},
+            end,
+        )
+
+        str = syntaxnodes.NewBinaryExpressionNode(str, cast, lexer.Token{ Type: lexer.TT_Plus, Position: start.Position })
+
+        // the lexer always follows up with the rest of the string
+        rest := prs.consume(lexer.TT_String)
+        if rest.Buffer != "" {
+            str = syntaxnodes.NewBinaryExpressionNode(str, syntaxnodes.NewLiteralExpressionNode(rest), lexer.Token{ Type: lexer.TT_Plus, Position: rest.Position })
+        }
+    }
+
+    return str
+}
+
 func (prs *Parser) parseAssignmentExpression(expr syntaxnodes.ExpressionNode) *syntaxnodes.AssignmentExpressionNode {
     // consume '<-'
     prs.consume(lexer.TT_LeftArrow)
@@ -905,6 +1517,30 @@ func (prs *Parser) parseCallExpression() syntaxnodes.ExpressionNode {
     return syntaxnodes.NewCallExpressionNode(id, pack, hasPackage, args, cprm)
 }
 
+func (prs *Parser) parseCallValueExpression(expr syntaxnodes.ExpressionNode) *syntaxnodes.CallValueExpressionNode {
+    // consume '('
+    prs.consume(lexer.TT_OpenParenthesis)
+
+    // arguments
+    args := make([]syntaxnodes.ExpressionNode, 0)
+    for prs.current().Type != lexer.TT_CloseParenthesis {
+        // parse arg
+        args = append(args, prs.parseExpression())
+
+        if prs.current().Type == lexer.TT_Comma {
+            prs.consume(lexer.TT_Comma)
+        } else {
+            break
+        }
+    }
+
+    // consume ')'
+    cprm := prs.consume(lexer.TT_CloseParenthesis)
+
+    // create new node
+    return syntaxnodes.NewCallValueExpressionNode(expr, args, cprm)
+}
+
 func (prs *Parser) parseNameExpression() syntaxnodes.ExpressionNode {
     var pack lexer.Token
     hasPackage := false
@@ -956,9 +1592,32 @@ func (prs *Parser) parseMakeExpression() syntaxnodes.ExpressionNode {
     id := prs.consume(lexer.TT_Identifier)
     closing := id
 
+    // function types cant be containers
+    // -> this is an array or a map of functions (make func[int -> int] array)
+    if !hasPack && id.Buffer == "func" && prs.current().Type == lexer.TT_OpenBrackets {
+        // skip over the entire function type
+        prs.rewind(kw)
+        prs.consume(lexer.TT_KW_Make)
+        prs.parseTypeClause()
+
+        isArray := prs.current().Buffer == "array" && prs.peek(1).Type != lexer.TT_OpenBrackets
+
+        // go back and do it properly
+        prs.rewind(kw)
+        if isArray {
+            return prs.parseMakeArrayExpression()
+        }
+
+        return prs.parseMakeMapExpression()
+    }
+
+    // generic containers need their type arguments
+    typargs := prs.parseTypeArguments()
+
     // is this next token an identifier?
     if prs.current().Type == lexer.TT_Identifier {
-        if prs.current().Buffer == "array" {
+        // (array[int] would be the value type of a map)
+        if prs.current().Buffer == "array" && prs.peek(1).Type != lexer.TT_OpenBrackets {
             // aw man we fucked up
             // this is actually an array creation
 
@@ -966,6 +1625,10 @@ func (prs *Parser) parseMakeExpression() syntaxnodes.ExpressionNode {
             prs.rewind(kw)
             return prs.parseMakeArrayExpression()
         }
+
+        // a second type -> this is a map creation (make <key> <value> map)
+        prs.rewind(kw)
+        return prs.parseMakeMapExpression()
     }
 
     // parse the initializer / constructor / nothing
@@ -1020,7 +1683,7 @@ func (prs *Parser) parseMakeExpression() syntaxnodes.ExpressionNode {
         hasInitializer = true
     } 
 
-    return syntaxnodes.NewMakeExpressionNode(kw, closing, id, pack, hasPack, initializer, hasInitializer, args, hasConstructor)
+    return syntaxnodes.NewMakeExpressionNode(kw, closing, id, pack, hasPack, typargs, initializer, hasInitializer, args, hasConstructor)
 }
 
 func (prs *Parser) parseMakeArrayExpression() *syntaxnodes.MakeArrayExpressionNode {
@@ -1078,6 +1741,54 @@ func (prs *Parser) parseMakeArrayExpression() *syntaxnodes.MakeArrayExpressionNo
     return syntaxnodes.NewMakeArrayExpressionNode(kw, closing, typ, length, initializer, hasInitializer)
 }
 
+func (prs *Parser) parseMakeMapExpression() *syntaxnodes.MakeMapExpressionNode {
+    // consume the make kw
+    kw := prs.consume(lexer.TT_KW_Make)
+
+    // consume key and value type
+    keytyp := prs.parseTypeClause()
+    valtyp := prs.parseTypeClause()
+
+    // consume 'map' word
+    prs.consumeWord("map")
+
+    // maps only come as literals
+    keys := []syntaxnodes.ExpressionNode{}
+    vals := []syntaxnodes.ExpressionNode{}
+
+    // consume {
+    prs.consume(lexer.TT_OpenBraces)
+
+    for prs.current().Type != lexer.TT_CloseBraces &&
+        prs.current().Type != lexer.TT_EOF {
+
+        // <key> <- <value> looks exactly like an assignment
+        // -> if the expression parser thought so too, just take it apart again
+        key := prs.parseExpression()
+
+        if key.Type() == syntaxnodes.NT_AssignmentExpr {
+            keys = append(keys, key.(*syntaxnodes.AssignmentExpressionNode).Expression)
+            vals = append(vals, key.(*syntaxnodes.AssignmentExpressionNode).Value)
+        } else {
+            prs.consume(lexer.TT_LeftArrow)
+            keys = append(keys, key)
+            vals = append(vals, prs.parseExpression())
+        }
+
+        // Require a comma after every entry
+        if prs.current().Type == lexer.TT_Comma {
+            prs.consume(lexer.TT_Comma)
+        } else {
+            break
+        }
+    }
+
+    // consume }
+    closing := prs.consume(lexer.TT_CloseBraces)
+
+    return syntaxnodes.NewMakeMapExpressionNode(kw, closing, keytyp, valtyp, keys, vals)
+}
+
 func (prs *Parser) parseArrayIndexExpression(expr syntaxnodes.ExpressionNode) *syntaxnodes.ArrayIndexExpressionNode {
     // consume [
     prs.consume(lexer.TT_OpenBrackets)
@@ -1092,8 +1803,9 @@ func (prs *Parser) parseArrayIndexExpression(expr syntaxnodes.ExpressionNode) *s
 }
 
 func (prs *Parser) parseAccessExpression(expr syntaxnodes.ExpressionNode) *syntaxnodes.AccessExpressionNode {
-    // consume ->
-    prs.consume(lexer.TT_RightArrow)
+    // consume -> (or ?->)
+    isNullSafe := prs.current().Type == lexer.TT_QuestionArrow
+    prs.consume(prs.current().Type)
 
     // consume the field / method name
     id := prs.consume(lexer.TT_Identifier)
@@ -1125,5 +1837,5 @@ func (prs *Parser) parseAccessExpression(expr syntaxnodes.ExpressionNode) *synta
     }
 
 
-    return syntaxnodes.NewAccessExpressionNode(expr, id, args, cls, isCall)
+    return syntaxnodes.NewAccessExpressionNode(expr, id, args, cls, isCall, isNullSafe)
 }
@@ -0,0 +1,364 @@
+// Printer - printer.go
+// --------------------------------------------------------
+// Prints tokens, syntax trees and bound trees in a way
+// that humans can actually read
+// --------------------------------------------------------
+package printer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"bytespace.network/rerect/boundnodes"
+	"bytespace.network/rerect/bytecode"
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+)
+
+// --------------------------------------------------------
+// Tokens
+// --------------------------------------------------------
+func PrintTokens(comp *compunit.Compilation, tokens []lexer.Token) {
+    for _, tok := range tokens {
+        line, col := tok.Position.GetLineAndCol(comp.SourceFiles[tok.Position.File].Content)
+
+        if tok.Buffer != "" {
+            fmt.Printf("[L:%d, C:%d]\t%-28s '%s'\n", line, col, tok.Type, tok.Buffer)
+        } else {
+            fmt.Printf("[L:%d, C:%d]\t%s\n", line, col, tok.Type)
+        }
+    }
+}
+
+// --------------------------------------------------------
+// Trees
+// --------------------------------------------------------
+
+// Print any syntax or bound node (and everything below it)
+// --------------------------------------------------------
+func PrintTree(node interface{}) {
+    val := reflect.ValueOf(node)
+
+    fmt.Println(describeNode(val))
+    printChildren(val, "")
+}
+
+// Print a bound function body with a little header
+// ------------------------------------------------
+func PrintFunction(fnc *symbols.FunctionSymbol, body boundnodes.BoundStatementNode) {
+    fmt.Println(FunctionSignature(fnc))
+    printValue("Body", reflect.ValueOf(body), "", true)
+    fmt.Println()
+}
+
+// Format a function symbol like it would be declared
+// --------------------------------------------------
+func FunctionSignature(fnc *symbols.FunctionSymbol) string {
+    prms := []string{}
+    for _, v := range fnc.Parameters {
+        prms = append(prms, fmt.Sprintf("%s %s", v.Name(), v.VarType().Name()))
+    }
+
+    name := fmt.Sprintf("%s::%s", fnc.ParentPackage.Name(), fnc.FuncName)
+    if fnc.FunctionKind == symbols.FT_METH {
+        name = fmt.Sprintf("%s::%s->%s", fnc.ParentPackage.Name(), fnc.MethodSource.Name(), fnc.FuncName)
+    }
+
+    if len(fnc.TypeParameters) > 0 {
+        typprms := []string{}
+        for _, v := range fnc.TypeParameters {
+            typprms = append(typprms, v.Name())
+        }
+
+        name += "[" + strings.Join(typprms, ", ") + "]"
+    }
+
+    return fmt.Sprintf("function %s(%s) %s", name, strings.Join(prms, ", "), fnc.ReturnType.Name())
+}
+
+// --------------------------------------------------------
+// Helpers
+// --------------------------------------------------------
+var (
+    syntaxNodeType = reflect.TypeOf((*syntaxnodes.SyntaxNode)(nil)).Elem()
+    boundNodeType  = reflect.TypeOf((*boundnodes.BoundNode)(nil)).Elem()
+    symbolType     = reflect.TypeOf((*symbols.Symbol)(nil)).Elem()
+    tokenType      = reflect.TypeOf(lexer.Token{})
+)
+
+func printValue(name string, val reflect.Value, prefix string, last bool) {
+    branch := "├─ "
+    if last {
+        branch = "└─ "
+    }
+
+    // leaves just get one line
+    if desc, ok := describeLeaf(val); ok {
+        fmt.Printf("%s%s%s: %s\n", prefix, branch, name, desc)
+        return
+    }
+
+    // nodes get their type as a title
+    title := name
+    if isNode(val) {
+        title = fmt.Sprintf("%s: %s", name, describeNode(val))
+    }
+
+    fmt.Printf("%s%s%s\n", prefix, branch, title)
+
+    // and then all of their children
+    if last {
+        printChildren(val, prefix + "   ")
+    } else {
+        printChildren(val, prefix + "│  ")
+    }
+}
+
+func printChildren(val reflect.Value, prefix string) {
+    names, values := children(val)
+
+    for i := range names {
+        printValue(names[i], values[i], prefix, i == len(names) - 1)
+    }
+}
+
+// Collect everything worth printing below a value
+// -----------------------------------------------
+func children(val reflect.Value) ([]string, []reflect.Value) {
+    names := []string{}
+    values := []reflect.Value{}
+
+    val = unwrap(val)
+
+    switch val.Kind() {
+    case reflect.Struct:
+        for i := 0; i < val.NumField(); i++ {
+            fld := val.Type().Field(i)
+
+            // skip embedded interfaces, unexported stuff and the references back to the syntax tree
+            if fld.Anonymous || !fld.IsExported() || fld.Name == "SourceNode" {
+                continue
+            }
+
+            if isEmpty(val.Field(i)) {
+                continue
+            }
+
+            names = append(names, fld.Name)
+            values = append(values, val.Field(i))
+        }
+
+    case reflect.Slice, reflect.Array:
+        for i := 0; i < val.Len(); i++ {
+            names = append(names, fmt.Sprintf("[%d]", i))
+            values = append(values, val.Index(i))
+        }
+
+    case reflect.Map:
+        iter := val.MapRange()
+        for iter.Next() {
+            key, _ := describeLeaf(iter.Key())
+            names = append(names, fmt.Sprintf("[%s]", key))
+            values = append(values, iter.Value())
+        }
+    }
+
+    return names, values
+}
+
+// Is this node a syntax or bound node?
+// ------------------------------------
+func isNode(val reflect.Value) bool {
+    val = unwrapInterface(val)
+    return val.IsValid() && (val.Type().Implements(syntaxNodeType) || val.Type().Implements(boundNodeType))
+}
+
+func describeNode(val reflect.Value) string {
+    val = unwrapInterface(val)
+
+    if val.Type().Implements(boundNodeType) {
+        node := val.Interface().(boundnodes.BoundNode)
+
+        // expressions also tell us their type
+        if expr, ok := node.(boundnodes.BoundExpressionNode); ok {
+            return fmt.Sprintf("%s (%s)", node.Type(), expr.ExprType().Name())
+        }
+
+        return string(node.Type())
+    }
+
+    return string(val.Interface().(syntaxnodes.SyntaxNode).Type())
+}
+
+// Can this value be printed in a single line?
+// -------------------------------------------
+func describeLeaf(val reflect.Value) (string, bool) {
+    val = unwrapInterface(val)
+
+    if !val.IsValid() {
+        return "null", true
+    }
+
+    // tokens
+    if val.Type() == tokenType {
+        tok := val.Interface().(lexer.Token)
+
+        if tok.Buffer == "" {
+            return string(tok.Type), true
+        }
+
+        return fmt.Sprintf("'%s' (%s)", tok.Buffer, tok.Type), true
+    }
+
+    // symbols
+    if val.Type().Implements(symbolType) {
+        sym := val.Interface().(symbols.Symbol)
+
+        if typ, ok := sym.(*symbols.TypeSymbol); ok {
+            return typ.Name(), true
+        }
+
+        if vari, ok := sym.(symbols.VariableSymbol); ok {
+            return fmt.Sprintf("'%s' %s (%s)", vari.Name(), vari.VarType().Name(), sym.Type()), true
+        }
+
+        if fnc, ok := sym.(*symbols.FunctionSymbol); ok {
+            return FunctionSignature(fnc), true
+        }
+
+        return fmt.Sprintf("'%s' (%s)", sym.Name(), sym.Type()), true
+    }
+
+    // nodes, lists and other structs have children
+    if isNode(val) {
+        return "", false
+    }
+
+    val = unwrap(val)
+
+    switch val.Kind() {
+    case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+        return "", false
+
+    case reflect.String:
+        return fmt.Sprintf("%s", val.Interface()), true
+    }
+
+    // whatever is left is a plain value
+    return fmt.Sprintf("%v (%s)", val.Interface(), val.Type()), true
+}
+
+// Things that arent worth printing
+// --------------------------------
+func isEmpty(val reflect.Value) bool {
+    switch val.Kind() {
+    case reflect.Interface, reflect.Pointer:
+        return val.IsNil()
+
+    case reflect.Slice, reflect.Map:
+        return val.Len() == 0
+
+    case reflect.Struct:
+        // tokens that were never set
+        if val.Type() == tokenType {
+            return val.Interface().(lexer.Token).Type == ""
+        }
+    }
+
+    return false
+}
+
+func unwrapInterface(val reflect.Value) reflect.Value {
+    for val.IsValid() && val.Kind() == reflect.Interface {
+        if val.IsNil() {
+            return reflect.Value{}
+        }
+
+        val = val.Elem()
+    }
+
+    return val
+}
+
+func unwrap(val reflect.Value) reflect.Value {
+    val = unwrapInterface(val)
+
+    for val.IsValid() && val.Kind() == reflect.Pointer {
+        if val.IsNil() {
+            return reflect.Value{}
+        }
+
+        val = val.Elem()
+    }
+
+    return val
+}
+
+// --------------------------------------------------------
+// Bytecode
+// --------------------------------------------------------
+
+// Print the instructions of every function in a program
+// -----------------------------------------------------
+func PrintBytecode(prg *bytecode.Program) {
+    for _, fnc := range prg.Functions {
+        // functions without code only exist to be redirected (virtual calls)
+        if fnc.Code == nil {
+            continue
+        }
+
+        fmt.Printf("%s (locals: %d)\n", FunctionSignature(fnc.Symbol), fnc.LocalCount)
+
+        for i, ins := range fnc.Code {
+            fmt.Println(strings.TrimRight(fmt.Sprintf("  %04d  %-20s %s", i, ins.Op, describeOperands(prg, ins)), " "))
+        }
+
+        for _, rgn := range fnc.Regions {
+            fmt.Printf("  protect [%04d, %04d) -> %04d (error in local %d)\n", rgn.Start, rgn.End, rgn.Catch, rgn.ErrorSlot)
+        }
+
+        fmt.Println()
+    }
+}
+
+// Make the operands of an instruction a bit more readable
+// -------------------------------------------------------
+func describeOperands(prg *bytecode.Program, ins bytecode.Instruction) string {
+    switch ins.Op {
+    case bytecode.OP_Const, bytecode.OP_LoadField, bytecode.OP_StoreField, bytecode.OP_InitField:
+        return fmt.Sprintf("%d (%#v)", ins.A, prg.Constants[ins.A])
+
+    case bytecode.OP_Default, bytecode.OP_MakeArray, bytecode.OP_Convert, bytecode.OP_EnumName, bytecode.OP_Is, bytecode.OP_As:
+        return fmt.Sprintf("%d (%s)", ins.A, prg.Types[ins.A].Name())
+
+    case bytecode.OP_MakeArrayFrom, bytecode.OP_MakeMap:
+        return fmt.Sprintf("%d (%s), %d", ins.A, prg.Types[ins.A].Name(), ins.B)
+
+    case bytecode.OP_Make:
+        return fmt.Sprintf("%d (%s)", ins.A, prg.Containers[ins.A].ContainerName)
+
+    case bytecode.OP_LoadGlobal, bytecode.OP_StoreGlobal:
+        return fmt.Sprintf("%d (%s)", ins.A, prg.Globals[ins.A].Name())
+
+    case bytecode.OP_Call, bytecode.OP_CallMethod, bytecode.OP_MakeFunction:
+        return fmt.Sprintf("%d (%s), %d", ins.A, prg.Functions[ins.A].Symbol.FuncName, ins.B)
+
+    case bytecode.OP_CallNative, bytecode.OP_CallNativeMethod:
+        return fmt.Sprintf("%d (%s), %d", ins.A, prg.Natives[ins.A].FuncName, ins.B)
+
+    case bytecode.OP_MakeNativeFunction:
+        return fmt.Sprintf("%d (%s)", ins.A, prg.Natives[ins.A].FuncName)
+
+    case bytecode.OP_CallValue:
+        return fmt.Sprintf("%d", ins.B)
+
+    case bytecode.OP_LoadLocal, bytecode.OP_StoreLocal, bytecode.OP_ApproachLocal, bytecode.OP_Jump, bytecode.OP_JumpIf,
+         bytecode.OP_MakeCell, bytecode.OP_LoadCell, bytecode.OP_StoreCell, bytecode.OP_ApproachCell:
+        return fmt.Sprintf("%d", ins.A)
+    }
+
+    return ""
+}
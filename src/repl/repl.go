@@ -0,0 +1,377 @@
+// REPL - repl.go
+// --------------------------------------------------------
+// Interactive mode: type some code, get some results
+// --------------------------------------------------------
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"bytespace.network/rerect/binder"
+	"bytespace.network/rerect/bytecode"
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/error"
+	evalobjects "bytespace.network/rerect/eval_objects"
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/lowerer"
+	packageprocessor "bytespace.network/rerect/package_processor"
+	"bytespace.network/rerect/parser"
+	"bytespace.network/rerect/symbols"
+	"bytespace.network/rerect/syntaxnodes"
+	"bytespace.network/rerect/vm"
+)
+
+// REPL struct
+// -----------
+type Repl struct {
+    Comp *compunit.Compilation
+    Program *bytecode.Program
+    VM *vm.VM
+    Globals []*symbols.GlobalSymbol
+
+    Input *bufio.Reader
+    EntryCount int
+}
+
+// Package snapshot (so failed inputs dont leave half registered symbols behind)
+// -----------------------------------------------------------------------------
+type snapshot struct {
+    functions, globals, containers, traits, enums, names, included int
+    loaded map[string]*symbols.PackageSymbol
+}
+
+// --------------------------------------------------------
+// Running
+// --------------------------------------------------------
+func Run() {
+    // one compilation for the whole session
+    comp := compunit.NewCompilation()
+
+    // load all native packages
+    packageprocessor.Init(comp)
+
+    prg := bytecode.NewProgram()
+
+    rpl := Repl{
+        Comp: comp,
+        Program: prg,
+        VM: vm.NewVM(comp, prg),
+        Globals: make([]*symbols.GlobalSymbol, 0),
+        Input: bufio.NewReader(os.Stdin),
+    }
+
+    fmt.Println("ReRect REPL - type ':quit' or press Ctrl+D to leave")
+
+    for {
+        src, ok := rpl.read()
+
+        // EOF -> we're done here
+        if !ok {
+            fmt.Println()
+            return
+        }
+
+        // commands
+        trimmed := strings.TrimSpace(src)
+        if trimmed == "" {
+            continue
+        }
+
+        if trimmed == ":quit" || trimmed == ":q" {
+            return
+        }
+
+        rpl.execute(trimmed)
+    }
+}
+
+// Read one (possibly multi line) input
+// ------------------------------------
+func (rpl *Repl) read() (string, bool) {
+    src := ""
+
+    fmt.Print("> ")
+
+    for {
+        line, err := rpl.Input.ReadString('\n')
+        src += line
+        depth := bracketDepth(src)
+
+        // nothing more to read
+        if err == io.EOF {
+            return src, src != ""
+        }
+
+        // still inside of some braces -> keep reading
+        if depth > 0 {
+            fmt.Print(". ")
+            continue
+        }
+
+        return src, true
+    }
+}
+
+// Execute one input
+// -----------------
+func (rpl *Repl) execute(src string) {
+    // be nice and add a missing semicolon
+    // (scripts dont mind extra ones)
+    if !strings.HasSuffix(src, ";") {
+        src += ";"
+    }
+
+    // forget about errors from older inputs
+    rpl.Comp.ClearErrors()
+
+    // Lexing
+    // ------
+    rpl.EntryCount++
+    idx := rpl.Comp.RegisterSource(fmt.Sprintf("<repl:%d>", rpl.EntryCount), src)
+    tokens := lexer.LexString(rpl.Comp, src, idx)
+
+    if rpl.failed() {
+        return
+    }
+
+    // Parsing
+    // -------
+    members, stmts := parser.ParseScript(rpl.Comp, tokens)
+
+    // package declarations dont really make sense here
+    for _, mem := range members {
+        if mem.Type() == syntaxnodes.NT_Package {
+            rpl.Comp.Report(error.NewError(error.PCK, mem.Position(), "Package declarations are not allowed in the REPL!"))
+        }
+    }
+
+    if rpl.failed() {
+        return
+    }
+
+    // Package processing
+    // ------------------
+    pck := rpl.Comp.GetPackageAtAllCosts("main")
+    snap := takeSnapshot(pck)
+
+    file := packageprocessor.Process(rpl.Comp, [][]syntaxnodes.MemberNode{members})[0]
+
+    // everything declared so far is visible to this input
+    file.Globals = append(file.Globals, rpl.Globals...)
+
+    if rpl.failedAndRestore(pck, snap) {
+        return
+    }
+
+    // Binding
+    // -------
+    binder.IndexEnums(rpl.Comp, file)
+    if rpl.failedAndRestore(pck, snap) {
+        return
+    }
+
+    binder.IndexTraitTypes(rpl.Comp, file)
+    if rpl.failedAndRestore(pck, snap) {
+        return
+    }
+
+    binder.IndexContainerTypes(rpl.Comp, file)
+    if rpl.failedAndRestore(pck, snap) {
+        return
+    }
+
+    binder.IndexTraitContents(rpl.Comp, file)
+    if rpl.failedAndRestore(pck, snap) {
+        return
+    }
+
+    binder.IndexContainerContents(rpl.Comp, file)
+    if rpl.failedAndRestore(pck, snap) {
+        return
+    }
+
+    binder.IndexFunctions(rpl.Comp, file)
+    if rpl.failedAndRestore(pck, snap) {
+        return
+    }
+
+    binder.BindFunctions(rpl.Comp, file)
+    if rpl.failedAndRestore(pck, snap) {
+        return
+    }
+
+    // the loose statements get wrapped into a little function
+    script := symbols.NewFunctionSymbol(file.Package, fmt.Sprintf("<repl:%d>", rpl.EntryCount), compunit.GlobalDataType("any"), []*symbols.ParameterSymbol{})
+    binder.BindScript(rpl.Comp, file, script, stmts)
+
+    if rpl.failedAndRestore(pck, snap) {
+        return
+    }
+
+    // Lowering
+    // --------
+    lowerer.Lower(rpl.Comp, file)
+
+    if rpl.failedAndRestore(pck, snap) {
+        return
+    }
+
+    // Bytecode
+    // --------
+    bytecode.Compile(rpl.Comp, rpl.Program, []*packageprocessor.CompilationFile{file})
+
+    if rpl.failedAndRestore(pck, snap) {
+        return
+    }
+
+    // Evaluation
+    // ----------
+    rpl.Globals = file.Globals
+    rpl.VM.Load()
+
+    res := rpl.VM.Run(script)
+
+    // runtime errors only end this input, not the session
+    if res.Error != nil {
+        rpl.Comp.OutputError(res.Error.Error)
+        return
+    }
+
+    // same goes for die()
+    if res.ExitCode != 0 {
+        fmt.Printf("(exited with code %d)\n", res.ExitCode)
+        return
+    }
+
+    // if the input had a value -> show it
+    if res.Value != nil {
+        fmt.Println(format(res.Value))
+    }
+}
+
+// --------------------------------------------------------
+// Helpers
+// --------------------------------------------------------
+func (rpl *Repl) failed() bool {
+    if rpl.Comp.HasErrors() {
+        rpl.Comp.OutputErrors()
+        return true
+    }
+
+    return false
+}
+
+func (rpl *Repl) failedAndRestore(pck *symbols.PackageSymbol, snap snapshot) bool {
+    if !rpl.failed() {
+        return false
+    }
+
+    // throw away everything this input registered
+    pck.Functions = pck.Functions[:snap.functions]
+    pck.Globals = pck.Globals[:snap.globals]
+    pck.Containers = pck.Containers[:snap.containers]
+    pck.Traits = pck.Traits[:snap.traits]
+    pck.Enums = pck.Enums[:snap.enums]
+    pck.SymbolNames = pck.SymbolNames[:snap.names]
+    pck.IncludedPackages = pck.IncludedPackages[:snap.included]
+    pck.LoadedPackages = snap.loaded
+
+    return true
+}
+
+func takeSnapshot(pck *symbols.PackageSymbol) snapshot {
+    loaded := make(map[string]*symbols.PackageSymbol)
+    for k, v := range pck.LoadedPackages {
+        loaded[k] = v
+    }
+
+    return snapshot{
+        functions: len(pck.Functions),
+        globals: len(pck.Globals),
+        containers: len(pck.Containers),
+        traits: len(pck.Traits),
+        enums: len(pck.Enums),
+        names: len(pck.SymbolNames),
+        included: len(pck.IncludedPackages),
+        loaded: loaded,
+    }
+}
+
+// Count how many brackets are still open in an input
+// ----------------------------------------------------
+// (this goes through the lexer, so brackets in strings and
+//  comments dont count, and anything left unterminated at
+//  the end counts as one more open bracket)
+func bracketDepth(src string) int {
+    comp := compunit.NewCompilation()
+    tokens := lexer.LexString(comp, src, comp.RegisterSource("<repl>", src))
+
+    depth := 0
+    for _, tok := range tokens {
+        if tok.Type == lexer.TT_OpenBraces || tok.Type == lexer.TT_OpenParenthesis || tok.Type == lexer.TT_OpenBrackets {
+            depth++
+        } else if tok.Type == lexer.TT_CloseBraces || tok.Type == lexer.TT_CloseParenthesis || tok.Type == lexer.TT_CloseBrackets {
+            depth--
+        }
+    }
+
+    // a string or block comment that runs into the end of the input
+    // -> the rest of it is probably still coming
+    end := len([]rune(src))
+    for _, err := range comp.Errors {
+        if err.Position.ToIdx >= end {
+            depth++
+            break
+        }
+    }
+
+    return depth
+}
+
+// Turn a runtime value into something readable
+// --------------------------------------------
+func format(val interface{}) string {
+    switch v := val.(type) {
+    case string:
+        return fmt.Sprintf("\"%s\"", v)
+
+    case *evalobjects.ArrayInstance:
+        elements := []string{}
+        for _, e := range v.Elements {
+            elements = append(elements, format(e))
+        }
+
+        return fmt.Sprintf("{%s}", strings.Join(elements, ", "))
+
+    case *evalobjects.MapInstance:
+        entries := []string{}
+        for _, k := range v.Keys {
+            entries = append(entries, fmt.Sprintf("%s <- %s", format(k), format(v.Elements[k])))
+        }
+
+        return fmt.Sprintf("{%s}", strings.Join(entries, ", "))
+
+    case *evalobjects.ContainerInstance:
+        fields := []string{}
+        for _, f := range v.Type.Container.Fields {
+            fields = append(fields, fmt.Sprintf("%s <- %s", f.FieldName, format(v.Fields[f.FieldName])))
+        }
+
+        return fmt.Sprintf("%s {%s}", v.Type.Name(), strings.Join(fields, ", "))
+
+    case nil:
+        return "null"
+    }
+
+    // everything else can just be converted
+    str, ok := evalobjects.EvalConversion(val, compunit.GlobalDataType("string"))
+    if !ok {
+        return fmt.Sprintf("%v", val)
+    }
+
+    return str.(string)
+}
@@ -0,0 +1,73 @@
+package repl
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestBracketDepth(t *testing.T) {
+    cases := []struct {
+        src   string
+        depth int
+    }{
+        {"var x <- 1;", 0},
+        {"function f() {", 1},
+        {"function f() {\n    if (x) {\n", 2},
+        {"function f() {\n}", 0},
+
+        // brackets in strings dont count (not even after escaped quotes)
+        {`var s <- "a\"{";`, 0},
+        {`var s <- "\\";`, 0},
+        {`var s <- "(${x}[";`, 0},
+        {"var s <- `a\\`;", 0},
+        {"var s <- `{\"`;", 0},
+
+        // neither do brackets in comments
+        {"var x <- 1; // {", 0},
+        {"var x <- /* { */ 1;", 0},
+        {"var x <- /* /* { */ ( */ 1;", 0},
+
+        // unterminated raw strings and block comments want more lines
+        {"var s <- `first line", 1},
+        {"var s <- `first line\nsecond line`;", 0},
+        {"/* still talking", 1},
+        {"/* still talking\ndone */ var x <- 1;", 0},
+    }
+
+    for _, c := range cases {
+        if got := bracketDepth(c.src); got != c.depth {
+            t.Errorf("bracketDepth(%q) = %d, expected %d", c.src, got, c.depth)
+        }
+    }
+}
+
+// every line should come out as its own input
+func TestReadDoesNotGetStuck(t *testing.T) {
+    input := strings.Join([]string{
+        `var s <- "a\"{";`,
+        "var r <- `}\"`;",
+        "var c <- 1; /* ( */",
+        "function f() {",
+        "    return;",
+        "}",
+        "Print(s);",
+    }, "\n") + "\n"
+
+    rpl := Repl{Input: bufio.NewReader(strings.NewReader(input))}
+
+    expected := []string{
+        "var s <- \"a\\\"{\";\n",
+        "var r <- `}\"`;\n",
+        "var c <- 1; /* ( */\n",
+        "function f() {\n    return;\n}\n",
+        "Print(s);\n",
+    }
+
+    for _, exp := range expected {
+        src, ok := rpl.read()
+        if !ok || src != exp {
+            t.Fatalf("expected input %q, got %q", exp, src)
+        }
+    }
+}
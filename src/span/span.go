@@ -6,8 +6,6 @@ package span
 
 import (
 	"fmt"
-
-	"bytespace.network/rerect/compunit"
 )
 
 type Span struct {
@@ -65,15 +63,16 @@ func (s1 Span) SpanBetween(s2 Span) Span {
 
 // Convert index values into line and column numbers
 // -------------------------------------------------
-func (s Span) GetLineAndCol() (line int, col int) {
-    // get the contents of the file the span is in
-    content := []rune(compunit.SourceFileRegister[s.File].Content)
+// (content is the source of the file the span is in)
+func (s Span) GetLineAndCol(source string) (line int, col int) {
+    content := []rune(source)
 
     line = 1
     col = 1
 
     // count newlines up to the span
-    for i := 0; i < s.FromIdx; i++ {
+    // (or the end of the source, if the span is somewhere past it)
+    for i := 0; i < s.FromIdx && i < len(content); i++ {
         if content[i] == '\n' {
             col = 0
             line++
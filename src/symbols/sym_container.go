@@ -7,15 +7,19 @@ type ContainerSymbol struct {
 
     ParentPackage *PackageSymbol
     Traits []*TraitSymbol
+    TraitTypes []*TypeSymbol        // the traits like they were applied (with type arguments filled in)
 
     ContainerName string
     ContainerType *TypeSymbol
+    TypeParameters []*TypeSymbol
 
     Constructor *FunctionSymbol
 
     Symbols []string
     Fields []*FieldSymbol
     Methods []*FunctionSymbol
+
+    Doc string // documentation from /// comments
 }
 
 func NewContainerSymbol(pck *PackageSymbol, name string, typ *TypeSymbol) *ContainerSymbol {
@@ -32,6 +36,7 @@ func NewContainerSymbol(pck *PackageSymbol, name string, typ *TypeSymbol) *Conta
 
         // same here
         Traits: make([]*TraitSymbol, 0),
+        TraitTypes: make([]*TypeSymbol, 0),
 
         // same here
         Methods: make([]*FunctionSymbol, 0),
@@ -40,6 +45,9 @@ func NewContainerSymbol(pck *PackageSymbol, name string, typ *TypeSymbol) *Conta
     // link the given type symbol to this container
     typ.Container = cnt
 
+    // a generic container uses its type parameters as its own subtypes (Box[T])
+    cnt.TypeParameters = typ.SubTypes
+
     // ok we don
     return cnt
 }
@@ -55,3 +63,10 @@ func (sym *ContainerSymbol) Type() SymbolType {
 func (sym *ContainerSymbol) VarType() *TypeSymbol {
     return sym.ContainerType
 }
+
+// The type of a trait this container implements, as seen by an instance of the given type
+// (container Box[T] (Holder[T]) -> a Box[int] is a Holder[int])
+// ---------------------------------------------------------------------------------------
+func (sym *ContainerSymbol) TraitTypeFor(idx int, inst *TypeSymbol) *TypeSymbol {
+    return Substitute(sym.TraitTypes[idx], sym.TypeParameters, inst.SubTypes)
+}
@@ -0,0 +1,81 @@
+package symbols
+
+// Enum symbol
+// -----------
+type EnumSymbol struct {
+    Symbol
+
+    ParentPackage *PackageSymbol
+
+    EnumName string
+    EnumType *TypeSymbol
+
+    Members []*EnumMember // (in the order they were declared)
+
+    Doc string // documentation from /// comments
+}
+
+// A single named value of an enum
+type EnumMember struct {
+    MemberName string
+    Value int32
+
+    Doc string
+}
+
+func NewEnumSymbol(pck *PackageSymbol, name string, typ *TypeSymbol) *EnumSymbol {
+    enm := &EnumSymbol{
+        ParentPackage: pck,
+        EnumName: name,
+        EnumType: typ,
+
+        // Members will be filled in later
+        Members: make([]*EnumMember, 0),
+    }
+
+    // link the given type symbol to this enum
+    typ.Enum = enm
+
+    return enm
+}
+
+func (sym *EnumSymbol) Name() string {
+    return sym.EnumName
+}
+
+func (sym *EnumSymbol) Type() SymbolType {
+    return ST_Enum
+}
+
+func (sym *EnumSymbol) VarType() *TypeSymbol {
+    return sym.EnumType
+}
+
+// Add a member (the first member is also the default value of the enum)
+func (sym *EnumSymbol) AddMember(name string, value int32, doc string) {
+    sym.Members = append(sym.Members, &EnumMember{name, value, doc})
+
+    if len(sym.Members) == 1 {
+        sym.EnumType.Default = value
+    }
+}
+
+func (sym *EnumSymbol) LookupMember(name string) *EnumMember {
+    for _, v := range sym.Members {
+        if v.MemberName == name {
+            return v
+        }
+    }
+
+    return nil
+}
+
+func (sym *EnumSymbol) LookupValue(value int32) *EnumMember {
+    for _, v := range sym.Members {
+        if v.Value == value {
+            return v
+        }
+    }
+
+    return nil
+}
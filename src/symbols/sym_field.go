@@ -13,6 +13,10 @@ type FieldSymbol struct {
 
     FieldName string
     FieldType *TypeSymbol
+
+    IsReadonly bool // can only be set in the Constructor
+
+    Doc string // documentation from /// comments
 }
 
 func NewFieldSymbol(cnt *ContainerSymbol, name string, typ *TypeSymbol) *FieldSymbol {
@@ -23,11 +23,20 @@ type FunctionSymbol struct {
 	FuncName   string
 	ReturnType *TypeSymbol
 
+	// type parameters of generic functions (inferred from the arguments when called)
+	TypeParameters []*TypeSymbol
+
 	IsVMFunction    bool
 	FunctionPointer VMFPtr
 	MethodPointer   VMMPtr
 
 	Parameters []*ParameterSymbol
+
+	// lambdas get the variables they use from their surroundings passed in before their parameters
+	IsLambda bool
+	Captures []VariableSymbol
+
+	Doc string // documentation from /// comments
 }
 
 type VMFPtr func([]interface{}) interface{}
@@ -9,6 +9,11 @@ type GlobalSymbol struct {
 
     GlobalName string
     GlobalType *TypeSymbol
+
+    IsConst bool
+    Value interface{} // value of a constant, known at compile time (nil until its been folded)
+
+    Doc string // documentation from /// comments
 }
 
 func NewGlobalSymbol(pck *PackageSymbol, name string, typ *TypeSymbol) *GlobalSymbol {
@@ -7,6 +7,9 @@ type LocalSymbol struct {
 
     LocalName string
     LocalType *TypeSymbol
+
+    Captured bool // used by a lambda -> lives in a cell instead of a plain slot
+    IsConst bool  // declared using 'const' -> cant be assigned to after its declaration
 }
 
 func NewLocalSymbol(name string, typ *TypeSymbol) *LocalSymbol {
@@ -12,11 +12,14 @@ type PackageSymbol struct {
     Globals []*GlobalSymbol
     Containers []*ContainerSymbol
     Traits []*TraitSymbol
+    Enums []*EnumSymbol
 
     SymbolNames []string
 
     LoadedPackages map[string]*PackageSymbol
     IncludedPackages []string
+
+    LambdaCount int // for naming lambdas (__lambda1, __lambda2, ...)
 }
 
 func NewPackageSymbol(name string, funcs []*FunctionSymbol) *PackageSymbol {
@@ -48,6 +51,17 @@ func (sym *PackageSymbol) TryRegisterTrait(trt *TraitSymbol) bool {
     return true
 }
 
+func (sym *PackageSymbol) TryRegisterEnum(enm *EnumSymbol) bool {
+    // check if a symbol with this name already exists
+    if slices.Contains(sym.SymbolNames, enm.Name()) {
+        return false
+    }
+
+    sym.Enums = append(sym.Enums, enm)
+    sym.SymbolNames = append(sym.SymbolNames, enm.Name())
+    return true
+}
+
 func (sym *PackageSymbol) TryRegisterContainer(cnt *ContainerSymbol) bool {
     // check if a symbol with this name already exists
     if slices.Contains(sym.SymbolNames, cnt.Name()) {
@@ -8,6 +8,8 @@ type ParameterSymbol struct {
     ParameterName string
     ParameterIdx  int
     ParameterType *TypeSymbol
+
+    Captured bool // used by a lambda -> lives in a cell instead of a plain slot
 }
 
 func NewParameterSymbol(name string, idx int, typ *TypeSymbol) *ParameterSymbol {
@@ -9,10 +9,13 @@ type TraitSymbol struct {
 
     TraitName string
     TraitType *TypeSymbol
+    TypeParameters []*TypeSymbol
 
     Symbols []string
     Fields []*FieldSymbol
     Methods []*FunctionSymbol
+
+    Doc string // documentation from /// comments
 }
 
 func NewTraitSymbol(pck *PackageSymbol, name string, typ *TypeSymbol) *TraitSymbol {
@@ -34,6 +37,9 @@ func NewTraitSymbol(pck *PackageSymbol, name string, typ *TypeSymbol) *TraitSymb
     // link the given type symbol to this container
     typ.Trait = cnt
 
+    // a generic trait uses its type parameters as its own subtypes (Holder[T])
+    cnt.TypeParameters = typ.SubTypes
+
     // ok we don
     return cnt
 }
@@ -1,5 +1,7 @@
 package symbols
 
+import "strings"
+
 // Type symbol struct
 // ------------------
 type TypeSymbol struct {
@@ -13,6 +15,9 @@ type TypeSymbol struct {
 
     Container *ContainerSymbol
     Trait *TraitSymbol
+    Enum *EnumSymbol
+
+    Nullable bool // Foo? (only containers and traits can be null)
 
     Default interface{}
 }
@@ -28,7 +33,23 @@ func NewTypeSymbol(name string, subtypes []*TypeSymbol, grp TypeGroupType, sz in
 }
 
 func  (typ *TypeSymbol) Name() string {
-    return typ.TypeName
+    name := typ.TypeName
+
+    // generic containers and traits show their type arguments (Box[int])
+    if (typ.TypeGroup == CONT || typ.TypeGroup == TRT) && len(typ.SubTypes) > 0 {
+        args := []string{}
+        for _, v := range typ.SubTypes {
+            args = append(args, v.Name())
+        }
+
+        name += "[" + strings.Join(args, ", ") + "]"
+    }
+
+    if typ.Nullable {
+        name += "?"
+    }
+
+    return name
 }
 
 func (typ *TypeSymbol) Type() SymbolType {
@@ -36,6 +57,12 @@ func (typ *TypeSymbol) Type() SymbolType {
 }
 
 func (t1 *TypeSymbol) Equal(t2 *TypeSymbol) bool {
+    // Type parameters are only ever equal to themselves
+    // (the T of one container has nothing to do with the T of another)
+    if t1.TypeGroup == TPRM || t2.TypeGroup == TPRM {
+        return t1 == t2
+    }
+
     // Names dont match? -> they're DEFINITELY not the same lol
     if t1.Name() != t2.Name() {
         return false
@@ -63,6 +90,184 @@ func (t1 *TypeSymbol) Equal(t2 *TypeSymbol) bool {
     return true
 } 
 
+// Same as Equal, but type parameters match anything
+// (values dont remember what their type parameters were at runtime)
+func (t1 *TypeSymbol) Matches(t2 *TypeSymbol) bool {
+    if t1.TypeGroup == TPRM || t2.TypeGroup == TPRM {
+        return true
+    }
+
+    if t1.TypeGroup != t2.TypeGroup || len(t1.SubTypes) != len(t2.SubTypes) {
+        return false
+    }
+
+    // arrays, maps and function types are named after their subtypes, so only compare those
+    if t1.TypeGroup != ARR && t1.TypeGroup != MAP && t1.TypeGroup != FUNC && t1.TypeName != t2.TypeName {
+        return false
+    }
+
+    for i := range t1.SubTypes {
+        if !t1.SubTypes[i].Matches(t2.SubTypes[i]) {
+            return false
+        }
+    }
+
+    return true
+}
+
+// --------------------------------------------------------
+// Generics
+// --------------------------------------------------------
+
+// Does this type mention any type parameters?
+func (typ *TypeSymbol) IsGeneric() bool {
+    if typ.TypeGroup == TPRM {
+        return true
+    }
+
+    for _, v := range typ.SubTypes {
+        if v.IsGeneric() {
+            return true
+        }
+    }
+
+    return false
+}
+
+// Create the type of a generic container or trait for some type arguments
+// (Box[T] + [int] -> Box[int])
+// ------------------------------------------------------------------------
+func NewInstanceType(base *TypeSymbol, args []*TypeSymbol) *TypeSymbol {
+    return &TypeSymbol{
+        TypeName: base.TypeName,
+        SubTypes: args,
+        TypeGroup: base.TypeGroup,
+        TypeSize: base.TypeSize,
+        Container: base.Container,
+        Trait: base.Trait,
+        Nullable: base.Nullable,
+        Default: base.Default,
+    }
+}
+
+// Replace type parameters in a type with the given arguments
+// (T Array with T -> int becomes int Array)
+// -----------------------------------------------------------
+func Substitute(typ *TypeSymbol, prms []*TypeSymbol, args []*TypeSymbol) *TypeSymbol {
+    // nothing to replace -> nothing to do
+    if !typ.IsGeneric() || len(prms) != len(args) {
+        return typ
+    }
+
+    if typ.TypeGroup == TPRM {
+        for i, v := range prms {
+            if v == typ {
+                return args[i]
+            }
+        }
+
+        // not one of ours
+        return typ
+    }
+
+    subtypes := []*TypeSymbol{}
+    for _, v := range typ.SubTypes {
+        subtypes = append(subtypes, Substitute(v, prms, args))
+    }
+
+    // arrays and maps are named after their subtypes
+    if typ.TypeGroup == ARR {
+        return NewTypeSymbol(subtypes[0].Name() + " Array", subtypes, ARR, typ.TypeSize, typ.Default)
+    }
+
+    if typ.TypeGroup == MAP {
+        return NewTypeSymbol(subtypes[0].Name() + " " + subtypes[1].Name() + " Map", subtypes, MAP, typ.TypeSize, typ.Default)
+    }
+
+    if typ.TypeGroup == FUNC {
+        return NewFunctionType(subtypes[:len(subtypes)-1], subtypes[len(subtypes)-1])
+    }
+
+    return NewInstanceType(typ, subtypes)
+}
+
+// --------------------------------------------------------
+// Function types
+// --------------------------------------------------------
+
+// func[int, int -> bool]
+// (the return type is always the last subtype)
+func NewFunctionType(params []*TypeSymbol, ret *TypeSymbol) *TypeSymbol {
+    args := []string{}
+    for _, v := range params {
+        args = append(args, v.Name())
+    }
+
+    name := "func[" + strings.Join(args, ", ")
+    if ret.TypeName != "void" {
+        if len(args) > 0 {
+            name += " "
+        }
+
+        name += "-> " + ret.Name()
+    }
+    name += "]"
+
+    subtypes := append(append([]*TypeSymbol{}, params...), ret)
+    return NewTypeSymbol(name, subtypes, FUNC, 0, nil)
+}
+
+// The type a function has when used as a value
+func FunctionTypeOf(fnc *FunctionSymbol) *TypeSymbol {
+    params := []*TypeSymbol{}
+    for _, v := range fnc.Parameters {
+        params = append(params, v.ParameterType)
+    }
+
+    return NewFunctionType(params, fnc.ReturnType)
+}
+
+// Parameter types of a function type
+func (typ *TypeSymbol) FunctionParameters() []*TypeSymbol {
+    return typ.SubTypes[:len(typ.SubTypes)-1]
+}
+
+// Return type of a function type
+func (typ *TypeSymbol) FunctionReturnType() *TypeSymbol {
+    return typ.SubTypes[len(typ.SubTypes)-1]
+}
+
+// --------------------------------------------------------
+// Nullability
+// --------------------------------------------------------
+
+// Only references to containers and traits can be null
+func (typ *TypeSymbol) CanBeNullable() bool {
+    return typ.TypeGroup == CONT || typ.TypeGroup == TRT
+}
+
+// Foo -> Foo?
+func NewNullableType(typ *TypeSymbol) *TypeSymbol {
+    if typ.Nullable {
+        return typ
+    }
+
+    res := *typ
+    res.Nullable = true
+    return &res
+}
+
+// Foo? -> Foo
+func (typ *TypeSymbol) NonNullable() *TypeSymbol {
+    if !typ.Nullable {
+        return typ
+    }
+
+    res := *typ
+    res.Nullable = false
+    return &res
+}
+
 // Types of data types
 // -------------------
 type TypeGroupType string;
@@ -71,6 +276,10 @@ const (
     INT   TypeGroupType = "Integer type"
     FLOAT TypeGroupType = "Floating point type"
     ARR   TypeGroupType = "Array type"
+    MAP   TypeGroupType = "Map type"
     CONT  TypeGroupType = "Container type"
     TRT   TypeGroupType = "Trait type"
+    ENUM  TypeGroupType = "Enum type"
+    TPRM  TypeGroupType = "Type parameter"
+    FUNC  TypeGroupType = "Function type"
 )
@@ -6,3 +6,15 @@ type VariableSymbol interface {
     Symbol
     VarType() *TypeSymbol
 } 
+
+// Is this a local or parameter some lambda holds on to?
+func IsCaptured(vari VariableSymbol) bool {
+    switch v := vari.(type) {
+    case *LocalSymbol:
+        return v.Captured
+    case *ParameterSymbol:
+        return v.Captured
+    }
+
+    return false
+}
@@ -22,4 +22,5 @@ const (
     ST_Field     SymbolType = "Field symbol"
     ST_Instance  SymbolType = "Instance symbol"
     ST_Trait     SymbolType = "Trait symbol"
+    ST_Enum      SymbolType = "Enum symbol"
 )
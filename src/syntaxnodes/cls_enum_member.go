@@ -0,0 +1,43 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+type EnumMemberClauseNode struct {
+    SyntaxNode
+
+    MemberName lexer.Token
+
+    // optional explicit value (Red <- -1)
+    HasValue bool
+    Minus lexer.Token
+    IsNegative bool
+    Value lexer.Token
+
+    Doc string // (from /// comments)
+}
+
+func NewEnumMemberClauseNode(name lexer.Token, hasval bool, minus lexer.Token, isneg bool, val lexer.Token, doc string) *EnumMemberClauseNode {
+    return &EnumMemberClauseNode{
+        MemberName: name,
+        HasValue: hasval,
+        Minus: minus,
+        IsNegative: isneg,
+        Value: val,
+        Doc: doc,
+    }
+}
+
+func (n *EnumMemberClauseNode) Position() span.Span {
+    if n.HasValue {
+        return n.MemberName.Position.SpanBetween(n.Value.Position)
+    }
+
+    return n.MemberName.Position
+}
+
+func (n *EnumMemberClauseNode) Type() SyntaxNodeType {
+    return NT_EnumMemberCls
+}
@@ -8,18 +8,30 @@ import (
 type FieldClauseNode struct {
     SyntaxNode
 
+    ReadonlyKw lexer.Token
+    IsReadonly bool // (can only be set in the Constructor)
+
     FieldName lexer.Token
     FieldType *TypeClauseNode
+
+    Doc string // (from /// comments)
 }
 
-func NewFieldClauseNode(prmname lexer.Token, typ *TypeClauseNode) *FieldClauseNode {
+func NewFieldClauseNode(rokw lexer.Token, readonly bool, prmname lexer.Token, typ *TypeClauseNode, doc string) *FieldClauseNode {
     return &FieldClauseNode{
+        ReadonlyKw: rokw,
+        IsReadonly: readonly,
         FieldName: prmname,
         FieldType: typ,
+        Doc: doc,
     }
 }
 
 func (n *FieldClauseNode) Position() span.Span {
+    if n.IsReadonly {
+        return n.ReadonlyKw.Position.SpanBetween(n.FieldType.Position())
+    }
+
     return n.FieldName.Position.SpanBetween(n.FieldType.Position())
 }
 
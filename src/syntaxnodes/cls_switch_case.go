@@ -0,0 +1,51 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+type SwitchCaseClauseNode struct {
+    SyntaxNode
+
+    CaseKw lexer.Token // (or 'default')
+    IsDefault bool
+
+    // case 1, 2:
+    Values []ExpressionNode
+
+    // case c SomeContainer:
+    IsPattern bool
+    PatternName lexer.Token
+    PatternType *TypeClauseNode
+
+    Colon lexer.Token
+    Statements []StatementNode
+}
+
+func NewSwitchCaseClauseNode(kw lexer.Token, isdefault bool, values []ExpressionNode, ispattern bool, name lexer.Token, typ *TypeClauseNode, colon lexer.Token, stmts []StatementNode) *SwitchCaseClauseNode {
+    return &SwitchCaseClauseNode{
+        CaseKw: kw,
+        IsDefault: isdefault,
+        Values: values,
+        IsPattern: ispattern,
+        PatternName: name,
+        PatternType: typ,
+        Colon: colon,
+        Statements: stmts,
+    }
+}
+
+func (n *SwitchCaseClauseNode) Position() span.Span {
+    spn := n.CaseKw.Position.SpanBetween(n.Colon.Position)
+
+    if len(n.Statements) > 0 {
+        spn = spn.SpanBetween(n.Statements[len(n.Statements)-1].Position())
+    }
+
+    return spn
+}
+
+func (n *SwitchCaseClauseNode) Type() SyntaxNodeType {
+    return NT_SwitchCaseCls
+}
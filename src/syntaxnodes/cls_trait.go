@@ -12,22 +12,29 @@ type TraitClauseNode struct {
     HasPackage bool
 
     TraitName lexer.Token
+    SubTypes []*TypeClauseNode
 }
 
-func NewTraitClauseNode(pck lexer.Token, haspack bool, name lexer.Token) *TraitClauseNode {
+func NewTraitClauseNode(pck lexer.Token, haspack bool, name lexer.Token, subtypes []*TypeClauseNode) *TraitClauseNode {
     return &TraitClauseNode{
         Package: pck,
         HasPackage: haspack,
         TraitName: name,
+        SubTypes: subtypes,
     }
 }
 
 func (n *TraitClauseNode) Position() span.Span {
+    spn := n.TraitName.Position
     if n.HasPackage {
-        return n.Package.Position.SpanBetween(n.TraitName.Position)
-    } else {
-        return n.TraitName.Position
+        spn = n.Package.Position.SpanBetween(spn)
     }
+
+    for _, v := range n.SubTypes {
+        spn = spn.SpanBetween(v.Position())
+    }
+
+    return spn
 }
 
 func (n *TraitClauseNode) Type() SyntaxNodeType {
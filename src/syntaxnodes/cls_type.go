@@ -13,24 +13,53 @@ type TypeClauseNode struct {
 
     TypeName lexer.Token
     SubTypes []*TypeClauseNode
+
+    NullableMarker lexer.Token
+    IsNullable bool
+
+    // func[int, int -> bool]
+    // (the parameter types are the subtypes, no return type means void)
+    IsFunctionType bool
+    ReturnType *TypeClauseNode
+    ClosingTok lexer.Token
 }
 
-func NewTypeClauseNode(packname lexer.Token, haspack bool, typname lexer.Token, subtypes []*TypeClauseNode) *TypeClauseNode {
+func NewTypeClauseNode(packname lexer.Token, haspack bool, typname lexer.Token, subtypes []*TypeClauseNode, marker lexer.Token, nullable bool) *TypeClauseNode {
     return &TypeClauseNode{
         PackageName: packname,
         HasPackageName: haspack,
         TypeName: typname,
         SubTypes: subtypes,
+        NullableMarker: marker,
+        IsNullable: nullable,
+    }
+}
+
+func NewFunctionTypeClauseNode(typname lexer.Token, params []*TypeClauseNode, ret *TypeClauseNode, cls lexer.Token) *TypeClauseNode {
+    return &TypeClauseNode{
+        TypeName: typname,
+        SubTypes: params,
+        IsFunctionType: true,
+        ReturnType: ret,
+        ClosingTok: cls,
     }
 }
 
 func (n *TypeClauseNode) Position() span.Span {
     spn := n.TypeName.Position
 
+    if n.IsFunctionType {
+        return spn.SpanBetween(n.ClosingTok.Position)
+    }
+
     for _, v := range n.SubTypes {
         spn = spn.SpanBetween(v.Position())
     }
 
+    if n.IsNullable {
+        spn = spn.SpanBetween(n.NullableMarker.Position)
+    }
+
     return spn
 }
 
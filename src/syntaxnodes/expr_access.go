@@ -14,15 +14,18 @@ type AccessExpressionNode struct {
     Arguments []ExpressionNode
     Closing lexer.Token
     IsCall bool
+
+    IsNullSafe bool // ?-> instead of ->
 }
 
-func NewAccessExpressionNode(expr ExpressionNode, id lexer.Token, args []ExpressionNode, cls lexer.Token, iscall bool) *AccessExpressionNode {
+func NewAccessExpressionNode(expr ExpressionNode, id lexer.Token, args []ExpressionNode, cls lexer.Token, iscall bool, nullsafe bool) *AccessExpressionNode {
     return &AccessExpressionNode{
         Expression: expr,
         Identifier: id,
         Arguments: args,
         Closing: cls,
         IsCall: iscall,
+        IsNullSafe: nullsafe,
     }
 }
 
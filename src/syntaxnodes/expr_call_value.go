@@ -0,0 +1,31 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+// Calling whatever some expression hands out (fns[0](), f()(), ...)
+type CallValueExpressionNode struct {
+    ExpressionNode
+
+    Expression ExpressionNode
+    Parameters []ExpressionNode
+    CloseParam lexer.Token
+}
+
+func NewCallValueExpressionNode(expr ExpressionNode, param []ExpressionNode, cprm lexer.Token) *CallValueExpressionNode {
+    return &CallValueExpressionNode{
+        Expression: expr,
+        Parameters: param,
+        CloseParam: cprm,
+    }
+}
+
+func (n *CallValueExpressionNode) Position() span.Span {
+    return n.Expression.Position().SpanBetween(n.CloseParam.Position)
+}
+
+func (n *CallValueExpressionNode) Type() SyntaxNodeType {
+    return NT_CallValueExpr
+}
@@ -0,0 +1,30 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+type CompoundAssignmentExpressionNode struct {
+    ExpressionNode
+
+    Expression ExpressionNode
+    Operator lexer.Token
+    Value ExpressionNode
+}
+
+func NewCompoundAssignmentExpressionNode(expr ExpressionNode, op lexer.Token, val ExpressionNode) *CompoundAssignmentExpressionNode {
+    return &CompoundAssignmentExpressionNode{
+        Expression: expr,
+        Operator: op,
+        Value: val,
+    }
+}
+
+func (n *CompoundAssignmentExpressionNode) Position() span.Span {
+    return n.Expression.Position().SpanBetween(n.Value.Position())
+}
+
+func (n *CompoundAssignmentExpressionNode) Type() SyntaxNodeType {
+    return NT_CompoundExpr
+}
@@ -0,0 +1,32 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+// ++x, x++, --x and x--
+// ---------------------
+type IncrementExpressionNode struct {
+    ExpressionNode
+
+    Expression ExpressionNode
+    Operator lexer.Token
+    IsPostfix bool
+}
+
+func NewIncrementExpressionNode(expr ExpressionNode, op lexer.Token, postfix bool) *IncrementExpressionNode {
+    return &IncrementExpressionNode{
+        Expression: expr,
+        Operator: op,
+        IsPostfix: postfix,
+    }
+}
+
+func (n *IncrementExpressionNode) Position() span.Span {
+    return n.Expression.Position().SpanBetween(n.Operator.Position)
+}
+
+func (n *IncrementExpressionNode) Type() SyntaxNodeType {
+    return NT_IncrementExpr
+}
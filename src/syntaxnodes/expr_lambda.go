@@ -0,0 +1,37 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+type LambdaExpressionNode struct {
+    ExpressionNode
+
+    FunctionKw lexer.Token
+
+    Parameters []*ParameterClauseNode
+
+    ReturnType *TypeClauseNode
+    HasReturnType bool
+
+    Body StatementNode
+}
+
+func NewLambdaExpressionNode(fnckw lexer.Token, prm []*ParameterClauseNode, rettype *TypeClauseNode, hasrettype bool, body StatementNode) *LambdaExpressionNode {
+    return &LambdaExpressionNode{
+        FunctionKw: fnckw,
+        Parameters: prm,
+        ReturnType: rettype,
+        HasReturnType: hasrettype,
+        Body: body,
+    }
+}
+
+func (n *LambdaExpressionNode) Position() span.Span {
+    return n.FunctionKw.Position.SpanBetween(n.Body.Position())
+}
+
+func (n *LambdaExpressionNode) Type() SyntaxNodeType {
+    return NT_LambdaExpr
+}
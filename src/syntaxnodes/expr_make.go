@@ -14,6 +14,7 @@ type MakeExpressionNode struct {
     Package lexer.Token
     Container lexer.Token
     HasPackage bool
+    TypeArguments []*TypeClauseNode
 
     Initializer []*FieldAssignmentClauseNode
     HasInitializer bool
@@ -22,13 +23,14 @@ type MakeExpressionNode struct {
     HasConstructor bool
 }
 
-func NewMakeExpressionNode(kw lexer.Token, cls lexer.Token, cnt lexer.Token, pck lexer.Token, haspck bool, init []*FieldAssignmentClauseNode, hasinit bool, args []ExpressionNode, hascst bool) *MakeExpressionNode {
+func NewMakeExpressionNode(kw lexer.Token, cls lexer.Token, cnt lexer.Token, pck lexer.Token, haspck bool, typargs []*TypeClauseNode, init []*FieldAssignmentClauseNode, hasinit bool, args []ExpressionNode, hascst bool) *MakeExpressionNode {
     return &MakeExpressionNode{
         MakeKw: kw,
         ClosingTok: cls,
         Package: pck,
         Container: cnt,
         HasPackage: haspck,
+        TypeArguments: typargs,
         Initializer: init,
         HasInitializer: hasinit,
         ConstructorArguments: args,
@@ -0,0 +1,38 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+type MakeMapExpressionNode struct {
+    ExpressionNode
+
+    MakeKw lexer.Token
+    ClosingTok lexer.Token
+
+    KeyType *TypeClauseNode
+    ValueType *TypeClauseNode
+
+    Keys []ExpressionNode
+    Values []ExpressionNode
+}
+
+func NewMakeMapExpressionNode(makekw lexer.Token, cls lexer.Token, keytyp *TypeClauseNode, valtyp *TypeClauseNode, keys []ExpressionNode, vals []ExpressionNode) *MakeMapExpressionNode {
+    return &MakeMapExpressionNode{
+        MakeKw: makekw,
+        ClosingTok: cls,
+        KeyType: keytyp,
+        ValueType: valtyp,
+        Keys: keys,
+        Values: vals,
+    }
+}
+
+func (n *MakeMapExpressionNode) Position() span.Span {
+    return n.MakeKw.Position.SpanBetween(n.ClosingTok.Position)
+}
+
+func (n *MakeMapExpressionNode) Type() SyntaxNodeType {
+    return NT_MakeMapExpr
+}
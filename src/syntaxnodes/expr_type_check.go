@@ -0,0 +1,36 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+// x is Foo / x as Foo
+type TypeCheckExpressionNode struct {
+    ExpressionNode
+
+    Value ExpressionNode
+    Keyword lexer.Token
+    TestType *TypeClauseNode
+}
+
+func NewTypeCheckExpressionNode(val ExpressionNode, kw lexer.Token, typ *TypeClauseNode) *TypeCheckExpressionNode {
+    return &TypeCheckExpressionNode{
+        Value: val,
+        Keyword: kw,
+        TestType: typ,
+    }
+}
+
+// is this an 'as'? (otherwise its an 'is')
+func (n *TypeCheckExpressionNode) IsCast() bool {
+    return n.Keyword.Buffer == "as"
+}
+
+func (n *TypeCheckExpressionNode) Position() span.Span {
+    return n.Value.Position().SpanBetween(n.TestType.Position())
+}
+
+func (n *TypeCheckExpressionNode) Type() SyntaxNodeType {
+    return NT_TypeCheckExpr
+}
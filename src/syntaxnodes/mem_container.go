@@ -10,6 +10,7 @@ type ContainerNode struct {
 
     ContainerKw lexer.Token
     ContainerName lexer.Token
+    TypeParameters []lexer.Token
 
     Traits []*TraitClauseNode
 
@@ -17,16 +18,20 @@ type ContainerNode struct {
     Methods []*FunctionNode
 
     Closing lexer.Token
+
+    Doc string // (from /// comments)
 }
 
-func NewContainerNode(kw lexer.Token, name lexer.Token, fields []*FieldClauseNode, meth []*FunctionNode, traits []*TraitClauseNode, cls lexer.Token) *ContainerNode {
+func NewContainerNode(kw lexer.Token, name lexer.Token, typprms []lexer.Token, fields []*FieldClauseNode, meth []*FunctionNode, traits []*TraitClauseNode, cls lexer.Token, doc string) *ContainerNode {
     return &ContainerNode{
         ContainerKw: kw,
         ContainerName: name,
+        TypeParameters: typprms,
         Fields: fields,
         Methods: meth,
         Traits: traits,
         Closing: cls,
+        Doc: doc,
     }
 }
 
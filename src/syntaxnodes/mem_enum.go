@@ -0,0 +1,37 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+type EnumNode struct {
+    MemberNode
+
+    EnumKw lexer.Token
+    EnumName lexer.Token
+
+    Members []*EnumMemberClauseNode
+
+    Closing lexer.Token
+
+    Doc string // (from /// comments)
+}
+
+func NewEnumNode(kw lexer.Token, name lexer.Token, members []*EnumMemberClauseNode, cls lexer.Token, doc string) *EnumNode {
+    return &EnumNode{
+        EnumKw: kw,
+        EnumName: name,
+        Members: members,
+        Closing: cls,
+        Doc: doc,
+    }
+}
+
+func (n *EnumNode) Position() span.Span {
+    return n.EnumKw.Position.SpanBetween(n.Closing.Position)
+}
+
+func (n *EnumNode) Type() SyntaxNodeType {
+    return NT_Enum
+}
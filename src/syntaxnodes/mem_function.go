@@ -11,6 +11,7 @@ type FunctionNode struct {
     FunctionKw lexer.Token
     FunctionName lexer.Token
     IsConstructor bool
+    TypeParameters []lexer.Token
 
     Parameters []*ParameterClauseNode 
 
@@ -20,19 +21,23 @@ type FunctionNode struct {
     Body StatementNode
     HasBody bool
     Closing lexer.Token
+
+    Doc string // (from /// comments)
 }
 
-func NewFunctionNode(fnckw lexer.Token, fncname lexer.Token, iscst bool, prm []*ParameterClauseNode, rettype *TypeClauseNode, hasrettype bool, body StatementNode, hasbody bool, closing lexer.Token) *FunctionNode {
+func NewFunctionNode(fnckw lexer.Token, fncname lexer.Token, iscst bool, typprms []lexer.Token, prm []*ParameterClauseNode, rettype *TypeClauseNode, hasrettype bool, body StatementNode, hasbody bool, closing lexer.Token, doc string) *FunctionNode {
     return &FunctionNode{
         FunctionKw: fnckw,
         FunctionName: fncname,
         IsConstructor: iscst,
+        TypeParameters: typprms,
         Parameters: prm,
         ReturnType: rettype,
         HasReturnType: hasrettype,
         Body: body,
         HasBody: hasbody,
         Closing: closing,
+        Doc: doc,
     }
 }
 
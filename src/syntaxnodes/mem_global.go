@@ -10,19 +10,45 @@ type GlobalNode struct {
 
     VarKw lexer.Token
     GlobalName lexer.Token
+
     VarType *TypeClauseNode
+    HasExplicitType bool // (only constants can leave it out)
+
+    Initializer ExpressionNode
+    HasInitializer bool  // (only constants have one)
+
+    Doc string // (from /// comments)
 }
 
-func NewGlobalNode(varkw lexer.Token, glbname lexer.Token, typ *TypeClauseNode) *GlobalNode {
+func NewGlobalNode(varkw lexer.Token, glbname lexer.Token, typ *TypeClauseNode, hastyp bool, init ExpressionNode, hasinit bool, doc string) *GlobalNode {
     return &GlobalNode{
         VarKw: varkw,
         GlobalName: glbname,
         VarType: typ,
+        HasExplicitType: hastyp,
+        Initializer: init,
+        HasInitializer: hasinit,
+        Doc: doc,
     }
 }
 
+// was this declared using 'const'? (otherwise its a 'var')
+func (n *GlobalNode) IsConst() bool {
+    return n.VarKw.Type == lexer.TT_KW_Const
+}
+
 func (n *GlobalNode) Position() span.Span {
-    return n.VarKw.Position.SpanBetween(n.VarType.Position())
+    spn := n.VarKw.Position.SpanBetween(n.GlobalName.Position)
+
+    if n.HasExplicitType {
+        spn = spn.SpanBetween(n.VarType.Position())
+    }
+
+    if n.HasInitializer {
+        spn = spn.SpanBetween(n.Initializer.Position())
+    }
+
+    return spn
 }
 
 func (n *GlobalNode) Type() SyntaxNodeType {
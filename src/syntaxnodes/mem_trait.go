@@ -10,20 +10,25 @@ type TraitNode struct {
 
     TraitKw lexer.Token
     TraitName lexer.Token
+    TypeParameters []lexer.Token
 
     Fields []*FieldClauseNode
     Methods []*FunctionNode
 
     Closing lexer.Token
+
+    Doc string // (from /// comments)
 }
 
-func NewTraitNode(kw lexer.Token, name lexer.Token, fields []*FieldClauseNode, meth []*FunctionNode, cls lexer.Token) *TraitNode {
+func NewTraitNode(kw lexer.Token, name lexer.Token, typprms []lexer.Token, fields []*FieldClauseNode, meth []*FunctionNode, cls lexer.Token, doc string) *TraitNode {
     return &TraitNode{
         TraitKw: kw,
         TraitName: name,
+        TypeParameters: typprms,
         Fields: fields,
         Methods: meth,
         Closing: cls,
+        Doc: doc,
     }
 }
 
@@ -7,11 +7,17 @@ import "bytespace.network/rerect/lexer"
 func GetBinaryOperatorPrecedence(tok lexer.TokenType) int {
     switch tok {
         case lexer.TT_Star,
-             lexer.TT_Slash:
+             lexer.TT_Slash,
+             lexer.TT_Percent,
+             lexer.TT_ShiftLeft,
+             lexer.TT_ShiftRight,
+             lexer.TT_Ampersand:
             return 5
 
         case lexer.TT_Plus,
-             lexer.TT_Minus:
+             lexer.TT_Minus,
+             lexer.TT_Pipe,
+             lexer.TT_Caret:
             return 4
 
         case lexer.TT_Equal,
@@ -33,16 +39,59 @@ func GetBinaryOperatorPrecedence(tok lexer.TokenType) int {
     }
 }
 
+// Type check precedence
+// ---------------------
+// (is and as arent keywords, just words sitting where an operator would be)
+func GetTypeCheckPrecedence(tok lexer.Token) int {
+    if tok.Type == lexer.TT_Identifier && (tok.Buffer == "is" || tok.Buffer == "as") {
+        return 3 // same as comparisons
+    }
+
+    return 0
+}
+
 // Unary operator precendence 
 // ---------------------------
 func GetUnaryOperatorPrecedence(tok lexer.TokenType) int {
     switch tok {
         case lexer.TT_Plus,
              lexer.TT_Minus,
-             lexer.TT_Bang:
+             lexer.TT_Bang,
+             lexer.TT_Tilde:
             return 6 // must always be higher than the highest binary op precedence
 
         default:
             return 0
     }
 }
+
+// Binary operator behind a compound assignment
+// (+<- is just + with extra steps)
+// --------------------------------------------
+func GetCompoundOperator(tok lexer.TokenType) (lexer.TokenType, bool) {
+    switch tok {
+        case lexer.TT_PlusAssign:
+            return lexer.TT_Plus, true
+        case lexer.TT_MinusAssign:
+            return lexer.TT_Minus, true
+        case lexer.TT_StarAssign:
+            return lexer.TT_Star, true
+        case lexer.TT_SlashAssign:
+            return lexer.TT_Slash, true
+        case lexer.TT_PercentAssign:
+            return lexer.TT_Percent, true
+        case lexer.TT_AmpersandAssign:
+            return lexer.TT_Ampersand, true
+        case lexer.TT_PipeAssign:
+            return lexer.TT_Pipe, true
+        case lexer.TT_CaretAssign:
+            return lexer.TT_Caret, true
+        case lexer.TT_ShiftLeftAssign:
+            return lexer.TT_ShiftLeft, true
+        case lexer.TT_ShiftRightAssign:
+            return lexer.TT_ShiftRight, true
+
+        default:
+            return "", false
+    }
+}
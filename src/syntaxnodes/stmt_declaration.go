@@ -29,6 +29,11 @@ func NewDeclarationStatementNode(varkw lexer.Token, varname lexer.Token, typ *Ty
     }
 }
 
+// was this declared using 'const'? (otherwise its a 'var')
+func (n *DeclarationStatementNode) IsConst() bool {
+    return n.VarKw.Type == lexer.TT_KW_Const
+}
+
 func (n *DeclarationStatementNode) Position() span.Span {
     spn := n.VarKw.Position
 
@@ -0,0 +1,36 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+type ForEachStatementNode struct {
+    StatementNode
+
+    ForKw lexer.Token
+    HasIndex bool
+    Index lexer.Token
+    Variable lexer.Token
+    Collection ExpressionNode
+    Body StatementNode
+}
+
+func NewForEachStatementNode(forkw lexer.Token, hasIndex bool, index lexer.Token, vari lexer.Token, coll ExpressionNode, body StatementNode) *ForEachStatementNode {
+    return &ForEachStatementNode{
+        ForKw: forkw,
+        HasIndex: hasIndex,
+        Index: index,
+        Variable: vari,
+        Collection: coll,
+        Body: body,
+    }
+}
+
+func (n *ForEachStatementNode) Position() span.Span {
+    return n.ForKw.Position.SpanBetween(n.Body.Position())
+}
+
+func (n *ForEachStatementNode) Type() SyntaxNodeType {
+    return NT_ForEachStmt
+}
@@ -0,0 +1,32 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+type SwitchStatementNode struct {
+    StatementNode
+
+    SwitchKw lexer.Token
+    Expression ExpressionNode
+    Cases []*SwitchCaseClauseNode // (default is in here too)
+    Closing lexer.Token
+}
+
+func NewSwitchStatementNode(switchkw lexer.Token, expr ExpressionNode, cases []*SwitchCaseClauseNode, cls lexer.Token) *SwitchStatementNode {
+    return &SwitchStatementNode{
+        SwitchKw: switchkw,
+        Expression: expr,
+        Cases: cases,
+        Closing: cls,
+    }
+}
+
+func (n *SwitchStatementNode) Position() span.Span {
+    return n.SwitchKw.Position.SpanBetween(n.Closing.Position)
+}
+
+func (n *SwitchStatementNode) Type() SyntaxNodeType {
+    return NT_SwitchStmt
+}
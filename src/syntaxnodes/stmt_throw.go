@@ -0,0 +1,28 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+type ThrowStatementNode struct {
+    StatementNode
+
+    ThrowKw lexer.Token
+    Expression ExpressionNode
+}
+
+func NewThrowStatementNode(throwkw lexer.Token, expr ExpressionNode) *ThrowStatementNode {
+    return &ThrowStatementNode{
+        ThrowKw: throwkw,
+        Expression: expr,
+    }
+}
+
+func (n *ThrowStatementNode) Position() span.Span {
+    return n.ThrowKw.Position.SpanBetween(n.Expression.Position())
+}
+
+func (n *ThrowStatementNode) Type() SyntaxNodeType {
+    return NT_ThrowStmt
+}
@@ -0,0 +1,37 @@
+package syntaxnodes
+
+import (
+	"bytespace.network/rerect/lexer"
+	"bytespace.network/rerect/span"
+)
+
+type TryStatementNode struct {
+    StatementNode
+
+    TryKw lexer.Token
+    Body StatementNode
+
+    CatchKw lexer.Token
+    ErrorName lexer.Token
+    ErrorType *TypeClauseNode
+    CatchBody StatementNode
+}
+
+func NewTryStatementNode(trykw lexer.Token, body StatementNode, catchkw lexer.Token, errname lexer.Token, errtype *TypeClauseNode, catchbody StatementNode) *TryStatementNode {
+    return &TryStatementNode{
+        TryKw: trykw,
+        Body: body,
+        CatchKw: catchkw,
+        ErrorName: errname,
+        ErrorType: errtype,
+        CatchBody: catchbody,
+    }
+}
+
+func (n *TryStatementNode) Position() span.Span {
+    return n.TryKw.Position.SpanBetween(n.CatchBody.Position())
+}
+
+func (n *TryStatementNode) Type() SyntaxNodeType {
+    return NT_TryStmt
+}
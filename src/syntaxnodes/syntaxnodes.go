@@ -38,6 +38,7 @@ const (
     NT_Global             SyntaxNodeType = "Global variable member"
     NT_Container          SyntaxNodeType = "Container member"
     NT_Trait              SyntaxNodeType = "Trait member"
+    NT_Enum               SyntaxNodeType = "Enum member"
 
     // Statements
     NT_DeclarationStmt    SyntaxNodeType = "Local variable statement"
@@ -45,25 +46,35 @@ const (
     NT_WhileStmt          SyntaxNodeType = "While statement"
     NT_FromToStmt         SyntaxNodeType = "From-To statement node"
     NT_ForStmt            SyntaxNodeType = "For statement node"
+    NT_ForEachStmt        SyntaxNodeType = "For-each statement node"
     NT_LoopStmt           SyntaxNodeType = "Loop statement node"
     NT_BreakStmt          SyntaxNodeType = "Break statement node"
     NT_ContinueStmt       SyntaxNodeType = "Continue statement node"
     NT_BlockStmt          SyntaxNodeType = "Block statement node"
     NT_ExpressionStmt     SyntaxNodeType = "Expression statement node"
     NT_IfStmt             SyntaxNodeType = "If statement node"
+    NT_TryStmt            SyntaxNodeType = "Try statement node"
+    NT_ThrowStmt          SyntaxNodeType = "Throw statement node"
+    NT_SwitchStmt         SyntaxNodeType = "Switch statement node"
 
     // Expressions
     NT_LiteralExpr        SyntaxNodeType = "Literal expression node"
     NT_AssignmentExpr     SyntaxNodeType = "Assignment expression node"
+    NT_CompoundExpr       SyntaxNodeType = "Compound assignment expression node"
+    NT_IncrementExpr      SyntaxNodeType = "Increment expression node"
     NT_UnaryExpr          SyntaxNodeType = "Unary expression node"
     NT_BinaryExpr         SyntaxNodeType = "Binary expression node"
     NT_CallExpr           SyntaxNodeType = "Call expression node"
+    NT_CallValueExpr      SyntaxNodeType = "Call value expression node"
     NT_NameExpr           SyntaxNodeType = "Name expression node"
     NT_ParenthesizedExpr  SyntaxNodeType = "Parenthesized expression node"
     NT_MakeArrayExpr      SyntaxNodeType = "Array creation expression node"
+    NT_MakeMapExpr        SyntaxNodeType = "Map creation expression node"
     NT_ArrayIndexExpr     SyntaxNodeType = "Array index expression node"
     NT_AccessExpr         SyntaxNodeType = "Access expression node"
     NT_MakeExpr           SyntaxNodeType = "Object creation expression node"
+    NT_LambdaExpr         SyntaxNodeType = "Lambda expression node"
+    NT_TypeCheckExpr      SyntaxNodeType = "Type check expression node"
 
     NT_ErrorExpr          SyntaxNodeType = "Error expression node"
 
@@ -73,4 +84,6 @@ const (
     NT_FieldCls           SyntaxNodeType = "Field clause"
     NT_FieldAssignmentCls SyntaxNodeType = "Field assignment clause"
     NT_TraitCls           SyntaxNodeType = "Trait clause"
+    NT_EnumMemberCls      SyntaxNodeType = "Enum member clause"
+    NT_SwitchCaseCls      SyntaxNodeType = "Switch case clause"
 )
@@ -0,0 +1,280 @@
+// VM - arithmetic.go
+// --------------------------------------------------------
+// Typed arithmetic instructions (one per operator and type,
+// so nobody has to look at a type symbol at runtime)
+// --------------------------------------------------------
+package vm
+
+import (
+	"bytespace.network/rerect/bytecode"
+	"bytespace.network/rerect/error"
+)
+
+// Binary operations
+// -----------------
+func (vm *VM) evalBinary(op bytecode.Opcode, left interface{}, right interface{}) interface{} {
+    // go would panic on these with its own messages, so we complain first
+    switch op {
+    case bytecode.OP_DivI64, bytecode.OP_DivI32, bytecode.OP_DivI16, bytecode.OP_DivI8:
+        if integerSign(right) == 0 {
+            vm.throw(error.NewError(error.RNT, vm.position(), "Division by zero!"))
+        }
+
+    case bytecode.OP_ModI64, bytecode.OP_ModI32, bytecode.OP_ModI16, bytecode.OP_ModI8:
+        if integerSign(right) == 0 {
+            vm.throw(error.NewError(error.RNT, vm.position(), "Modulo by zero!"))
+        }
+
+    case bytecode.OP_ShlI64, bytecode.OP_ShlI32, bytecode.OP_ShlI16, bytecode.OP_ShlI8,
+         bytecode.OP_ShrI64, bytecode.OP_ShrI32, bytecode.OP_ShrI16, bytecode.OP_ShrI8:
+        if integerSign(right) < 0 {
+            vm.throw(error.NewError(error.RNT, vm.position(), "Cannot shift by a negative amount! (%v)", right))
+        }
+    }
+
+    switch op {
+
+    // Add
+    // ---
+    case bytecode.OP_AddI64:
+        return left.(int64) + right.(int64)
+    case bytecode.OP_AddI32:
+        return left.(int32) + right.(int32)
+    case bytecode.OP_AddI16:
+        return left.(int16) + right.(int16)
+    case bytecode.OP_AddI8:
+        return left.(int8) + right.(int8)
+    case bytecode.OP_AddF64:
+        return left.(float64) + right.(float64)
+    case bytecode.OP_AddF32:
+        return left.(float32) + right.(float32)
+
+    // Sub
+    // ---
+    case bytecode.OP_SubI64:
+        return left.(int64) - right.(int64)
+    case bytecode.OP_SubI32:
+        return left.(int32) - right.(int32)
+    case bytecode.OP_SubI16:
+        return left.(int16) - right.(int16)
+    case bytecode.OP_SubI8:
+        return left.(int8) - right.(int8)
+    case bytecode.OP_SubF64:
+        return left.(float64) - right.(float64)
+    case bytecode.OP_SubF32:
+        return left.(float32) - right.(float32)
+
+    // Mul
+    // ---
+    case bytecode.OP_MulI64:
+        return left.(int64) * right.(int64)
+    case bytecode.OP_MulI32:
+        return left.(int32) * right.(int32)
+    case bytecode.OP_MulI16:
+        return left.(int16) * right.(int16)
+    case bytecode.OP_MulI8:
+        return left.(int8) * right.(int8)
+    case bytecode.OP_MulF64:
+        return left.(float64) * right.(float64)
+    case bytecode.OP_MulF32:
+        return left.(float32) * right.(float32)
+
+    // Div
+    // ---
+    case bytecode.OP_DivI64:
+        return left.(int64) / right.(int64)
+    case bytecode.OP_DivI32:
+        return left.(int32) / right.(int32)
+    case bytecode.OP_DivI16:
+        return left.(int16) / right.(int16)
+    case bytecode.OP_DivI8:
+        return left.(int8) / right.(int8)
+    case bytecode.OP_DivF64:
+        return left.(float64) / right.(float64)
+    case bytecode.OP_DivF32:
+        return left.(float32) / right.(float32)
+
+    // Less
+    // ----
+    case bytecode.OP_LessI64:
+        return left.(int64) < right.(int64)
+    case bytecode.OP_LessI32:
+        return left.(int32) < right.(int32)
+    case bytecode.OP_LessI16:
+        return left.(int16) < right.(int16)
+    case bytecode.OP_LessI8:
+        return left.(int8) < right.(int8)
+    case bytecode.OP_LessF64:
+        return left.(float64) < right.(float64)
+    case bytecode.OP_LessF32:
+        return left.(float32) < right.(float32)
+
+    // LessEqual
+    // ---------
+    case bytecode.OP_LessEqualI64:
+        return left.(int64) <= right.(int64)
+    case bytecode.OP_LessEqualI32:
+        return left.(int32) <= right.(int32)
+    case bytecode.OP_LessEqualI16:
+        return left.(int16) <= right.(int16)
+    case bytecode.OP_LessEqualI8:
+        return left.(int8) <= right.(int8)
+    case bytecode.OP_LessEqualF64:
+        return left.(float64) <= right.(float64)
+    case bytecode.OP_LessEqualF32:
+        return left.(float32) <= right.(float32)
+
+    // Greater
+    // -------
+    case bytecode.OP_GreaterI64:
+        return left.(int64) > right.(int64)
+    case bytecode.OP_GreaterI32:
+        return left.(int32) > right.(int32)
+    case bytecode.OP_GreaterI16:
+        return left.(int16) > right.(int16)
+    case bytecode.OP_GreaterI8:
+        return left.(int8) > right.(int8)
+    case bytecode.OP_GreaterF64:
+        return left.(float64) > right.(float64)
+    case bytecode.OP_GreaterF32:
+        return left.(float32) > right.(float32)
+
+    // GreaterEqual
+    // ------------
+    case bytecode.OP_GreaterEqualI64:
+        return left.(int64) >= right.(int64)
+    case bytecode.OP_GreaterEqualI32:
+        return left.(int32) >= right.(int32)
+    case bytecode.OP_GreaterEqualI16:
+        return left.(int16) >= right.(int16)
+    case bytecode.OP_GreaterEqualI8:
+        return left.(int8) >= right.(int8)
+    case bytecode.OP_GreaterEqualF64:
+        return left.(float64) >= right.(float64)
+    case bytecode.OP_GreaterEqualF32:
+        return left.(float32) >= right.(float32)
+
+    // Mod
+    // ---
+    case bytecode.OP_ModI64:
+        return left.(int64) % right.(int64)
+    case bytecode.OP_ModI32:
+        return left.(int32) % right.(int32)
+    case bytecode.OP_ModI16:
+        return left.(int16) % right.(int16)
+    case bytecode.OP_ModI8:
+        return left.(int8) % right.(int8)
+
+    // BitAnd
+    // ------
+    case bytecode.OP_BitAndI64:
+        return left.(int64) & right.(int64)
+    case bytecode.OP_BitAndI32:
+        return left.(int32) & right.(int32)
+    case bytecode.OP_BitAndI16:
+        return left.(int16) & right.(int16)
+    case bytecode.OP_BitAndI8:
+        return left.(int8) & right.(int8)
+
+    // BitOr
+    // -----
+    case bytecode.OP_BitOrI64:
+        return left.(int64) | right.(int64)
+    case bytecode.OP_BitOrI32:
+        return left.(int32) | right.(int32)
+    case bytecode.OP_BitOrI16:
+        return left.(int16) | right.(int16)
+    case bytecode.OP_BitOrI8:
+        return left.(int8) | right.(int8)
+
+    // BitXor
+    // ------
+    case bytecode.OP_BitXorI64:
+        return left.(int64) ^ right.(int64)
+    case bytecode.OP_BitXorI32:
+        return left.(int32) ^ right.(int32)
+    case bytecode.OP_BitXorI16:
+        return left.(int16) ^ right.(int16)
+    case bytecode.OP_BitXorI8:
+        return left.(int8) ^ right.(int8)
+
+    // Shl
+    // ---
+    case bytecode.OP_ShlI64:
+        return left.(int64) << right.(int64)
+    case bytecode.OP_ShlI32:
+        return left.(int32) << right.(int32)
+    case bytecode.OP_ShlI16:
+        return left.(int16) << right.(int16)
+    case bytecode.OP_ShlI8:
+        return left.(int8) << right.(int8)
+
+    // Shr
+    // ---
+    case bytecode.OP_ShrI64:
+        return left.(int64) >> right.(int64)
+    case bytecode.OP_ShrI32:
+        return left.(int32) >> right.(int32)
+    case bytecode.OP_ShrI16:
+        return left.(int16) >> right.(int16)
+    case bytecode.OP_ShrI8:
+        return left.(int8) >> right.(int8)
+    }
+
+    vm.throw(error.NewError(error.RNT, vm.position(), "Binary instruction not implemented! You should implement NOW! (%d)", op))
+    return nil
+}
+
+// -1, 0 or 1 depending on the sign of an integer
+func integerSign(val interface{}) int {
+    var v int64
+    switch n := val.(type) {
+    case int64:
+        v = n
+    case int32:
+        v = int64(n)
+    case int16:
+        v = int64(n)
+    case int8:
+        v = int64(n)
+    }
+
+    if v < 0 {
+        return -1
+    } else if v > 0 {
+        return 1
+    }
+
+    return 0
+}
+
+// Unary operations
+// ----------------
+func (vm *VM) evalUnary(op bytecode.Opcode, operand interface{}) interface{} {
+    switch op {
+    case bytecode.OP_NegI64:
+        return -(operand.(int64))
+    case bytecode.OP_NegI32:
+        return -(operand.(int32))
+    case bytecode.OP_NegI16:
+        return -(operand.(int16))
+    case bytecode.OP_NegI8:
+        return -(operand.(int8))
+    case bytecode.OP_NegF64:
+        return -(operand.(float64))
+    case bytecode.OP_NegF32:
+        return -(operand.(float32))
+
+    case bytecode.OP_BitNotI64:
+        return ^(operand.(int64))
+    case bytecode.OP_BitNotI32:
+        return ^(operand.(int32))
+    case bytecode.OP_BitNotI16:
+        return ^(operand.(int16))
+    case bytecode.OP_BitNotI8:
+        return ^(operand.(int8))
+    }
+
+    vm.throw(error.NewError(error.RNT, vm.position(), "Unary instruction not implemented! You should implement NOW! (%d)", op))
+    return nil
+}
@@ -0,0 +1,155 @@
+// VM - exception.go
+// --------------------------------------------------------
+// Everything needed to throw errors around and catch them
+// again (try, catch, throw)
+// --------------------------------------------------------
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	"bytespace.network/rerect/error"
+	evalobjects "bytespace.network/rerect/eval_objects"
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+)
+
+// Exception
+// ---------
+// A ReRect Error on its way up the call stack
+type Exception struct {
+    Instance *evalobjects.ContainerInstance // the Error instance (what catch gets to see)
+    Runtime *RuntimeError                   // what the host gets to see if nobody catches it
+}
+
+// --------------------------------------------------------
+// Catching
+// --------------------------------------------------------
+
+// Find someone to catch an exception (frames above depth only)
+// -------------------------------------------------------------
+func (vm *VM) catch(exc *Exception, depth int) bool {
+    for i := len(vm.Frames) - 1; i >= depth; i-- {
+        frm := vm.Frames[i]
+
+        // the instruction that blew up (or the call that led to it)
+        ip := frm.InstPtr - 1
+
+        // nested regions always come after the ones containing them
+        // -> go backwards to find the innermost one first
+        for j := len(frm.Function.Regions) - 1; j >= 0; j-- {
+            rgn := frm.Function.Regions[j]
+
+            if ip < rgn.Start || ip >= rgn.End {
+                continue
+            }
+
+            // throw away everything that was called from inside the region
+            vm.Frames = vm.Frames[:i+1]
+            vm.Stack = vm.Stack[:frm.StackBase]
+
+            // and continue in the catch block
+            frm.Locals[rgn.ErrorSlot] = exc.Instance
+            frm.InstPtr = rgn.Catch
+
+            return true
+        }
+    }
+
+    // nope -> nobody here wants this
+    return false
+}
+
+// --------------------------------------------------------
+// Errors
+// --------------------------------------------------------
+
+// Throw a ReRect Error
+// --------------------
+func (vm *VM) throwError(val interface{}) *Exception {
+    if val == nil {
+        vm.throw(error.NewError(error.RNT, vm.position(), "Cannot throw null! (I am literally calling the police rn)"))
+    }
+
+    inst := val.(*evalobjects.ContainerInstance)
+    msg := inst.Fields["Message"].(string)
+
+    // remember where this was thrown
+    rt := vm.createRuntimeError(error.NewError(error.RNT, vm.position(), "Uncaught Error: %s", msg))
+    inst.Fields["StackTrace"] = vm.formatTrace(rt)
+
+    return &Exception{
+        Instance: inst,
+        Runtime: rt,
+    }
+}
+
+// Turn anything that was panicked into an exception
+// -------------------------------------------------
+func (vm *VM) toException(r interface{}) *Exception {
+    // already is one
+    if exc, ok := r.(*Exception); ok {
+        return exc
+    }
+
+    // runtime errors (from the vm or from natives)
+    var rt *RuntimeError
+    if err, ok := r.(error.Error); ok {
+        rt = vm.createRuntimeError(err)
+
+    // something in go land blew up (bad conversion, nil pointer, ...)
+    } else {
+        rt = vm.createRuntimeError(error.NewError(error.RNT, span.Internal(), "%v", r))
+    }
+
+    return &Exception{
+        Instance: vm.newError(rt.Error.Message, vm.formatTrace(rt)),
+        Runtime: rt,
+    }
+}
+
+// Create a new instance of the internal Error container
+// -----------------------------------------------------
+func (vm *VM) newError(msg string, trace string) *evalobjects.ContainerInstance {
+    var cnt *symbols.ContainerSymbol
+    for _, v := range vm.Comp.GetPackage("internal").Containers {
+        if v.ContainerName == "Error" {
+            cnt = v
+        }
+    }
+
+    return &evalobjects.ContainerInstance{
+        Type: cnt.ContainerType,
+        Fields: map[string]interface{}{
+            "Message": msg,
+            "StackTrace": trace,
+        },
+    }
+}
+
+// Format a stack trace for humans
+// -------------------------------
+func (vm *VM) formatTrace(rt *RuntimeError) string {
+    lines := []string{}
+
+    for _, frm := range rt.Error.Trace {
+        if frm.Position.Internal {
+            lines = append(lines, fmt.Sprintf("at %s", frm.Function))
+            continue
+        }
+
+        // (this runs while an error is being handled, so it really shouldnt blow up itself)
+        if frm.Position.File < 0 || frm.Position.File >= len(vm.Comp.SourceFiles) {
+            lines = append(lines, fmt.Sprintf("at %s (<unknown>)", frm.Function))
+            continue
+        }
+
+        src := vm.Comp.SourceFiles[frm.Position.File]
+        line, col := frm.Position.GetLineAndCol(src.Content)
+
+        lines = append(lines, fmt.Sprintf("at %s (%s, L:%d, C:%d)", frm.Function, src.Path, line, col))
+    }
+
+    return strings.Join(lines, "\n")
+}
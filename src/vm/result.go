@@ -0,0 +1,33 @@
+// VM - result.go
+// --------------------------------------------------------
+// What running a program leaves behind
+// --------------------------------------------------------
+package vm
+
+import (
+	"bytespace.network/rerect/error"
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+)
+
+// Evaluation result
+// -----------------
+type EvaluationResult struct {
+    Value interface{}      // whatever the entry function returned
+    ExitCode int           // 0 unless die() was called or something went wrong
+    Error *RuntimeError    // nil if everything went fine
+}
+
+// Runtime error
+// -------------
+type RuntimeError struct {
+    Error error.Error
+    CallStack []CallStackEntry // outermost call first
+}
+
+// One function on the call stack and where it was at
+// --------------------------------------------------
+type CallStackEntry struct {
+    Function *symbols.FunctionSymbol
+    Position span.Span
+}
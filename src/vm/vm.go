@@ -0,0 +1,840 @@
+// VM - vm.go
+// --------------------------------------------------------
+// Finally, the very last step: running the program
+// (a small stack machine executing the compiled bytecode)
+// --------------------------------------------------------
+package vm
+
+import (
+	"fmt"
+	"reflect"
+
+	"bytespace.network/rerect/bytecode"
+	"bytespace.network/rerect/compunit"
+	"bytespace.network/rerect/error"
+	evalobjects "bytespace.network/rerect/eval_objects"
+	"bytespace.network/rerect/span"
+	"bytespace.network/rerect/symbols"
+)
+
+// How deep calls can nest before we call it a stack overflow
+const maxFrames = 100000
+
+// How many frames of a (long) stack trace are worth showing
+const traceHead = 32  // innermost
+const traceTail = 8   // outermost
+
+type VM struct {
+    Comp *compunit.Compilation
+    Program *bytecode.Program
+
+    Globals []interface{}   // one slot per program global
+    Stack []interface{}     // operand stack (shared by all frames)
+    Frames []*Frame
+}
+
+type Frame struct {
+    Function *bytecode.Function
+
+    InstPtr int
+    Locals []interface{}
+    This interface{}
+
+    StackBase int           // where this frames part of the operand stack starts
+}
+
+// Captured variables live in cells, so the function that declared them
+// and every lambda that captured them see the same value
+type Cell struct {
+    Value interface{}
+}
+
+// --------------------------------------------------------
+// Helpers
+// --------------------------------------------------------
+func (vm *VM) push(val interface{}) {
+    vm.Stack = append(vm.Stack, val)
+}
+
+func (vm *VM) pop() interface{} {
+    val := vm.Stack[len(vm.Stack)-1]
+    vm.Stack = vm.Stack[:len(vm.Stack)-1]
+
+    return val
+}
+
+func (vm *VM) top() interface{} {
+    return vm.Stack[len(vm.Stack)-1]
+}
+
+// Take the top n values off the stack (in the order they were pushed)
+func (vm *VM) popArgs(n int) []interface{} {
+    args := make([]interface{}, n)
+    copy(args, vm.Stack[len(vm.Stack)-n:])
+    vm.Stack = vm.Stack[:len(vm.Stack)-n]
+
+    return args
+}
+
+// Name of a function like it would be written down (pkg::name or pkg::Type->name)
+func functionName(fnc *symbols.FunctionSymbol) string {
+    if fnc.FunctionKind == symbols.FT_METH {
+        return fmt.Sprintf("%s::%s->%s", fnc.ParentPackage.Name(), fnc.MethodSource.Name(), fnc.FuncName)
+    }
+
+    return fmt.Sprintf("%s::%s", fnc.ParentPackage.Name(), fnc.FuncName)
+}
+
+// Where in the source a frame currently is
+func (frm *Frame) position() span.Span {
+    if frm.InstPtr == 0 {
+        return span.Internal()
+    }
+
+    return frm.Function.Spans[frm.InstPtr-1]
+}
+
+// Where in the source we currently are
+func (vm *VM) position() span.Span {
+    if len(vm.Frames) == 0 {
+        return span.Internal()
+    }
+
+    return vm.Frames[len(vm.Frames)-1].position()
+}
+
+// --------------------------------------------------------
+// Evaluation
+// --------------------------------------------------------
+func Evaluate(comp *compunit.Compilation, prg *bytecode.Program) *EvaluationResult {
+    // create a new vm
+    vm := NewVM(comp, prg)
+    vm.Load()
+
+    // look for a "main()" function in a "main" package
+    var main *symbols.FunctionSymbol = nil
+    for _, fnc := range prg.Functions {
+        if fnc.Symbol.FuncName == "main" && fnc.Symbol.ParentPackage.Name() == "main" {
+            main = fnc.Symbol
+            break
+        }
+    }
+
+    // no entry point found
+    if main == nil {
+        return &EvaluationResult{
+            ExitCode: -1,
+            Error: &RuntimeError{
+                Error: error.NewError(error.RNT, span.Internal(), "Could not find 'main()' function! An entry point is needed for execution."),
+            },
+        }
+    }
+
+    // otherwise -> run main function
+    return vm.Run(main)
+}
+
+// Create an empty vm for a program
+// --------------------------------
+func NewVM(comp *compunit.Compilation, prg *bytecode.Program) *VM {
+    return &VM{
+        Comp: comp,
+        Program: prg,
+        Globals: make([]interface{}, 0),
+        Stack: make([]interface{}, 0, 256),
+        Frames: make([]*Frame, 0),
+    }
+}
+
+// Create all globals the program has gained since the last load
+// (can be done multiple times, the REPL keeps adding stuff as it goes)
+// --------------------------------------------------------------------
+func (vm *VM) Load() {
+    // globals we already know keep their value
+    for i := len(vm.Globals); i < len(vm.Program.Globals); i++ {
+        // initialize with default value for each datatype
+        vm.Globals = append(vm.Globals, getDefault(vm.Program.Globals[i].VarType()))
+    }
+}
+
+// Run a parameterless function and hand back whatever it returned
+// ---------------------------------------------------------------
+func (vm *VM) Run(sym *symbols.FunctionSymbol) (res *EvaluationResult) {
+    res = &EvaluationResult{}
+
+    // runtime errors and die() unwind everything up to here
+    defer func() {
+        r := recover()
+        if r == nil {
+            return
+        }
+
+        if exit, ok := r.(evalobjects.ExitSignal); ok {
+            res.ExitCode = exit.Code
+
+        } else {
+            // errors nobody caught (and whatever blew up in go land)
+            // -> still dont take the host down with us
+            res.ExitCode = -1
+            res.Error = vm.toException(r).Runtime
+        }
+
+        // everything that was running is gone now
+        vm.Frames = vm.Frames[:0]
+        vm.Stack = vm.Stack[:0]
+    }()
+
+    fnc := vm.Program.Function(sym)
+    if fnc == nil || fnc.Code == nil {
+        vm.throw(error.NewError(error.RNT, span.Internal(), "Function '%s' has never been compiled!", functionName(sym)))
+    }
+
+    vm.enter(fnc, nil, 0)
+    res.Value = vm.execute(0)
+
+    return res
+}
+
+// Stop execution with a runtime error
+// -----------------------------------
+func (vm *VM) throw(err error.Error) {
+    panic(err)
+}
+
+// Bundle an error together with the current call stack
+// ----------------------------------------------------
+func (vm *VM) createRuntimeError(err error.Error) *RuntimeError {
+    stack := []CallStackEntry{}
+    for _, frm := range vm.Frames {
+        stack = append(stack, CallStackEntry{
+            Function: frm.Function.Symbol,
+            Position: frm.position(),
+        })
+    }
+
+    // errors without a position happened wherever we were last
+    if err.Position.Internal && len(stack) > 0 {
+        err.Position = stack[len(stack)-1].Position
+    }
+
+    // build a printable trace (innermost call first)
+    err.Trace = []error.TraceFrame{}
+    for i := len(stack)-1; i >= 0; i-- {
+        // nobody wants to read a hundred thousand frames of recursion
+        // -> only keep the innermost and outermost ones
+        skipped := len(stack) - traceHead - traceTail
+        if skipped > 0 && i == len(stack) - traceHead - 1 {
+            err.Trace = append(err.Trace, error.TraceFrame{
+                Function: fmt.Sprintf("... (%d more calls)", skipped),
+                Position: span.Internal(),
+            })
+
+            i -= skipped - 1
+            continue
+        }
+
+        err.Trace = append(err.Trace, error.TraceFrame{
+            Function: functionName(stack[i].Function),
+            Position: stack[i].Position,
+        })
+    }
+
+    return &RuntimeError{
+        Error: err,
+        CallStack: stack,
+    }
+}
+
+// --------------------------------------------------------
+// Calls
+// --------------------------------------------------------
+
+// Create a frame for a function, its arguments are the top argc values
+// --------------------------------------------------------------------
+func (vm *VM) enter(fnc *bytecode.Function, this interface{}, argc int) *Frame {
+    if fnc.Code == nil {
+        vm.throw(error.NewError(error.RNT, span.Internal(), "Someone fucked up the runtime function lookup :) (%s)", functionName(fnc.Symbol)))
+    }
+
+    // frames live on the heap, so nothing else is going to stop infinite recursion
+    if len(vm.Frames) >= maxFrames {
+        vm.throw(error.NewError(error.RNT, vm.position(), "Stack overflow! (more than %d nested calls)", maxFrames))
+    }
+
+    frm := &Frame{
+        Function: fnc,
+        Locals: make([]interface{}, fnc.LocalCount),
+        This: this,
+    }
+
+    // parameters live in the first slots
+    copy(frm.Locals, vm.Stack[len(vm.Stack)-argc:])
+    vm.Stack = vm.Stack[:len(vm.Stack)-argc]
+
+    frm.StackBase = len(vm.Stack)
+    vm.Frames = append(vm.Frames, frm)
+
+    return frm
+}
+
+// Call a method, the instance sits right below the arguments
+// ----------------------------------------------------------
+func (vm *VM) callMethod(fnc *bytecode.Function, argc int) *Frame {
+    instance := vm.Stack[len(vm.Stack)-argc-1]
+
+    // if this is null -> we're doomed
+    if instance == nil {
+        vm.throw(error.NewError(error.RNT, vm.position(), "Cannot call method on null! (I am literally calling the police rn)"))
+    }
+
+    // is this a virtual call?
+    // (like a trait method being called on a container)
+    sym := fnc.Symbol
+    if sym.NeedsVirtualCallToTrait {
+        // redirect
+        sym = sym.TraitSourceMethod
+    }
+
+    if sym.NeedsVirtualCallToContainer {
+        // redirect
+        sym = vm.resolveVirtualMethod(sym, instance)
+
+        if sym == nil {
+            vm.throw(error.NewError(error.RNT, vm.position(), "Something has gone horribly wrong! (could not resolve container method implementation for virtual call from trait)"))
+        }
+    }
+
+    if sym != fnc.Symbol {
+        fnc = vm.Program.Function(sym)
+
+        if fnc == nil {
+            vm.throw(error.NewError(error.RNT, vm.position(), "Someone fucked up the runtime function lookup :) (%s)", functionName(sym)))
+        }
+    }
+
+    // take the instance out from under the arguments
+    copy(vm.Stack[len(vm.Stack)-argc-1:], vm.Stack[len(vm.Stack)-argc:])
+    vm.Stack = vm.Stack[:len(vm.Stack)-1]
+
+    return vm.enter(fnc, instance, argc)
+}
+
+// Function values
+// ---------------
+func (vm *VM) makeFunction(fnc *bytecode.Function, captures []interface{}) *evalobjects.FunctionInstance {
+    return &evalobjects.FunctionInstance{
+        Type: symbols.FunctionTypeOf(fnc.Symbol),
+        Captures: captures,
+        Function: fnc.Symbol,
+
+        // natives calling us back (arr->Map(...)) get a whole new round of execution
+        Invoke: func(fn *evalobjects.FunctionInstance, args []interface{}) interface{} {
+            depth := len(vm.Frames)
+
+            vm.Stack = append(append(vm.Stack, fn.Captures...), args...)
+            vm.enter(fnc, nil, len(fn.Captures) + len(args))
+
+            return vm.execute(depth)
+        },
+    }
+}
+
+func makeNativeFunction(sym *symbols.FunctionSymbol) *evalobjects.FunctionInstance {
+    return &evalobjects.FunctionInstance{
+        Type: symbols.FunctionTypeOf(sym),
+        Function: sym,
+
+        Invoke: func(fn *evalobjects.FunctionInstance, args []interface{}) interface{} {
+            return sym.FunctionPointer(args)
+        },
+    }
+}
+
+// Virtual method lookup
+// ---------------------
+func (vm *VM) resolveVirtualMethod(fnc *symbols.FunctionSymbol, instance interface{}) *symbols.FunctionSymbol {
+    // the instance needs to be a container, anything else would honestly not make much sense
+    cnt := instance.(*evalobjects.ContainerInstance)
+
+    // look for the method
+    for _, v := range cnt.Type.Container.Methods {
+        if v.Name() == fnc.Name() {
+            return v
+        }
+    }
+
+    // aw man we're fucked
+    return nil
+}
+
+// --------------------------------------------------------
+// Execution
+// --------------------------------------------------------
+
+// Run until the frame count drops back down to depth
+// --------------------------------------------------
+func (vm *VM) execute(depth int) interface{} {
+    for {
+        val, exc := vm.loop(depth)
+
+        // everything went fine
+        if exc == nil {
+            return val
+        }
+
+        // is anyone here to catch this?
+        if !vm.catch(exc, depth) {
+            // nope -> on to whoever started us
+            panic(exc)
+        }
+
+        // yes -> keep going in the catch block
+    }
+}
+
+// The actual instruction loop (errors come back out as exceptions)
+// ----------------------------------------------------------------
+func (vm *VM) loop(depth int) (val interface{}, exc *Exception) {
+    defer func() {
+        r := recover()
+        if r == nil {
+            return
+        }
+
+        // die() is not an error, nobody gets to catch that
+        if _, ok := r.(evalobjects.ExitSignal); ok {
+            panic(r)
+        }
+
+        // turn whatever happened into an Error (while the call stack is still intact)
+        exc = vm.toException(r)
+    }()
+
+    prg := vm.Program
+    frm := vm.Frames[len(vm.Frames)-1]
+    code := frm.Function.Code
+
+    for {
+        ins := code[frm.InstPtr]
+        frm.InstPtr++
+
+        switch ins.Op {
+        case bytecode.OP_Nop:
+            // literally do nothing
+
+        // Stack
+        // -----
+        case bytecode.OP_Pop:
+            vm.Stack = vm.Stack[:len(vm.Stack)-1]
+
+        case bytecode.OP_Dup:
+            vm.push(vm.top())
+
+        case bytecode.OP_Const:
+            vm.push(prg.Constants[ins.A])
+
+        case bytecode.OP_Null:
+            vm.push(nil)
+
+        case bytecode.OP_Default:
+            vm.push(getDefault(prg.Types[ins.A]))
+
+        // Variables
+        // ---------
+        case bytecode.OP_LoadLocal:
+            vm.push(frm.Locals[ins.A])
+
+        case bytecode.OP_StoreLocal:
+            frm.Locals[ins.A] = vm.pop()
+
+        case bytecode.OP_LoadGlobal:
+            vm.push(vm.Globals[ins.A])
+
+        case bytecode.OP_StoreGlobal:
+            vm.Globals[ins.A] = vm.pop()
+
+        case bytecode.OP_LoadThis:
+            vm.push(frm.This)
+
+        case bytecode.OP_LoadField:
+            src := vm.pop()
+
+            // if this is null -> we're doomed
+            if src == nil {
+                vm.throw(error.NewError(error.RNT, vm.position(), "Cannot access field on null! (I am literally calling the police rn)"))
+            }
+
+            vm.push(src.(*evalobjects.ContainerInstance).Fields[prg.Constants[ins.A].(string)])
+
+        case bytecode.OP_StoreField:
+            src := vm.pop()
+
+            // if this is null -> we're doomed
+            if src == nil {
+                vm.throw(error.NewError(error.RNT, vm.position(), "Cannot assign field on null! (I am literally calling the police rn)"))
+            }
+
+            src.(*evalobjects.ContainerInstance).Fields[prg.Constants[ins.A].(string)] = vm.top()
+
+        case bytecode.OP_InitField:
+            val := vm.pop()
+            vm.top().(*evalobjects.ContainerInstance).Fields[prg.Constants[ins.A].(string)] = val
+
+        case bytecode.OP_LoadIndex:
+            idx := vm.popIndex()
+            src := vm.popArray()
+
+            vm.checkBounds(src, idx)
+            vm.push(src.Elements[idx])
+
+        case bytecode.OP_StoreIndex:
+            idx := vm.popIndex()
+            src := vm.popArray()
+
+            vm.checkBounds(src, idx)
+            src.Elements[idx] = vm.top()
+
+        case bytecode.OP_LoadKey:
+            key := vm.pop()
+            src := vm.popMap()
+
+            vm.checkKey(src, key)
+            vm.push(src.Elements[key])
+
+        case bytecode.OP_StoreKey:
+            key := vm.pop()
+            src := vm.popMap()
+
+            src.Set(key, vm.top())
+
+        // Control flow
+        // ------------
+        case bytecode.OP_Jump:
+            frm.InstPtr = int(ins.A)
+
+        case bytecode.OP_JumpIf:
+            if vm.pop() == true {
+                frm.InstPtr = int(ins.A)
+            }
+
+        case bytecode.OP_Return:
+            ret := vm.pop()
+
+            // destroy the frame
+            vm.Stack = vm.Stack[:frm.StackBase]
+            vm.Frames = vm.Frames[:len(vm.Frames)-1]
+
+            // are we done?
+            if len(vm.Frames) == depth {
+                return ret, nil
+            }
+
+            // otherwise -> back to the caller
+            vm.push(ret)
+
+            frm = vm.Frames[len(vm.Frames)-1]
+            code = frm.Function.Code
+
+        case bytecode.OP_Throw:
+            return nil, vm.throwError(vm.pop())
+
+        case bytecode.OP_Call:
+            frm = vm.enter(prg.Functions[ins.A], nil, int(ins.B))
+            code = frm.Function.Code
+
+        case bytecode.OP_CallNative:
+            args := vm.popArgs(int(ins.B))
+            vm.push(prg.Natives[ins.A].FunctionPointer(args))
+
+        case bytecode.OP_CallMethod:
+            frm = vm.callMethod(prg.Functions[ins.A], int(ins.B))
+            code = frm.Function.Code
+
+        case bytecode.OP_CallNativeMethod:
+            args := vm.popArgs(int(ins.B))
+            instance := vm.pop()
+
+            // if this is null -> we're doomed
+            if instance == nil {
+                vm.throw(error.NewError(error.RNT, vm.position(), "Cannot call method on null! (I am literally calling the police rn)"))
+            }
+
+            vm.push(prg.Natives[ins.A].MethodPointer(instance, args))
+
+        // Objects
+        // -------
+        case bytecode.OP_Make:
+            vm.push(makeInstance(prg.Containers[ins.A], prg.Types[ins.B]))
+
+        case bytecode.OP_MakeArray:
+            vm.push(makeArray(prg.Types[ins.A], vm.pop().(int32)))
+
+        case bytecode.OP_MakeArrayFrom:
+            vm.push(&evalobjects.ArrayInstance{
+                Type: prg.Types[ins.A],
+                Elements: vm.popArgs(int(ins.B)),
+            })
+
+        case bytecode.OP_MakeMap:
+            entries := vm.popArgs(int(ins.B))
+            mp := evalobjects.NewMapInstance(prg.Types[ins.A])
+
+            for i := 0; i < len(entries); i += 2 {
+                mp.Set(entries[i], entries[i+1])
+            }
+
+            vm.push(mp)
+
+        case bytecode.OP_Convert:
+            vm.push(vm.convert(vm.pop(), prg.Types[ins.A]))
+
+        case bytecode.OP_EnumName:
+            val := vm.pop()
+            name, ok := evalobjects.EnumName(val, prg.Types[ins.A])
+            if !ok {
+                vm.throw(error.NewError(error.RNT, vm.position(), "Value %v is not a member of enum %s!", val, prg.Types[ins.A].Name()))
+            }
+
+            vm.push(name)
+
+        case bytecode.OP_Is:
+            vm.push(evalobjects.EvalTypeCheck(vm.pop(), prg.Types[ins.A]))
+
+        case bytecode.OP_As:
+            val := vm.pop()
+
+            // no luck -> null
+            if !evalobjects.EvalTypeCheck(val, prg.Types[ins.A]) {
+                val = nil
+            }
+
+            vm.push(val)
+
+        case bytecode.OP_ApproachLocal:
+            frm.Locals[ins.A] = approach(frm.Locals[ins.A].(int32), vm.pop().(int32))
+
+        // Functions
+        // ---------
+        case bytecode.OP_MakeCell:
+            frm.Locals[ins.A] = &Cell{Value: vm.pop()}
+
+        case bytecode.OP_LoadCell:
+            vm.push(frm.Locals[ins.A].(*Cell).Value)
+
+        case bytecode.OP_StoreCell:
+            frm.Locals[ins.A].(*Cell).Value = vm.pop()
+
+        case bytecode.OP_ApproachCell:
+            cell := frm.Locals[ins.A].(*Cell)
+            cell.Value = approach(cell.Value.(int32), vm.pop().(int32))
+
+        case bytecode.OP_MakeFunction:
+            vm.push(vm.makeFunction(prg.Functions[ins.A], vm.popArgs(int(ins.B))))
+
+        case bytecode.OP_MakeNativeFunction:
+            vm.push(makeNativeFunction(prg.Natives[ins.A]))
+
+        case bytecode.OP_CallValue:
+            args := vm.popArgs(int(ins.B))
+            fn, _ := vm.pop().(*evalobjects.FunctionInstance)
+
+            // if this is null -> we're doomed
+            if fn == nil {
+                vm.throw(error.NewError(error.RNT, vm.position(), "Cannot call a null function! (I am literally calling the police rn)"))
+            }
+
+            // our own functions get called right here
+            if callee := vm.Program.Function(fn.Function); callee != nil {
+                vm.Stack = append(append(vm.Stack, fn.Captures...), args...)
+                frm = vm.enter(callee, nil, len(fn.Captures) + len(args))
+                code = frm.Function.Code
+                break
+            }
+
+            vm.push(fn.Invoke(fn, args))
+
+        // Logic
+        // -----
+        case bytecode.OP_Equal:
+            right := vm.pop()
+            vm.Stack[len(vm.Stack)-1] = vm.top() == right
+
+        case bytecode.OP_Unequal:
+            right := vm.pop()
+            vm.Stack[len(vm.Stack)-1] = vm.top() != right
+
+        case bytecode.OP_Not:
+            vm.Stack[len(vm.Stack)-1] = !vm.top().(bool)
+
+        case bytecode.OP_And:
+            right := vm.pop().(bool)
+            vm.Stack[len(vm.Stack)-1] = vm.top().(bool) && right
+
+        case bytecode.OP_Or:
+            right := vm.pop().(bool)
+            vm.Stack[len(vm.Stack)-1] = vm.top().(bool) || right
+
+        case bytecode.OP_Concat:
+            right := vm.pop().(string)
+            vm.Stack[len(vm.Stack)-1] = vm.top().(string) + right
+
+        // Typed arithmetic
+        // ----------------
+        default:
+            if ins.Op >= bytecode.OP_AddI64 && ins.Op < bytecode.OP_NegI64 {
+                right := vm.pop()
+                vm.Stack[len(vm.Stack)-1] = vm.evalBinary(ins.Op, vm.top(), right)
+
+            } else if ins.Op >= bytecode.OP_NegI64 && ins.Op < bytecode.OP_Count {
+                vm.Stack[len(vm.Stack)-1] = vm.evalUnary(ins.Op, vm.top())
+
+            } else {
+                vm.throw(error.NewError(error.RNT, vm.position(), "Instruction not implemented! You should implement NOW! (%d)", ins.Op))
+            }
+        }
+    }
+}
+
+// --------------------------------------------------------
+// Objects
+// --------------------------------------------------------
+// (the binder makes sure these always fit, but broken bytecode shouldnt take the host down)
+func (vm *VM) popIndex() int32 {
+    val := vm.pop()
+
+    idx, ok := val.(int32)
+    if !ok {
+        vm.throw(error.NewError(error.RNT, vm.position(), "Array index has to be an int, got %s!", describeValue(val)))
+    }
+
+    return idx
+}
+
+func (vm *VM) popArray() *evalobjects.ArrayInstance {
+    val := vm.pop()
+
+    arr, ok := val.(*evalobjects.ArrayInstance)
+    if !ok || arr == nil {
+        vm.throw(error.NewError(error.RNT, vm.position(), "Cannot index into %s, its not an array!", describeValue(val)))
+    }
+
+    return arr
+}
+
+func (vm *VM) popMap() *evalobjects.MapInstance {
+    val := vm.pop()
+
+    mp, ok := val.(*evalobjects.MapInstance)
+    if !ok || mp == nil {
+        vm.throw(error.NewError(error.RNT, vm.position(), "Cannot look up keys in %s, its not a map!", describeValue(val)))
+    }
+
+    return mp
+}
+
+// What is this? (for error messages)
+func describeValue(val interface{}) string {
+    if val == nil {
+        return "null"
+    }
+
+    return reflect.TypeOf(val).String()
+}
+
+func (vm *VM) checkBounds(src *evalobjects.ArrayInstance, idx int32) {
+    if idx < 0 || idx >= int32(len(src.Elements)) {
+        vm.throw(error.NewError(error.RNT, vm.position(), "Index out of bounds! (index: %d, length of array: %d)", idx, len(src.Elements)))
+    }
+}
+
+func (vm *VM) checkKey(src *evalobjects.MapInstance, key interface{}) {
+    if !src.Has(key) {
+        vm.throw(error.NewError(error.RNT, vm.position(), "Key '%v' does not exist in map of type '%s'!", key, src.Type.Name()))
+    }
+}
+
+func (vm *VM) convert(val interface{}, typ *symbols.TypeSymbol) interface{} {
+    res, ok := evalobjects.EvalConversion(val, typ)
+    if ok {
+        return res
+    }
+
+    // null has no go type to show
+    if val == nil {
+        vm.throw(error.NewError(error.RNT, vm.position(), "Unable to cast null to %s!", typ.Name()))
+        return nil
+    }
+
+    // provide some more helpful error messages for containers
+    if cnt, ok := val.(*evalobjects.ContainerInstance); ok {
+        vm.throw(error.NewError(error.RNT, vm.position(), "Unable to cast container instance of type %s to %s!", cnt.Type.Name(), typ.Name()))
+    }
+
+    // ...and enums
+    if typ.TypeGroup == symbols.ENUM {
+        vm.throw(error.NewError(error.RNT, vm.position(), "Value %v is not a member of enum %s!", val, typ.Name()))
+    }
+
+    vm.throw(error.NewError(error.RNT, vm.position(), "Unable to cast %s to %s!", reflect.TypeOf(val), typ.Name()))
+    return nil
+}
+
+func makeInstance(cnt *symbols.ContainerSymbol, typ *symbols.TypeSymbol) *evalobjects.ContainerInstance {
+    instance := &evalobjects.ContainerInstance {
+        Type: typ,
+        Fields: make(map[string]interface{}),
+    }
+
+    // create all fields
+    // (generic fields get the default of whatever their type argument is)
+    for _, v := range cnt.Fields {
+        instance.Fields[v.FieldName] = getDefault(symbols.Substitute(v.FieldType, cnt.TypeParameters, typ.SubTypes))
+    }
+
+    return instance
+}
+
+func makeArray(typ *symbols.TypeSymbol, length int32) *evalobjects.ArrayInstance {
+    arr := &evalobjects.ArrayInstance {
+        Type: typ,
+        Elements: make([]interface{}, 0),
+    }
+
+    // fill the array with default values
+    for i := int32(0); i < length; i++ {
+        arr.Elements = append(arr.Elements, getDefault(typ.SubTypes[0]))
+    }
+
+    return arr
+}
+
+func getDefault(typ *symbols.TypeSymbol) interface{} {
+    // Arrays need some special care because theyre reference types
+    if typ.TypeGroup == symbols.ARR {
+        return &evalobjects.ArrayInstance{
+            Type: typ,
+            Elements: make([]interface{}, 0),
+        }
+    }
+
+    // same goes for maps
+    if typ.TypeGroup == symbols.MAP {
+        return evalobjects.NewMapInstance(typ)
+    }
+
+    // otherwise: return the predefined default
+    return typ.Default
+}
+
+// One step of a from-to loop
+func approach(iter int32, target int32) int32 {
+    if iter < target {
+        return iter + 1
+    }
+
+    if iter > target {
+        return iter - 1
+    }
+
+    return iter
+}